@@ -17,8 +17,10 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"flag"
+	"fmt"
 	"os"
 	"path/filepath"
 	"time"
@@ -27,22 +29,45 @@ import (
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlconfig "sigs.k8s.io/controller-runtime/pkg/client/config"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
+	"github.com/matanbaruch/configmap-rs-operator/internal/admin"
+	"github.com/matanbaruch/configmap-rs-operator/internal/capabilities"
+	"github.com/matanbaruch/configmap-rs-operator/internal/cli"
 	"github.com/matanbaruch/configmap-rs-operator/internal/config"
 	"github.com/matanbaruch/configmap-rs-operator/internal/controller"
+	"github.com/matanbaruch/configmap-rs-operator/internal/leadership"
+	"github.com/matanbaruch/configmap-rs-operator/internal/pause"
+	"github.com/matanbaruch/configmap-rs-operator/internal/preflight"
+	"github.com/matanbaruch/configmap-rs-operator/internal/pushgateway"
+	"github.com/matanbaruch/configmap-rs-operator/internal/schedule"
+	"github.com/matanbaruch/configmap-rs-operator/internal/state"
+	"github.com/matanbaruch/configmap-rs-operator/internal/tenant"
+	"github.com/matanbaruch/configmap-rs-operator/internal/webhookcert"
 	// +kubebuilder:scaffold:imports
 )
 
+// leaderElectionID is the name of the leader election lease
+const leaderElectionID = "77b0221c.github.com"
+
 var (
 	scheme   = runtime.NewScheme()
 	setupLog = ctrl.Log.WithName("setup")
@@ -56,16 +81,33 @@ func init() {
 
 // nolint:gocyclo // main function needs complex setup logic
 func main() {
+	// Dispatch one-shot subcommands (install, uninstall, ...) before the manager flags are registered
+	if len(os.Args) > 1 {
+		if cmd, ok := cli.Lookup(os.Args[1]); ok {
+			ctrl.SetLogger(zap.New())
+			if err := cmd.Run(ctrl.SetupSignalHandler(), os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", cmd.Name, err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
 	// Initialize operator configuration (this must be done before flag.Parse())
 	operatorConfig := config.NewConfig()
 
 	var metricsAddr string
 	var metricsCertPath, metricsCertName, metricsCertKey string
 	var webhookCertPath, webhookCertName, webhookCertKey string
+	var webhookCertMinValidity time.Duration
+	var webhookConfigurations string
 	var enableLeaderElection bool
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var kubeContext string
+	var once bool
+	var recordFile string
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -78,23 +120,55 @@ func main() {
 	flag.StringVar(&webhookCertPath, "webhook-cert-path", "", "The directory that contains the webhook certificate.")
 	flag.StringVar(&webhookCertName, "webhook-cert-name", "tls.crt", "The name of the webhook certificate file.")
 	flag.StringVar(&webhookCertKey, "webhook-cert-key", "tls.key", "The name of the webhook key file.")
+	flag.DurationVar(&webhookCertMinValidity, "webhook-cert-min-validity", 72*time.Hour,
+		"The healthz check fails when the webhook certificate expires sooner than this.")
+	flag.StringVar(&webhookConfigurations, "webhook-configurations", "",
+		"Comma-separated webhook configurations (validating/<name> or mutating/<name>) whose CA bundle "+
+			"must match the webhook certificate.")
 	flag.StringVar(&metricsCertPath, "metrics-cert-path", "",
 		"The directory that contains the metrics server certificate.")
 	flag.StringVar(&metricsCertName, "metrics-cert-name", "tls.crt", "The name of the metrics server certificate file.")
 	flag.StringVar(&metricsCertKey, "metrics-cert-key", "tls.key", "The name of the metrics server key file.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.StringVar(&kubeContext, "context", "", "The kubeconfig context to use when running out-of-cluster.")
+	flag.BoolVar(&once, "once", false,
+		"Reconcile every existing ReplicaSet in scope a single time and exit, instead of running the manager.")
+	flag.StringVar(&recordFile, "record", "",
+		"Append every reconcile with the objects it read and its decisions to this file, for the replay subcommand.")
 
 	opts := zap.Options{
 		Development: true,
 	}
 	opts.BindFlags(flag.CommandLine)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage of %s [subcommand]:\n", os.Args[0])
+		flag.PrintDefaults()
+		cli.PrintUsage(flag.CommandLine.Output())
+	}
 	flag.Parse()
 	// Finalize operator configuration after all flags are parsed
 	operatorConfig.FinalizeConfig()
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	if err := controller.ValidateMode(operatorConfig.Mode); err != nil {
+		setupLog.Error(err, "invalid mode")
+		os.Exit(1)
+	}
+	if err := controller.ValidateEventTypes(operatorConfig.EventTypes); err != nil {
+		setupLog.Error(err, "invalid Event types")
+		os.Exit(1)
+	}
+	servesWebhooks := controller.ServesWebhooks(operatorConfig.Mode)
+	if !servesWebhooks {
+		webhookCertPath = ""
+	}
+	if !controller.RunsControllers(operatorConfig.Mode) {
+		// Every webhook replica serves admission, and none may take the lease of the controllers
+		enableLeaderElection = false
+	}
+
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
 	// prevent from being vulnerable to the HTTP/2 Stream Cancellation and
@@ -135,9 +209,13 @@ func main() {
 		})
 	}
 
-	webhookServer := webhook.NewServer(webhook.Options{
-		TLSOpts: webhookTLSOpts,
-	})
+	// Without a webhook server of its own, the manager only creates one if a webhook is registered
+	var webhookServer webhook.Server
+	if servesWebhooks {
+		webhookServer = webhook.NewServer(webhook.Options{
+			TLSOpts: webhookTLSOpts,
+		})
+	}
 
 	// Metrics endpoint is enabled in 'config/default/kustomization.yaml'. The Metrics options configure the server.
 	// More info:
@@ -184,13 +262,165 @@ func main() {
 		})
 	}
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+	restConfig, err := ctrlconfig.GetConfigWithContext(kubeContext)
+	if err != nil {
+		setupLog.Error(err, "unable to load kubeconfig", "context", kubeContext)
+		os.Exit(1)
+	}
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		setupLog.Error(err, "unable to create clientset")
+		os.Exit(1)
+	}
+
+	// Adapt the enabled features to the APIs the cluster serves, instead of failing at runtime on older clusters
+	caps, err := capabilities.Detect(clientset.Discovery())
+	if err != nil {
+		setupLog.Error(err, "unable to detect cluster capabilities, assuming every feature is supported")
+		caps = capabilities.All()
+	} else {
+		setupLog.Info("detected cluster capabilities", caps.Summary()...)
+	}
+	for _, change := range caps.Degrade(operatorConfig) {
+		setupLog.Info("feature degraded", "reason", change)
+	}
+	if enableLeaderElection && !caps.Leases {
+		setupLog.Error(nil, "leader election requires coordination.k8s.io/v1 Leases, which the cluster does not serve; "+
+			"run a single replica without --leader-elect")
+		os.Exit(1)
+	}
+	if webhookConfigurations != "" && !caps.WebhookConfigurations {
+		setupLog.Info("feature degraded",
+			"reason", "webhook CA bundle check disabled: the cluster does not serve admissionregistration.k8s.io/v1")
+		webhookConfigurations = ""
+	}
+
+	// Verify the permissions of the enabled features up front; missing ones degrade the operator
+	switch {
+	case !controller.RunsControllers(operatorConfig.Mode):
+		// The permissions checked are those of the controllers, which run in another deployment
+	case caps.AccessReviews:
+		preflight.Run(context.Background(), clientset, operatorConfig, ctrl.Log.WithName("preflight"))
+	default:
+		setupLog.Info("feature degraded",
+			"reason", "RBAC preflight check skipped: the cluster does not serve SelfSubjectAccessReviews")
+	}
+
+	maintenanceLocation, err := time.LoadLocation(operatorConfig.MaintenanceTimezone)
+	if err != nil {
+		setupLog.Error(err, "invalid maintenance time zone", "timezone", operatorConfig.MaintenanceTimezone)
+		os.Exit(1)
+	}
+	maintenanceWindow, err := schedule.Parse(operatorConfig.MaintenanceWindows, maintenanceLocation)
+	if err != nil {
+		setupLog.Error(err, "invalid maintenance windows")
+		os.Exit(1)
+	}
+	if err := controller.ValidateCronJobOwner(operatorConfig.CronJobOwner); err != nil {
+		setupLog.Error(err, "invalid CronJob configuration")
+		os.Exit(1)
+	}
+	if err := controller.ValidateOwnerTarget(operatorConfig.OwnerTarget); err != nil {
+		setupLog.Error(err, "invalid owner target")
+		os.Exit(1)
+	}
+	if operatorConfig.NameConvention {
+		if err := controller.ValidateNameConvention(operatorConfig.NameConventionPattern); err != nil {
+			setupLog.Error(err, "invalid name convention")
+			os.Exit(1)
+		}
+	}
+	if err := controller.ValidateWorkloadNamePatterns(operatorConfig.WorkloadNameRegex,
+		operatorConfig.WorkloadNameExclude); err != nil {
+		setupLog.Error(err, "invalid workload name patterns")
+		os.Exit(1)
+	}
+	if err := controller.ValidateWatchKinds(operatorConfig.WatchKinds); err != nil {
+		setupLog.Error(err, "invalid watch kinds")
+		os.Exit(1)
+	}
+	if len(operatorConfig.CELReferences) > 0 {
+		extractor, err := controller.CompileCELReferences(operatorConfig.CELReferences)
+		if err != nil {
+			setupLog.Error(err, "invalid CEL references")
+			os.Exit(1)
+		}
+		controller.RegisterReferenceExtractor(controller.ReferenceCEL, extractor)
+	}
+	if err := controller.ValidateReferenceTypes(operatorConfig.ReferenceTypes); err != nil {
+		setupLog.Error(err, "invalid reference types")
+		os.Exit(1)
+	}
+	if _, err := controller.ParseCustomKinds(operatorConfig.CustomKinds); err != nil {
+		setupLog.Error(err, "invalid custom kinds")
+		os.Exit(1)
+	}
+	if err := controller.ValidateOptionalReferences(operatorConfig.OptionalReferences); err != nil {
+		setupLog.Error(err, "invalid optional references configuration")
+		os.Exit(1)
+	}
+	if err := controller.ValidateWatchStallAction(operatorConfig.WatchStallAction); err != nil {
+		setupLog.Error(err, "invalid watchdog configuration")
+		os.Exit(1)
+	}
+	if err := controller.ValidateNamespaceLabels(operatorConfig.MetricsNamespaceLabels,
+		operatorConfig.MetricsTopNamespaces); err != nil {
+		setupLog.Error(err, "invalid metrics configuration")
+		os.Exit(1)
+	}
+	controller.SetNamespaceLabels(operatorConfig.MetricsNamespaceLabels, operatorConfig.MetricsTopNamespaces)
+	namespaceInclude, namespaceExclude, err := controller.NamespacePatterns(operatorConfig.NamespaceRegex,
+		operatorConfig.NamespaceMatch)
+	if err != nil {
+		setupLog.Error(err, "invalid namespace patterns")
+		os.Exit(1)
+	}
+	operatorConfig.NamespaceRegex = namespaceInclude
+	operatorConfig.NamespaceExclude = append(namespaceExclude, operatorConfig.NamespaceExclude...)
+
+	var recordings *controller.RecordingWriter
+	if recordFile != "" {
+		f, err := os.OpenFile(recordFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+		if err != nil {
+			setupLog.Error(err, "unable to open recording file", "file", recordFile)
+			os.Exit(1)
+		}
+		defer f.Close() //nolint:errcheck
+		recordings = controller.NewRecordingWriter(f)
+	}
+
+	if once {
+		if !controller.RunsControllers(operatorConfig.Mode) {
+			setupLog.Error(nil, "--once reconciles, so it cannot run with --mode=webhook")
+			os.Exit(1)
+		}
+		if !operatorConfig.WatchesReplicaSets() {
+			setupLog.Error(nil, "--once reconciles ReplicaSets, so --watch-kinds must include replicasets")
+			os.Exit(1)
+		}
+		if err := runOnce(ctrl.SetupSignalHandler(), restConfig, clientset, operatorConfig, maintenanceWindow,
+			recordings, metricsServerOptions); err != nil {
+			setupLog.Error(err, "single pass failed")
+			os.Exit(1)
+		}
+		return
+	}
+
+	// A shadow elects its own leader, so it runs alongside the active operator instead of waiting for its lease
+	electionID := leaderElectionID
+	var shadowReport *controller.ShadowReport
+	if operatorConfig.Shadow {
+		electionID = "shadow-" + leaderElectionID
+		shadowReport = controller.NewShadowReport(operatorConfig.ShadowDelay)
+	}
+
+	mgr, err := ctrl.NewManager(restConfig, ctrl.Options{
 		Scheme:                 scheme,
 		Metrics:                metricsServerOptions,
 		WebhookServer:          webhookServer,
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
-		LeaderElectionID:       "77b0221c.github.com",
+		LeaderElectionID:       electionID,
 		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
 		// when the Manager ends. This requires the binary to immediately end when the
 		// Manager is stopped, otherwise, this setting is unsafe. Setting this significantly
@@ -208,17 +438,6 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err = (&controller.ReplicaSetReconciler{
-		Client:    mgr.GetClient(),
-		Scheme:    mgr.GetScheme(),
-		Config:    operatorConfig,
-		StartTime: time.Now(),
-	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "ReplicaSet")
-		os.Exit(1)
-	}
-	// +kubebuilder:scaffold:builder
-
 	if metricsCertWatcher != nil {
 		setupLog.Info("Adding metrics certificate watcher to manager")
 		if err := mgr.Add(metricsCertWatcher); err != nil {
@@ -239,14 +458,541 @@ func main() {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
 	}
+	if webhookCertPath != "" {
+		checker := &webhookcert.Checker{
+			CertFile:       filepath.Join(webhookCertPath, webhookCertName),
+			MinValidity:    webhookCertMinValidity,
+			Reader:         mgr.GetAPIReader(),
+			Configurations: config.SplitList(webhookConfigurations),
+		}
+		if err := mgr.AddHealthzCheck("webhook-cert", checker.Check); err != nil {
+			setupLog.Error(err, "unable to set up webhook certificate check")
+			os.Exit(1)
+		}
+	}
 	if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up ready check")
 		os.Exit(1)
 	}
 
+	if !controller.RunsControllers(operatorConfig.Mode) {
+		setupLog.Info("effective configuration", append(operatorConfig.Summary(),
+			"webhookCertPath", webhookCertPath,
+		)...)
+		if err := runWebhookServer(mgr); err != nil {
+			setupLog.Error(err, "problem running webhook server")
+			os.Exit(1)
+		}
+		return
+	}
+
+	pauseSwitch, err := pause.NewSwitch(mgr.GetClient(), operatorConfig.ControlConfigMap)
+	if err != nil {
+		setupLog.Error(err, "invalid control ConfigMap")
+		os.Exit(1)
+	}
+
+	writer, err := newWriter(restConfig, clientset, operatorConfig)
+	if err != nil {
+		setupLog.Error(err, "unable to create write client")
+		os.Exit(1)
+	}
+
+	reconciler := &controller.ReplicaSetReconciler{
+		Client:            mgr.GetClient(),
+		Scheme:            mgr.GetScheme(),
+		Config:            operatorConfig,
+		StartTime:         time.Now(),
+		Recorder:          controller.NewEventRecorder(mgr.GetEventRecorderFor("configmap-rs-operator"), operatorConfig),
+		MaintenanceWindow: maintenanceWindow,
+		Pause:             pauseSwitch,
+		Writer:            writer,
+		Churn:             controller.NewChurnDetector(operatorConfig.ChurnThreshold, operatorConfig.ChurnWindow),
+		Shadow:            shadowReport,
+		Recordings:        recordings,
+		Activity:          controller.NewActivityLog(0),
+		Tracker:           controller.NewReconcileTracker(operatorConfig.MaxReconcileStaleness),
+		Coalescer:         controller.NewCoalescer(operatorConfig.CoalesceWindow),
+	}
+	if reconciler.Coalescer != nil {
+		reconciler.Coalescer.CompareOptions = operatorConfig.ArgoCDCompareOptions
+	}
+	if reconciler.State, err = state.New(mgr.GetClient(), mgr.GetAPIReader(), operatorConfig.StateStore); err != nil {
+		setupLog.Error(err, "unable to create state store")
+		os.Exit(1)
+	}
+	if reconciler.Namespaces, err = controller.NewNamespaceFilter(operatorConfig.NamespaceRegex,
+		operatorConfig.NamespaceExclude); err != nil {
+		setupLog.Error(err, "unable to parse namespace regex")
+		os.Exit(1)
+	}
+	if err := reconciler.Namespaces.Watch(context.Background(), mgr.GetCache()); err != nil {
+		setupLog.Error(err, "unable to watch namespaces")
+		os.Exit(1)
+	}
+	if operatorConfig.PrecomputeDeployments {
+		reconciler.Precomputed = controller.NewPrecomputation(mgr.GetAPIReader())
+	}
+	if reconciler.OwnerRules, err = controller.ParseOwnerRules(operatorConfig.OwnerRules); err != nil {
+		setupLog.Error(err, "invalid owner rules")
+		os.Exit(1)
+	}
+	if operatorConfig.WatchesReplicaSets() {
+		if err = reconciler.SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "ReplicaSet")
+			os.Exit(1)
+		}
+	}
+	if operatorConfig.IngressTLSSecrets && !operatorConfig.Shadow {
+		if err = (&controller.IngressTLSReconciler{
+			ReplicaSetReconciler: reconciler,
+			SecretReader:         mgr.GetAPIReader(),
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "Ingress")
+			os.Exit(1)
+		}
+	}
+	if operatorConfig.SecretsEnabled && operatorConfig.WatchesReplicaSets() && !operatorConfig.Shadow {
+		if err = (&controller.SecretReconciler{
+			ReplicaSetReconciler: reconciler,
+			SecretReader:         mgr.GetAPIReader(),
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "Secret")
+			os.Exit(1)
+		}
+	}
+	if !operatorConfig.Shadow {
+		for _, kind := range controller.EnabledWorkloadKinds(operatorConfig) {
+			workload := &controller.WorkloadReconciler{ReplicaSetReconciler: reconciler, Kind: kind}
+			if err = workload.SetupWithManager(mgr); err != nil {
+				setupLog.Error(err, "unable to create controller", "controller", kind.Kind)
+				os.Exit(1)
+			}
+		}
+	}
+	if operatorConfig.RevalidateRollbacks && !operatorConfig.Shadow {
+		if err = (&controller.RollbackReconciler{ReplicaSetReconciler: reconciler}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "Rollback")
+			os.Exit(1)
+		}
+	}
+	if operatorConfig.RolloutHandoff && operatorConfig.WatchesReplicaSets() && !operatorConfig.Shadow {
+		if err = (&controller.HandoffReconciler{ReplicaSetReconciler: reconciler}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "Handoff")
+			os.Exit(1)
+		}
+	}
+	if operatorConfig.ConfigMapBindings && !operatorConfig.Shadow {
+		bindings := &controller.ConfigMapBindingReconciler{ReplicaSetReconciler: reconciler}
+		if err = bindings.SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "ConfigMapBinding")
+			os.Exit(1)
+		}
+	}
+	if operatorConfig.RequireApproval && !operatorConfig.Shadow {
+		approval := &controller.ApprovalReconciler{ReplicaSetReconciler: reconciler}
+		if operatorConfig.SecretsEnabled || operatorConfig.IngressTLSSecrets {
+			approval.SecretReader = mgr.GetAPIReader()
+		}
+		if err = approval.SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "Approval")
+			os.Exit(1)
+		}
+	}
+	if reconciler.Precomputed != nil {
+		if err = (&controller.DeploymentReconciler{ReplicaSetReconciler: reconciler}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "Deployment")
+			os.Exit(1)
+		}
+	}
+	// +kubebuilder:scaffold:builder
+
+	if err := setupWatchdog(mgr, operatorConfig); err != nil {
+		setupLog.Error(err, "unable to set up watchdog")
+		os.Exit(1)
+	}
+
+	if err := controller.ValidateUsageLevel(operatorConfig.UsageMetrics); err != nil {
+		setupLog.Error(err, "invalid usage metrics configuration")
+		os.Exit(1)
+	}
+	if operatorConfig.UsageMetrics != controller.UsageLevelOff {
+		if err := metrics.Registry.Register(&controller.UsageCollector{
+			Reader:           mgr.GetClient(),
+			Level:            operatorConfig.UsageMetrics,
+			MaxSeries:        operatorConfig.UsageMetricsMaxSeries,
+			NamespaceRegex:   operatorConfig.NamespaceRegex,
+			NamespaceExclude: operatorConfig.NamespaceExclude,
+		}); err != nil {
+			setupLog.Error(err, "unable to register ConfigMap usage metrics")
+			os.Exit(1)
+		}
+	}
+
+	// The shadow never writes, so it leaves the inventory report and migrations to the active operator
+	inventory, err := controller.NewInventoryReporter(reconciler, operatorConfig.InventoryConfigMap,
+		operatorConfig.InventoryInterval, ctrl.Log.WithName("inventory"))
+	if err != nil {
+		setupLog.Error(err, "invalid inventory report configuration")
+		os.Exit(1)
+	}
+	if inventory != nil && !operatorConfig.Shadow {
+		if err := mgr.Add(inventory); err != nil {
+			setupLog.Error(err, "unable to add inventory report to manager")
+			os.Exit(1)
+		}
+	}
+
+	// The startup runners write with the identity the reconcilers write with
+	runnerWriter := writer
+	if runnerWriter == nil {
+		runnerWriter = mgr.GetClient()
+	}
+	if operatorConfig.Migrate && !operatorConfig.Shadow {
+		if err := mgr.Add(&controller.MigrationRunner{
+			Reader: mgr.GetAPIReader(),
+			Writer: runnerWriter,
+			Options: controller.MigrationOptions{
+				DryRun:        operatorConfig.DryRun,
+				BatchInterval: time.Second,
+				Hold:          reconciler.WriteHold,
+			},
+			Log: ctrl.Log.WithName("migration"),
+		}); err != nil {
+			setupLog.Error(err, "unable to add semantics migration to manager")
+			os.Exit(1)
+		}
+	}
+	if operatorConfig.CleanupDisabledKinds && !operatorConfig.Shadow {
+		if err := mgr.Add(&controller.CleanupRunner{
+			Reader: mgr.GetClient(),
+			Writer: runnerWriter,
+			Options: controller.CleanupOptions{
+				DryRun: operatorConfig.DryRun, Kinds: controller.DisabledKinds(operatorConfig), Hold: reconciler.WriteHold,
+			},
+			Log:   ctrl.Log.WithName("cleanup"),
+			Scope: "disabled workload kinds",
+		}); err != nil {
+			setupLog.Error(err, "unable to add disabled kinds cleanup to manager")
+			os.Exit(1)
+		}
+	}
+	if operatorConfig.CleanupOutOfScope && !operatorConfig.Shadow {
+		if err := mgr.Add(&controller.CleanupRunner{
+			Reader: mgr.GetClient(),
+			Writer: runnerWriter,
+			Options: controller.CleanupOptions{
+				DryRun: operatorConfig.DryRun, InScope: reconciler.InScope, Hold: reconciler.WriteHold,
+			},
+			Log:   ctrl.Log.WithName("cleanup"),
+			Scope: "ConfigMaps out of scope",
+		}); err != nil {
+			setupLog.Error(err, "unable to add out-of-scope cleanup to manager")
+			os.Exit(1)
+		}
+	}
+	if operatorConfig.DriftScanInterval > 0 && !operatorConfig.Shadow {
+		if err := mgr.Add(&controller.DriftScanner{
+			Reconciler: reconciler,
+			Interval:   operatorConfig.DriftScanInterval,
+			Log:        ctrl.Log.WithName("drift"),
+		}); err != nil {
+			setupLog.Error(err, "unable to add drift scan to manager")
+			os.Exit(1)
+		}
+	}
+	if operatorConfig.KustomizeCleanupInterval > 0 && !operatorConfig.Shadow {
+		if err := mgr.Add(&controller.KustomizeCleaner{
+			Reconciler: reconciler,
+			Reader:     mgr.GetAPIReader(),
+			Interval:   operatorConfig.KustomizeCleanupInterval,
+			Log:        ctrl.Log.WithName("kustomize"),
+		}); err != nil {
+			setupLog.Error(err, "unable to add kustomize cleanup to manager")
+			os.Exit(1)
+		}
+	}
+	if operatorConfig.ConflictScanInterval > 0 && !operatorConfig.Shadow {
+		if err := mgr.Add(&controller.ConflictScanner{
+			Reconciler: reconciler,
+			Interval:   operatorConfig.ConflictScanInterval,
+			Log:        ctrl.Log.WithName("conflict"),
+			Managers:   []string{controller.DefaultFieldManager(restConfig)},
+		}); err != nil {
+			setupLog.Error(err, "unable to add conflict scan to manager")
+			os.Exit(1)
+		}
+	}
+	if operatorConfig.MissingReferenceScanInterval > 0 && !operatorConfig.Shadow {
+		if err := mgr.Add(&controller.MissingReferenceScanner{
+			Reconciler: reconciler,
+			Interval:   operatorConfig.MissingReferenceScanInterval,
+			Log:        ctrl.Log.WithName("missing-references"),
+		}); err != nil {
+			setupLog.Error(err, "unable to add missing-reference scan to manager")
+			os.Exit(1)
+		}
+	}
+
+	if enableLeaderElection {
+		if err := mgr.Add(&leadership.Observer{
+			Reader:   mgr.GetAPIReader(),
+			Lease:    types.NamespacedName{Namespace: leadership.Namespace(), Name: electionID},
+			Elected:  mgr.Elected(),
+			Interval: 15 * time.Second,
+			Log:      ctrl.Log.WithName("leadership"),
+		}); err != nil {
+			setupLog.Error(err, "unable to add leadership observer to manager")
+			os.Exit(1)
+		}
+	}
+
+	if err := mgr.AddHealthzCheck("reconcile", reconciler.Tracker.Check); err != nil {
+		setupLog.Error(err, "unable to set up reconcile staleness check")
+		os.Exit(1)
+	}
+
+	selfTest := &admin.SelfTestHandler{Run: func(ctx context.Context) controller.SelfTestResult {
+		return controller.SelfTest(ctx, operatorConfig)
+	}}
+	if err := selfTest.Register(mgr.AddMetricsServerExtraHandler); err != nil {
+		setupLog.Error(err, "unable to set up self-test endpoint")
+		os.Exit(1)
+	}
+
+	explain := &admin.ExplainHandler{Explain: reconciler.Explain}
+	if err := explain.Register(mgr.AddMetricsServerExtraHandler); err != nil {
+		setupLog.Error(err, "unable to set up explain endpoint")
+		os.Exit(1)
+	}
+	whoUses := &admin.WhoUsesHandler{WhoUses: reconciler.WhoUses}
+	if err := whoUses.Register(mgr.AddMetricsServerExtraHandler); err != nil {
+		setupLog.Error(err, "unable to set up who-uses endpoint")
+		os.Exit(1)
+	}
+	dashboard := &admin.DashboardHandler{Overview: reconciler.Overview}
+	if err := dashboard.Register(mgr.AddMetricsServerExtraHandler); err != nil {
+		setupLog.Error(err, "unable to set up dashboard")
+		os.Exit(1)
+	}
+	openAPI := &admin.OpenAPIHandler{}
+	openAPI.Add(selfTest, explain, whoUses, dashboard)
+
+	if shadowReport != nil {
+		shadow := &admin.ShadowHandler{Report: shadowReport}
+		if err := shadow.Register(mgr.AddMetricsServerExtraHandler); err != nil {
+			setupLog.Error(err, "unable to set up shadow report endpoint")
+			os.Exit(1)
+		}
+		openAPI.Add(shadow)
+	}
+
+	if pauseSwitch != nil {
+		if err := setupPauseControl(mgr, clientset, pauseSwitch, operatorConfig.UnreadyWhenPaused, openAPI); err != nil {
+			setupLog.Error(err, "unable to set up pause control")
+			os.Exit(1)
+		}
+	}
+	if err := openAPI.Register(mgr.AddMetricsServerExtraHandler); err != nil {
+		setupLog.Error(err, "unable to set up OpenAPI endpoints")
+		os.Exit(1)
+	}
+
+	// Log the effective configuration once, so support requests start from known settings
+	setupLog.Info("effective configuration", append(operatorConfig.Summary(),
+		"workloadKinds", workloadKinds(operatorConfig),
+		"leaderElection", enableLeaderElection,
+		"metricsBindAddress", metricsAddr,
+		"secureMetrics", secureMetrics,
+		"webhookCertPath", webhookCertPath,
+	)...)
+
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
 }
+
+// runWebhookServer runs the manager with nothing but the webhook server, for --mode=webhook. The server is
+// started even before a webhook registers with it, and the pod is ready once it serves.
+func runWebhookServer(mgr manager.Manager) error {
+	if err := mgr.AddReadyzCheck("webhook", mgr.GetWebhookServer().StartedChecker()); err != nil {
+		return err
+	}
+	setupLog.Info("starting webhook server")
+	return mgr.Start(ctrl.SetupSignalHandler())
+}
+
+// setupPauseControl exposes the kill switch through the pause/resume endpoints, a metric and,
+// optionally, the readiness probe, and adds the endpoints to the OpenAPI document
+func setupPauseControl(
+	mgr manager.Manager,
+	clientset kubernetes.Interface,
+	pauseSwitch *pause.Switch,
+	unready bool,
+	openAPI *admin.OpenAPIHandler,
+) error {
+	if err := metrics.Registry.Register(pauseSwitch.Collector()); err != nil {
+		return err
+	}
+
+	handler := &admin.PauseHandler{Switch: pauseSwitch, Identify: admin.TokenReviewIdentifier(clientset)}
+	if err := handler.Register(mgr.AddMetricsServerExtraHandler); err != nil {
+		return err
+	}
+	openAPI.Add(handler)
+
+	if unready {
+		return mgr.AddReadyzCheck("paused", pauseSwitch.Checker())
+	}
+	return nil
+}
+
+// setupWatchdog watches the informers of the reconciled kinds, and of ConfigMaps, for silent watch stalls and
+// fails the readiness or liveness probe while one lasts
+func setupWatchdog(mgr manager.Manager, cfg *config.OperatorConfig) error {
+	watchdog := controller.NewWatchdog(cfg.WatchStallTimeout)
+	if watchdog == nil {
+		return nil
+	}
+	watched := map[string]client.Object{"ConfigMap": &corev1.ConfigMap{}}
+	if cfg.WatchesReplicaSets() {
+		watched["ReplicaSet"] = &appsv1.ReplicaSet{}
+	}
+	if !cfg.Shadow {
+		for _, kind := range controller.EnabledWorkloadKinds(cfg) {
+			watched[kind.Kind] = kind.New()
+		}
+	}
+	for kind, obj := range watched {
+		if err := watchdog.Watch(context.Background(), mgr.GetCache(), kind, obj); err != nil {
+			return err
+		}
+	}
+	if cfg.WatchStallAction == controller.WatchStallRestart {
+		return mgr.AddHealthzCheck("watch", watchdog.Check)
+	}
+	return mgr.AddReadyzCheck("watch", watchdog.Check)
+}
+
+// runOnce reconciles every existing ReplicaSet in scope a single time with a direct client,
+// so the operator can be run from a laptop or a script without deploying it. The metrics endpoint,
+// if enabled, serves the progress of the pass.
+func runOnce(
+	ctx context.Context,
+	restConfig *rest.Config,
+	clientset kubernetes.Interface,
+	cfg *config.OperatorConfig,
+	maintenanceWindow *schedule.Schedule,
+	recordings *controller.RecordingWriter,
+	metricsOptions metricsserver.Options,
+) error {
+	c, err := client.New(restConfig, client.Options{Scheme: scheme})
+	if err != nil {
+		return err
+	}
+	httpClient, err := rest.HTTPClientFor(restConfig)
+	if err != nil {
+		return err
+	}
+	metricsServer, err := metricsserver.NewServer(metricsOptions, restConfig, httpClient)
+	if err != nil {
+		return err
+	}
+	if metricsServer != nil {
+		serverCtx, stop := context.WithCancel(ctx)
+		defer stop()
+		go func() {
+			if err := metricsServer.Start(serverCtx); err != nil {
+				setupLog.Error(err, "metrics server failed")
+			}
+		}()
+	}
+	pauseSwitch, err := pause.NewSwitch(c, cfg.ControlConfigMap)
+	if err != nil {
+		return err
+	}
+	writer, err := newWriter(restConfig, clientset, cfg)
+	if err != nil {
+		return err
+	}
+
+	ownerRules, err := controller.ParseOwnerRules(cfg.OwnerRules)
+	if err != nil {
+		return err
+	}
+
+	stateStore, err := state.New(c, c, cfg.StateStore)
+	if err != nil {
+		return err
+	}
+
+	reconciler := &controller.ReplicaSetReconciler{
+		Client:            c,
+		Scheme:            scheme,
+		Config:            cfg,
+		MaintenanceWindow: maintenanceWindow,
+		Pause:             pauseSwitch,
+		Writer:            writer,
+		Recordings:        recordings,
+		OwnerRules:        ownerRules,
+		State:             stateStore,
+	}
+	start := time.Now()
+	processed, err := reconciler.RunOnce(ctx)
+	setupLog.Info("single pass complete", "replicaSets", processed)
+	if cfg.PushgatewayURL != "" {
+		if pushErr := pushgateway.Push(ctx, cfg.PushgatewayURL, "backfill", start, err); pushErr != nil {
+			setupLog.Error(pushErr, "unable to push metrics")
+		}
+	}
+	return err
+}
+
+// newWriter returns the client used for writes: tenant credentials where configured, otherwise the
+// impersonated identity. It returns nil when writes use the operator's own identity.
+func newWriter(restConfig *rest.Config, clientset kubernetes.Interface, cfg *config.OperatorConfig) (client.Writer, error) {
+	var writer client.Writer
+	if cfg.ImpersonateUser != "" || len(cfg.ImpersonateGroups) > 0 {
+		impersonating := rest.CopyConfig(restConfig)
+		impersonating.Impersonate = rest.ImpersonationConfig{
+			UserName: cfg.ImpersonateUser,
+			Groups:   cfg.ImpersonateGroups,
+		}
+		c, err := client.New(impersonating, client.Options{Scheme: scheme})
+		if err != nil {
+			return nil, err
+		}
+		writer = c
+	}
+	if len(cfg.TenantServiceAccounts) == 0 {
+		return writer, nil
+	}
+	if writer == nil {
+		c, err := client.New(restConfig, client.Options{Scheme: scheme})
+		if err != nil {
+			return nil, err
+		}
+		writer = c
+	}
+	return &tenant.Writer{
+		Config:    restConfig,
+		Clientset: clientset,
+		Scheme:    scheme,
+		Accounts:  cfg.TenantServiceAccounts,
+		Fallback:  writer,
+	}, nil
+}
+
+// workloadKinds returns the kinds whose ConfigMap references are owned with cfg
+func workloadKinds(cfg *config.OperatorConfig) []string {
+	var kinds []string
+	if cfg.WatchesReplicaSets() {
+		kinds = append(kinds, "ReplicaSet")
+	}
+	for _, kind := range controller.EnabledWorkloadKinds(cfg) {
+		kinds = append(kinds, kind.Kind)
+	}
+	return kinds
+}
@@ -59,4 +59,24 @@ Create the name of the service account to use
 {{- else }}
 {{- default "default" .Values.serviceAccount.name }}
 {{- end }}
-{{- end }}
\ No newline at end of file
+{{- end }}
+
+{{/*
+The workload kinds the operator watches, as a JSON array: config.watchKinds if set, otherwise ReplicaSets and the
+kinds the per-kind values enable
+*/}}
+{{- define "configmap-rs-operator.watchKinds" -}}
+{{- $config := .Values.config }}
+{{- $kinds := $config.watchKinds }}
+{{- if not $kinds }}
+{{- $kinds = list "replicasets" }}
+{{- if $config.precomputeDeployments }}{{ $kinds = append $kinds "deployments" }}{{ end }}
+{{- if $config.podTemplates }}{{ $kinds = append $kinds "podtemplates" }}{{ end }}
+{{- if $config.statefulSets }}{{ $kinds = append $kinds "statefulsets" }}{{ end }}
+{{- if $config.daemonSets }}{{ $kinds = append $kinds "daemonsets" }}{{ end }}
+{{- if $config.jobs }}{{ $kinds = append $kinds "jobs" }}{{ end }}
+{{- if $config.cronJobs }}{{ $kinds = append $kinds "cronjobs" }}{{ end }}
+{{- if $config.pods }}{{ $kinds = append $kinds "pods" }}{{ end }}
+{{- end }}
+{{- toJson $kinds }}
+{{- end }}
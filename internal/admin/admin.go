@@ -0,0 +1,60 @@
+// Package admin implements the operator's administrative HTTP endpoints. They are served by the
+// metrics server, so with --metrics-secure they are authenticated and authorized like /metrics.
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// anonymousUser identifies callers whose identity could not be established
+const anonymousUser = "anonymous"
+
+// Identifier returns the identity of the caller that sent the request
+type Identifier func(req *http.Request) string
+
+// TokenReviewIdentifier resolves the caller's bearer token to a user name through a TokenReview
+func TokenReviewIdentifier(clientset kubernetes.Interface) Identifier {
+	return func(req *http.Request) string {
+		token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			return anonymousUser
+		}
+		review, err := clientset.AuthenticationV1().TokenReviews().Create(req.Context(),
+			&authenticationv1.TokenReview{Spec: authenticationv1.TokenReviewSpec{Token: token}},
+			metav1.CreateOptions{})
+		if err != nil || !review.Status.Authenticated {
+			return anonymousUser
+		}
+		return review.Status.User.Username
+	}
+}
+
+// writeJSON writes v as the JSON response body with the given status code
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// errorResponse is the body returned for failed requests
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
+
+// maxRequestBody bounds the size of JSON request bodies
+const maxRequestBody = 1 << 20
+
+// decodeJSON decodes the request body into v
+func decodeJSON(req *http.Request, v interface{}) error {
+	return json.NewDecoder(http.MaxBytesReader(nil, req.Body, maxRequestBody)).Decode(v)
+}
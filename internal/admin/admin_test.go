@@ -0,0 +1,170 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/controller"
+	"github.com/matanbaruch/configmap-rs-operator/internal/pause"
+)
+
+var _ = ginkgo.Describe("PauseHandler", func() {
+	var handlers map[string]http.Handler
+
+	ginkgo.BeforeEach(func() {
+		handlers = map[string]http.Handler{}
+		h := &PauseHandler{
+			Switch: &pause.Switch{
+				Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).Build(),
+				Key:    types.NamespacedName{Namespace: "ops", Name: "control"},
+			},
+			Identify: func(*http.Request) string { return "alice" },
+		}
+		gomega.Expect(h.Register(func(path string, handler http.Handler) error {
+			handlers[path] = handler
+			return nil
+		})).To(gomega.Succeed())
+	})
+
+	serve := func(method, path, body string) (int, pause.State) {
+		rec := httptest.NewRecorder()
+		handlers[path].ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		var state pause.State
+		_ = json.Unmarshal(rec.Body.Bytes(), &state)
+		return rec.Code, state
+	}
+
+	ginkgo.It("should pause with the caller's identity and reason", func() {
+		code, state := serve(http.MethodPost, "/pause", `{"reason":"incident"}`)
+		gomega.Expect(code).To(gomega.Equal(http.StatusOK))
+		gomega.Expect(state.Paused).To(gomega.BeTrue())
+		gomega.Expect(state.ChangedBy).To(gomega.Equal("alice"))
+		gomega.Expect(state.Reason).To(gomega.Equal("incident"))
+
+		code, state = serve(http.MethodGet, "/pause", "")
+		gomega.Expect(code).To(gomega.Equal(http.StatusOK))
+		gomega.Expect(state.Paused).To(gomega.BeTrue())
+	})
+
+	ginkgo.It("should resume", func() {
+		serve(http.MethodPost, "/pause", "")
+		code, state := serve(http.MethodPost, "/resume", "")
+		gomega.Expect(code).To(gomega.Equal(http.StatusOK))
+		gomega.Expect(state.Paused).To(gomega.BeFalse())
+	})
+
+	ginkgo.It("should reject other methods", func() {
+		code, _ := serve(http.MethodDelete, "/pause", "")
+		gomega.Expect(code).To(gomega.Equal(http.StatusMethodNotAllowed))
+	})
+})
+
+var _ = ginkgo.Describe("SelfTestHandler", func() {
+	ginkgo.It("should answer 503 when the self-test fails", func() {
+		h := &SelfTestHandler{Run: func(context.Context) controller.SelfTestResult {
+			return controller.SelfTestResult{Passed: false}
+		}}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/selftest", nil))
+		gomega.Expect(rec.Code).To(gomega.Equal(http.StatusServiceUnavailable))
+	})
+
+	ginkgo.It("should answer 200 when the self-test passes", func() {
+		h := &SelfTestHandler{Run: func(context.Context) controller.SelfTestResult {
+			return controller.SelfTestResult{Passed: true}
+		}}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/selftest", nil))
+		gomega.Expect(rec.Code).To(gomega.Equal(http.StatusOK))
+	})
+})
+
+var _ = ginkgo.Describe("DashboardHandler", func() {
+	h := &DashboardHandler{Overview: func(context.Context) (*controller.Overview, error) {
+		return &controller.Overview{
+			Namespaces: []controller.NamespaceOverview{{Name: "team-a", Hold: "PAUSED"}},
+			ConfigMaps: []controller.ManagedConfigMap{{Namespace: "team-a", Name: "<cm>", Owners: []string{"ReplicaSet/rs-a"}}},
+		}, nil
+	}}
+
+	ginkgo.It("should render the overview as escaped HTML", func() {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/dashboard", nil))
+		gomega.Expect(rec.Code).To(gomega.Equal(http.StatusOK))
+		gomega.Expect(rec.Header().Get("Content-Type")).To(gomega.HavePrefix("text/html"))
+		gomega.Expect(rec.Body.String()).To(gomega.ContainSubstring("held (PAUSED)"))
+		gomega.Expect(rec.Body.String()).To(gomega.ContainSubstring("&lt;cm&gt;"))
+	})
+
+	ginkgo.It("should serve JSON on request", func() {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/dashboard?format=json", nil))
+		var overview controller.Overview
+		gomega.Expect(json.Unmarshal(rec.Body.Bytes(), &overview)).To(gomega.Succeed())
+		gomega.Expect(overview.ConfigMaps).To(gomega.HaveLen(1))
+	})
+})
+
+var _ = ginkgo.Describe("OpenAPIHandler", func() {
+	var handlers map[string]http.Handler
+
+	ginkgo.BeforeEach(func() {
+		handlers = map[string]http.Handler{}
+		h := &OpenAPIHandler{}
+		h.Add(&ExplainHandler{}, &DashboardHandler{}, &PauseHandler{})
+		gomega.Expect(h.Register(func(path string, handler http.Handler) error {
+			handlers[path] = handler
+			return nil
+		})).To(gomega.Succeed())
+	})
+
+	get := func(path string) (int, map[string]interface{}) {
+		rec := httptest.NewRecorder()
+		handler := handlers[path]
+		if handler == nil {
+			handler = handlers["/schemas/v1/"]
+		}
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		var body map[string]interface{}
+		_ = json.Unmarshal(rec.Body.Bytes(), &body)
+		return rec.Code, body
+	}
+
+	ginkgo.It("should document the endpoints added to it", func() {
+		code, doc := get("/openapi.json")
+		gomega.Expect(code).To(gomega.Equal(http.StatusOK))
+		gomega.Expect(doc).To(gomega.HaveKeyWithValue("openapi", "3.1.0"))
+		gomega.Expect(doc["paths"]).To(gomega.HaveKey("/explain"))
+		gomega.Expect(doc["paths"]).To(gomega.HaveKeyWithValue("/pause", gomega.And(
+			gomega.HaveKey("get"), gomega.HaveKey("post"))))
+		gomega.Expect(doc["paths"]).NotTo(gomega.HaveKey("/who-uses"))
+		gomega.Expect(doc["components"]).To(gomega.HaveKeyWithValue("schemas", gomega.And(
+			gomega.HaveKey("Explanation"), gomega.HaveKey("ErrorResponse"), gomega.HaveKey("State"))))
+	})
+
+	ginkgo.It("should serve a versioned JSON schema per response type", func() {
+		code, schema := get("/schemas/v1/Activity.json")
+		gomega.Expect(code).To(gomega.Equal(http.StatusOK))
+		gomega.Expect(schema).To(gomega.HaveKeyWithValue("$id", "/schemas/v1/Activity.json"))
+		// Fields of the embedded Decision are promoted like encoding/json does
+		gomega.Expect(schema["properties"]).To(gomega.And(gomega.HaveKey("time"), gomega.HaveKey("replicaSet")))
+
+		code, _ = get("/schemas/v1/Unknown.json")
+		gomega.Expect(code).To(gomega.Equal(http.StatusNotFound))
+	})
+})
+
+func TestAdmin(t *testing.T) {
+	gomega.RegisterFailHandler(ginkgo.Fail)
+	ginkgo.RunSpecs(t, "Admin Suite")
+}
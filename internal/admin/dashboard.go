@@ -0,0 +1,91 @@
+package admin
+
+import (
+	"context"
+	"html/template"
+	"net/http"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/controller"
+)
+
+// dashboardTemplate renders the overview; it has no scripts and refreshes itself every 30 seconds
+var dashboardTemplate = template.Must(template.New("dashboard").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta http-equiv="refresh" content="30">
+<title>configmap-rs-operator</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; }
+.failed { color: #b00; }
+</style>
+</head>
+<body>
+<h1>configmap-rs-operator</h1>
+
+<h2>Namespaces in scope ({{len .Namespaces}})</h2>
+<table>
+<tr><th>Namespace</th><th>Writes</th></tr>
+{{range .Namespaces}}<tr><td>{{.Name}}</td><td>{{if .Hold}}held ({{.Hold}}){{else}}allowed{{end}}</td></tr>
+{{end}}</table>
+
+<h2>Managed ConfigMaps ({{len .ConfigMaps}})</h2>
+<table>
+<tr><th>Namespace</th><th>ConfigMap</th><th>Owners added by the operator</th></tr>
+{{range .ConfigMaps}}<tr><td>{{.Namespace}}</td><td>{{.Name}}</td>
+<td>{{range $i, $o := .Owners}}{{if $i}}, {{end}}{{$o}}{{end}}</td></tr>
+{{end}}</table>
+
+<h2>Recent activity</h2>
+<table>
+<tr><th>Time</th><th>Namespace</th><th>ReplicaSet</th><th>ConfigMap</th><th>Action</th><th>Reason</th></tr>
+{{range .Activity}}<tr{{if .Error}} class="failed"{{end}}>
+<td>{{.Time.Format "2006-01-02T15:04:05Z07:00"}}</td><td>{{.Namespace}}</td><td>{{.ReplicaSet}}</td>
+<td>{{.ConfigMap}}</td><td>{{.Action}}</td><td>{{if .Error}}{{.Error}}{{else}}{{.Reason}}{{end}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// DashboardHandler serves a read-only overview of the operator on GET /dashboard, as HTML or,
+// with ?format=json, as JSON
+type DashboardHandler struct {
+	Overview func(ctx context.Context) (*controller.Overview, error)
+}
+
+// Register adds the dashboard endpoint to the metrics server
+func (h *DashboardHandler) Register(add func(path string, handler http.Handler) error) error {
+	return add("/dashboard", h)
+}
+
+// Endpoints implements Describer; the HTML page isn't part of the API
+func (h *DashboardHandler) Endpoints() []Endpoint {
+	return []Endpoint{{
+		Path: "/dashboard", Method: http.MethodGet, Summary: "Show the namespaces, managed ConfigMaps and recent activity",
+		Parameters: []Parameter{{Name: "format", Description: "json for JSON instead of HTML", Required: true}},
+		Responses: map[int]interface{}{
+			http.StatusOK: controller.Overview{}, http.StatusInternalServerError: errorResponse{},
+		},
+	}}
+}
+
+func (h *DashboardHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	overview, err := h.Overview(req.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if req.URL.Query().Get("format") == "json" {
+		writeJSON(w, http.StatusOK, overview)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_ = dashboardTemplate.Execute(w, overview)
+}
@@ -0,0 +1,58 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/controller"
+)
+
+// ExplainHandler serves the decision trace of a ConfigMap on GET /explain?namespace=&name=
+type ExplainHandler struct {
+	Explain func(ctx context.Context, key types.NamespacedName) (*controller.Explanation, error)
+}
+
+// Register adds the explain endpoint to the metrics server
+func (h *ExplainHandler) Register(add func(path string, handler http.Handler) error) error {
+	return add("/explain", h)
+}
+
+// Endpoints implements Describer
+func (h *ExplainHandler) Endpoints() []Endpoint {
+	return []Endpoint{{
+		Path: "/explain", Method: http.MethodGet, Summary: "Explain the ownership decisions of a ConfigMap",
+		Parameters: []Parameter{
+			{Name: "namespace", Description: "Namespace of the ConfigMap", Required: true},
+			{Name: "name", Description: "Name of the ConfigMap", Required: true},
+		},
+		Responses: map[int]interface{}{
+			http.StatusOK: controller.Explanation{}, http.StatusBadRequest: errorResponse{},
+			http.StatusInternalServerError: errorResponse{},
+		},
+	}}
+}
+
+func (h *ExplainHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	key := types.NamespacedName{
+		Namespace: req.URL.Query().Get("namespace"),
+		Name:      req.URL.Query().Get("name"),
+	}
+	if key.Namespace == "" || key.Name == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("the namespace and name query parameters are required"))
+		return
+	}
+	explanation, err := h.Explain(req.Context(), key)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, explanation)
+}
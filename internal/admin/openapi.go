@@ -0,0 +1,261 @@
+package admin
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// APIVersion versions the response bodies of the endpoints. Incompatible changes bump it, and the JSON schemas
+// of each version are served under /schemas/<version>/.
+const APIVersion = "v1"
+
+// schemasPath is where the JSON schemas of the response bodies are served, one per type as <Type>.json
+const schemasPath = "/schemas/" + APIVersion + "/"
+
+// Parameter is a query parameter of an endpoint
+type Parameter struct {
+	Name        string
+	Description string
+	Required    bool
+}
+
+// Endpoint describes an endpoint for the OpenAPI document. Bodies are given as values of their Go types,
+// whose schemas are generated from the JSON encoding.
+type Endpoint struct {
+	Path       string
+	Method     string
+	Summary    string
+	Parameters []Parameter
+
+	// Body is the JSON request body, nil for none
+	Body interface{}
+
+	// Responses are the JSON response bodies by status code
+	Responses map[int]interface{}
+}
+
+// Describer is a handler that describes its endpoints
+type Describer interface {
+	Endpoints() []Endpoint
+}
+
+// OpenAPIHandler serves the OpenAPI document of the endpoints added to it on GET /openapi.json and the JSON
+// schema of each response body on GET /schemas/<version>/<Type>.json
+type OpenAPIHandler struct {
+	endpoints []Endpoint
+}
+
+// Add adds the endpoints of handlers to the document
+func (h *OpenAPIHandler) Add(handlers ...Describer) {
+	for _, d := range handlers {
+		h.endpoints = append(h.endpoints, d.Endpoints()...)
+	}
+}
+
+// Register adds the OpenAPI document and schema endpoints to the metrics server
+func (h *OpenAPIHandler) Register(add func(path string, handler http.Handler) error) error {
+	if err := add("/openapi.json", http.HandlerFunc(h.serveDocument)); err != nil {
+		return err
+	}
+	return add(schemasPath, http.HandlerFunc(h.serveSchema))
+}
+
+func (h *OpenAPIHandler) serveDocument(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, http.StatusOK, h.Document())
+}
+
+func (h *OpenAPIHandler) serveSchema(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	name, ok := strings.CutSuffix(strings.TrimPrefix(req.URL.Path, schemasPath), ".json")
+	schemas := h.schemas("#/$defs/")
+	if name == "" {
+		names := make([]string, 0, len(schemas))
+		for name := range schemas {
+			names = append(names, name+".json")
+		}
+		sort.Strings(names)
+		writeJSON(w, http.StatusOK, names)
+		return
+	}
+	schema, found := schemas[name]
+	if !ok || !found {
+		writeError(w, http.StatusNotFound, fmt.Errorf("no schema %s in API version %s", name, APIVersion))
+		return
+	}
+	document := map[string]interface{}{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$id":     schemasPath + name + ".json",
+		"$defs":   schemas,
+	}
+	for key, value := range schema {
+		document[key] = value
+	}
+	writeJSON(w, http.StatusOK, document)
+}
+
+// schemas returns the schemas of every body, and of the types they contain, by type name
+func (h *OpenAPIHandler) schemas(refPrefix string) map[string]map[string]interface{} {
+	g := &schemaGenerator{refPrefix: refPrefix, defs: map[string]map[string]interface{}{}}
+	for _, e := range h.endpoints {
+		if e.Body != nil {
+			g.schema(reflect.TypeOf(e.Body))
+		}
+		for _, body := range e.Responses {
+			g.schema(reflect.TypeOf(body))
+		}
+	}
+	return g.defs
+}
+
+// Document returns the OpenAPI 3.1 document of the endpoints
+func (h *OpenAPIHandler) Document() map[string]interface{} {
+	g := &schemaGenerator{refPrefix: "#/components/schemas/", defs: map[string]map[string]interface{}{}}
+	paths := map[string]map[string]interface{}{}
+	for _, e := range h.endpoints {
+		operation := map[string]interface{}{"summary": e.Summary}
+		var parameters []map[string]interface{}
+		for _, p := range e.Parameters {
+			parameters = append(parameters, map[string]interface{}{
+				"name": p.Name, "in": "query", "description": p.Description, "required": p.Required,
+				"schema": map[string]interface{}{"type": "string"},
+			})
+		}
+		if parameters != nil {
+			operation["parameters"] = parameters
+		}
+		if e.Body != nil {
+			operation["requestBody"] = map[string]interface{}{"content": jsonContent(g.schema(reflect.TypeOf(e.Body)))}
+		}
+		responses := map[string]interface{}{}
+		for status, body := range e.Responses {
+			responses[strconv.Itoa(status)] = map[string]interface{}{
+				"description": http.StatusText(status),
+				"content":     jsonContent(g.schema(reflect.TypeOf(body))),
+			}
+		}
+		operation["responses"] = responses
+		if paths[e.Path] == nil {
+			paths[e.Path] = map[string]interface{}{}
+		}
+		paths[e.Path][strings.ToLower(e.Method)] = operation
+	}
+	return map[string]interface{}{
+		"openapi": "3.1.0",
+		"info": map[string]interface{}{
+			"title":   "configmap-rs-operator administrative API",
+			"version": APIVersion,
+		},
+		"paths":      paths,
+		"components": map[string]interface{}{"schemas": g.defs},
+	}
+}
+
+func jsonContent(schema map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"application/json": map[string]interface{}{"schema": schema}}
+}
+
+// schemaGenerator derives JSON schemas from Go types as encoding/json encodes them. Named structs become
+// definitions referenced by name.
+type schemaGenerator struct {
+	refPrefix string
+	defs      map[string]map[string]interface{}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func (g *schemaGenerator) schema(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case t.Kind() == reflect.Struct:
+		return g.structSchema(t)
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		return map[string]interface{}{"type": "string", "contentEncoding": "base64"}
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		return map[string]interface{}{"type": "array", "items": g.schema(t.Elem())}
+	case t.Kind() == reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	}
+	return scalarSchema(t.Kind())
+}
+
+// scalarSchema returns the schema of a kind without elements; interfaces accept any value
+func scalarSchema(kind reflect.Kind) map[string]interface{} {
+	switch kind {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	}
+	return map[string]interface{}{}
+}
+
+// structSchema defines the schema of a named struct once and returns a reference to it
+func (g *schemaGenerator) structSchema(t reflect.Type) map[string]interface{} {
+	name := t.Name()
+	if name == "" {
+		return g.objectSchema(t)
+	}
+	// Unexported types such as errorResponse are exported in the document
+	name = strings.ToUpper(name[:1]) + name[1:]
+	if _, ok := g.defs[name]; !ok {
+		g.defs[name] = nil
+		g.defs[name] = g.objectSchema(t)
+	}
+	return map[string]interface{}{"$ref": g.refPrefix + name}
+}
+
+// objectSchema returns the schema of the JSON object a struct encodes to
+func (g *schemaGenerator) objectSchema(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	required := []string{}
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" && field.Anonymous && field.Type.Kind() == reflect.Struct {
+			// Fields of embedded structs are promoted into the object
+			embedded := g.objectSchema(field.Type)
+			for key, value := range embedded["properties"].(map[string]interface{}) {
+				properties[key] = value
+			}
+			required = append(required, embedded["required"].([]string)...)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = g.schema(field.Type)
+		if !strings.Contains(options, "omitempty") {
+			required = append(required, name)
+		}
+	}
+	return map[string]interface{}{"type": "object", "properties": properties, "required": required}
+}
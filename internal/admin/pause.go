@@ -0,0 +1,84 @@
+package admin
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/pause"
+)
+
+// pauseRequest is the optional JSON body of pause and resume requests
+type pauseRequest struct {
+	Reason string `json:"reason"`
+}
+
+// PauseHandler serves the pause state on GET and changes it on POST to /pause or /resume
+type PauseHandler struct {
+	Switch   *pause.Switch
+	Identify Identifier
+}
+
+// Register adds the pause endpoints to the metrics server
+func (h *PauseHandler) Register(add func(path string, handler http.Handler) error) error {
+	if err := add("/pause", h.handle(h.Switch.Pause)); err != nil {
+		return err
+	}
+	return add("/resume", h.handle(h.Switch.Resume))
+}
+
+// Endpoints implements Describer
+func (h *PauseHandler) Endpoints() []Endpoint {
+	var endpoints []Endpoint
+	for _, path := range []string{"/pause", "/resume"} {
+		responses := map[int]interface{}{
+			http.StatusOK: pause.State{}, http.StatusInternalServerError: errorResponse{},
+		}
+		endpoints = append(endpoints, Endpoint{
+			Path: path, Method: http.MethodGet, Summary: "Show the kill switch state", Responses: responses,
+		}, Endpoint{
+			Path: path, Method: http.MethodPost, Summary: "Set the kill switch, " + strings.TrimPrefix(path, "/") + " writes",
+			Parameters: []Parameter{{Name: "reason", Description: "Reason recorded with the change, unless the body has one"}},
+			Body:       pauseRequest{},
+			Responses: map[int]interface{}{
+				http.StatusOK: pause.State{}, http.StatusBadRequest: errorResponse{},
+				http.StatusInternalServerError: errorResponse{},
+			},
+		})
+	}
+	return endpoints
+}
+
+func (h *PauseHandler) handle(change func(ctx context.Context, who, reason string) (pause.State, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet:
+			state, err := h.Switch.State(req.Context())
+			if err != nil {
+				writeError(w, http.StatusInternalServerError, err)
+				return
+			}
+			writeJSON(w, http.StatusOK, state)
+		case http.MethodPost:
+			var body pauseRequest
+			if req.ContentLength > 0 {
+				if err := decodeJSON(req, &body); err != nil {
+					writeError(w, http.StatusBadRequest, err)
+					return
+				}
+			}
+			if body.Reason == "" {
+				body.Reason = req.URL.Query().Get("reason")
+			}
+			state, err := change(req.Context(), h.Identify(req), body.Reason)
+			if err != nil {
+				writeError(w, http.StatusInternalServerError, err)
+				return
+			}
+			writeJSON(w, http.StatusOK, state)
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+}
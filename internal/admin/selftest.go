@@ -0,0 +1,43 @@
+package admin
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/controller"
+)
+
+// SelfTestHandler runs the decision engine self-test and answers 200 when it passes
+// and 503 when it fails, so it can be probed directly by monitoring
+type SelfTestHandler struct {
+	Run func(ctx context.Context) controller.SelfTestResult
+}
+
+// Register adds the self-test endpoint to the metrics server
+func (h *SelfTestHandler) Register(add func(path string, handler http.Handler) error) error {
+	return add("/selftest", h)
+}
+
+// Endpoints implements Describer
+func (h *SelfTestHandler) Endpoints() []Endpoint {
+	return []Endpoint{{
+		Path: "/selftest", Method: http.MethodGet, Summary: "Run the decision engine self-test",
+		Responses: map[int]interface{}{
+			http.StatusOK: controller.SelfTestResult{}, http.StatusServiceUnavailable: controller.SelfTestResult{},
+		},
+	}}
+}
+
+func (h *SelfTestHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	result := h.Run(req.Context())
+	status := http.StatusOK
+	if !result.Passed {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, result)
+}
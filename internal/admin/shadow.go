@@ -0,0 +1,34 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/controller"
+)
+
+// ShadowHandler serves the shadow mode diff report
+type ShadowHandler struct {
+	Report *controller.ShadowReport
+}
+
+// Register adds the shadow report endpoint to the metrics server
+func (h *ShadowHandler) Register(add func(path string, handler http.Handler) error) error {
+	return add("/shadow", h)
+}
+
+// Endpoints implements Describer
+func (h *ShadowHandler) Endpoints() []Endpoint {
+	return []Endpoint{{
+		Path: "/shadow", Method: http.MethodGet, Summary: "Report the divergences found in shadow mode",
+		Responses: map[int]interface{}{http.StatusOK: controller.ShadowSummary{}},
+	}}
+}
+
+func (h *ShadowHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, http.StatusOK, h.Report.Summary())
+}
@@ -0,0 +1,61 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/controller"
+)
+
+// WhoUsesHandler serves the workloads referencing a ConfigMap on GET /who-uses?namespace=&name=
+type WhoUsesHandler struct {
+	WhoUses func(ctx context.Context, key types.NamespacedName) ([]controller.ConfigMapUser, error)
+}
+
+// Register adds the who-uses endpoint to the metrics server
+func (h *WhoUsesHandler) Register(add func(path string, handler http.Handler) error) error {
+	return add("/who-uses", h)
+}
+
+// Endpoints implements Describer
+func (h *WhoUsesHandler) Endpoints() []Endpoint {
+	return []Endpoint{{
+		Path: "/who-uses", Method: http.MethodGet, Summary: "List the workloads referencing a ConfigMap",
+		Parameters: []Parameter{
+			{Name: "namespace", Description: "Namespace of the ConfigMap", Required: true},
+			{Name: "name", Description: "Name of the ConfigMap", Required: true},
+		},
+		Responses: map[int]interface{}{
+			http.StatusOK: []controller.ConfigMapUser{}, http.StatusBadRequest: errorResponse{},
+			http.StatusInternalServerError: errorResponse{},
+		},
+	}}
+}
+
+func (h *WhoUsesHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	key := types.NamespacedName{
+		Namespace: req.URL.Query().Get("namespace"),
+		Name:      req.URL.Query().Get("name"),
+	}
+	if key.Namespace == "" || key.Name == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("the namespace and name query parameters are required"))
+		return
+	}
+	users, err := h.WhoUses(req.Context(), key)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if users == nil {
+		users = []controller.ConfigMapUser{}
+	}
+	writeJSON(w, http.StatusOK, users)
+}
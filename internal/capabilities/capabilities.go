@@ -0,0 +1,133 @@
+// Package capabilities detects which APIs the cluster serves, so features depending on newer
+// Kubernetes versions or optional APIs are disabled with a clear message on clusters lacking them.
+package capabilities
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/discovery"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+// Capabilities lists the optional APIs and behaviors the operator depends on
+type Capabilities struct {
+	// ServerVersion is the Kubernetes version reported by the API server, e.g. v1.30.2
+	ServerVersion string
+
+	// Minor is the Kubernetes minor version, 0 when it can't be parsed
+	Minor int
+
+	// TokenRequest reports whether service account tokens can be requested (serviceaccounts/token)
+	TokenRequest bool
+
+	// AccessReviews reports whether SelfSubjectAccessReviews are served
+	AccessReviews bool
+
+	// Leases reports whether coordination.k8s.io/v1 Leases, used for leader election, are served
+	Leases bool
+
+	// WebhookConfigurations reports whether admissionregistration.k8s.io/v1 webhook configurations are served
+	WebhookConfigurations bool
+
+	// ValidatingAdmissionPolicies reports whether admissionregistration.k8s.io/v1 ValidatingAdmissionPolicies are served
+	ValidatingAdmissionPolicies bool
+
+	// SidecarContainers reports whether init containers with restartPolicy Always run as native sidecars (1.29+)
+	SidecarContainers bool
+}
+
+// sidecarMinor is the first minor version with native sidecar containers enabled by default
+const sidecarMinor = 29
+
+// Detect queries the discovery API for the capabilities of the cluster
+func Detect(d discovery.DiscoveryInterface) (*Capabilities, error) {
+	info, err := d.ServerVersion()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get server version: %w", err)
+	}
+	c := &Capabilities{ServerVersion: info.GitVersion, Minor: parseMinor(info.Minor)}
+	c.SidecarContainers = c.Minor >= sidecarMinor
+
+	served := func(groupVersion, resource string) (bool, error) {
+		list, err := d.ServerResourcesForGroupVersion(groupVersion)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return false, nil
+			}
+			return false, fmt.Errorf("unable to discover %s: %w", groupVersion, err)
+		}
+		for _, r := range list.APIResources {
+			if r.Name == resource {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	checks := []struct {
+		target       *bool
+		groupVersion string
+		resource     string
+	}{
+		{&c.TokenRequest, "v1", "serviceaccounts/token"},
+		{&c.AccessReviews, "authorization.k8s.io/v1", "selfsubjectaccessreviews"},
+		{&c.Leases, "coordination.k8s.io/v1", "leases"},
+		{&c.WebhookConfigurations, "admissionregistration.k8s.io/v1", "validatingwebhookconfigurations"},
+		{&c.ValidatingAdmissionPolicies, "admissionregistration.k8s.io/v1", "validatingadmissionpolicies"},
+	}
+	for _, check := range checks {
+		if *check.target, err = served(check.groupVersion, check.resource); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// All returns capabilities assuming every API is served, for when discovery fails
+func All() *Capabilities {
+	return &Capabilities{
+		TokenRequest:                true,
+		AccessReviews:               true,
+		Leases:                      true,
+		WebhookConfigurations:       true,
+		ValidatingAdmissionPolicies: true,
+		SidecarContainers:           true,
+	}
+}
+
+// parseMinor parses a minor version such as "30" or "30+"
+func parseMinor(minor string) int {
+	n, err := strconv.Atoi(strings.TrimSuffix(minor, "+"))
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// Degrade disables the features of cfg the cluster can't support and returns a message for each
+func (c *Capabilities) Degrade(cfg *config.OperatorConfig) []string {
+	var changes []string
+	if len(cfg.TenantServiceAccounts) > 0 && !c.TokenRequest {
+		cfg.TenantServiceAccounts = nil
+		changes = append(changes,
+			"per-tenant credentials disabled: the cluster does not serve serviceaccounts/token, writes use the operator's identity")
+	}
+	return changes
+}
+
+// Summary returns the capabilities as structured logging key/value pairs
+func (c *Capabilities) Summary() []interface{} {
+	return []interface{}{
+		"serverVersion", c.ServerVersion,
+		"tokenRequest", c.TokenRequest,
+		"accessReviews", c.AccessReviews,
+		"leases", c.Leases,
+		"webhookConfigurations", c.WebhookConfigurations,
+		"validatingAdmissionPolicies", c.ValidatingAdmissionPolicies,
+		"sidecarContainers", c.SidecarContainers,
+	}
+}
@@ -0,0 +1,64 @@
+package capabilities
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+// fakeDiscovery serves the given resources, keyed by group version, on a cluster of the given minor version
+func fakeDiscovery(minor string, resources map[string][]string) *fakediscovery.FakeDiscovery {
+	d := fake.NewSimpleClientset().Discovery().(*fakediscovery.FakeDiscovery)
+	d.FakedServerVersion = &version.Info{Major: "1", Minor: minor, GitVersion: "v1." + strings.TrimSuffix(minor, "+") + ".0"}
+	for groupVersion, names := range resources {
+		list := &metav1.APIResourceList{GroupVersion: groupVersion}
+		for _, name := range names {
+			list.APIResources = append(list.APIResources, metav1.APIResource{Name: name})
+		}
+		d.Resources = append(d.Resources, list)
+	}
+	return d
+}
+
+var _ = ginkgo.Describe("Capabilities", func() {
+	ginkgo.It("should detect the served APIs", func() {
+		caps, err := Detect(fakeDiscovery("30+", map[string][]string{
+			"v1":                              {"configmaps", "serviceaccounts", "serviceaccounts/token"},
+			"coordination.k8s.io/v1":          {"leases"},
+			"admissionregistration.k8s.io/v1": {"validatingwebhookconfigurations", "validatingadmissionpolicies"},
+		}))
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(*caps).To(gomega.Equal(Capabilities{
+			ServerVersion:               "v1.30.0",
+			Minor:                       30,
+			TokenRequest:                true,
+			Leases:                      true,
+			WebhookConfigurations:       true,
+			ValidatingAdmissionPolicies: true,
+			SidecarContainers:           true,
+		}))
+	})
+
+	ginkgo.It("should disable per-tenant credentials without the TokenRequest API", func() {
+		caps, err := Detect(fakeDiscovery("21", map[string][]string{"v1": {"serviceaccounts"}}))
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(caps.SidecarContainers).To(gomega.BeFalse())
+
+		cfg := &config.OperatorConfig{TenantServiceAccounts: map[string]string{"team-a": "writer"}}
+		gomega.Expect(caps.Degrade(cfg)).To(gomega.HaveLen(1))
+		gomega.Expect(cfg.TenantServiceAccounts).To(gomega.BeEmpty())
+	})
+})
+
+func TestCapabilities(t *testing.T) {
+	gomega.RegisterFailHandler(ginkgo.Fail)
+	ginkgo.RunSpecs(t, "Capabilities Suite")
+}
@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+	"github.com/matanbaruch/configmap-rs-operator/internal/controller"
+	"github.com/matanbaruch/configmap-rs-operator/internal/pause"
+)
+
+func init() {
+	register(&Command{
+		Name:  "adopt",
+		Short: "Add owner references for the existing ReplicaSets of a namespace",
+		Run:   runAdopt,
+	})
+}
+
+func runAdopt(ctx context.Context, args []string) (err error) {
+	var opts controller.AdoptOptions
+	var selector string
+	cfg := &config.OperatorConfig{}
+	fs := newFlagSet("adopt")
+	fs.StringVar(&opts.Namespace, "namespace", "", "Namespace whose ReplicaSets are adopted")
+	fs.StringVar(&selector, "selector", "", "Only adopt ReplicaSets matching this label selector, e.g. app=web")
+	fs.BoolVar(&cfg.DryRun, "dry-run", false, "Same as the operator's --dry-run flag")
+	fs.StringVar(&cfg.ControlConfigMap, "control-configmap", "", "Same as the operator's --control-configmap flag")
+	fs.BoolVar(&cfg.ConsumedKeysOnly, "consumed-keys-only", false, "Same as the operator's --consumed-keys-only flag")
+	fs.StringVar(&cfg.OptionalReferences, "optional-references", controller.OptionalOwn,
+		"Same as the operator's --optional-references flag")
+	pushgatewayURL := pushgatewayFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	start := time.Now()
+	defer func() { pushMetrics(ctx, *pushgatewayURL, "adopt", start, err) }()
+	if opts.Namespace == "" {
+		return fmt.Errorf("--namespace is required")
+	}
+	if selector != "" {
+		parsed, err := labels.Parse(selector)
+		if err != nil {
+			return fmt.Errorf("invalid --selector: %w", err)
+		}
+		opts.Selector = parsed
+	}
+	if err := controller.ValidateOptionalReferences(cfg.OptionalReferences); err != nil {
+		return err
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	pauseSwitch, err := pause.NewSwitch(c, cfg.ControlConfigMap)
+	if err != nil {
+		return err
+	}
+
+	// Without a start time, ReplicaSets created before the operator was deployed are adopted too
+	reconciler := &controller.ReplicaSetReconciler{Client: c, Scheme: scheme, Config: cfg, Pause: pauseSwitch}
+	decisions, err := reconciler.Adopt(ctx, opts)
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if encErr := enc.Encode(decisions); encErr != nil {
+		return encErr
+	}
+	return err
+}
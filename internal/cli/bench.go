@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+	"github.com/matanbaruch/configmap-rs-operator/internal/controller"
+)
+
+const benchNamespacePrefix = "configmap-rs-operator-bench-"
+
+func init() {
+	register(&Command{
+		Name:  "bench",
+		Short: "Measure reconcile throughput and API calls on synthetic ReplicaSets and ConfigMaps",
+		Run:   runBench,
+	})
+}
+
+func runBench(ctx context.Context, args []string) error {
+	opts := controller.BenchOptions{}
+	var inMemory bool
+	var ownerRules string
+	var qps float64
+	cfg := &config.OperatorConfig{}
+	fs := newFlagSet("bench")
+	fs.BoolVar(&inMemory, "in-memory", false,
+		"Run against an in-memory API instead of a new sandbox namespace of the current cluster")
+	fs.IntVar(&opts.ReplicaSets, "replicasets", 100, "Number of synthetic ReplicaSets")
+	fs.IntVar(&opts.ConfigMaps, "configmaps", 100, "Number of synthetic ConfigMaps")
+	fs.IntVar(&opts.Mounts, "mounts", 2, "Number of ConfigMaps each ReplicaSet mounts")
+	fs.IntVar(&opts.Concurrency, "concurrency", 1, "Number of reconciles run at once")
+	fs.Float64Var(&qps, "qps", 20, "Client-side rate limit of API calls per second (0: unlimited)")
+	fs.IntVar(&opts.Burst, "burst", 30, "Client-side burst of API calls")
+	fs.BoolVar(&cfg.DryRun, "dry-run", false, "Same as the operator's --dry-run flag")
+	fs.BoolVar(&cfg.ConsumedKeysOnly, "consumed-keys-only", false, "Same as the operator's --consumed-keys-only flag")
+	fs.StringVar(&ownerRules, "owner-rules", "", "Same as the operator's --owner-rules flag")
+	fs.StringVar(&cfg.OwnerTarget, "owner-target", controller.OwnerReplicaSet,
+		"Same as the operator's --owner-target flag")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	rules, err := controller.ParseOwnerRules(config.SplitSemicolons(ownerRules))
+	if err != nil {
+		return err
+	}
+	if err := controller.ValidateOwnerTarget(cfg.OwnerTarget); err != nil {
+		return err
+	}
+
+	var c client.Client
+	if inMemory {
+		c = fake.NewClientBuilder().WithScheme(scheme).Build()
+		opts.QPS = float32(qps)
+	} else {
+		restConfig, err := ctrl.GetConfig()
+		if err != nil {
+			return fmt.Errorf("unable to load kubeconfig: %w", err)
+		}
+		restConfig.QPS, restConfig.Burst = float32(qps), opts.Burst
+		if qps == 0 {
+			restConfig.QPS = -1
+		}
+		if c, err = client.New(restConfig, client.Options{Scheme: scheme}); err != nil {
+			return err
+		}
+	}
+
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{GenerateName: benchNamespacePrefix,
+		Labels: map[string]string{controller.BenchLabel: "true"}}}
+	if err := c.Create(ctx, ns); err != nil {
+		return fmt.Errorf("failed to create the sandbox namespace: %w", err)
+	}
+	opts.Namespace = ns.Name
+	defer func() {
+		if err := client.IgnoreNotFound(c.Delete(ctx, ns)); err != nil {
+			fmt.Fprintf(os.Stderr, "unable to delete the sandbox namespace %s: %v\n", ns.Name, err)
+		}
+	}()
+
+	reconciler := &controller.ReplicaSetReconciler{Client: c, Scheme: scheme, Config: cfg, OwnerRules: rules}
+	result, err := reconciler.Bench(ctx, opts)
+	if result != nil {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if encErr := enc.Encode(result); encErr != nil {
+			return encErr
+		}
+	}
+	return err
+}
@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+	"github.com/matanbaruch/configmap-rs-operator/internal/controller"
+)
+
+func init() {
+	register(&Command{
+		Name:  "cleanup",
+		Short: "Remove the owner references the operator added for some or all workload kinds",
+		Run:   runCleanup,
+	})
+}
+
+func runCleanup(ctx context.Context, args []string) (err error) {
+	opts := controller.CleanupOptions{}
+	var kinds, ownerRules string
+	var outOfScope bool
+	fs := newFlagSet("cleanup")
+	fs.StringVar(&opts.Namespace, "namespace", "", "Only clean up ConfigMaps in this namespace (default: all namespaces)")
+	fs.StringVar(&kinds, "kind", "", "Comma-separated owner kinds to remove, e.g. PodTemplate (default: every kind)")
+	fs.BoolVar(&opts.DryRun, "dry-run", false, "Only print what would be removed")
+	fs.BoolVar(&outOfScope, "out-of-scope", false,
+		"Only clean up ConfigMaps that --namespace-regex or --owner-rules exclude")
+	setNamespaces := namespaceFlags(fs)
+	fs.StringVar(&ownerRules, "owner-rules", "", "Same as the operator's --owner-rules flag")
+	pushgatewayURL := pushgatewayFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	start := time.Now()
+	defer func() { pushMetrics(ctx, *pushgatewayURL, "cleanup", start, err) }()
+	opts.Kinds = config.SplitList(kinds)
+	cfg := &config.OperatorConfig{}
+	if err := setNamespaces(cfg); err != nil {
+		return err
+	}
+	rules, err := controller.ParseOwnerRules(config.SplitSemicolons(ownerRules))
+	if err != nil {
+		return err
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	if outOfScope {
+		reconciler := &controller.ReplicaSetReconciler{Client: c, Scheme: scheme, Config: cfg, OwnerRules: rules}
+		opts.InScope = reconciler.InScope
+	}
+	changed, err := controller.RemoveManagedOwnerReferences(ctx, c, c, opts, ctrl.Log.WithName("cleanup"))
+	if err != nil {
+		return fmt.Errorf("owner reference cleanup failed: %w", err)
+	}
+	fmt.Printf("cleaned owner references from %d ConfigMaps\n", changed)
+	return nil
+}
@@ -0,0 +1,121 @@
+// Package cli implements the operator's one-shot subcommands, such as install and uninstall.
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+	"github.com/matanbaruch/configmap-rs-operator/internal/controller"
+	"github.com/matanbaruch/configmap-rs-operator/internal/pushgateway"
+)
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+}
+
+// Command is a subcommand of the operator binary
+type Command struct {
+	// Name is the word used to invoke the command, e.g. "install"
+	Name string
+
+	// Short is a one-line description shown in the usage output
+	Short string
+
+	// Run executes the command with the arguments following its name
+	Run func(ctx context.Context, args []string) error
+}
+
+var commands = map[string]*Command{}
+
+func register(cmd *Command) {
+	commands[cmd.Name] = cmd
+}
+
+// Lookup returns the subcommand with the given name
+func Lookup(name string) (*Command, bool) {
+	cmd, ok := commands[name]
+	return cmd, ok
+}
+
+// PrintUsage writes the list of available subcommands to w
+func PrintUsage(w io.Writer) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	_, _ = fmt.Fprintln(w, "Subcommands:")
+	for _, name := range names {
+		_, _ = fmt.Fprintf(w, "  %-12s %s\n", name, commands[name].Short)
+	}
+}
+
+// newFlagSet creates the flag set for a subcommand
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	return fs
+}
+
+// namespaceFlags adds the operator's --namespace-regex, --namespace-match and --namespace-exclude-regex flags to fs.
+// The returned function sets the namespaces they select on cfg once the flags are parsed.
+func namespaceFlags(fs *flag.FlagSet) func(cfg *config.OperatorConfig) error {
+	var patterns, mode, exclude string
+	fs.StringVar(&patterns, "namespace-regex", "", "Same as the operator's --namespace-regex flag")
+	fs.StringVar(&mode, "namespace-match", controller.NamespaceMatchAuto, "Same as the operator's --namespace-match flag")
+	fs.StringVar(&exclude, "namespace-exclude-regex", "", "Same as the operator's --namespace-exclude-regex flag")
+	return func(cfg *config.OperatorConfig) error {
+		var err error
+		cfg.NamespaceRegex, cfg.NamespaceExclude, err = controller.NamespacePatterns(config.SplitList(patterns), mode)
+		cfg.NamespaceExclude = append(cfg.NamespaceExclude, config.SplitList(exclude)...)
+		return err
+	}
+}
+
+// wrapClient, when set, wraps the clients newClient returns, e.g. to plan the writes of a command
+var wrapClient func(c client.Client) client.Client
+
+// newClient builds a client for the cluster selected by the standard kubeconfig resolution
+func newClient() (client.Client, error) {
+	cfg, err := ctrl.GetConfig()
+	if err != nil {
+		return nil, fmt.Errorf("unable to load kubeconfig: %w", err)
+	}
+	c, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil || wrapClient == nil {
+		return c, err
+	}
+	return wrapClient(c), nil
+}
+
+// pushgatewayFlag registers --pushgateway-url on a batch subcommand, defaulting to $PUSHGATEWAY_URL
+func pushgatewayFlag(fs *flag.FlagSet) *string {
+	return fs.String("pushgateway-url", os.Getenv("PUSHGATEWAY_URL"),
+		"Prometheus Pushgateway the run's metrics are pushed to when it ends (default: disabled)")
+}
+
+// pushMetrics pushes the metrics of the run of job that started at start to the Pushgateway at url, if any.
+// A failed push is reported but doesn't fail the run.
+func pushMetrics(ctx context.Context, url, job string, start time.Time, runErr error) {
+	if url == "" {
+		return
+	}
+	if err := pushgateway.Push(ctx, url, job, start, runErr); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", job, err)
+	}
+}
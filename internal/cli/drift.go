@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+	"github.com/matanbaruch/configmap-rs-operator/internal/controller"
+)
+
+func init() {
+	register(&Command{
+		Name:  "drift",
+		Short: "Print where the recorded and actual owner references of ConfigMaps disagree, as JSON",
+		Run:   runDrift,
+	})
+}
+
+func runDrift(ctx context.Context, args []string) error {
+	var ownerRules string
+	cfg := &config.OperatorConfig{}
+	fs := newFlagSet("drift")
+	fs.BoolVar(&cfg.PodTemplates, "pod-templates", false, "Same as the operator's --pod-templates flag")
+	fs.BoolVar(&cfg.StatefulSets, "statefulsets", false, "Same as the operator's --statefulsets flag")
+	fs.BoolVar(&cfg.DaemonSets, "daemonsets", false, "Same as the operator's --daemonsets flag")
+	fs.BoolVar(&cfg.Jobs, "jobs", false, "Same as the operator's --jobs flag")
+	fs.BoolVar(&cfg.CronJobs, "cronjobs", false, "Same as the operator's --cronjobs flag")
+	fs.StringVar(&cfg.CronJobOwner, "cronjob-owner", "cronjob", "Same as the operator's --cronjob-owner flag")
+	fs.BoolVar(&cfg.Pods, "pods", false, "Same as the operator's --pods flag")
+	fs.StringVar(&ownerRules, "owner-rules", "", "Same as the operator's --owner-rules flag")
+	fs.StringVar(&cfg.OwnerTarget, "owner-target", controller.OwnerReplicaSet,
+		"Same as the operator's --owner-target flag")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	rules, err := controller.ParseOwnerRules(config.SplitSemicolons(ownerRules))
+	if err != nil {
+		return err
+	}
+	if err := controller.ValidateOwnerTarget(cfg.OwnerTarget); err != nil {
+		return err
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	reconciler := &controller.ReplicaSetReconciler{Client: c, Scheme: scheme, Config: cfg, OwnerRules: rules}
+	drifts, err := reconciler.ScanDrift(ctx)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(drifts)
+}
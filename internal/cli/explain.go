@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+	"github.com/matanbaruch/configmap-rs-operator/internal/controller"
+	"github.com/matanbaruch/configmap-rs-operator/internal/pause"
+)
+
+func init() {
+	register(&Command{
+		Name:  "explain",
+		Short: "Print which workloads reference a ConfigMap and why ownership was or wasn't added",
+		Run:   runExplain,
+	})
+}
+
+func runExplain(ctx context.Context, args []string) error {
+	var key types.NamespacedName
+	cfg := &config.OperatorConfig{}
+	fs := newFlagSet("explain")
+	fs.StringVar(&key.Namespace, "namespace", "", "Namespace of the ConfigMap")
+	fs.StringVar(&key.Name, "name", "", "Name of the ConfigMap")
+	setNamespaces := namespaceFlags(fs)
+	fs.BoolVar(&cfg.DryRun, "dry-run", false, "Same as the operator's --dry-run flag")
+	fs.StringVar(&cfg.ControlConfigMap, "control-configmap", "", "Same as the operator's --control-configmap flag")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if key.Namespace == "" || key.Name == "" {
+		return fmt.Errorf("--namespace and --name are required")
+	}
+	if err := setNamespaces(cfg); err != nil {
+		return err
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	pauseSwitch, err := pause.NewSwitch(c, cfg.ControlConfigMap)
+	if err != nil {
+		return err
+	}
+
+	// Without a running operator there is no start time, so no ReplicaSet is skipped for predating it;
+	// query GET /explain on the operator's metrics server for the running operator's view
+	reconciler := &controller.ReplicaSetReconciler{Client: c, Scheme: scheme, Config: cfg, Pause: pauseSwitch}
+	explanation, err := reconciler.Explain(ctx, key)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(explanation)
+}
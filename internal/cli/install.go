@@ -0,0 +1,337 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/wait"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+	"github.com/matanbaruch/configmap-rs-operator/internal/controller"
+)
+
+const (
+	defaultInstallNamespace = "configmap-rs-operator-system"
+	defaultImage            = "ghcr.io/matanbaruch/configmap-rs-operator:latest"
+	namePrefix              = "configmap-rs-operator-"
+	fieldOwner              = "configmap-rs-operator"
+	managedByLabel          = "app.kubernetes.io/managed-by"
+
+	// stopTimeout bounds how long uninstall waits for the operator pods to terminate
+	stopTimeout      = 2 * time.Minute
+	stopPollInterval = 2 * time.Second
+)
+
+func init() {
+	register(&Command{
+		Name:  "install",
+		Short: "Apply the operator's RBAC and Deployment to the current cluster",
+		Run:   runInstall,
+	})
+	register(&Command{
+		Name:  "uninstall",
+		Short: "Stop the operator, remove its owner references, then delete its manifests",
+		Run:   runUninstall,
+	})
+}
+
+// installOptions holds the settings used to render the operator manifests
+type installOptions struct {
+	namespace string
+	image     string
+	args      []string
+	dryRun    bool
+}
+
+func runInstall(ctx context.Context, args []string) error {
+	opts := installOptions{}
+	var extraArgs string
+	fs := newFlagSet("install")
+	fs.StringVar(&opts.namespace, "namespace", defaultInstallNamespace, "Namespace to install the operator into")
+	fs.StringVar(&opts.image, "image", defaultImage, "Operator container image")
+	fs.StringVar(&extraArgs, "operator-args", "",
+		"Comma-separated list of extra arguments passed to the operator, e.g. --dry-run,--debug")
+	fs.BoolVar(&opts.dryRun, "dry-run", false, "Validate the manifests server-side without persisting them")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	opts.args = config.SplitList(extraArgs)
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	applyOpts := []client.PatchOption{client.FieldOwner(fieldOwner), client.ForceOwnership}
+	if opts.dryRun {
+		applyOpts = append(applyOpts, client.DryRunAll)
+	}
+	for _, obj := range installObjects(opts) {
+		if ns, ok := obj.(*corev1.Namespace); ok {
+			exists, created, err := installerNamespace(ctx, c, ns.Name)
+			if err != nil {
+				return err
+			}
+			// Labeling an existing namespace would have uninstall delete it
+			if exists && !created {
+				fmt.Printf("using existing Namespace/%s\n", ns.Name)
+				continue
+			}
+		}
+		if err := c.Patch(ctx, obj, client.Apply, applyOpts...); err != nil {
+			return fmt.Errorf("unable to apply %s %s: %w", obj.GetObjectKind().GroupVersionKind().Kind, obj.GetName(), err)
+		}
+		fmt.Printf("applied %s/%s\n", obj.GetObjectKind().GroupVersionKind().Kind, obj.GetName())
+	}
+	return nil
+}
+
+func runUninstall(ctx context.Context, args []string) error {
+	opts := installOptions{}
+	var skipCleanup bool
+	fs := newFlagSet("uninstall")
+	fs.StringVar(&opts.namespace, "namespace", defaultInstallNamespace, "Namespace the operator is installed in")
+	fs.BoolVar(&skipCleanup, "skip-cleanup", false,
+		"Do not remove operator-added owner references before deleting the manifests")
+	fs.BoolVar(&opts.dryRun, "dry-run", false, "Only print what would be removed")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	objs := installObjects(opts)
+	// Stop the operator first, so it doesn't add back the owner references being removed
+	deployment := objs[len(objs)-1]
+	if err := stopOperator(ctx, c, deployment, opts.dryRun); err != nil {
+		return err
+	}
+	if !skipCleanup {
+		changed, err := controller.RemoveManagedOwnerReferences(ctx, c, c,
+			controller.CleanupOptions{DryRun: opts.dryRun}, ctrl.Log.WithName("uninstall"))
+		if err != nil {
+			return fmt.Errorf("owner reference cleanup failed: %w", err)
+		}
+		fmt.Printf("cleaned owner references from %d ConfigMaps\n", changed)
+	}
+
+	var deleteOpts []client.DeleteOption
+	if opts.dryRun {
+		deleteOpts = append(deleteOpts, client.DryRunAll)
+	}
+	for i := len(objs) - 2; i >= 0; i-- {
+		obj := objs[i]
+		if ns, ok := obj.(*corev1.Namespace); ok {
+			exists, created, err := installerNamespace(ctx, c, ns.Name)
+			if err != nil {
+				return err
+			}
+			if exists && !created {
+				fmt.Printf("kept Namespace/%s, which install did not create\n", ns.Name)
+				continue
+			}
+		}
+		if err := c.Delete(ctx, obj, deleteOpts...); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("unable to delete %s %s: %w", obj.GetObjectKind().GroupVersionKind().Kind, obj.GetName(), err)
+		}
+		fmt.Printf("deleted %s/%s\n", obj.GetObjectKind().GroupVersionKind().Kind, obj.GetName())
+	}
+	return nil
+}
+
+// stopOperator deletes the operator Deployment and waits until its pods are gone
+func stopOperator(ctx context.Context, c client.Client, deployment client.Object, dryRun bool) error {
+	deleteOpts := []client.DeleteOption{client.PropagationPolicy(metav1.DeletePropagationForeground)}
+	if dryRun {
+		deleteOpts = append(deleteOpts, client.DryRunAll)
+	}
+	if err := c.Delete(ctx, deployment, deleteOpts...); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("unable to delete Deployment %s: %w", deployment.GetName(), err)
+	}
+	fmt.Printf("deleted Deployment/%s\n", deployment.GetName())
+	if dryRun {
+		return nil
+	}
+	// With foreground deletion the Deployment remains until its ReplicaSets and pods are deleted
+	key := client.ObjectKeyFromObject(deployment)
+	err := wait.PollUntilContextTimeout(ctx, stopPollInterval, stopTimeout, true, func(ctx context.Context) (bool, error) {
+		err := c.Get(ctx, key, &appsv1.Deployment{})
+		if errors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	})
+	if err != nil {
+		return fmt.Errorf("operator Deployment %s did not stop: %w", deployment.GetName(), err)
+	}
+	return nil
+}
+
+// installerNamespace reports whether the namespace name exists, and whether install created it, which it labels as
+// managed by the operator
+func installerNamespace(ctx context.Context, c client.Reader, name string) (exists, created bool, err error) {
+	var ns corev1.Namespace
+	if err := c.Get(ctx, client.ObjectKey{Name: name}, &ns); err != nil {
+		if errors.IsNotFound(err) {
+			return false, false, nil
+		}
+		return false, false, fmt.Errorf("unable to get Namespace %s: %w", name, err)
+	}
+	return true, ns.Labels[managedByLabel] == fieldOwner, nil
+}
+
+// installObjects renders the operator manifests in apply order
+func installObjects(opts installOptions) []client.Object {
+	labels := map[string]string{
+		"app.kubernetes.io/name": "configmap-rs-operator",
+		managedByLabel:           fieldOwner,
+	}
+	serviceAccount := namePrefix + "controller-manager"
+
+	meta := func(name, namespace string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels}
+	}
+
+	return []client.Object{
+		&corev1.Namespace{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Namespace"},
+			ObjectMeta: meta(opts.namespace, ""),
+		},
+		&corev1.ServiceAccount{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ServiceAccount"},
+			ObjectMeta: meta(serviceAccount, opts.namespace),
+		},
+		&rbacv1.ClusterRole{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRole"},
+			ObjectMeta: meta(namePrefix+"manager-role", ""),
+			Rules:      managerRules(),
+		},
+		&rbacv1.ClusterRoleBinding{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRoleBinding"},
+			ObjectMeta: meta(namePrefix+"manager-rolebinding", ""),
+			RoleRef: rbacv1.RoleRef{
+				APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: namePrefix + "manager-role",
+			},
+			Subjects: []rbacv1.Subject{{Kind: "ServiceAccount", Name: serviceAccount, Namespace: opts.namespace}},
+		},
+		&rbacv1.Role{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "Role"},
+			ObjectMeta: meta(namePrefix+"leader-election-role", opts.namespace),
+			Rules:      leaderElectionRules(),
+		},
+		&rbacv1.RoleBinding{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "RoleBinding"},
+			ObjectMeta: meta(namePrefix+"leader-election-rolebinding", opts.namespace),
+			RoleRef: rbacv1.RoleRef{
+				APIGroup: rbacv1.GroupName, Kind: "Role", Name: namePrefix + "leader-election-role",
+			},
+			Subjects: []rbacv1.Subject{{Kind: "ServiceAccount", Name: serviceAccount, Namespace: opts.namespace}},
+		},
+		managerDeployment(opts, meta(namePrefix+"controller-manager", opts.namespace), serviceAccount),
+	}
+}
+
+// managerRules returns the rules of config/rbac/role.yaml, in its order; a test keeps them in sync
+func managerRules() []rbacv1.PolicyRule {
+	all := []string{"create", "delete", "get", "list", "patch", "update", "watch"}
+	read := []string{"get", "list", "watch"}
+	annotate := []string{"get", "list", "patch", "watch"}
+	return []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: all},
+		{APIGroups: []string{""}, Resources: []string{"events"}, Verbs: []string{"create", "patch"}},
+		{APIGroups: []string{""}, Resources: []string{"namespaces"}, Verbs: read},
+		{APIGroups: []string{""}, Resources: []string{"pods", "podtemplates"}, Verbs: annotate},
+		{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get", "list", "patch", "update", "watch"}},
+		{
+			APIGroups: []string{"admissionregistration.k8s.io"},
+			Resources: []string{"mutatingwebhookconfigurations", "validatingwebhookconfigurations"},
+			Verbs:     []string{"get"},
+		},
+		{APIGroups: []string{"apps"}, Resources: []string{"daemonsets", "deployments", "statefulsets"}, Verbs: annotate},
+		{APIGroups: []string{"apps"}, Resources: []string{"replicasets"}, Verbs: all},
+		{APIGroups: []string{"batch"}, Resources: []string{"cronjobs", "jobs"}, Verbs: annotate},
+		{APIGroups: []string{"networking.k8s.io"}, Resources: []string{"ingresses"}, Verbs: read},
+	}
+}
+
+func leaderElectionRules() []rbacv1.PolicyRule {
+	all := []string{"get", "list", "watch", "create", "update", "patch", "delete"}
+	return []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: all},
+		{APIGroups: []string{"coordination.k8s.io"}, Resources: []string{"leases"}, Verbs: all},
+		{APIGroups: []string{""}, Resources: []string{"events"}, Verbs: []string{"create", "patch"}},
+	}
+}
+
+func managerDeployment(opts installOptions, meta metav1.ObjectMeta, serviceAccount string) *appsv1.Deployment {
+	selector := map[string]string{
+		"control-plane":          "controller-manager",
+		"app.kubernetes.io/name": "configmap-rs-operator",
+	}
+	replicas := int32(1)
+	runAsNonRoot := true
+	allowPrivilegeEscalation := false
+	readOnlyRootFilesystem := true
+	terminationGracePeriod := int64(10)
+
+	probe := func(path string, delay, period int32) *corev1.Probe {
+		return &corev1.Probe{
+			ProbeHandler: corev1.ProbeHandler{
+				HTTPGet: &corev1.HTTPGetAction{Path: path, Port: intstr.FromInt32(8081)},
+			},
+			InitialDelaySeconds: delay,
+			PeriodSeconds:       period,
+		}
+	}
+
+	return &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: meta,
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: selector},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      selector,
+					Annotations: map[string]string{"kubectl.kubernetes.io/default-container": "manager"},
+				},
+				Spec: corev1.PodSpec{
+					ServiceAccountName:            serviceAccount,
+					TerminationGracePeriodSeconds: &terminationGracePeriod,
+					SecurityContext: &corev1.PodSecurityContext{
+						RunAsNonRoot:   &runAsNonRoot,
+						SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+					},
+					Containers: []corev1.Container{{
+						Name:           "manager",
+						Image:          opts.image,
+						Command:        []string{"/manager"},
+						Args:           append([]string{"--leader-elect", "--health-probe-bind-address=:8081"}, opts.args...),
+						LivenessProbe:  probe("/healthz", 15, 20),
+						ReadinessProbe: probe("/readyz", 5, 10),
+						SecurityContext: &corev1.SecurityContext{
+							AllowPrivilegeEscalation: &allowPrivilegeEscalation,
+							ReadOnlyRootFilesystem:   &readOnlyRootFilesystem,
+							Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+						},
+					}},
+				},
+			},
+		},
+	}
+}
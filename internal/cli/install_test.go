@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+)
+
+var _ = ginkgo.Describe("Install", func() {
+	var ctx context.Context
+
+	ginkgo.BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	ginkgo.It("Should label the namespace it creates and only claim that one", func() {
+		objs := installObjects(installOptions{namespace: "ops"})
+		gomega.Expect(objs[0].GetLabels()).To(gomega.HaveKeyWithValue(managedByLabel, fieldOwner))
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ops", Labels: objs[0].GetLabels()}},
+		).Build()
+		for name, want := range map[string][2]bool{
+			"default": {true, false}, "ops": {true, true}, "missing": {false, false},
+		} {
+			exists, created, err := installerNamespace(ctx, c, name)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect([2]bool{exists, created}).To(gomega.Equal(want), name)
+		}
+	})
+
+	ginkgo.It("Should grant the rules of config/rbac", func() {
+		data, err := os.ReadFile(filepath.Join("..", "..", "config", "rbac", "role.yaml"))
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		var role rbacv1.ClusterRole
+		gomega.Expect(yaml.Unmarshal(data, &role)).To(gomega.Succeed())
+		gomega.Expect(managerRules()).To(gomega.Equal(role.Rules))
+	})
+
+	ginkgo.It("Should delete the operator Deployment before anything else", func() {
+		objs := installObjects(installOptions{namespace: "ops"})
+		deployment := objs[len(objs)-1]
+		gomega.Expect(deployment).To(gomega.BeAssignableToTypeOf(&appsv1.Deployment{}))
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(deployment).Build()
+		gomega.Expect(stopOperator(ctx, c, deployment, true)).To(gomega.Succeed())
+		gomega.Expect(c.Get(ctx, client.ObjectKeyFromObject(deployment), &appsv1.Deployment{})).To(gomega.Succeed())
+
+		gomega.Expect(stopOperator(ctx, c, deployment, false)).To(gomega.Succeed())
+		err := c.Get(ctx, client.ObjectKeyFromObject(deployment), &appsv1.Deployment{})
+		gomega.Expect(client.IgnoreNotFound(err)).To(gomega.Succeed())
+		gomega.Expect(err).To(gomega.HaveOccurred())
+
+		// An operator already gone is not an error
+		gomega.Expect(stopOperator(ctx, c, deployment, false)).To(gomega.Succeed())
+	})
+})
+
+func TestCLI(t *testing.T) {
+	gomega.RegisterFailHandler(ginkgo.Fail)
+	ginkgo.RunSpecs(t, "CLI Suite")
+}
@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/controller"
+)
+
+func init() {
+	register(&Command{
+		Name:  "migrate",
+		Short: "Upgrade metadata written by earlier releases to the current ownership semantics",
+		Run:   runMigrate,
+	})
+}
+
+func runMigrate(ctx context.Context, args []string) error {
+	opts := controller.MigrationOptions{}
+	fs := newFlagSet("migrate")
+	fs.StringVar(&opts.Namespace, "namespace", "", "Only migrate ConfigMaps in this namespace (default: all namespaces)")
+	fs.BoolVar(&opts.DryRun, "dry-run", false, "Only print what would be migrated")
+	fs.Int64Var(&opts.BatchSize, "batch-size", 100, "Number of ConfigMaps listed per request")
+	fs.DurationVar(&opts.BatchInterval, "batch-interval", 0, "Pause between batches, e.g. 1s")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	result, err := controller.MigrateConfigMaps(ctx, c, c, opts, ctrl.Log.WithName("migrate"))
+	fmt.Printf("scanned %d ConfigMaps, migrated %d to semantics version %d\n",
+		result.Scanned, result.Migrated, controller.SemanticsVersion())
+	if err != nil {
+		return fmt.Errorf("migration stopped, run it again to resume: %w", err)
+	}
+	return nil
+}
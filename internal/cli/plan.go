@@ -0,0 +1,185 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/controller"
+)
+
+// plannable are the commands whose changes plan can capture
+var plannable = []string{"adopt", "cleanup", "repair"}
+
+const (
+	// planConfigMapPrefix marks a plan location as a ConfigMap, configmap:<namespace>/<name>
+	planConfigMapPrefix = "configmap:"
+
+	// planKey is the ConfigMap data key holding a plan
+	planKey = "plan.json"
+)
+
+func init() {
+	register(&Command{
+		Name:  "plan",
+		Short: "Write the changes of adopt, cleanup or repair to a plan instead of making them",
+		Run:   runPlan,
+	})
+	register(&Command{
+		Name:  "apply",
+		Short: "Make exactly the changes of a plan, failing if the cluster drifted since",
+		Run:   runApply,
+	})
+}
+
+func runPlan(ctx context.Context, args []string) error {
+	var out string
+	fs := newFlagSet("plan")
+	fs.StringVar(&out, "out", "",
+		"File or configmap:<namespace>/<name> the plan is written to, followed by the command and its flags")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if out == "" {
+		return fmt.Errorf("--out is required")
+	}
+	if fs.NArg() == 0 || !slices.Contains(plannable, fs.Arg(0)) {
+		return fmt.Errorf("expected one of %s after the flags", strings.Join(plannable, ", "))
+	}
+	cmd, _ := Lookup(fs.Arg(0))
+
+	var planner *controller.Planner
+	wrapClient = func(c client.Client) client.Client {
+		planner = controller.NewPlanner(c)
+		return planner
+	}
+	err := cmd.Run(ctx, fs.Args()[1:])
+	wrapClient = nil
+	if err != nil {
+		return fmt.Errorf("%s failed, no plan written: %w", cmd.Name, err)
+	}
+	if planner == nil {
+		return fmt.Errorf("%s made no changes to plan", cmd.Name)
+	}
+
+	plan := planner.Plan(strings.Join(fs.Args(), " "))
+	if err := writePlan(ctx, out, plan); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "planned changes to %d ConfigMaps in %s\n", len(plan.Changes), out)
+	return nil
+}
+
+func runApply(ctx context.Context, args []string) (err error) {
+	var in string
+	fs := newFlagSet("apply")
+	fs.StringVar(&in, "plan", "", "File or configmap:<namespace>/<name> holding the plan written by plan")
+	pushgatewayURL := pushgatewayFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	start := time.Now()
+	defer func() { pushMetrics(ctx, *pushgatewayURL, "apply", start, err) }()
+	if in == "" {
+		return fmt.Errorf("--plan is required")
+	}
+
+	plan, err := readPlan(ctx, in)
+	if err != nil {
+		return err
+	}
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	reconciler := &controller.ReplicaSetReconciler{Client: c, Scheme: scheme}
+	applied, err := reconciler.ApplyPlan(ctx, plan)
+	fmt.Printf("applied changes to %d of %d ConfigMaps planned by %q\n", applied, len(plan.Changes), plan.Command)
+	return err
+}
+
+// planConfigMap returns the ConfigMap of a configmap:<namespace>/<name> location
+func planConfigMap(location string) (types.NamespacedName, bool, error) {
+	ref, ok := strings.CutPrefix(location, planConfigMapPrefix)
+	if !ok {
+		return types.NamespacedName{}, false, nil
+	}
+	namespace, name, ok := strings.Cut(ref, "/")
+	if !ok || namespace == "" || name == "" {
+		return types.NamespacedName{}, false, fmt.Errorf("invalid plan ConfigMap %q: expected %s<namespace>/<name>",
+			location, planConfigMapPrefix)
+	}
+	return types.NamespacedName{Namespace: namespace, Name: name}, true, nil
+}
+
+// writePlan writes plan to a file or ConfigMap
+func writePlan(ctx context.Context, location string, plan *controller.Plan) error {
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return err
+	}
+	key, isConfigMap, err := planConfigMap(location)
+	if err != nil {
+		return err
+	}
+	if !isConfigMap {
+		return os.WriteFile(location, append(data, '\n'), 0o600)
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	var cm corev1.ConfigMap
+	err = c.Get(ctx, key, &cm)
+	if apierrors.IsNotFound(err) {
+		cm = corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name},
+			Data:       map[string]string{planKey: string(data)},
+		}
+		return c.Create(ctx, &cm)
+	}
+	if err != nil {
+		return err
+	}
+	cm.Data = map[string]string{planKey: string(data)}
+	return c.Update(ctx, &cm)
+}
+
+// readPlan reads the plan of a file or ConfigMap
+func readPlan(ctx context.Context, location string) (*controller.Plan, error) {
+	key, isConfigMap, err := planConfigMap(location)
+	if err != nil {
+		return nil, err
+	}
+	var data []byte
+	if isConfigMap {
+		c, err := newClient()
+		if err != nil {
+			return nil, err
+		}
+		var cm corev1.ConfigMap
+		if err := c.Get(ctx, key, &cm); err != nil {
+			return nil, err
+		}
+		data = []byte(cm.Data[planKey])
+	} else if data, err = os.ReadFile(location); err != nil {
+		return nil, err
+	}
+
+	var plan controller.Plan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("invalid plan %s: %w", location, err)
+	}
+	return &plan, nil
+}
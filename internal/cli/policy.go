@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"context"
+	"os"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+	"github.com/matanbaruch/configmap-rs-operator/internal/policy"
+)
+
+func init() {
+	register(&Command{
+		Name:  "policy",
+		Short: "Print Kyverno or Gatekeeper policies mirroring the operator's filters",
+		Run:   runPolicy,
+	})
+}
+
+func runPolicy(_ context.Context, args []string) error {
+	var format, tenants, serviceAccount string
+	cfg := &config.OperatorConfig{}
+	fs := newFlagSet("policy")
+	fs.StringVar(&format, "format", policy.FormatKyverno, "Policy engine: kyverno or gatekeeper")
+	fs.StringVar(&serviceAccount, "service-account", defaultInstallNamespace+"/"+namePrefix+"controller-manager",
+		"namespace/name of the operator's service account")
+	setNamespaces := namespaceFlags(fs)
+	fs.StringVar(&cfg.ImpersonateUser, "as", "", "Same as the operator's --as flag")
+	fs.StringVar(&tenants, "tenant-service-accounts", "", "Same as the operator's --tenant-service-accounts flag")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := setNamespaces(cfg); err != nil {
+		return err
+	}
+	cfg.TenantServiceAccounts = config.SplitPairs(tenants)
+
+	manifests, err := policy.Generate(format, policy.Options{
+		NamespaceRegex:   cfg.NamespaceRegex,
+		NamespaceExclude: cfg.NamespaceExclude,
+		ExemptUsers:      policy.ExemptUsers(cfg, serviceAccount),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(manifests)
+	return err
+}
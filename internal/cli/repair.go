@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+	"github.com/matanbaruch/configmap-rs-operator/internal/controller"
+)
+
+func init() {
+	register(&Command{
+		Name:  "repair",
+		Short: "Fix the drift between the recorded and actual owner references of ConfigMaps",
+		Run:   runRepair,
+	})
+}
+
+func runRepair(ctx context.Context, args []string) error {
+	var opts controller.RepairOptions
+	var types, ownerRules string
+	cfg := &config.OperatorConfig{}
+	fs := newFlagSet("repair")
+	fs.StringVar(&opts.Namespace, "namespace", "", "Only repair ConfigMaps in this namespace (default: all namespaces)")
+	fs.StringVar(&types, "type", "",
+		"Comma-separated drift types to repair: reference_removed, annotation_orphaned (default: both)")
+	fs.BoolVar(&opts.DryRun, "dry-run", false, "Only print what would be repaired")
+	fs.BoolVar(&cfg.PodTemplates, "pod-templates", false, "Same as the operator's --pod-templates flag")
+	fs.BoolVar(&cfg.StatefulSets, "statefulsets", false, "Same as the operator's --statefulsets flag")
+	fs.BoolVar(&cfg.DaemonSets, "daemonsets", false, "Same as the operator's --daemonsets flag")
+	fs.BoolVar(&cfg.Jobs, "jobs", false, "Same as the operator's --jobs flag")
+	fs.BoolVar(&cfg.CronJobs, "cronjobs", false, "Same as the operator's --cronjobs flag")
+	fs.StringVar(&cfg.CronJobOwner, "cronjob-owner", "cronjob", "Same as the operator's --cronjob-owner flag")
+	fs.BoolVar(&cfg.Pods, "pods", false, "Same as the operator's --pods flag")
+	fs.StringVar(&ownerRules, "owner-rules", "", "Same as the operator's --owner-rules flag")
+	fs.StringVar(&cfg.OwnerTarget, "owner-target", controller.OwnerReplicaSet,
+		"Same as the operator's --owner-target flag")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	opts.Types = config.SplitList(types)
+	if err := controller.ValidateRepairTypes(opts.Types); err != nil {
+		return err
+	}
+	rules, err := controller.ParseOwnerRules(config.SplitSemicolons(ownerRules))
+	if err != nil {
+		return err
+	}
+	if err := controller.ValidateOwnerTarget(cfg.OwnerTarget); err != nil {
+		return err
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	reconciler := &controller.ReplicaSetReconciler{Client: c, Scheme: scheme, Config: cfg, OwnerRules: rules}
+	repaired, err := reconciler.Repair(ctx, opts, ctrl.Log.WithName("repair"))
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if encErr := enc.Encode(repaired); encErr != nil {
+		return encErr
+	}
+	return err
+}
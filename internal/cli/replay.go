@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+	"github.com/matanbaruch/configmap-rs-operator/internal/controller"
+)
+
+func init() {
+	register(&Command{
+		Name:  "replay",
+		Short: "Feed a recording made with --record through the reconciler and report changed decisions",
+		Run:   runReplay,
+	})
+}
+
+func runReplay(ctx context.Context, args []string) error {
+	var file string
+	cfg := &config.OperatorConfig{}
+	fs := newFlagSet("replay")
+	fs.StringVar(&file, "file", "", "Recording file written by the operator's --record flag")
+	setNamespaces := namespaceFlags(fs)
+	fs.BoolVar(&cfg.DryRun, "dry-run", false, "Same as the operator's --dry-run flag")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if file == "" {
+		return fmt.Errorf("--file is required")
+	}
+	if err := setNamespaces(cfg); err != nil {
+		return err
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close() //nolint:errcheck
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	result, err := controller.Replay(ctx, f, cfg, func(rec controller.Recording, got []controller.Decision) {
+		_ = enc.Encode(map[string]interface{}{
+			"time":     rec.Time,
+			"request":  rec.Request,
+			"recorded": rec.Decisions,
+			"replayed": got,
+		})
+	})
+	fmt.Printf("replayed %d reconciles, %d with different decisions\n", result.Replayed, result.Diverged)
+	return err
+}
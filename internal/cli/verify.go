@@ -0,0 +1,189 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/controller"
+)
+
+const (
+	verifyNamespacePrefix = "configmap-rs-operator-verify-"
+	verifyName            = "verify"
+	verifyPollInterval    = time.Second
+)
+
+func init() {
+	register(&Command{
+		Name:  "verify",
+		Short: "Check end to end that the running operator adds ownership and garbage collection follows",
+		Run:   runVerify,
+	})
+}
+
+// verifier runs the end-to-end checks against the cluster, recording each outcome
+type verifier struct {
+	c       client.Client
+	timeout time.Duration
+	result  controller.SelfTestResult
+}
+
+func runVerify(ctx context.Context, args []string) error {
+	var namespace string
+	v := &verifier{result: controller.SelfTestResult{Passed: true}}
+	fs := newFlagSet("verify")
+	fs.StringVar(&namespace, "namespace", "",
+		"Existing namespace the canary objects are created in (default: a new canary namespace, deleted afterwards)")
+	fs.DurationVar(&v.timeout, "timeout", time.Minute, "How long to wait for the operator and the garbage collector")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	v.c = c
+
+	start := time.Now()
+	v.run(ctx, namespace)
+	v.result.Duration = time.Since(start).String()
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v.result); err != nil {
+		return err
+	}
+	if !v.result.Passed {
+		return fmt.Errorf("verification failed")
+	}
+	return nil
+}
+
+// check records the outcome of a step and reports whether it passed
+func (v *verifier) check(name string, err error) bool {
+	c := controller.SelfTestCheck{Name: name, Passed: err == nil}
+	if err != nil {
+		c.Message = err.Error()
+		v.result.Passed = false
+	}
+	v.result.Checks = append(v.result.Checks, c)
+	return err == nil
+}
+
+// run creates the canary objects, checks that the operator owns the ConfigMap for the ReplicaSet and that
+// deleting the ReplicaSet garbage collects the ConfigMap, then removes whatever is left
+func (v *verifier) run(ctx context.Context, namespace string) {
+	if namespace == "" {
+		ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{GenerateName: verifyNamespacePrefix}}
+		if !v.check("create_namespace", v.c.Create(ctx, ns)) {
+			return
+		}
+		namespace = ns.Name
+		defer v.cleanup(ctx, ns)
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{GenerateName: verifyName + "-", Namespace: namespace},
+		Data:       map[string]string{"verify": "true"},
+	}
+	if !v.check("create_configmap", v.c.Create(ctx, cm)) {
+		return
+	}
+	rs := verifyReplicaSet(namespace, cm.Name)
+	if !v.check("create_replicaset", v.c.Create(ctx, rs)) {
+		v.cleanup(ctx, cm)
+		return
+	}
+
+	cmKey := types.NamespacedName{Namespace: namespace, Name: cm.Name}
+	owned := v.poll(ctx, func(ctx context.Context) (bool, error) {
+		if err := v.c.Get(ctx, cmKey, cm); err != nil {
+			return false, err
+		}
+		for _, ref := range cm.OwnerReferences {
+			if ref.UID == rs.UID {
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+	if !v.check("owner_reference_added", owned) {
+		v.cleanup(ctx, rs)
+		v.cleanup(ctx, cm)
+		return
+	}
+
+	background := metav1.DeletePropagationBackground
+	if !v.check("delete_replicaset", v.c.Delete(ctx, rs, &client.DeleteOptions{PropagationPolicy: &background})) {
+		v.cleanup(ctx, cm)
+		return
+	}
+	collected := v.poll(ctx, func(ctx context.Context) (bool, error) {
+		err := v.c.Get(ctx, cmKey, &corev1.ConfigMap{})
+		if errors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	})
+	if !v.check("configmap_garbage_collected", collected) {
+		v.cleanup(ctx, cm)
+	}
+}
+
+// poll waits until condition is met, describing a timeout in terms of the step that didn't happen
+func (v *verifier) poll(ctx context.Context, condition wait.ConditionWithContextFunc) error {
+	err := wait.PollUntilContextTimeout(ctx, verifyPollInterval, v.timeout, true, condition)
+	if wait.Interrupted(err) {
+		return fmt.Errorf("not observed within %s", v.timeout)
+	}
+	return err
+}
+
+// cleanup deletes a leftover canary object; failures are reported but don't fail the verification
+func (v *verifier) cleanup(ctx context.Context, obj client.Object) {
+	if err := client.IgnoreNotFound(v.c.Delete(ctx, obj)); err != nil {
+		fmt.Fprintf(os.Stderr, "unable to delete %T %s: %v\n", obj, client.ObjectKeyFromObject(obj), err)
+	}
+}
+
+// verifyReplicaSet returns a canary ReplicaSet without replicas mounting the ConfigMap name,
+// so no pod is ever scheduled
+func verifyReplicaSet(namespace, configMap string) *appsv1.ReplicaSet {
+	replicas := int32(0)
+	labels := map[string]string{"app.kubernetes.io/name": verifyNamespacePrefix + verifyName}
+	return &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{GenerateName: verifyName + "-", Namespace: namespace, Labels: labels},
+		Spec: appsv1.ReplicaSetSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name:         verifyName,
+						Image:        "registry.k8s.io/pause:3.10",
+						VolumeMounts: []corev1.VolumeMount{{Name: "config", MountPath: "/etc/config"}},
+					}},
+					Volumes: []corev1.Volume{{
+						Name: "config",
+						VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+							LocalObjectReference: corev1.LocalObjectReference{Name: configMap},
+						}},
+					}},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+	"github.com/matanbaruch/configmap-rs-operator/internal/controller"
+)
+
+func init() {
+	register(&Command{
+		Name:  "who-uses",
+		Short: "List every workload referencing a ConfigMap, by volume, projected volume, env or envFrom",
+		Run:   runWhoUses,
+	})
+}
+
+func runWhoUses(ctx context.Context, args []string) error {
+	key := types.NamespacedName{}
+	cfg := &config.OperatorConfig{}
+	fs := newFlagSet("who-uses")
+	fs.StringVar(&key.Namespace, "namespace", "default", "Namespace of the ConfigMap")
+	fs.BoolVar(&cfg.PodTemplates, "pod-templates", true, "Also list standalone PodTemplates")
+	fs.BoolVar(&cfg.StatefulSets, "statefulsets", true, "Also list StatefulSets")
+	fs.BoolVar(&cfg.DaemonSets, "daemonsets", true, "Also list DaemonSets")
+	fs.BoolVar(&cfg.Jobs, "jobs", true, "Also list Jobs")
+	fs.BoolVar(&cfg.CronJobs, "cronjobs", true, "Also list CronJobs")
+	fs.BoolVar(&cfg.Pods, "pods", true, "Also list bare Pods, those without a controller")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: who-uses [--namespace=<namespace>] <configmap>")
+	}
+	key.Name = fs.Arg(0)
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	reconciler := &controller.ReplicaSetReconciler{Client: c, Scheme: scheme, Config: cfg}
+	users, err := reconciler.WhoUses(ctx, key)
+	if err != nil {
+		return err
+	}
+	if users == nil {
+		users = []controller.ConfigMapUser{}
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(users)
+}
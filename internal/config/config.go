@@ -3,16 +3,34 @@ package config
 import (
 	"flag"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 )
 
 const trueValue = "true"
 
-// OperatorConfig holds the configuration for the operator
+// WatchKindNames are the resources --watch-kinds accepts
+var WatchKindNames = []string{
+	"replicasets", "deployments", "podtemplates", "statefulsets", "daemonsets", "jobs", "cronjobs", "pods",
+}
+
+// OperatorConfig holds the configuration for the operator. It is complete once FinalizeConfig has run and is
+// only adjusted during setup (e.g. by capability degradation); once the manager starts, reconcilers share it
+// read-only without locking. Anything changing it at runtime must swap whole copies behind an atomic pointer
+// instead of writing fields in place.
 type OperatorConfig struct {
 	// NamespaceRegex is a list of regular expressions to match namespaces
 	NamespaceRegex []string
 
+	// NamespaceExclude is a list of regular expressions of namespaces excluded even when NamespaceRegex matches them,
+	// from --namespace-exclude-regex and the ! globs of --namespace-regex
+	NamespaceExclude []string
+
+	// NamespaceMatch is the syntax of the --namespace-regex patterns: auto, regex or glob
+	NamespaceMatch string
+
 	// DryRun indicates whether to perform actual changes or just log what would be done
 	DryRun bool
 
@@ -22,8 +40,317 @@ type OperatorConfig struct {
 	// Trace enables trace logging (more verbose than debug)
 	Trace bool
 
+	// EventsEnabled controls whether the operator emits Kubernetes Events at all
+	EventsEnabled bool
+
+	// EventTypes restricts emitted Events to the listed types (Normal, Warning); empty means all types
+	EventTypes []string
+
+	// EventQPS is the sustained number of Events per second the operator may emit
+	EventQPS float64
+
+	// EventBurst is the maximum number of Events the operator may emit in a burst
+	EventBurst int
+
+	// MaintenanceWindows restricts writes to cron-like windows ("<cron> <duration>"); empty means always
+	MaintenanceWindows []string
+
+	// MaintenanceTimezone is the IANA time zone the maintenance windows are evaluated in
+	MaintenanceTimezone string
+
+	// ControlConfigMap is the "namespace/name" of the ConfigMap whose "paused" key acts as a kill switch
+	ControlConfigMap string
+
+	// UnreadyWhenPaused makes the readiness probe fail while the kill switch is engaged
+	UnreadyWhenPaused bool
+
+	// PatchOnly writes ConfigMaps with merge patches only, so the operator needs get, list, watch and patch on
+	// ConfigMaps but not update
+	PatchOnly bool
+
+	// ImpersonateUser is the identity the operator acts as when writing; empty uses its own service account
+	ImpersonateUser string
+
+	// ImpersonateGroups are the groups the operator acts as when writing
+	ImpersonateGroups []string
+
+	// TenantServiceAccounts maps a namespace to the service account whose credentials are used for
+	// writes in it; the "*" key applies to every namespace without an explicit entry
+	TenantServiceAccounts map[string]string
+
+	// ChurnThreshold is the number of ownership changes per namespace within ChurnWindow that is
+	// reported as an anomaly; 0 disables the check
+	ChurnThreshold int
+
+	// ChurnWindow is the period over which ownership changes are counted
+	ChurnWindow time.Duration
+
+	// Shadow runs the operator read-only next to the active one and reports where its decisions differ
+	Shadow bool
+
+	// ShadowDelay is how long after a workload's creation the shadow compares it with the active operator's writes
+	ShadowDelay time.Duration
+
+	// Migrate upgrades metadata written by earlier releases to the current ownership semantics on startup
+	Migrate bool
+
+	// UsageMetrics is the detail level of the ConfigMap usage metrics: off, configmap or workload
+	UsageMetrics string
+
+	// UsageMetricsMaxSeries caps the number of ConfigMap usage series per scrape; 0 means unlimited
+	UsageMetricsMaxSeries int
+
+	// InventoryConfigMap is the namespace/name of the ConfigMap the inventory report is written to; empty disables it
+	InventoryConfigMap string
+
+	// InventoryInterval is how often the inventory report is regenerated
+	InventoryInterval time.Duration
+
+	// IngressTLSSecrets adds Ingresses as owners of the TLS Secrets they reference, except those issued by cert-manager
+	IngressTLSSecrets bool
+
+	// SecretsEnabled adds ReplicaSets as owners of the Secrets they reference, except those issued by cert-manager
+	SecretsEnabled bool
+
+	// ImagePullSecrets also owns the image pull Secrets of ReplicaSets with SecretsEnabled, e.g. those CI pipelines
+	// generate per workload
+	ImagePullSecrets bool
+
+	// ConsumedKeysOnly only adds owner references for ConfigMaps at least one of whose keys a workload
+	// consumes, judging by volume items, subPaths and env key references
+	ConsumedKeysOnly bool
+
+	// OptionalReferences is how ConfigMap references marked optional are handled: own, skip or conservative
+	OptionalReferences string
+
+	// PodTemplates adds standalone PodTemplates as owners of the ConfigMaps they mount
+	PodTemplates bool
+
+	// StatefulSets adds StatefulSets as owners of the ConfigMaps they mount
+	StatefulSets bool
+
+	// DaemonSets adds DaemonSets as owners of the ConfigMaps they mount
+	DaemonSets bool
+
+	// Jobs adds Jobs as owners of the ConfigMaps they mount
+	Jobs bool
+
+	// CronJobs adds CronJobs, or the Jobs they spawn, as owners of the ConfigMaps their job template mounts
+	CronJobs bool
+
+	// CronJobOwner selects what owns the ConfigMaps of CronJobs: cronjob or job (each spawned Job)
+	CronJobOwner string
+
+	// Pods adds bare Pods, those without a controller, as owners of the ConfigMaps they mount
+	Pods bool
+
+	// WatchKinds limits the watched workload kinds to these resources, e.g. replicasets,jobs, overriding the
+	// per-kind switches; empty watches ReplicaSets and the kinds the switches enable
+	WatchKinds []string
+
+	// CustomKinds are group/version/Kind=jsonpath declarations of additional workload kinds, whose objects embed the
+	// pod template the JSONPath selects
+	CustomKinds []string
+
+	// BackfillCheckpoint is the namespace/name of the ConfigMap --once records its progress in, so an interrupted
+	// pass resumes where it stopped; empty disables it
+	BackfillCheckpoint string
+
+	// PrioritizeLiveEvents reconciles fresh workload events before requeued and retried work
+	PrioritizeLiveEvents bool
+
+	// PrecomputeDeployments watches Deployments to warm the ConfigMap cache before their ReplicaSets are created
+	PrecomputeDeployments bool
+
+	// CleanupDisabledKinds removes the owner references of disabled workload kinds on startup
+	CleanupDisabledKinds bool
+
+	// CleanupOutOfScope removes on startup the owner references the operator added to ConfigMaps that the namespace
+	// regex or the owner rules now exclude
+	CleanupOutOfScope bool
+
+	// AnnotateWorkloads writes the ConfigMaps the operator bound to a workload into an annotation on the workload
+	// owning them: the ReplicaSet or its Deployment, or the object of another workload kind
+	AnnotateWorkloads bool
+
+	// OwnerRules are pattern=strategy rules selecting the owner of ConfigMaps by name, evaluated in order
+	OwnerRules []string
+
+	// OwnerTarget is the owner of ConfigMaps no owner rule matches: replicaset, or deployment for the ReplicaSet's
+	// Deployment, so ConfigMaps outlive the ReplicaSets pruned by revisionHistoryLimit
+	OwnerTarget string
+
+	// SkipOwnerKinds leaves alone the ReplicaSets owned by these kinds, given as Kind or Kind.group, e.g. Argo
+	// Rollouts or operators managing their own ConfigMaps
+	SkipOwnerKinds []string
+
+	// SkipDormant leaves alone the ReplicaSets scaled to zero and those of paused Deployments, so stale
+	// ReplicaSets don't take ConfigMaps with them when they are cleaned up
+	SkipDormant bool
+
+	// ConfigMapDenylist lists more ConfigMap names the operator never owns, next to the built-in denylist
+	ConfigMapDenylist []string
+
+	// DisableBuiltinDenylist lets the operator own the ConfigMaps Kubernetes and common platforms inject into
+	// every namespace, such as kube-root-ca.crt
+	DisableBuiltinDenylist bool
+
+	// OwnHelmManaged also owns the ConfigMaps Helm manages or keeps on uninstall, which are skipped by default so
+	// helm uninstall and the garbage collector don't both delete them
+	OwnHelmManaged bool
+
+	// OwnArgoCDManaged also owns the ConfigMaps Argo CD tracks as part of an application, which are skipped by
+	// default
+	OwnArgoCDManaged bool
+
+	// ArgoCDCompareOptions are Argo CD compare options, e.g. IgnoreExtraneous, added to the
+	// argocd.argoproj.io/compare-options annotation of the ConfigMaps the operator adds owner references to
+	ArgoCDCompareOptions string
+
+	// OptIn only owns the ConfigMaps of workloads annotated with configmap-rs-operator/enabled: "true", so
+	// installing the operator changes nothing until teams opt in
+	OptIn bool
+
+	// WorkloadNameRegex is a list of regular expressions of the workload names the operator owns ConfigMaps for,
+	// the Deployment's for its ReplicaSets; empty selects every workload
+	WorkloadNameRegex []string
+
+	// WorkloadNameExclude is a list of regular expressions of workload names left alone even when
+	// WorkloadNameRegex matches them
+	WorkloadNameExclude []string
+
+	// NameConvention also owns the ConfigMap named after each workload by NameConventionPattern, mounted or not,
+	// for apps reading their ConfigMap through the API
+	NameConvention bool
+
+	// NameConventionPattern is the name of the ConfigMap NameConvention ties to a workload, where {workload} is the
+	// workload's name, the Deployment's for ReplicaSets
+	NameConventionPattern string
+
+	// ConfigMapBindings adds the workload a ConfigMap names in its configmap-rs-operator/owner-workload annotation
+	// as its owner, including ConfigMaps created before the workload
+	ConfigMapBindings bool
+
+	// ReferenceTypes are the ways of referencing a ConfigMap the operator owns ConfigMaps for: volume, projected,
+	// envFrom or env; empty means every way
+	ReferenceTypes []string
+
+	// CELReferences are CEL expressions evaluated against the pod template that name ConfigMaps it references,
+	// for references no built-in reference type covers
+	CELReferences []string
+
+	// UnmountedVolumes also owns the ConfigMaps of volumes no container mounts, e.g. for sidecars injected later
+	UnmountedVolumes bool
+
+	// MaxExistingOwners skips ConfigMaps that already carry more owner references; 0 means unlimited
+	MaxExistingOwners int
+
+	// MaxReconcileStaleness fails the liveness check when a controller that reconciled successfully before
+	// hasn't for this long; 0 disables the check
+	MaxReconcileStaleness time.Duration
+
+	// WatchStallTimeout is the shortest time without watch events the watchdog treats as a stalled watch,
+	// raised on quiet clusters; 0 disables the watchdog
+	WatchStallTimeout time.Duration
+
+	// WatchStallAction is what the watchdog does on a stalled watch: unready or restart
+	WatchStallAction string
+
+	// ConflictScanInterval is how often the managed fields of the operator's ConfigMaps are checked for other
+	// field managers writing their owner references or annotations; 0 disables the scan
+	ConflictScanInterval time.Duration
+
+	// MissingReferenceScanInterval is how often ReplicaSets are checked for required references to ConfigMaps
+	// that don't exist; 0 disables the scan
+	MissingReferenceScanInterval time.Duration
+
+	// MissingReferenceWindow is how long after its ReplicaSet was created a ConfigMap may be missing before the
+	// reference is reported as unresolved
+	MissingReferenceWindow time.Duration
+
+	// DriftScanInterval is how often the provenance annotations are compared with the actual owner
+	// references; 0 disables the scan
+	DriftScanInterval time.Duration
+
+	// KustomizeCleanupInterval is how often the kustomize ConfigMaps superseded by a newer generation are deleted;
+	// 0 disables the cleanup
+	KustomizeCleanupInterval time.Duration
+
+	// RequireApproval queues owner references on the ConfigMap for approval instead of adding them
+	RequireApproval bool
+
+	// CoalesceWindow batches the owner references added to the same ConfigMap within it into one server-side
+	// apply; 0 updates the ConfigMap for each owner right away
+	CoalesceWindow time.Duration
+
+	// MetricsNamespaceLabels is how per-namespace metrics are labeled: all, top or off
+	MetricsNamespaceLabels string
+
+	// MetricsTopNamespaces is the number of namespaces that keep their own metric label with top namespace labels
+	MetricsTopNamespaces int
+
+	// StateStore is where bookkeeping shared by replicas and restarts is kept, as <kind>:<namespace>/<name> with
+	// kind configmap or lease; empty keeps it in memory
+	StateStore string
+
+	// RevalidateRollbacks reconciles ReplicaSets that scale up from zero again, e.g. on a rollback
+	RevalidateRollbacks bool
+
+	// RolloutHandoff hands the ConfigMaps of a Deployment's ReplicaSet scaled down to zero over to its running
+	// ReplicaSets, even past MaxExistingOwners
+	RolloutHandoff bool
+
+	// PushgatewayURL is the Prometheus Pushgateway --once pushes its metrics to when the pass ends; empty disables it
+	PushgatewayURL string
+
+	// Mode selects what the process runs: controller, webhook or all, so the admission path can be deployed
+	// apart from the reconciliation path
+	Mode string
+
 	// Internal field to store the namespace regex string for later parsing
 	namespaceRegexStr *string
+
+	// Internal field to store the namespace exclude regex string for later parsing
+	namespaceExcludeStr string
+
+	// Internal field to store the event types string for later parsing
+	eventTypesStr string
+
+	// Internal field to store the maintenance windows string for later parsing
+	maintenanceWindowsStr string
+
+	// Internal field to store the owner rules string for later parsing
+	ownerRulesStr string
+
+	// Internal field to store the skipped owner kinds string for later parsing
+	skipOwnerKindsStr string
+
+	// Internal field to store the ConfigMap denylist string for later parsing
+	configMapDenylistStr string
+
+	// Internal field to store the workload name regex string for later parsing
+	workloadNameRegexStr string
+
+	// Internal field to store the workload name exclude regex string for later parsing
+	workloadNameExcludeStr string
+
+	// Internal field to store the reference types string for later parsing
+	referenceTypesStr string
+
+	// Internal field to store the CEL references string for later parsing
+	celReferencesStr string
+
+	// Internal field to store the impersonated groups string for later parsing
+	impersonateGroupsStr string
+
+	// Internal field to store the tenant service accounts string for later parsing
+	tenantServiceAccountsStr string
+
+	// Internal field to store the custom kinds string for later parsing
+	customKindsStr string
+
+	// Internal field to store the watched kinds string for later parsing
+	watchKindsStr string
 }
 
 // NewConfig creates a new configuration from command line flags and environment variables
@@ -33,12 +360,192 @@ func NewConfig() *OperatorConfig {
 	var namespaceRegexStr string
 	flag.StringVar(&namespaceRegexStr, "namespace-regex", "",
 		"Comma-separated list of regex patterns to match namespaces (default: all namespaces)")
+	flag.StringVar(&config.NamespaceMatch, "namespace-match", "auto",
+		"Syntax of the --namespace-regex patterns: regex, glob (team-*, !kube-*) or auto to detect globs")
+	flag.StringVar(&config.namespaceExcludeStr, "namespace-exclude-regex", "",
+		"Comma-separated list of regex patterns of namespaces excluded even when --namespace-regex matches them")
 	flag.BoolVar(&config.DryRun, "dry-run", false,
 		"If true, only log what changes would be made without actually making them")
 	flag.BoolVar(&config.Debug, "debug", false,
 		"Enable debug logging")
 	flag.BoolVar(&config.Trace, "trace", false,
 		"Enable trace logging (implies debug)")
+	flag.BoolVar(&config.EventsEnabled, "events-enabled", true,
+		"If false, the operator does not emit any Kubernetes Events")
+	flag.StringVar(&config.eventTypesStr, "event-types", "",
+		"Comma-separated list of Event types to emit, e.g. Warning (default: all types)")
+	flag.Float64Var(&config.EventQPS, "event-qps", 1,
+		"Sustained number of Events per second the operator may emit")
+	flag.IntVar(&config.EventBurst, "event-burst", 10,
+		"Maximum number of Events the operator may emit in a burst")
+	flag.StringVar(&config.maintenanceWindowsStr, "maintenance-windows", "",
+		"Semicolon-separated maintenance windows as '<minute> <hour> <dom> <month> <dow> <duration>', "+
+			"e.g. '0 2 * * 1-5 2h'; outside them writes are deferred (default: always allowed)")
+	flag.StringVar(&config.MaintenanceTimezone, "maintenance-timezone", "UTC",
+		"IANA time zone used to evaluate the maintenance windows")
+	flag.StringVar(&config.ControlConfigMap, "control-configmap", "",
+		"namespace/name of a ConfigMap whose 'paused: \"true\"' key pauses all writes cluster-wide")
+	flag.BoolVar(&config.UnreadyWhenPaused, "unready-when-paused", false,
+		"If true, the readiness probe fails while the operator is paused")
+	flag.BoolVar(&config.PatchOnly, "patch-only", false,
+		"Write ConfigMaps with merge patches only, so the operator needs no update permission on ConfigMaps")
+	flag.StringVar(&config.ImpersonateUser, "as", "",
+		"Username to impersonate for all writes, e.g. system:serviceaccount:ops:configmap-writer")
+	flag.StringVar(&config.impersonateGroupsStr, "as-group", "",
+		"Comma-separated groups to impersonate for all writes")
+	flag.StringVar(&config.tenantServiceAccountsStr, "tenant-service-accounts", "",
+		"Comma-separated namespace=serviceaccount pairs; writes in a namespace use that service account's "+
+			"credentials, and '*=name' applies to every other namespace")
+	flag.IntVar(&config.ChurnThreshold, "churn-threshold", 100,
+		"Number of ownership changes in a namespace within --churn-window reported as an anomaly (0 disables)")
+	flag.DurationVar(&config.ChurnWindow, "churn-window", 10*time.Minute,
+		"Period over which ownership changes are counted for anomaly detection")
+	flag.BoolVar(&config.Shadow, "shadow", false,
+		"Run read-only next to the active operator and report where this version's decisions differ")
+	flag.DurationVar(&config.ShadowDelay, "shadow-delay", 30*time.Second,
+		"How long the shadow waits after a workload's creation before comparing it with the active operator's writes")
+	flag.StringVar(&config.UsageMetrics, "usage-metrics", "configmap",
+		"Detail of the ConfigMap usage metrics: off, configmap (one series per ConfigMap) "+
+			"or workload (one more series per ConfigMap and workload)")
+	flag.IntVar(&config.UsageMetricsMaxSeries, "usage-metrics-max-series", 10000,
+		"Maximum number of ConfigMap usage series per scrape (0 means unlimited)")
+	flag.BoolVar(&config.Migrate, "migrate", false,
+		"Upgrade owner references and annotations written by earlier releases to the current semantics on startup")
+	flag.StringVar(&config.InventoryConfigMap, "inventory-configmap", "",
+		"namespace/name of a ConfigMap the operator periodically writes its ownership inventory to (default: disabled)")
+	flag.DurationVar(&config.InventoryInterval, "inventory-interval", time.Hour,
+		"How often the inventory report is regenerated")
+	flag.BoolVar(&config.IngressTLSSecrets, "ingress-tls-secrets", false,
+		"Add Ingresses as owners of the TLS Secrets they reference, except Secrets issued by cert-manager")
+	flag.BoolVar(&config.SecretsEnabled, "secrets-enabled", false,
+		"Add ReplicaSets as owners of the Secrets they reference, except Secrets issued by cert-manager")
+	flag.BoolVar(&config.ImagePullSecrets, "image-pull-secrets", false,
+		"With --secrets-enabled, also own the Secrets listed in the imagePullSecrets of ReplicaSets")
+	flag.BoolVar(&config.ConsumedKeysOnly, "consumed-keys-only", false,
+		"Only own ConfigMaps at least one of whose keys is consumed, judging by volume items, subPaths "+
+			"and env key references")
+	flag.StringVar(&config.OptionalReferences, "optional-references", "own",
+		"Handling of ConfigMap references marked optional: own, skip, or conservative "+
+			"(own unless another workload also mounts the ConfigMap)")
+	flag.BoolVar(&config.PodTemplates, "pod-templates", false,
+		"Also add standalone PodTemplates as owners of the ConfigMaps they mount")
+	flag.BoolVar(&config.StatefulSets, "statefulsets", false,
+		"Also add StatefulSets as owners of the ConfigMaps they mount")
+	flag.BoolVar(&config.DaemonSets, "daemonsets", false,
+		"Also add DaemonSets as owners of the ConfigMaps they mount")
+	flag.BoolVar(&config.Jobs, "jobs", false,
+		"Also add Jobs as owners of the ConfigMaps they mount, so they are deleted with the Job")
+	flag.BoolVar(&config.CronJobs, "cronjobs", false,
+		"Also add CronJobs, or the Jobs they spawn, as owners of the ConfigMaps their job template mounts")
+	flag.StringVar(&config.CronJobOwner, "cronjob-owner", "cronjob",
+		"What owns the ConfigMaps of CronJobs with --cronjobs: cronjob, or job for each spawned Job")
+	flag.BoolVar(&config.Pods, "pods", false,
+		"Also add bare Pods, those without a controller, as owners of the ConfigMaps they mount")
+	flag.StringVar(&config.watchKindsStr, "watch-kinds", "",
+		"Comma-separated workload kinds to watch, e.g. replicasets,jobs, overriding the per-kind flags; one of "+
+			strings.Join(WatchKindNames, ", ")+" (default: ReplicaSets and the kinds the per-kind flags enable)")
+	flag.StringVar(&config.customKindsStr, "custom-kinds", "",
+		"Semicolon-separated group/version/Kind=jsonpath declarations of additional workload kinds, e.g. "+
+			"example.com/v1/Worker={.spec.template}, whose objects own the ConfigMaps of the pod template the JSONPath "+
+			"selects")
+	flag.StringVar(&config.BackfillCheckpoint, "backfill-checkpoint", "",
+		"namespace/name of a ConfigMap --once records its progress in to resume after a restart (default: disabled)")
+	flag.BoolVar(&config.PrioritizeLiveEvents, "prioritize-live-events", true,
+		"Reconcile newly created workloads before requeued and retried work")
+	flag.BoolVar(&config.PrecomputeDeployments, "precompute-deployments", false,
+		"Watch Deployments to look up their ConfigMaps before their ReplicaSets are created")
+	flag.BoolVar(&config.CleanupDisabledKinds, "cleanup-disabled-kinds", false,
+		"On startup, remove the owner references the operator added for workload kinds that are now disabled")
+	flag.BoolVar(&config.CleanupOutOfScope, "cleanup-out-of-scope", false,
+		"On startup, remove the owner references the operator added to ConfigMaps the namespace regex or owner rules "+
+			"now exclude")
+	flag.BoolVar(&config.AnnotateWorkloads, "annotate-workloads", false,
+		"Annotate the workloads owning ConfigMaps with the names of the ConfigMaps bound to them")
+	flag.StringVar(&config.ownerRulesStr, "owner-rules", "",
+		"Semicolon-separated name-regex=strategy rules picking the owner of ConfigMaps, where strategy is "+
+			"replicaset, deployment or skip; the first match applies (default: ReplicaSets own every ConfigMap)")
+	flag.StringVar(&config.OwnerTarget, "owner-target", "replicaset",
+		"Owner of the ConfigMaps no owner rule matches: replicaset, or deployment for the ReplicaSet's Deployment")
+	flag.StringVar(&config.skipOwnerKindsStr, "skip-owner-kinds", "",
+		"Comma-separated owner kinds, as Kind or Kind.group (e.g. Rollout.argoproj.io), whose ReplicaSets are left alone")
+	flag.BoolVar(&config.SkipDormant, "skip-dormant", false,
+		"Leave alone the ReplicaSets scaled to zero and those of paused Deployments")
+	flag.StringVar(&config.configMapDenylistStr, "configmap-denylist", "",
+		"Comma-separated ConfigMap names never to own, in addition to the built-in denylist")
+	flag.BoolVar(&config.DisableBuiltinDenylist, "disable-builtin-denylist", false,
+		"Also own injected ConfigMaps such as kube-root-ca.crt, which are recreated when their owner is deleted")
+	flag.BoolVar(&config.OwnHelmManaged, "own-helm-managed", false,
+		"Also own the ConfigMaps labeled app.kubernetes.io/managed-by: Helm or annotated helm.sh/resource-policy: keep")
+	flag.BoolVar(&config.OwnArgoCDManaged, "own-argocd-managed", false,
+		"Also own the ConfigMaps Argo CD tracks with the argocd.argoproj.io/tracking-id annotation or instance label")
+	flag.StringVar(&config.ArgoCDCompareOptions, "argocd-compare-options", "",
+		"Comma-separated Argo CD compare options, e.g. IgnoreExtraneous, to add to the ConfigMaps given owner "+
+			"references (default: none)")
+	flag.BoolVar(&config.OptIn, "opt-in", false,
+		"Only own the ConfigMaps of workloads annotated with configmap-rs-operator/enabled: \"true\"")
+	flag.StringVar(&config.workloadNameRegexStr, "workload-name-regex", "",
+		"Comma-separated list of regex patterns of the workload names, the Deployment's for ReplicaSets, to own "+
+			"ConfigMaps for (default: all workloads)")
+	flag.StringVar(&config.workloadNameExcludeStr, "workload-name-exclude-regex", "",
+		"Comma-separated list of regex patterns of workload names left alone even when --workload-name-regex "+
+			"matches them")
+	flag.BoolVar(&config.NameConvention, "name-convention", false,
+		"Also own the ConfigMap named after each workload by --name-convention-pattern, even if it isn't mounted")
+	flag.StringVar(&config.NameConventionPattern, "name-convention-pattern", "{workload}-config",
+		"Name of the ConfigMap --name-convention ties to a workload, where {workload} is the workload's name")
+	flag.BoolVar(&config.ConfigMapBindings, "configmap-bindings", false,
+		"Own ConfigMaps by the workload their configmap-rs-operator/owner-workload annotation names as Kind/name")
+	flag.StringVar(&config.referenceTypesStr, "reference-types", "",
+		"Comma-separated ways of referencing ConfigMaps the operator owns them for: volume, projected, envFrom, env "+
+			"(default: every way)")
+	flag.StringVar(&config.celReferencesStr, "cel-references", "",
+		"Semicolon-separated CEL expressions evaluated against the pod template, returning the names of ConfigMaps "+
+			"it references")
+	flag.BoolVar(&config.UnmountedVolumes, "unmounted-volumes", false,
+		"Also own the ConfigMaps of volumes no container mounts, e.g. for sidecars an injector adds later")
+	flag.IntVar(&config.MaxExistingOwners, "max-existing-owners", 0,
+		"Skip ConfigMaps that already have more owner references than this, with a warning (0 means unlimited)")
+	flag.DurationVar(&config.MaxReconcileStaleness, "max-reconcile-staleness", 0,
+		"Fail the liveness check when a controller hasn't reconciled successfully for this long (0 disables the check)")
+	flag.DurationVar(&config.WatchStallTimeout, "watch-stall-timeout", 0,
+		"Shortest time without watch events treated as a stalled watch, raised on quiet clusters (0 disables it)")
+	flag.StringVar(&config.WatchStallAction, "watch-stall-action", "unready",
+		"What to do on a stalled watch: unready (fail the readiness check) or restart (fail the liveness check)")
+	flag.DurationVar(&config.ConflictScanInterval, "conflict-scan-interval", 0,
+		"How often to check, also on startup, for other field managers writing the owner references or annotations "+
+			"of the operator's ConfigMaps (default: 0, disabled)")
+	flag.DurationVar(&config.MissingReferenceScanInterval, "missing-reference-scan-interval", 0,
+		"How often to count, also on startup, the required ConfigMap references unresolved past "+
+			"--missing-reference-window (default: 0, disabled)")
+	flag.DurationVar(&config.MissingReferenceWindow, "missing-reference-window", 5*time.Minute,
+		"How long after its ReplicaSet was created a ConfigMap may be missing before the reference is reported")
+	flag.DurationVar(&config.DriftScanInterval, "drift-scan-interval", 0,
+		"How often to compare recorded with actual owner references and report drift (0 disables the scan)")
+	flag.DurationVar(&config.KustomizeCleanupInterval, "kustomize-cleanup-interval", 0,
+		"How often to delete the kustomize ConfigMaps superseded by a newer generation (0 disables the cleanup)")
+	flag.BoolVar(&config.RequireApproval, "require-approval", false,
+		"Queue owner references on the ConfigMap and only add them once it is annotated as approved")
+	flag.DurationVar(&config.CoalesceWindow, "coalesce-window", 0,
+		"Batch the owner references added to the same ConfigMap within this window into one server-side apply "+
+			"(0 updates the ConfigMap for each owner right away)")
+	flag.StringVar(&config.MetricsNamespaceLabels, "metrics-namespace-labels", "all",
+		"Namespace label of per-namespace metrics: all, top (the first --metrics-top-namespaces namespaces, "+
+			"the rest as \"other\") or off (one series for all namespaces)")
+	flag.IntVar(&config.MetricsTopNamespaces, "metrics-top-namespaces", 50,
+		"Number of namespaces that keep their own metric label with --metrics-namespace-labels=top")
+	flag.StringVar(&config.StateStore, "state-store", "",
+		"configmap:<namespace>/<name> or lease:<namespace>/<name> to keep the operator's bookkeeping in, shared by "+
+			"replicas and restarts (default: in memory)")
+	flag.BoolVar(&config.RevalidateRollbacks, "revalidate-rollbacks", true,
+		"Reconcile ReplicaSets that scale up from zero again, e.g. on kubectl rollout undo, and warn about their "+
+			"missing ConfigMaps")
+	flag.BoolVar(&config.RolloutHandoff, "rollout-handoff", true,
+		"Hand the ConfigMaps of a Deployment's ReplicaSet scaled down to zero over to the Deployment's running "+
+			"ReplicaSets, so they aren't garbage collected with the old revision")
+	flag.StringVar(&config.PushgatewayURL, "pushgateway-url", "",
+		"Prometheus Pushgateway --once pushes its metrics to when the pass ends (default: disabled)")
+	flag.StringVar(&config.Mode, "mode", "all",
+		"What this process runs: controller (the controllers only), webhook (the webhook server only) or all")
 
 	// Store the namespace regex string reference for later parsing
 	config.namespaceRegexStr = &namespaceRegexStr
@@ -50,18 +557,60 @@ func NewConfig() *OperatorConfig {
 func (c *OperatorConfig) FinalizeConfig() {
 	// Parse namespace regex patterns from flags
 	if c.namespaceRegexStr != nil && *c.namespaceRegexStr != "" {
-		c.NamespaceRegex = strings.Split(*c.namespaceRegexStr, ",")
-		for i, pattern := range c.NamespaceRegex {
-			c.NamespaceRegex[i] = strings.TrimSpace(pattern)
-		}
+		c.NamespaceRegex = SplitList(*c.namespaceRegexStr)
+	}
+	if c.namespaceExcludeStr != "" {
+		c.NamespaceExclude = SplitList(c.namespaceExcludeStr)
+	}
+	if c.eventTypesStr != "" {
+		c.EventTypes = SplitList(c.eventTypesStr)
+	}
+	if c.maintenanceWindowsStr != "" {
+		c.MaintenanceWindows = SplitSemicolons(c.maintenanceWindowsStr)
+	}
+	if c.ownerRulesStr != "" {
+		c.OwnerRules = SplitSemicolons(c.ownerRulesStr)
+	}
+	if c.skipOwnerKindsStr != "" {
+		c.SkipOwnerKinds = SplitList(c.skipOwnerKindsStr)
+	}
+	if c.configMapDenylistStr != "" {
+		c.ConfigMapDenylist = SplitList(c.configMapDenylistStr)
+	}
+	if c.workloadNameRegexStr != "" {
+		c.WorkloadNameRegex = SplitList(c.workloadNameRegexStr)
+	}
+	if c.workloadNameExcludeStr != "" {
+		c.WorkloadNameExclude = SplitList(c.workloadNameExcludeStr)
+	}
+	if c.referenceTypesStr != "" {
+		c.ReferenceTypes = SplitList(c.referenceTypesStr)
+	}
+	if c.celReferencesStr != "" {
+		c.CELReferences = SplitSemicolons(c.celReferencesStr)
+	}
+	if c.impersonateGroupsStr != "" {
+		c.ImpersonateGroups = SplitList(c.impersonateGroupsStr)
+	}
+	if c.watchKindsStr != "" {
+		c.WatchKinds = SplitList(c.watchKindsStr)
+	}
+	if c.customKindsStr != "" {
+		c.CustomKinds = SplitSemicolons(c.customKindsStr)
+	}
+	if c.tenantServiceAccountsStr != "" {
+		c.TenantServiceAccounts = SplitPairs(c.tenantServiceAccountsStr)
 	}
 
 	// Override with environment variables if present
 	if envRegex := os.Getenv("NAMESPACE_REGEX"); envRegex != "" {
-		c.NamespaceRegex = strings.Split(envRegex, ",")
-		for i, pattern := range c.NamespaceRegex {
-			c.NamespaceRegex[i] = strings.TrimSpace(pattern)
-		}
+		c.NamespaceRegex = SplitList(envRegex)
+	}
+	if envMatch := os.Getenv("NAMESPACE_MATCH"); envMatch != "" {
+		c.NamespaceMatch = envMatch
+	}
+	if envExclude := os.Getenv("NAMESPACE_EXCLUDE_REGEX"); envExclude != "" {
+		c.NamespaceExclude = SplitList(envExclude)
 	}
 
 	if os.Getenv("DRY_RUN") == trueValue {
@@ -76,6 +625,402 @@ func (c *OperatorConfig) FinalizeConfig() {
 		c.Trace = true
 		c.Debug = true // Trace implies debug
 	}
+
+	if v, err := strconv.ParseBool(os.Getenv("EVENTS_ENABLED")); err == nil {
+		c.EventsEnabled = v
+	}
+	if envTypes := os.Getenv("EVENT_TYPES"); envTypes != "" {
+		c.EventTypes = SplitList(envTypes)
+	}
+	if v, err := strconv.ParseFloat(os.Getenv("EVENT_QPS"), 64); err == nil {
+		c.EventQPS = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("EVENT_BURST")); err == nil {
+		c.EventBurst = v
+	}
+
+	if envWindows := os.Getenv("MAINTENANCE_WINDOWS"); envWindows != "" {
+		c.MaintenanceWindows = SplitSemicolons(envWindows)
+	}
+	if envTimezone := os.Getenv("MAINTENANCE_TIMEZONE"); envTimezone != "" {
+		c.MaintenanceTimezone = envTimezone
+	}
+
+	if envControl := os.Getenv("CONTROL_CONFIGMAP"); envControl != "" {
+		c.ControlConfigMap = envControl
+	}
+	if os.Getenv("UNREADY_WHEN_PAUSED") == trueValue {
+		c.UnreadyWhenPaused = true
+	}
+
+	if envUser := os.Getenv("IMPERSONATE_USER"); envUser != "" {
+		c.ImpersonateUser = envUser
+	}
+	if envGroups := os.Getenv("IMPERSONATE_GROUPS"); envGroups != "" {
+		c.ImpersonateGroups = SplitList(envGroups)
+	}
+	if envTenants := os.Getenv("TENANT_SERVICE_ACCOUNTS"); envTenants != "" {
+		c.TenantServiceAccounts = SplitPairs(envTenants)
+	}
+
+	if v, err := strconv.Atoi(os.Getenv("CHURN_THRESHOLD")); err == nil {
+		c.ChurnThreshold = v
+	}
+	if v, err := time.ParseDuration(os.Getenv("CHURN_WINDOW")); err == nil {
+		c.ChurnWindow = v
+	}
+
+	if os.Getenv("SHADOW") == trueValue {
+		c.Shadow = true
+	}
+	if v, err := time.ParseDuration(os.Getenv("SHADOW_DELAY")); err == nil {
+		c.ShadowDelay = v
+	}
+
+	if os.Getenv("MIGRATE") == trueValue {
+		c.Migrate = true
+	}
+
+	if envUsage := os.Getenv("USAGE_METRICS"); envUsage != "" {
+		c.UsageMetrics = envUsage
+	}
+	if v, err := strconv.Atoi(os.Getenv("USAGE_METRICS_MAX_SERIES")); err == nil {
+		c.UsageMetricsMaxSeries = v
+	}
+
+	if envInventory := os.Getenv("INVENTORY_CONFIGMAP"); envInventory != "" {
+		c.InventoryConfigMap = envInventory
+	}
+	if v, err := time.ParseDuration(os.Getenv("INVENTORY_INTERVAL")); err == nil {
+		c.InventoryInterval = v
+	}
+
+	if os.Getenv("INGRESS_TLS_SECRETS") == trueValue {
+		c.IngressTLSSecrets = true
+	}
+	if os.Getenv("SECRETS_ENABLED") == trueValue {
+		c.SecretsEnabled = true
+	}
+	if os.Getenv("IMAGE_PULL_SECRETS") == trueValue {
+		c.ImagePullSecrets = true
+	}
+
+	if os.Getenv("CONSUMED_KEYS_ONLY") == trueValue {
+		c.ConsumedKeysOnly = true
+	}
+	if envOptional := os.Getenv("OPTIONAL_REFERENCES"); envOptional != "" {
+		c.OptionalReferences = envOptional
+	}
+
+	if os.Getenv("POD_TEMPLATES") == trueValue {
+		c.PodTemplates = true
+	}
+	if os.Getenv("STATEFULSETS") == trueValue {
+		c.StatefulSets = true
+	}
+	if os.Getenv("DAEMONSETS") == trueValue {
+		c.DaemonSets = true
+	}
+	if os.Getenv("JOBS") == trueValue {
+		c.Jobs = true
+	}
+	if os.Getenv("CRONJOBS") == trueValue {
+		c.CronJobs = true
+	}
+	if envOwner := os.Getenv("CRONJOB_OWNER"); envOwner != "" {
+		c.CronJobOwner = envOwner
+	}
+	if os.Getenv("PODS") == trueValue {
+		c.Pods = true
+	}
+	if envKinds := os.Getenv("WATCH_KINDS"); envKinds != "" {
+		c.WatchKinds = SplitList(envKinds)
+	}
+	if envKinds := os.Getenv("CUSTOM_KINDS"); envKinds != "" {
+		c.CustomKinds = SplitSemicolons(envKinds)
+	}
+
+	if envCheckpoint := os.Getenv("BACKFILL_CHECKPOINT"); envCheckpoint != "" {
+		c.BackfillCheckpoint = envCheckpoint
+	}
+	if v, err := strconv.ParseBool(os.Getenv("PRIORITIZE_LIVE_EVENTS")); err == nil {
+		c.PrioritizeLiveEvents = v
+	}
+
+	if os.Getenv("PRECOMPUTE_DEPLOYMENTS") == trueValue {
+		c.PrecomputeDeployments = true
+	}
+	if os.Getenv("CLEANUP_DISABLED_KINDS") == trueValue {
+		c.CleanupDisabledKinds = true
+	}
+	if os.Getenv("PATCH_ONLY") == trueValue {
+		c.PatchOnly = true
+	}
+	if os.Getenv("CLEANUP_OUT_OF_SCOPE") == trueValue {
+		c.CleanupOutOfScope = true
+	}
+	if os.Getenv("ANNOTATE_WORKLOADS") == trueValue {
+		c.AnnotateWorkloads = true
+	}
+
+	if envRules := os.Getenv("OWNER_RULES"); envRules != "" {
+		c.OwnerRules = SplitSemicolons(envRules)
+	}
+	if envTarget := os.Getenv("OWNER_TARGET"); envTarget != "" {
+		c.OwnerTarget = envTarget
+	}
+	if envKinds := os.Getenv("SKIP_OWNER_KINDS"); envKinds != "" {
+		c.SkipOwnerKinds = SplitList(envKinds)
+	}
+	if os.Getenv("SKIP_DORMANT") == trueValue {
+		c.SkipDormant = true
+	}
+	if envDenylist := os.Getenv("CONFIGMAP_DENYLIST"); envDenylist != "" {
+		c.ConfigMapDenylist = SplitList(envDenylist)
+	}
+	if os.Getenv("DISABLE_BUILTIN_DENYLIST") == trueValue {
+		c.DisableBuiltinDenylist = true
+	}
+	if os.Getenv("OWN_HELM_MANAGED") == trueValue {
+		c.OwnHelmManaged = true
+	}
+	if os.Getenv("OWN_ARGOCD_MANAGED") == trueValue {
+		c.OwnArgoCDManaged = true
+	}
+	if envOptions := os.Getenv("ARGOCD_COMPARE_OPTIONS"); envOptions != "" {
+		c.ArgoCDCompareOptions = envOptions
+	}
+	if os.Getenv("OPT_IN") == trueValue {
+		c.OptIn = true
+	}
+	if envRegex := os.Getenv("WORKLOAD_NAME_REGEX"); envRegex != "" {
+		c.WorkloadNameRegex = SplitList(envRegex)
+	}
+	if envExclude := os.Getenv("WORKLOAD_NAME_EXCLUDE_REGEX"); envExclude != "" {
+		c.WorkloadNameExclude = SplitList(envExclude)
+	}
+	if os.Getenv("NAME_CONVENTION") == trueValue {
+		c.NameConvention = true
+	}
+	if envPattern := os.Getenv("NAME_CONVENTION_PATTERN"); envPattern != "" {
+		c.NameConventionPattern = envPattern
+	}
+	if os.Getenv("CONFIGMAP_BINDINGS") == trueValue {
+		c.ConfigMapBindings = true
+	}
+	if envTypes := os.Getenv("REFERENCE_TYPES"); envTypes != "" {
+		c.ReferenceTypes = SplitList(envTypes)
+	}
+	if envCEL := os.Getenv("CEL_REFERENCES"); envCEL != "" {
+		c.CELReferences = SplitSemicolons(envCEL)
+	}
+	if os.Getenv("UNMOUNTED_VOLUMES") == trueValue {
+		c.UnmountedVolumes = true
+	}
+	if v, err := strconv.Atoi(os.Getenv("MAX_EXISTING_OWNERS")); err == nil {
+		c.MaxExistingOwners = v
+	}
+	if v, err := time.ParseDuration(os.Getenv("MAX_RECONCILE_STALENESS")); err == nil {
+		c.MaxReconcileStaleness = v
+	}
+	if v, err := time.ParseDuration(os.Getenv("WATCH_STALL_TIMEOUT")); err == nil {
+		c.WatchStallTimeout = v
+	}
+	if envAction := os.Getenv("WATCH_STALL_ACTION"); envAction != "" {
+		c.WatchStallAction = envAction
+	}
+	if v, err := time.ParseDuration(os.Getenv("DRIFT_SCAN_INTERVAL")); err == nil {
+		c.DriftScanInterval = v
+	}
+	if v, err := time.ParseDuration(os.Getenv("KUSTOMIZE_CLEANUP_INTERVAL")); err == nil {
+		c.KustomizeCleanupInterval = v
+	}
+	if v, err := time.ParseDuration(os.Getenv("CONFLICT_SCAN_INTERVAL")); err == nil {
+		c.ConflictScanInterval = v
+	}
+	if v, err := time.ParseDuration(os.Getenv("MISSING_REFERENCE_SCAN_INTERVAL")); err == nil {
+		c.MissingReferenceScanInterval = v
+	}
+	if v, err := time.ParseDuration(os.Getenv("MISSING_REFERENCE_WINDOW")); err == nil {
+		c.MissingReferenceWindow = v
+	}
+	if os.Getenv("REQUIRE_APPROVAL") == trueValue {
+		c.RequireApproval = true
+	}
+	if v, err := time.ParseDuration(os.Getenv("COALESCE_WINDOW")); err == nil {
+		c.CoalesceWindow = v
+	}
+	if envLabels := os.Getenv("METRICS_NAMESPACE_LABELS"); envLabels != "" {
+		c.MetricsNamespaceLabels = envLabels
+	}
+	if v, err := strconv.Atoi(os.Getenv("METRICS_TOP_NAMESPACES")); err == nil {
+		c.MetricsTopNamespaces = v
+	}
+	if envStore := os.Getenv("STATE_STORE"); envStore != "" {
+		c.StateStore = envStore
+	}
+	if v, err := strconv.ParseBool(os.Getenv("REVALIDATE_ROLLBACKS")); err == nil {
+		c.RevalidateRollbacks = v
+	}
+	if v, err := strconv.ParseBool(os.Getenv("ROLLOUT_HANDOFF")); err == nil {
+		c.RolloutHandoff = v
+	}
+	if envPushgateway := os.Getenv("PUSHGATEWAY_URL"); envPushgateway != "" {
+		c.PushgatewayURL = envPushgateway
+	}
+	if envMode := os.Getenv("MODE"); envMode != "" {
+		c.Mode = envMode
+	}
+	c.applyWatchKinds()
+}
+
+// applyWatchKinds sets the per-kind switches to the kinds WatchKinds lists, if any. Deployments are only watched to
+// precompute their ConfigMaps.
+func (c *OperatorConfig) applyWatchKinds() {
+	if len(c.WatchKinds) == 0 {
+		return
+	}
+	c.PrecomputeDeployments = slices.Contains(c.WatchKinds, "deployments")
+	c.PodTemplates = slices.Contains(c.WatchKinds, "podtemplates")
+	c.StatefulSets = slices.Contains(c.WatchKinds, "statefulsets")
+	c.DaemonSets = slices.Contains(c.WatchKinds, "daemonsets")
+	c.Jobs = slices.Contains(c.WatchKinds, "jobs")
+	c.CronJobs = slices.Contains(c.WatchKinds, "cronjobs")
+	c.Pods = slices.Contains(c.WatchKinds, "pods")
+}
+
+// WatchesReplicaSets reports whether the operator watches ReplicaSets, which it does unless WatchKinds omits them
+func (c *OperatorConfig) WatchesReplicaSets() bool {
+	return len(c.WatchKinds) == 0 || slices.Contains(c.WatchKinds, "replicasets")
+}
+
+// SplitPairs parses comma-separated key=value pairs; items without "=" are ignored
+func SplitPairs(value string) map[string]string {
+	pairs := make(map[string]string)
+	for _, item := range SplitList(value) {
+		if k, v, ok := strings.Cut(item, "="); ok {
+			pairs[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+	}
+	return pairs
+}
+
+// SplitSemicolons splits semicolon-separated items, for values such as cron fields and regular expressions
+// that contain commas
+func SplitSemicolons(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ";") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// SplitList splits a comma-separated value into trimmed, non-empty items
+func SplitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Summary returns the effective configuration as structured logging key/value pairs
+func (c *OperatorConfig) Summary() []interface{} {
+	mode := "write"
+	switch {
+	case c.Shadow:
+		mode = "shadow"
+	case c.DryRun:
+		mode = "dry-run"
+	}
+	return []interface{}{
+		"mode", mode,
+		"namespaceRegex", c.NamespaceRegex,
+		"namespaceMatch", c.NamespaceMatch,
+		"namespaceExclude", c.NamespaceExclude,
+		"debug", c.Debug,
+		"trace", c.Trace,
+		"eventsEnabled", c.EventsEnabled,
+		"eventTypes", c.EventTypes,
+		"eventQPS", c.EventQPS,
+		"eventBurst", c.EventBurst,
+		"maintenanceWindows", c.MaintenanceWindows,
+		"maintenanceTimezone", c.MaintenanceTimezone,
+		"controlConfigMap", c.ControlConfigMap,
+		"unreadyWhenPaused", c.UnreadyWhenPaused,
+		"impersonateUser", c.ImpersonateUser,
+		"impersonateGroups", c.ImpersonateGroups,
+		"tenantServiceAccounts", c.TenantServiceAccounts,
+		"churnThreshold", c.ChurnThreshold,
+		"churnWindow", c.ChurnWindow.String(),
+		"shadowDelay", c.ShadowDelay.String(),
+		"migrate", c.Migrate,
+		"usageMetrics", c.UsageMetrics,
+		"usageMetricsMaxSeries", c.UsageMetricsMaxSeries,
+		"inventoryConfigMap", c.InventoryConfigMap,
+		"inventoryInterval", c.InventoryInterval.String(),
+		"ingressTLSSecrets", c.IngressTLSSecrets,
+		"secretsEnabled", c.SecretsEnabled,
+		"imagePullSecrets", c.ImagePullSecrets,
+		"consumedKeysOnly", c.ConsumedKeysOnly,
+		"optionalReferences", c.OptionalReferences,
+		"podTemplates", c.PodTemplates,
+		"statefulSets", c.StatefulSets,
+		"daemonSets", c.DaemonSets,
+		"jobs", c.Jobs,
+		"cronJobs", c.CronJobs,
+		"cronJobOwner", c.CronJobOwner,
+		"pods", c.Pods,
+		"watchKinds", c.WatchKinds,
+		"customKinds", c.CustomKinds,
+		"backfillCheckpoint", c.BackfillCheckpoint,
+		"prioritizeLiveEvents", c.PrioritizeLiveEvents,
+		"precomputeDeployments", c.PrecomputeDeployments,
+		"cleanupDisabledKinds", c.CleanupDisabledKinds,
+		"cleanupOutOfScope", c.CleanupOutOfScope,
+		"patchOnly", c.PatchOnly,
+		"annotateWorkloads", c.AnnotateWorkloads,
+		"ownerRules", c.OwnerRules,
+		"ownerTarget", c.OwnerTarget,
+		"skipOwnerKinds", c.SkipOwnerKinds,
+		"skipDormant", c.SkipDormant,
+		"configMapDenylist", c.ConfigMapDenylist,
+		"disableBuiltinDenylist", c.DisableBuiltinDenylist,
+		"ownHelmManaged", c.OwnHelmManaged,
+		"ownArgoCDManaged", c.OwnArgoCDManaged,
+		"argoCDCompareOptions", c.ArgoCDCompareOptions,
+		"optIn", c.OptIn,
+		"workloadNameRegex", c.WorkloadNameRegex,
+		"workloadNameExclude", c.WorkloadNameExclude,
+		"nameConvention", c.NameConvention,
+		"nameConventionPattern", c.NameConventionPattern,
+		"configMapBindings", c.ConfigMapBindings,
+		"referenceTypes", c.ReferenceTypes,
+		"celReferences", c.CELReferences,
+		"unmountedVolumes", c.UnmountedVolumes,
+		"maxExistingOwners", c.MaxExistingOwners,
+		"maxReconcileStaleness", c.MaxReconcileStaleness.String(),
+		"watchStallTimeout", c.WatchStallTimeout.String(),
+		"watchStallAction", c.WatchStallAction,
+		"driftScanInterval", c.DriftScanInterval.String(),
+		"kustomizeCleanupInterval", c.KustomizeCleanupInterval.String(),
+		"conflictScanInterval", c.ConflictScanInterval.String(),
+		"missingReferenceScanInterval", c.MissingReferenceScanInterval.String(),
+		"missingReferenceWindow", c.MissingReferenceWindow.String(),
+		"requireApproval", c.RequireApproval,
+		"coalesceWindow", c.CoalesceWindow.String(),
+		"metricsNamespaceLabels", c.MetricsNamespaceLabels,
+		"metricsTopNamespaces", c.MetricsTopNamespaces,
+		"stateStore", c.StateStore,
+		"revalidateRollbacks", c.RevalidateRollbacks,
+		"rolloutHandoff", c.RolloutHandoff,
+		"pushgatewayURL", c.PushgatewayURL,
+		"topology", c.Mode,
+	}
 }
 
 // LogLevel returns the appropriate log level based on configuration
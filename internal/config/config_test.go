@@ -8,13 +8,31 @@ import (
 	"github.com/onsi/gomega"
 )
 
+// envKeys lists every environment variable read by FinalizeConfig
+var envKeys = []string{
+	"NAMESPACE_REGEX", "DRY_RUN", "DEBUG", "TRACE",
+	"EVENTS_ENABLED", "EVENT_TYPES", "EVENT_QPS", "EVENT_BURST",
+	"MAINTENANCE_WINDOWS", "MAINTENANCE_TIMEZONE", "CONTROL_CONFIGMAP",
+	"UNREADY_WHEN_PAUSED", "IMPERSONATE_USER", "IMPERSONATE_GROUPS",
+	"TENANT_SERVICE_ACCOUNTS", "CHURN_THRESHOLD", "CHURN_WINDOW",
+	"SHADOW", "SHADOW_DELAY", "MIGRATE", "USAGE_METRICS", "USAGE_METRICS_MAX_SERIES",
+	"INVENTORY_CONFIGMAP", "INVENTORY_INTERVAL", "INGRESS_TLS_SECRETS",
+	"CONSUMED_KEYS_ONLY", "OPTIONAL_REFERENCES",
+	"POD_TEMPLATES", "BACKFILL_CHECKPOINT", "PRIORITIZE_LIVE_EVENTS", "PRECOMPUTE_DEPLOYMENTS", "CLEANUP_DISABLED_KINDS", "OWNER_RULES", "MAX_EXISTING_OWNERS",
+	"MAX_RECONCILE_STALENESS", "WATCH_STALL_TIMEOUT",
+	"WATCH_STALL_ACTION", "DRIFT_SCAN_INTERVAL", "PUSHGATEWAY_URL", "REQUIRE_APPROVAL",
+	"COALESCE_WINDOW", "METRICS_NAMESPACE_LABELS", "METRICS_TOP_NAMESPACES", "STATE_STORE", "REVALIDATE_ROLLBACKS",
+	"CLEANUP_OUT_OF_SCOPE", "CONFLICT_SCAN_INTERVAL", "PATCH_ONLY", "ANNOTATE_WORKLOADS",
+	"MISSING_REFERENCE_SCAN_INTERVAL", "MISSING_REFERENCE_WINDOW", "NAMESPACE_MATCH", "NAMESPACE_EXCLUDE_REGEX", "MODE", "STATEFULSETS", "DAEMONSETS", "JOBS", "CRONJOBS", "CRONJOB_OWNER", "PODS", "CUSTOM_KINDS", "WATCH_KINDS", "OWNER_TARGET", "ROLLOUT_HANDOFF", "SKIP_OWNER_KINDS", "SKIP_DORMANT", "CONFIGMAP_DENYLIST", "DISABLE_BUILTIN_DENYLIST", "OWN_HELM_MANAGED", "OWN_ARGOCD_MANAGED", "ARGOCD_COMPARE_OPTIONS", "OPT_IN", "WORKLOAD_NAME_REGEX", "WORKLOAD_NAME_EXCLUDE_REGEX", "NAME_CONVENTION", "NAME_CONVENTION_PATTERN", "CONFIGMAP_BINDINGS", "REFERENCE_TYPES", "CEL_REFERENCES", "UNMOUNTED_VOLUMES", "SECRETS_ENABLED", "IMAGE_PULL_SECRETS", "KUSTOMIZE_CLEANUP_INTERVAL",
+}
+
 var _ = ginkgo.Describe("Config", func() {
 	var originalEnv map[string]string
 
 	ginkgo.BeforeEach(func() {
 		// Save original environment
 		originalEnv = make(map[string]string)
-		for _, key := range []string{"NAMESPACE_REGEX", "DRY_RUN", "DEBUG", "TRACE"} {
+		for _, key := range envKeys {
 			if val, exists := os.LookupEnv(key); exists {
 				originalEnv[key] = val
 			}
@@ -24,7 +42,7 @@ var _ = ginkgo.Describe("Config", func() {
 
 	ginkgo.AfterEach(func() {
 		// Restore original environment
-		for _, key := range []string{"NAMESPACE_REGEX", "DRY_RUN", "DEBUG", "TRACE"} {
+		for _, key := range envKeys {
 			os.Unsetenv(key)
 			if val, exists := originalEnv[key]; exists {
 				os.Setenv(key, val)
@@ -85,6 +103,123 @@ var _ = ginkgo.Describe("Config", func() {
 		})
 	})
 
+	ginkgo.Describe("FinalizeConfig", func() {
+		ginkgo.It("should parse event settings from environment", func() {
+			os.Setenv("EVENTS_ENABLED", "false")
+			os.Setenv("EVENT_TYPES", "Warning, Normal")
+			os.Setenv("EVENT_QPS", "0.5")
+			os.Setenv("EVENT_BURST", "3")
+
+			config := &OperatorConfig{EventsEnabled: true}
+			config.FinalizeConfig()
+
+			gomega.Expect(config.EventsEnabled).To(gomega.BeFalse())
+			gomega.Expect(config.EventTypes).To(gomega.Equal([]string{"Warning", "Normal"}))
+			gomega.Expect(config.EventQPS).To(gomega.Equal(0.5))
+			gomega.Expect(config.EventBurst).To(gomega.Equal(3))
+		})
+
+		ginkgo.It("should keep flag values when event variables are unset", func() {
+			config := &OperatorConfig{EventsEnabled: true, EventQPS: 1, EventBurst: 10}
+			config.FinalizeConfig()
+
+			gomega.Expect(config.EventsEnabled).To(gomega.BeTrue())
+			gomega.Expect(config.EventTypes).To(gomega.BeEmpty())
+			gomega.Expect(config.EventQPS).To(gomega.Equal(1.0))
+			gomega.Expect(config.EventBurst).To(gomega.Equal(10))
+		})
+
+		ginkgo.It("should parse the namespace exclusions from environment", func() {
+			os.Setenv("NAMESPACE_REGEX", "^team-.*")
+			os.Setenv("NAMESPACE_EXCLUDE_REGEX", "^kube-.*, ^monitoring$")
+
+			config := &OperatorConfig{}
+			config.FinalizeConfig()
+
+			gomega.Expect(config.NamespaceRegex).To(gomega.Equal([]string{"^team-.*"}))
+			gomega.Expect(config.NamespaceExclude).To(gomega.Equal([]string{"^kube-.*", "^monitoring$"}))
+		})
+	})
+
+	ginkgo.Describe("Maintenance windows", func() {
+		ginkgo.It("should split windows on semicolons", func() {
+			os.Setenv("MAINTENANCE_WINDOWS", "0 2 * * 1-5 2h; 0 0 * * 0,6 24h")
+			os.Setenv("MAINTENANCE_TIMEZONE", "Europe/Berlin")
+
+			config := &OperatorConfig{}
+			config.FinalizeConfig()
+
+			gomega.Expect(config.MaintenanceWindows).To(gomega.Equal([]string{"0 2 * * 1-5 2h", "0 0 * * 0,6 24h"}))
+			gomega.Expect(config.MaintenanceTimezone).To(gomega.Equal("Europe/Berlin"))
+		})
+	})
+
+	ginkgo.Describe("Impersonation", func() {
+		ginkgo.It("should parse the impersonated identity from environment", func() {
+			os.Setenv("IMPERSONATE_USER", "system:serviceaccount:ops:writer")
+			os.Setenv("IMPERSONATE_GROUPS", "ops, auditors")
+
+			config := &OperatorConfig{}
+			config.FinalizeConfig()
+
+			gomega.Expect(config.ImpersonateUser).To(gomega.Equal("system:serviceaccount:ops:writer"))
+			gomega.Expect(config.ImpersonateGroups).To(gomega.Equal([]string{"ops", "auditors"}))
+		})
+	})
+
+	ginkgo.Describe("Tenant service accounts", func() {
+		ginkgo.It("should parse namespace=serviceaccount pairs from environment", func() {
+			os.Setenv("TENANT_SERVICE_ACCOUNTS", "team-a=writer-a, *=writer, malformed")
+
+			config := &OperatorConfig{}
+			config.FinalizeConfig()
+
+			gomega.Expect(config.TenantServiceAccounts).To(gomega.Equal(map[string]string{
+				"team-a": "writer-a",
+				"*":      "writer",
+			}))
+		})
+	})
+
+	ginkgo.Describe("Watch kinds", func() {
+		ginkgo.It("should override the per-kind switches with the listed kinds", func() {
+			os.Setenv("WATCH_KINDS", "jobs, cronjobs")
+
+			config := &OperatorConfig{StatefulSets: true, PrecomputeDeployments: true}
+			config.FinalizeConfig()
+
+			gomega.Expect(config.Jobs).To(gomega.BeTrue())
+			gomega.Expect(config.CronJobs).To(gomega.BeTrue())
+			gomega.Expect(config.StatefulSets).To(gomega.BeFalse())
+			gomega.Expect(config.PrecomputeDeployments).To(gomega.BeFalse())
+			gomega.Expect(config.WatchesReplicaSets()).To(gomega.BeFalse())
+		})
+
+		ginkgo.It("should keep the per-kind switches and ReplicaSets when unset", func() {
+			config := &OperatorConfig{StatefulSets: true}
+			config.FinalizeConfig()
+
+			gomega.Expect(config.StatefulSets).To(gomega.BeTrue())
+			gomega.Expect(config.WatchesReplicaSets()).To(gomega.BeTrue())
+		})
+	})
+
+	ginkgo.Describe("Summary", func() {
+		ginkgo.It("should report the mode and settings as key/value pairs", func() {
+			config := &OperatorConfig{DryRun: true, NamespaceRegex: []string{"^app-"}}
+			summary := config.Summary()
+
+			gomega.Expect(len(summary) % 2).To(gomega.Equal(0))
+			gomega.Expect(summary[0:2]).To(gomega.Equal([]interface{}{"mode", "dry-run"}))
+			gomega.Expect(summary).To(gomega.ContainElement([]string{"^app-"}))
+		})
+
+		ginkgo.It("should report shadow mode", func() {
+			config := &OperatorConfig{Shadow: true}
+			gomega.Expect(config.Summary()[0:2]).To(gomega.Equal([]interface{}{"mode", "shadow"}))
+		})
+	})
+
 	ginkgo.Describe("LogLevel", func() {
 		ginkgo.It("should return normal level by default", func() {
 			config := &OperatorConfig{}
@@ -0,0 +1,134 @@
+package controller
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// defaultActivitySize bounds the number of entries kept in the activity log
+const defaultActivitySize = 200
+
+// Activity is a decision or error of a recent reconcile
+type Activity struct {
+	Time time.Time `json:"time"`
+	Decision
+	Error string `json:"error,omitempty"`
+}
+
+// ActivityLog keeps the most recent decisions and errors of the reconciler for the dashboard
+type ActivityLog struct {
+	// Size bounds the number of entries kept (default: 200)
+	Size int
+
+	mu      sync.Mutex
+	entries []Activity
+}
+
+// NewActivityLog returns an activity log keeping the most recent size entries
+func NewActivityLog(size int) *ActivityLog {
+	return &ActivityLog{Size: size}
+}
+
+// Recent returns the kept entries, newest first
+func (l *ActivityLog) Recent() []Activity {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	recent := slices.Clone(l.entries)
+	slices.Reverse(recent)
+	return recent
+}
+
+// record adds the decisions of a reconcile of req and, if it failed, its error
+func (l *ActivityLog) record(req types.NamespacedName, decisions []Decision, err error) {
+	now := time.Now().UTC()
+	entries := make([]Activity, 0, len(decisions)+1)
+	for _, d := range decisions {
+		entries = append(entries, Activity{Time: now, Decision: d})
+	}
+	if err != nil {
+		entries = append(entries, Activity{
+			Time:     now,
+			Decision: Decision{Namespace: req.Namespace, ReplicaSet: req.Name, Action: decisionFailed},
+			Error:    err.Error(),
+		})
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, entries...)
+	size := l.Size
+	if size <= 0 {
+		size = defaultActivitySize
+	}
+	if excess := len(l.entries) - size; excess > 0 {
+		l.entries = slices.Delete(l.entries, 0, excess)
+	}
+}
+
+// Overview is the state shown on the dashboard
+type Overview struct {
+	Namespaces []NamespaceOverview `json:"namespaces"`
+	ConfigMaps []ManagedConfigMap  `json:"configMaps"`
+	Activity   []Activity          `json:"activity"`
+}
+
+// NamespaceOverview is a namespace selected by the operator and why writes are held in it, if they are
+type NamespaceOverview struct {
+	Name string `json:"name"`
+	Hold string `json:"hold,omitempty"`
+}
+
+// ManagedConfigMap is a ConfigMap carrying owner references added by the operator
+type ManagedConfigMap struct {
+	Namespace string   `json:"namespace"`
+	Name      string   `json:"name"`
+	Owners    []string `json:"owners"`
+}
+
+// Overview returns the namespaces in scope, the ConfigMaps with owner references added by the
+// operator and the recent activity
+func (r *ReplicaSetReconciler) Overview(ctx context.Context) (*Overview, error) {
+	o := &Overview{}
+
+	var namespaces corev1.NamespaceList
+	if err := r.List(ctx, &namespaces); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for i := range namespaces.Items {
+		name := namespaces.Items[i].Name
+		if !r.shouldProcessNamespace(ctx, name) {
+			continue
+		}
+		o.Namespaces = append(o.Namespaces, NamespaceOverview{
+			Name: name, Hold: r.holdReason(ctx, name, now, logr.Discard()),
+		})
+	}
+
+	var configMaps corev1.ConfigMapList
+	if err := r.List(ctx, &configMaps); err != nil {
+		return nil, err
+	}
+	for i := range configMaps.Items {
+		if entry, ok := managedConfigMap(&configMaps.Items[i]); ok {
+			o.ConfigMaps = append(o.ConfigMaps, entry)
+		}
+	}
+	sort.Slice(o.Namespaces, func(i, j int) bool { return o.Namespaces[i].Name < o.Namespaces[j].Name })
+	sort.Slice(o.ConfigMaps, func(i, j int) bool {
+		a, b := o.ConfigMaps[i], o.ConfigMaps[j]
+		return a.Namespace < b.Namespace || a.Namespace == b.Namespace && a.Name < b.Name
+	})
+
+	if r.Activity != nil {
+		o.Activity = r.Activity.Recent()
+	}
+	return o, nil
+}
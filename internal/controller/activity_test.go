@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+var _ = ginkgo.Describe("ActivityLog", func() {
+	ginkgo.It("Should keep the most recent entries, newest first", func() {
+		l := NewActivityLog(2)
+		for i := range 3 {
+			l.record(types.NamespacedName{Namespace: "default", Name: fmt.Sprintf("rs-%d", i)},
+				[]Decision{{ReplicaSet: fmt.Sprintf("rs-%d", i), Action: decisionAdded}}, nil)
+		}
+		recent := l.Recent()
+		gomega.Expect(recent).To(gomega.HaveLen(2))
+		gomega.Expect(recent[0].ReplicaSet).To(gomega.Equal("rs-2"))
+		gomega.Expect(recent[1].ReplicaSet).To(gomega.Equal("rs-1"))
+	})
+
+	ginkgo.It("Should record errors", func() {
+		l := NewActivityLog(0)
+		l.record(types.NamespacedName{Namespace: "default", Name: "rs-a"}, nil, fmt.Errorf("boom"))
+		gomega.Expect(l.Recent()).To(gomega.ConsistOf(gomega.And(
+			gomega.HaveField("Action", decisionFailed),
+			gomega.HaveField("Error", "boom"),
+		)))
+	})
+})
+
+var _ = ginkgo.Describe("Overview", func() {
+	ginkgo.It("Should show namespaces in scope, managed ConfigMaps and recent activity", func() {
+		ctx := context.Background()
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a"}},
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system"}},
+			testReplicaSet("rs-a", "team-a", "cm-a"), testConfigMap("cm-a", "team-a"),
+			testConfigMap("unmanaged", "team-a"),
+		).Build()
+		r := &ReplicaSetReconciler{
+			Client:   c,
+			Scheme:   scheme.Scheme,
+			Config:   &config.OperatorConfig{NamespaceRegex: []string{"^team-"}},
+			Activity: NewActivityLog(0),
+		}
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "team-a", Name: "rs-a"}})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+		o, err := r.Overview(ctx)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(o.Namespaces).To(gomega.Equal([]NamespaceOverview{{Name: "team-a"}}))
+		gomega.Expect(o.ConfigMaps).To(gomega.Equal([]ManagedConfigMap{
+			{Namespace: "team-a", Name: "cm-a", Owners: []string{"ReplicaSet/rs-a"}},
+		}))
+		gomega.Expect(o.Activity).To(gomega.ConsistOf(gomega.HaveField("Action", decisionAdded)))
+	})
+})
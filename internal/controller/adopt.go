@@ -0,0 +1,46 @@
+package controller
+
+import (
+	"context"
+	"errors"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// AdoptOptions selects the existing ReplicaSets an adoption processes
+type AdoptOptions struct {
+	// Namespace limits the adoption to one namespace
+	Namespace string
+
+	// Selector limits the adoption to matching ReplicaSets; nil matches every ReplicaSet
+	Selector labels.Selector
+}
+
+// Adopt reconciles the existing ReplicaSets selected by opts on demand and returns the decisions made,
+// so teams can migrate one namespace at a time. Like RunOnce, the StartTime gate still applies, so callers
+// adopting ReplicaSets that predate the operator leave StartTime unset.
+func (r *ReplicaSetReconciler) Adopt(ctx context.Context, opts AdoptOptions) ([]Decision, error) {
+	listOpts := []client.ListOption{client.InNamespace(opts.Namespace)}
+	if opts.Selector != nil {
+		listOpts = append(listOpts, client.MatchingLabelsSelector{Selector: opts.Selector})
+	}
+	var list appsv1.ReplicaSetList
+	if err := r.List(ctx, &list, listOpts...); err != nil {
+		return nil, err
+	}
+
+	ctx, collector := withDecisions(ctx)
+	var errs []error
+	for i := range list.Items {
+		rs := &list.Items[i]
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: rs.Name, Namespace: rs.Namespace}}
+		if _, err := r.Reconcile(ctx, req); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return collector.list(), errors.Join(errs...)
+}
@@ -0,0 +1,45 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+var _ = ginkgo.Describe("Adopt", func() {
+	ginkgo.It("Should only adopt the selected ReplicaSets of the namespace", func() {
+		ctx := context.Background()
+		web := testReplicaSet("web", "team-a", "cm-web")
+		web.Labels = map[string]string{"tier": "frontend"}
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+			web, testConfigMap("cm-web", "team-a"),
+			testReplicaSet("worker", "team-a", "cm-worker"), testConfigMap("cm-worker", "team-a"),
+			testReplicaSet("other", "team-b", "cm-other"), testConfigMap("cm-other", "team-b"),
+		).Build()
+		reconciler := &ReplicaSetReconciler{Client: c, Scheme: scheme.Scheme, Config: &config.OperatorConfig{}}
+
+		decisions, err := reconciler.Adopt(ctx, AdoptOptions{
+			Namespace: "team-a",
+			Selector:  labels.SelectorFromSet(labels.Set{"tier": "frontend"}),
+		})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(decisions).To(gomega.ConsistOf(Decision{
+			Namespace: "team-a", ReplicaSet: "web", ConfigMap: "cm-web", Action: decisionAdded,
+		}))
+
+		owners := map[string]int{"cm-web": 1, "cm-worker": 0}
+		for name, expected := range owners {
+			var cm corev1.ConfigMap
+			gomega.Expect(c.Get(ctx, types.NamespacedName{Name: name, Namespace: "team-a"}, &cm)).To(gomega.Succeed())
+			gomega.Expect(cm.OwnerReferences).To(gomega.HaveLen(expected))
+		}
+	})
+})
@@ -0,0 +1,227 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// Annotations of the approval workflow
+const (
+	// PendingOwnersAnnotation holds the owner references proposed for a ConfigMap with --require-approval, as JSON
+	PendingOwnersAnnotation = "configmap-rs-operator/pending-owners"
+
+	// ApprovedAnnotation set to "true" on a ConfigMap approves its pending owner references
+	ApprovedAnnotation = "configmap-rs-operator/approved"
+)
+
+// holdApproval is the hold reason of owner references proposed and waiting for approval
+const holdApproval = "AWAITING-APPROVAL"
+
+// pendingOwners returns the owner references proposed for obj
+func pendingOwners(obj metav1.Object) []metav1.OwnerReference {
+	var refs []metav1.OwnerReference
+	if value := obj.GetAnnotations()[PendingOwnersAnnotation]; value != "" {
+		// An annotation edited into something unreadable proposes nothing; the next proposal rewrites it
+		_ = json.Unmarshal([]byte(value), &refs)
+	}
+	return refs
+}
+
+// setPendingOwners stores refs in the pending owners annotation of obj, removing it when empty
+func setPendingOwners(obj metav1.Object, refs []metav1.OwnerReference) {
+	annotations := obj.GetAnnotations()
+	if len(refs) == 0 {
+		delete(annotations, PendingOwnersAnnotation)
+		return
+	}
+	data, _ := json.Marshal(refs)
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[PendingOwnersAnnotation] = string(data)
+	obj.SetAnnotations(annotations)
+}
+
+// proposeOwner queues owner on obj, a ConfigMap or Secret, for approval instead of adding it. An owner proposed
+// before is left as is.
+func (r *ReplicaSetReconciler) proposeOwner(
+	ctx context.Context,
+	obj client.Object,
+	owner metav1.OwnerReference,
+	logger logr.Logger,
+) error {
+	pending := pendingOwners(obj)
+	if slices.ContainsFunc(pending, func(ref metav1.OwnerReference) bool { return ref.UID == owner.UID }) {
+		return nil
+	}
+	original := obj.DeepCopyObject().(client.Object)
+	setPendingOwners(obj, append(pending, owner))
+	if err := r.updateObject(ctx, r.writer(), obj, original); err != nil {
+		logger.Error(err, "Failed to propose owner reference", "name", obj.GetName())
+		return err
+	}
+	logger.Info("Proposed OwnerReference for approval", "name", obj.GetName(), "owner", owner.Kind+"/"+owner.Name)
+	r.recordEvent(obj, corev1.EventTypeNormal, "OwnerReferenceProposed",
+		"Owner reference to %s %s awaits approval: annotate it with %s=true", owner.Kind, owner.Name,
+		ApprovedAnnotation)
+	return nil
+}
+
+// ApprovalReconciler adds the pending owner references of a ConfigMap or Secret once it is approved. Owners
+// deleted or recreated since they were proposed are dropped.
+type ApprovalReconciler struct {
+	// ReplicaSetReconciler provides the namespace filter, the holds and the writer
+	*ReplicaSetReconciler
+
+	// SecretReader reads the Secrets awaiting approval directly from the API server; nil approves ConfigMaps only
+	SecretReader client.Reader
+}
+
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=list;watch
+
+func (r *ApprovalReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithValues("configmap", req.NamespacedName)
+	return r.approve(ctx, req, r.Client, &corev1.ConfigMap{}, logger)
+}
+
+// secretApproval reconciles the approvals of Secrets, which are read with the SecretReader
+type secretApproval struct {
+	*ApprovalReconciler
+}
+
+func (r *secretApproval) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithValues("secret", req.NamespacedName)
+	return r.approve(ctx, req, r.SecretReader, &corev1.Secret{}, logger)
+}
+
+// approve reads obj with reader and adds its pending owner references once it is approved
+func (r *ApprovalReconciler) approve(
+	ctx context.Context,
+	req ctrl.Request,
+	reader client.Reader,
+	obj client.Object,
+	logger logr.Logger,
+) (ctrl.Result, error) {
+	if !r.shouldProcessNamespace(ctx, req.Namespace) {
+		return ctrl.Result{}, nil
+	}
+	if err := reader.Get(ctx, req.NamespacedName, obj); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	pending := pendingOwners(obj)
+	if obj.GetAnnotations()[ApprovedAnnotation] != "true" || len(pending) == 0 {
+		return ctrl.Result{}, nil
+	}
+	now := time.Now()
+	if hold := r.holdReason(ctx, req.Namespace, now, logger); hold != "" {
+		logger.Info(hold+": Would add approved OwnerReferences", "owners", len(pending))
+		return r.heldResult(hold, now), nil
+	}
+
+	original := obj.DeepCopyObject().(client.Object)
+	if cm, ok := obj.(*corev1.ConfigMap); ok {
+		upgradeSemantics(cm)
+	}
+	var added []string
+	for _, ref := range pending {
+		current, err := r.ownerExists(ctx, req.Namespace, ref)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if !current {
+			logger.Info("Dropping approved owner that no longer exists", "owner", ref.Kind+"/"+ref.Name)
+			continue
+		}
+		upsertOwnerReference(obj, ref)
+		addManagedOwner(obj, ref.UID)
+		added = append(added, ref.Kind+"/"+ref.Name)
+	}
+	setPendingOwners(obj, nil)
+	delete(obj.GetAnnotations(), ApprovedAnnotation)
+	if err := r.updateObject(ctx, r.writer(), obj, original); err != nil {
+		logger.Error(err, "Failed to add approved owner references")
+		return ctrl.Result{}, err
+	}
+
+	for _, owner := range added {
+		logger.Info("Added approved OwnerReference", "owner", owner)
+		r.recordEvent(obj, corev1.EventTypeNormal, "OwnerReferenceAdded", "Added approved owner reference to %s", owner)
+		r.observeChurn(req.Namespace, ownershipAdded, logger)
+	}
+	return ctrl.Result{}, nil
+}
+
+// ownerExists reports whether the object ref points to still exists in namespace with the same UID
+func (r *ReplicaSetReconciler) ownerExists(
+	ctx context.Context,
+	namespace string,
+	ref metav1.OwnerReference,
+) (bool, error) {
+	gv, err := schema.ParseGroupVersion(ref.APIVersion)
+	if err != nil {
+		return false, nil
+	}
+	obj, err := r.Scheme.New(gv.WithKind(ref.Kind))
+	if err != nil {
+		return false, nil
+	}
+	owner, ok := obj.(client.Object)
+	if !ok {
+		return false, nil
+	}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: ref.Name}, owner); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return owner.GetUID() == ref.UID, nil
+}
+
+// SetupWithManager watches ConfigMaps, and Secrets with a SecretReader, reconciling them once they carry both
+// pending owners and an approval. Only the metadata of Secrets is watched, so their data is never cached.
+// Approvals are rare, so the controllers aren't tracked for the liveness check.
+func (r *ApprovalReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	approved := func(obj client.Object) bool {
+		annotations := obj.GetAnnotations()
+		return annotations[ApprovedAnnotation] == "true" && annotations[PendingOwnersAnnotation] != ""
+	}
+	filter := predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return approved(e.Object) },
+		UpdateFunc:  func(e event.UpdateEvent) bool { return approved(e.ObjectNew) },
+		DeleteFunc:  func(event.DeleteEvent) bool { return false },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+	}
+	if err := ctrl.NewControllerManagedBy(mgr).
+		Named("approval").
+		For(&corev1.ConfigMap{}).
+		WithEventFilter(filter).
+		WithOptions(r.controllerOptions()).
+		Complete(r); err != nil {
+		return err
+	}
+	if r.SecretReader == nil {
+		return nil
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("secret-approval").
+		For(&corev1.Secret{}, builder.OnlyMetadata).
+		WithEventFilter(filter).
+		WithOptions(r.controllerOptions()).
+		Complete(&secretApproval{r})
+}
@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+	"github.com/matanbaruch/configmap-rs-operator/internal/schedule"
+)
+
+var _ = ginkgo.Describe("Approval", func() {
+	ginkgo.It("Should propose owner references and add them once approved", func() {
+		ctx := context.Background()
+		key := types.NamespacedName{Namespace: "default", Name: "app-config"}
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+			testConfigMap("app-config", "default"), testReplicaSet("web", "default", "app-config"),
+		).Build()
+		recorder := record.NewFakeRecorder(10)
+		r := &ReplicaSetReconciler{
+			Client: c, Scheme: scheme.Scheme, Recorder: recorder,
+			Config: &config.OperatorConfig{RequireApproval: true},
+		}
+
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "web"}})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		var cm corev1.ConfigMap
+		gomega.Expect(c.Get(ctx, key, &cm)).To(gomega.Succeed())
+		gomega.Expect(cm.OwnerReferences).To(gomega.BeEmpty())
+		gomega.Expect(pendingOwners(&cm)).To(gomega.ConsistOf(gomega.HaveField("UID", types.UID("web-uid"))))
+		gomega.Expect(<-recorder.Events).To(gomega.ContainSubstring("OwnerReferenceProposed"))
+
+		// An owner recreated since it was proposed is dropped on approval
+		stale := metav1.OwnerReference{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "web", UID: "old-web-uid"}
+		setPendingOwners(&cm, append(pendingOwners(&cm), stale))
+		cm.Annotations[ApprovedAnnotation] = "true"
+		gomega.Expect(c.Update(ctx, &cm)).To(gomega.Succeed())
+
+		approval := &ApprovalReconciler{ReplicaSetReconciler: r}
+		_, err = approval.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(c.Get(ctx, key, &cm)).To(gomega.Succeed())
+		gomega.Expect(cm.OwnerReferences).To(gomega.ConsistOf(gomega.HaveField("UID", types.UID("web-uid"))))
+		gomega.Expect(managedOwnerUIDs(&cm)).To(gomega.ConsistOf(types.UID("web-uid")))
+		gomega.Expect(cm.Annotations).NotTo(gomega.HaveKey(PendingOwnersAnnotation))
+		gomega.Expect(cm.Annotations).NotTo(gomega.HaveKey(ApprovedAnnotation))
+	})
+
+	ginkgo.It("Should wait for the next maintenance window to add approved owner references", func() {
+		ctx := context.Background()
+		cm := testConfigMap("app-config", "default")
+		setPendingOwners(cm, []metav1.OwnerReference{
+			{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "web", UID: "web-uid"},
+		})
+		cm.Annotations[ApprovedAnnotation] = "true"
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(cm, testReplicaSet("web", "default")).Build()
+		window, err := schedule.Parse([]string{"0 0 1 1 * 1m"}, time.UTC)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		approval := &ApprovalReconciler{ReplicaSetReconciler: &ReplicaSetReconciler{
+			Client: c, Scheme: scheme.Scheme, Config: &config.OperatorConfig{RequireApproval: true},
+			MaintenanceWindow: window,
+		}}
+
+		key := types.NamespacedName{Namespace: "default", Name: "app-config"}
+		result, err := approval.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(result.RequeueAfter).To(gomega.BeNumerically(">", pausedRequeueInterval))
+		gomega.Expect(c.Get(ctx, key, cm)).To(gomega.Succeed())
+		gomega.Expect(cm.OwnerReferences).To(gomega.BeEmpty())
+	})
+
+	ginkgo.It("Should not add pending owner references before approval", func() {
+		ctx := context.Background()
+		cm := testConfigMap("app-config", "default")
+		setPendingOwners(cm, []metav1.OwnerReference{
+			{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "web", UID: "web-uid"},
+		})
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(cm, testReplicaSet("web", "default")).Build()
+		approval := &ApprovalReconciler{ReplicaSetReconciler: &ReplicaSetReconciler{
+			Client: c, Scheme: scheme.Scheme, Config: &config.OperatorConfig{RequireApproval: true},
+		}}
+
+		key := types.NamespacedName{Namespace: "default", Name: "app-config"}
+		_, err := approval.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(c.Get(ctx, key, cm)).To(gomega.Succeed())
+		gomega.Expect(cm.OwnerReferences).To(gomega.BeEmpty())
+		gomega.Expect(pendingOwners(cm)).To(gomega.HaveLen(1))
+	})
+})
@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// reasonArgoCDManaged is the skip reason of the ConfigMaps Argo CD tracks as part of an application
+const reasonArgoCDManaged = "argocd_managed"
+
+// Argo CD resource tracking metadata
+const (
+	argoCDTrackingIDAnnotation = "argocd.argoproj.io/tracking-id"
+	argoCDInstanceLabel        = "argocd.argoproj.io/instance"
+)
+
+// ArgoCDCompareOptionsAnnotation holds the Argo CD compare options of a resource, which --argocd-compare-options
+// adds to on the ConfigMaps the operator adds owner references to
+const ArgoCDCompareOptionsAnnotation = "argocd.argoproj.io/compare-options"
+
+// argoCDManaged reports whether Argo CD tracks cm, by annotation or with the argocd.argoproj.io/instance label.
+// The default app.kubernetes.io/instance label is shared with Helm and others, so it doesn't tell Argo CD apart.
+func argoCDManaged(cm *corev1.ConfigMap) bool {
+	return cm.Annotations[argoCDTrackingIDAnnotation] != "" || cm.Labels[argoCDInstanceLabel] != ""
+}
+
+// argoCDProtected reports whether cm is skipped as tracked by Argo CD, which it is unless --own-argocd-managed
+// is set
+func (r *ReplicaSetReconciler) argoCDProtected(cm *corev1.ConfigMap) bool {
+	return !r.Config.OwnArgoCDManaged && argoCDManaged(cm)
+}
+
+// stampCompareOptions sets the ArgoCDCompareOptionsAnnotation of dst to the compare options of src with the
+// comma-separated options added, so Argo CD doesn't report the owner references as drift; empty options leave dst
+// alone
+func stampCompareOptions(dst, src *corev1.ConfigMap, options string) {
+	if options == "" {
+		return
+	}
+	var merged []string
+	for _, option := range strings.Split(src.Annotations[ArgoCDCompareOptionsAnnotation]+","+options, ",") {
+		if option = strings.TrimSpace(option); option != "" && !containsFold(merged, option) {
+			merged = append(merged, option)
+		}
+	}
+	if dst.Annotations == nil {
+		dst.Annotations = make(map[string]string)
+	}
+	dst.Annotations[ArgoCDCompareOptionsAnnotation] = strings.Join(merged, ",")
+}
+
+// containsFold reports whether values holds value, ignoring case
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
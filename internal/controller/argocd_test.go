@@ -0,0 +1,66 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+var _ = ginkgo.Describe("Argo CD", func() {
+	reconcile := func(cfg *config.OperatorConfig) map[string]*corev1.ConfigMap {
+		ctx := context.Background()
+		rs := testReplicaSet("web-abc", "default", "tracked-config", "labeled-config", "web-config")
+		rs.CreationTimestamp = metav1.Now()
+		tracked := testConfigMap("tracked-config", "default")
+		tracked.Annotations = map[string]string{
+			argoCDTrackingIDAnnotation:     "web:/ConfigMap:default/tracked-config",
+			ArgoCDCompareOptionsAnnotation: "ServerSideDiff=true",
+		}
+		labeled := testConfigMap("labeled-config", "default")
+		labeled.Labels = map[string]string{argoCDInstanceLabel: "web"}
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(rs, tracked, labeled,
+			testConfigMap("web-config", "default")).Build()
+		r := &ReplicaSetReconciler{Client: c, Scheme: scheme.Scheme, Config: cfg}
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: rs.Name}})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+		configMaps := map[string]*corev1.ConfigMap{}
+		for _, name := range []string{"tracked-config", "labeled-config", "web-config"} {
+			var cm corev1.ConfigMap
+			gomega.Expect(c.Get(ctx, types.NamespacedName{Namespace: "default", Name: name}, &cm)).To(gomega.Succeed())
+			configMaps[name] = &cm
+		}
+		return configMaps
+	}
+
+	ginkgo.It("Should skip the ConfigMaps Argo CD tracks by default", func() {
+		configMaps := reconcile(&config.OperatorConfig{ArgoCDCompareOptions: "IgnoreExtraneous"})
+		gomega.Expect(configMaps["tracked-config"].OwnerReferences).To(gomega.BeEmpty())
+		gomega.Expect(configMaps["labeled-config"].OwnerReferences).To(gomega.BeEmpty())
+		gomega.Expect(configMaps["web-config"].OwnerReferences).To(gomega.HaveLen(1))
+		gomega.Expect(configMaps["web-config"].Annotations).To(
+			gomega.HaveKeyWithValue(ArgoCDCompareOptionsAnnotation, "IgnoreExtraneous"))
+	})
+
+	ginkgo.It("Should own them and add the compare options to those already set", func() {
+		configMaps := reconcile(&config.OperatorConfig{OwnArgoCDManaged: true, ArgoCDCompareOptions: "IgnoreExtraneous"})
+		gomega.Expect(configMaps["tracked-config"].OwnerReferences).To(gomega.HaveLen(1))
+		gomega.Expect(configMaps["tracked-config"].Annotations).To(
+			gomega.HaveKeyWithValue(ArgoCDCompareOptionsAnnotation, "ServerSideDiff=true,IgnoreExtraneous"))
+		gomega.Expect(configMaps["labeled-config"].OwnerReferences).To(gomega.HaveLen(1))
+	})
+
+	ginkgo.It("Should leave the compare options alone without --argocd-compare-options", func() {
+		configMaps := reconcile(&config.OperatorConfig{})
+		gomega.Expect(configMaps["web-config"].Annotations).NotTo(gomega.HaveKey(ArgoCDCompareOptionsAnnotation))
+	})
+})
@@ -0,0 +1,142 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/state"
+)
+
+// BackfillProgressKey is the state store key of the backfill progress, the data key of the checkpoint ConfigMap
+const BackfillProgressKey = "progress.json"
+
+// BackfillProgress is the progress of a single pass over the existing ReplicaSets. It is recorded in the
+// checkpoint ConfigMap or the state store after every namespace, so an interrupted pass resumes with the next one.
+type BackfillProgress struct {
+	StartedAt metav1.Time `json:"startedAt"`
+	UpdatedAt metav1.Time `json:"updatedAt"`
+
+	// Completed lists the namespaces whose ReplicaSets were all reconciled without error
+	Completed []string `json:"completed"`
+
+	// NamespacesRemaining is the number of namespaces still to be reconciled
+	NamespacesRemaining int `json:"namespacesRemaining"`
+
+	// Processed is the number of ReplicaSets reconciled since StartedAt, across restarts
+	Processed int `json:"processed"`
+
+	// Remaining is the number of ReplicaSets still to be reconciled
+	Remaining int `json:"remaining"`
+
+	// ETA estimates the time left from the rate of the current run
+	ETA string `json:"eta,omitempty"`
+
+	// Done reports whether the pass finished; the next pass starts over
+	Done bool `json:"done"`
+}
+
+// backfill tracks the progress of a pass and records it in the state store, if any
+type backfill struct {
+	r        *ReplicaSetReconciler
+	store    state.Store
+	progress BackfillProgress
+
+	// runStart and runProcessed measure the rate of the current run, for the ETA
+	runStart     time.Time
+	runProcessed int
+}
+
+// newBackfill returns the progress of a pass, resuming the pass recorded in the checkpoint ConfigMap, or else
+// the state store, unless it finished. Checkpoints are disabled in dry-run, where nothing was written to resume from.
+func (r *ReplicaSetReconciler) newBackfill(ctx context.Context) (*backfill, error) {
+	now := time.Now()
+	b := &backfill{r: r, runStart: now, progress: BackfillProgress{StartedAt: metav1.NewTime(now)}}
+	b.store = r.State
+	if ref := r.Config.BackfillCheckpoint; ref != "" {
+		namespace, name, ok := strings.Cut(ref, "/")
+		if !ok || namespace == "" || name == "" {
+			return nil, fmt.Errorf("invalid backfill checkpoint %q: expected namespace/name", ref)
+		}
+		key := types.NamespacedName{Namespace: namespace, Name: name}
+		b.store = &state.ConfigMapStore{Client: r.Client, Reader: r.Client, Key: key}
+	}
+	if b.store == nil || r.Config.DryRun {
+		b.store = nil
+		return b, nil
+	}
+
+	var previous BackfillProgress
+	found, err := b.store.Load(ctx, BackfillProgressKey, &previous)
+	if err != nil {
+		return nil, err
+	}
+	if found && !previous.Done {
+		b.progress = previous
+	}
+	return b, nil
+}
+
+// completed reports whether namespace was completed by an earlier run of the pass
+func (b *backfill) completed(namespace string) bool {
+	return slices.Contains(b.progress.Completed, namespace)
+}
+
+// start sets the amount of work left in the pass
+func (b *backfill) start(namespaces, replicaSets int) {
+	b.progress.NamespacesRemaining = namespaces
+	b.progress.Remaining = replicaSets
+	b.observe()
+}
+
+// processed records a reconciled ReplicaSet
+func (b *backfill) processed() {
+	b.progress.Processed++
+	b.progress.Remaining--
+	b.runProcessed++
+	b.observe()
+}
+
+// finishNamespace records that every ReplicaSet of namespace was reconciled, and whether all succeeded
+func (b *backfill) finishNamespace(ctx context.Context, namespace string, succeeded bool) error {
+	b.progress.NamespacesRemaining--
+	if succeeded {
+		b.progress.Completed = append(b.progress.Completed, namespace)
+	}
+	b.observe()
+	return b.save(ctx)
+}
+
+// finish records the end of the pass; a pass with errors stays resumable so failed namespaces are retried
+func (b *backfill) finish(ctx context.Context, succeeded bool) error {
+	b.progress.Done = succeeded
+	b.progress.ETA = ""
+	return b.save(ctx)
+}
+
+// observe updates the ETA and the progress metrics
+func (b *backfill) observe() {
+	var eta time.Duration
+	if b.runProcessed > 0 {
+		eta = time.Since(b.runStart) / time.Duration(b.runProcessed) * time.Duration(b.progress.Remaining)
+		b.progress.ETA = eta.Round(time.Second).String()
+	}
+	backfillNamespaces.WithLabelValues("completed").Set(float64(len(b.progress.Completed)))
+	backfillNamespaces.WithLabelValues("remaining").Set(float64(b.progress.NamespacesRemaining))
+	backfillProcessed.Set(float64(b.progress.Processed))
+	backfillETASeconds.Set(eta.Seconds())
+}
+
+// save records the progress in the state store
+func (b *backfill) save(ctx context.Context) error {
+	if b.store == nil {
+		return nil
+	}
+	b.progress.UpdatedAt = metav1.Now()
+	return b.store.Save(ctx, BackfillProgressKey, b.progress)
+}
@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+var _ = ginkgo.Describe("Backfill checkpoint", func() {
+	checkpointKey := types.NamespacedName{Namespace: "operator", Name: "backfill"}
+
+	readProgress := func(ctx context.Context, c client.Client) BackfillProgress {
+		var cm corev1.ConfigMap
+		gomega.Expect(c.Get(ctx, checkpointKey, &cm)).To(gomega.Succeed())
+		var progress BackfillProgress
+		gomega.Expect(json.Unmarshal([]byte(cm.Data[BackfillProgressKey]), &progress)).To(gomega.Succeed())
+		return progress
+	}
+
+	ginkgo.It("Should record the progress of a pass", func() {
+		ctx := context.Background()
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+			testReplicaSet("rs-a", "default", "cm-a"), testConfigMap("cm-a", "default"),
+			testReplicaSet("rs-b", "other", "cm-b"), testConfigMap("cm-b", "other"),
+		).Build()
+		reconciler := &ReplicaSetReconciler{Client: c, Scheme: scheme.Scheme,
+			Config: &config.OperatorConfig{BackfillCheckpoint: "operator/backfill"}}
+
+		processed, err := reconciler.RunOnce(ctx)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(processed).To(gomega.Equal(2))
+
+		progress := readProgress(ctx, c)
+		gomega.Expect(progress.Done).To(gomega.BeTrue())
+		gomega.Expect(progress.Completed).To(gomega.Equal([]string{"default", "other"}))
+		gomega.Expect(progress.Processed).To(gomega.Equal(2))
+		gomega.Expect(progress.Remaining).To(gomega.BeZero())
+		gomega.Expect(progress.NamespacesRemaining).To(gomega.BeZero())
+	})
+
+	ginkgo.It("Should resume an interrupted pass with the namespaces left", func() {
+		ctx := context.Background()
+		data, err := json.Marshal(BackfillProgress{StartedAt: metav1.Now(), Completed: []string{"default"}, Processed: 1})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		checkpoint := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: checkpointKey.Namespace, Name: checkpointKey.Name},
+			Data:       map[string]string{BackfillProgressKey: string(data)},
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+			testReplicaSet("rs-a", "default", "cm-a"), testConfigMap("cm-a", "default"),
+			testReplicaSet("rs-b", "other", "cm-b"), testConfigMap("cm-b", "other"),
+			checkpoint,
+		).Build()
+		reconciler := &ReplicaSetReconciler{Client: c, Scheme: scheme.Scheme,
+			Config: &config.OperatorConfig{BackfillCheckpoint: "operator/backfill"}}
+
+		processed, err := reconciler.RunOnce(ctx)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(processed).To(gomega.Equal(1))
+
+		var cm corev1.ConfigMap
+		gomega.Expect(c.Get(ctx, types.NamespacedName{Name: "cm-a", Namespace: "default"}, &cm)).To(gomega.Succeed())
+		gomega.Expect(cm.OwnerReferences).To(gomega.BeEmpty())
+		gomega.Expect(c.Get(ctx, types.NamespacedName{Name: "cm-b", Namespace: "other"}, &cm)).To(gomega.Succeed())
+		gomega.Expect(cm.OwnerReferences).To(gomega.HaveLen(1))
+
+		progress := readProgress(ctx, c)
+		gomega.Expect(progress.Done).To(gomega.BeTrue())
+		gomega.Expect(progress.Completed).To(gomega.Equal([]string{"default", "other"}))
+		gomega.Expect(progress.Processed).To(gomega.Equal(2))
+	})
+
+	ginkgo.It("Should reject an invalid checkpoint reference", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+		reconciler := &ReplicaSetReconciler{Client: c, Scheme: scheme.Scheme,
+			Config: &config.OperatorConfig{BackfillCheckpoint: "backfill"}}
+
+		_, err := reconciler.RunOnce(context.Background())
+		gomega.Expect(err).To(gomega.HaveOccurred())
+	})
+})
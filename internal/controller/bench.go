@@ -0,0 +1,229 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/flowcontrol"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// BenchLabel marks the objects a benchmark generates
+const BenchLabel = "configmap-rs-operator/bench"
+
+// BenchOptions describes the synthetic workload of a benchmark and how it is reconciled
+type BenchOptions struct {
+	// Namespace is the sandbox namespace the fixtures are created in
+	Namespace string
+
+	ReplicaSets int
+	ConfigMaps  int
+
+	// Mounts is the number of ConfigMaps each ReplicaSet mounts, spread round robin over the ConfigMaps
+	Mounts int
+
+	// Concurrency is the number of reconciles run at once, like the MaxConcurrentReconciles of a controller
+	Concurrency int
+
+	// QPS and Burst rate limit the API calls of the reconciles like a client-side rate limiter; 0 disables it.
+	// Against an API server, set them on the rest config instead.
+	QPS   float32
+	Burst int
+}
+
+// BenchResult is the throughput and API usage measured by a benchmark
+type BenchResult struct {
+	ReplicaSets         int     `json:"replicaSets"`
+	ConfigMaps          int     `json:"configMaps"`
+	Reconciles          int     `json:"reconciles"`
+	Errors              int     `json:"errors"`
+	Duration            string  `json:"duration"`
+	ReconcilesPerSecond float64 `json:"reconcilesPerSecond"`
+
+	// APICalls counts the API calls of the reconciles by verb; creating the fixtures isn't counted
+	APICalls             map[string]int `json:"apiCalls"`
+	APICallsPerReconcile float64        `json:"apiCallsPerReconcile"`
+}
+
+// countingClient counts the API calls made through it by verb, waiting for limiter first if set
+type countingClient struct {
+	client.Client
+	limiter flowcontrol.RateLimiter
+
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func (c *countingClient) call(verb string) {
+	if c.limiter != nil {
+		c.limiter.Accept()
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls[verb]++
+}
+
+func (c *countingClient) Get(
+	ctx context.Context,
+	key client.ObjectKey,
+	obj client.Object,
+	opts ...client.GetOption,
+) error {
+	c.call("get")
+	return c.Client.Get(ctx, key, obj, opts...)
+}
+
+func (c *countingClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	c.call("list")
+	return c.Client.List(ctx, list, opts...)
+}
+
+func (c *countingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	c.call("create")
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func (c *countingClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	c.call("update")
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func (c *countingClient) Patch(
+	ctx context.Context,
+	obj client.Object,
+	patch client.Patch,
+	opts ...client.PatchOption,
+) error {
+	c.call("patch")
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func (c *countingClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	c.call("delete")
+	return c.Client.Delete(ctx, obj, opts...)
+}
+
+// Bench creates the synthetic ConfigMaps and ReplicaSets of opts in the sandbox namespace, reconciles every
+// ReplicaSet once with the configuration of r and measures the throughput and API calls. The ReplicaSets have
+// no replicas, so no pod is scheduled. The caller removes the fixtures, e.g. by deleting the namespace.
+func (r *ReplicaSetReconciler) Bench(ctx context.Context, opts BenchOptions) (*BenchResult, error) {
+	if opts.ReplicaSets <= 0 || opts.ConfigMaps <= 0 || opts.Mounts <= 0 || opts.Concurrency <= 0 {
+		return nil, fmt.Errorf("the number of ReplicaSets, ConfigMaps, mounts and the concurrency must be positive")
+	}
+	names, err := r.createBenchFixtures(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	counting := &countingClient{Client: r.Client, calls: map[string]int{}}
+	if opts.QPS > 0 {
+		counting.limiter = flowcontrol.NewTokenBucketRateLimiter(opts.QPS, max(opts.Burst, 1))
+	}
+	bench := *r
+	bench.Client = counting
+	ctx = log.IntoContext(ctx, logr.Discard())
+
+	requests := make(chan string)
+	var mu sync.Mutex
+	var errs []error
+	var wg sync.WaitGroup
+	start := time.Now()
+	for range opts.Concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for name := range requests {
+				req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: opts.Namespace, Name: name}}
+				if _, err := bench.Reconcile(ctx, req); err != nil {
+					mu.Lock()
+					errs = append(errs, err)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for _, name := range names {
+		requests <- name
+	}
+	close(requests)
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	result := &BenchResult{
+		ReplicaSets: opts.ReplicaSets, ConfigMaps: opts.ConfigMaps, Reconciles: len(names), Errors: len(errs),
+		Duration: elapsed.String(), ReconcilesPerSecond: float64(len(names)) / elapsed.Seconds(),
+		APICalls: counting.calls,
+	}
+	for _, n := range counting.calls {
+		result.APICallsPerReconcile += float64(n)
+	}
+	result.APICallsPerReconcile /= float64(len(names))
+	return result, errors.Join(errs...)
+}
+
+// createBenchFixtures creates the ConfigMaps and ReplicaSets of a benchmark and returns the ReplicaSet names
+func (r *ReplicaSetReconciler) createBenchFixtures(ctx context.Context, opts BenchOptions) ([]string, error) {
+	labels := map[string]string{BenchLabel: "true"}
+	for i := range opts.ConfigMaps {
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("bench-config-%d", i), Namespace: opts.Namespace, Labels: labels},
+			Data:       map[string]string{"bench": "true"},
+		}
+		if err := r.Create(ctx, cm); err != nil {
+			return nil, fmt.Errorf("failed to create benchmark ConfigMap: %w", err)
+		}
+	}
+	names := make([]string, 0, opts.ReplicaSets)
+	for i := range opts.ReplicaSets {
+		var configMaps []string
+		for j := range opts.Mounts {
+			configMaps = append(configMaps, fmt.Sprintf("bench-config-%d", (i*opts.Mounts+j)%opts.ConfigMaps))
+		}
+		rs := benchReplicaSet(fmt.Sprintf("bench-%d", i), opts.Namespace, configMaps)
+		if err := r.Create(ctx, rs); err != nil {
+			return nil, fmt.Errorf("failed to create benchmark ReplicaSet: %w", err)
+		}
+		names = append(names, rs.Name)
+	}
+	return names, nil
+}
+
+// benchReplicaSet returns a benchmark ReplicaSet without replicas mounting configMaps
+func benchReplicaSet(name, namespace string, configMaps []string) *appsv1.ReplicaSet {
+	replicas := int32(0)
+	labels := map[string]string{BenchLabel: "true", "app.kubernetes.io/instance": name}
+	container := corev1.Container{Name: "bench", Image: "registry.k8s.io/pause:3.10"}
+	var volumes []corev1.Volume
+	for i, cm := range configMaps {
+		volume := fmt.Sprintf("config-%d", i)
+		volumes = append(volumes, corev1.Volume{
+			Name: volume,
+			VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: cm},
+			}},
+		})
+		container.VolumeMounts = append(container.VolumeMounts,
+			corev1.VolumeMount{Name: volume, MountPath: "/etc/" + volume})
+	}
+	return &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels, UID: types.UID(name + "-uid")},
+		Spec: appsv1.ReplicaSetSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{container}, Volumes: volumes},
+			},
+		},
+	}
+}
@@ -0,0 +1,41 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+var _ = ginkgo.Describe("Bench", func() {
+	ginkgo.It("Should reconcile the synthetic workload and count its API calls", func() {
+		ctx := context.Background()
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+		r := &ReplicaSetReconciler{Client: c, Scheme: scheme.Scheme, Config: &config.OperatorConfig{}}
+
+		result, err := r.Bench(ctx, BenchOptions{
+			Namespace: "bench", ReplicaSets: 10, ConfigMaps: 5, Mounts: 2, Concurrency: 3,
+		})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(result.Reconciles).To(gomega.Equal(10))
+		gomega.Expect(result.Errors).To(gomega.BeZero())
+		gomega.Expect(result.APICalls).To(gomega.HaveKeyWithValue("update", 20))
+		gomega.Expect(result.APICalls).NotTo(gomega.HaveKey("create"))
+
+		var cm corev1.ConfigMap
+		gomega.Expect(c.Get(ctx, types.NamespacedName{Namespace: "bench", Name: "bench-config-0"}, &cm)).To(gomega.Succeed())
+		gomega.Expect(cm.OwnerReferences).To(gomega.HaveLen(4))
+	})
+
+	ginkgo.It("Should reject an empty workload", func() {
+		r := &ReplicaSetReconciler{Config: &config.OperatorConfig{}}
+		_, err := r.Bench(context.Background(), BenchOptions{Namespace: "bench", Concurrency: 1})
+		gomega.Expect(err).To(gomega.HaveOccurred())
+	})
+})
@@ -0,0 +1,126 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// BoundConfigMapsAnnotation lists, comma-separated, the ConfigMaps the operator bound to the workload carrying it
+// with --annotate-workloads, so application teams see the lifecycle coupling on their own objects
+const BoundConfigMapsAnnotation = "configmap-rs-operator/configmaps"
+
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups=apps,resources=statefulsets;daemonsets,verbs=patch
+// +kubebuilder:rbac:groups=batch,resources=jobs;cronjobs,verbs=patch
+// +kubebuilder:rbac:groups="",resources=pods;podtemplates,verbs=patch
+
+// workloadBindings collects the ConfigMaps a reconcile bound to each owner, in the order the owners were seen
+type workloadBindings struct {
+	owners     []metav1.OwnerReference
+	configMaps map[types.UID][]string
+}
+
+// add records that the ConfigMap name is bound to owner; a nil owner binds nothing
+func (b *workloadBindings) add(owner *metav1.OwnerReference, name string) {
+	if owner == nil {
+		return
+	}
+	if b.configMaps == nil {
+		b.configMaps = map[types.UID][]string{}
+	}
+	if _, ok := b.configMaps[owner.UID]; !ok {
+		b.owners = append(b.owners, *owner)
+	}
+	b.configMaps[owner.UID] = append(b.configMaps[owner.UID], name)
+}
+
+// boundConfigMaps returns the ConfigMaps listed in the BoundConfigMapsAnnotation of obj
+func boundConfigMaps(obj client.Object) []string {
+	var names []string
+	for _, name := range strings.Split(obj.GetAnnotations()[BoundConfigMapsAnnotation], ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// annotateWorkloads adds the ConfigMaps of bindings to the BoundConfigMapsAnnotation of their owners. Nothing is
+// written while writes are held back.
+func (r *ReplicaSetReconciler) annotateWorkloads(
+	ctx context.Context,
+	namespace string,
+	bindings *workloadBindings,
+	holdReason string,
+	logger logr.Logger,
+) error {
+	if !r.Config.AnnotateWorkloads || holdReason != "" {
+		return nil
+	}
+	for _, owner := range bindings.owners {
+		if err := r.annotateWorkload(ctx, namespace, owner, bindings.configMaps[owner.UID]); err != nil {
+			logger.Error(err, "Failed to annotate workload with its ConfigMaps", "kind", owner.Kind, "name", owner.Name)
+			return err
+		}
+	}
+	return nil
+}
+
+// annotateWorkload adds configMaps to the BoundConfigMapsAnnotation of owner. The ConfigMaps it already lists are
+// kept, since a Deployment keeps owning its stable-named ConfigMaps across rollouts. An owner that no longer
+// exists is left alone. Owners of custom kinds, which the scheme doesn't know, are read as unstructured objects.
+func (r *ReplicaSetReconciler) annotateWorkload(
+	ctx context.Context,
+	namespace string,
+	owner metav1.OwnerReference,
+	configMaps []string,
+) error {
+	gv, err := schema.ParseGroupVersion(owner.APIVersion)
+	if err != nil {
+		return err
+	}
+	var workload client.Object = &unstructured.Unstructured{}
+	workload.GetObjectKind().SetGroupVersionKind(gv.WithKind(owner.Kind))
+	if obj, err := r.Scheme.New(gv.WithKind(owner.Kind)); err == nil {
+		typed, ok := obj.(client.Object)
+		if !ok {
+			return fmt.Errorf("%s is not an object", owner.Kind)
+		}
+		workload = typed
+	}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: owner.Name}, workload); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if workload.GetUID() != owner.UID {
+		return nil
+	}
+
+	names := boundConfigMaps(workload)
+	listed := len(names)
+	for _, name := range configMaps {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == listed {
+		return nil
+	}
+	slices.Sort(names)
+	original := workload.DeepCopyObject().(client.Object)
+	annotations := workload.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[BoundConfigMapsAnnotation] = strings.Join(names, ",")
+	workload.SetAnnotations(annotations)
+	return r.writer().Patch(ctx, workload, client.MergeFrom(original))
+}
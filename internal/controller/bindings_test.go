@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+var _ = ginkgo.Describe("Workload annotations", func() {
+	ginkgo.It("Should annotate each owner with the ConfigMaps bound to it", func() {
+		ctx := context.Background()
+		isController := true
+		deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+			Name: "web", Namespace: "default", UID: "web-uid",
+			Annotations: map[string]string{BoundConfigMapsAnnotation: "legacy"},
+		}}
+		rs := testReplicaSet("web-abc", "default", "app-config", "settings")
+		rs.OwnerReferences = []metav1.OwnerReference{{
+			APIVersion: "apps/v1", Kind: "Deployment", Name: "web", UID: "web-uid", Controller: &isController,
+		}}
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(deployment, rs,
+			testConfigMap("app-config", "default"), testConfigMap("settings", "default")).Build()
+		rules, err := ParseOwnerRules([]string{"^settings$=deployment"})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		r := &ReplicaSetReconciler{Client: c, Scheme: scheme.Scheme, OwnerRules: rules,
+			Config: &config.OperatorConfig{AnnotateWorkloads: true}}
+
+		_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: rs.Name}})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+		annotation := func(obj client.Object) string {
+			gomega.Expect(c.Get(ctx, client.ObjectKeyFromObject(obj), obj)).To(gomega.Succeed())
+			return obj.GetAnnotations()[BoundConfigMapsAnnotation]
+		}
+		gomega.Expect(annotation(&appsv1.ReplicaSet{ObjectMeta: rs.ObjectMeta})).To(gomega.Equal("app-config"))
+		gomega.Expect(annotation(&appsv1.Deployment{ObjectMeta: deployment.ObjectMeta})).To(gomega.Equal("legacy,settings"))
+	})
+
+	ginkgo.It("Should not annotate workloads in dry-run mode", func() {
+		ctx := context.Background()
+		rs := testReplicaSet("web-abc", "default", "app-config")
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).
+			WithObjects(rs, testConfigMap("app-config", "default")).Build()
+		r := &ReplicaSetReconciler{Client: c, Scheme: scheme.Scheme,
+			Config: &config.OperatorConfig{AnnotateWorkloads: true, DryRun: true}}
+
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: rs.Name}})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+		var got appsv1.ReplicaSet
+		gomega.Expect(c.Get(ctx, client.ObjectKeyFromObject(rs), &got)).To(gomega.Succeed())
+		gomega.Expect(got.Annotations).NotTo(gomega.HaveKey(BoundConfigMapsAnnotation))
+	})
+})
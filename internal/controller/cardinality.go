@@ -0,0 +1,83 @@
+package controller
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Modes of the namespace label of per-namespace metrics
+const (
+	// NamespaceLabelsAll labels every series with its namespace
+	NamespaceLabelsAll = "all"
+
+	// NamespaceLabelsTop keeps the label of the first namespaces to report a value and buckets the rest into
+	// otherNamespace. A counter can't move to another series, so namespaces aren't re-ranked later.
+	NamespaceLabelsTop = "top"
+
+	// NamespaceLabelsOff leaves the namespace label empty, which aggregates all namespaces into one series
+	NamespaceLabelsOff = "off"
+)
+
+// otherNamespace is the namespace label of namespaces beyond the top namespaces
+const otherNamespace = "other"
+
+// namespaceLabels bounds the namespace label values of the per-namespace metrics
+var namespaceLabels = &namespaceLabeler{mode: NamespaceLabelsAll}
+
+// namespaceLabeler maps namespaces to metric label values according to its mode
+type namespaceLabeler struct {
+	mode string
+	max  int
+
+	mu       sync.Mutex
+	admitted map[string]bool
+}
+
+// ValidateNamespaceLabels returns an error unless mode is a namespace label mode and top is positive
+// for NamespaceLabelsTop
+func ValidateNamespaceLabels(mode string, top int) error {
+	switch mode {
+	case NamespaceLabelsAll, NamespaceLabelsOff:
+		return nil
+	case NamespaceLabelsTop:
+		if top <= 0 {
+			return fmt.Errorf("invalid number of top namespaces %d: must be positive", top)
+		}
+		return nil
+	}
+	return fmt.Errorf("invalid namespace labels %q: expected %s, %s or %s",
+		mode, NamespaceLabelsAll, NamespaceLabelsTop, NamespaceLabelsOff)
+}
+
+// SetNamespaceLabels configures the namespace label of the per-namespace metrics; top is the number of
+// namespaces that keep their label with NamespaceLabelsTop. It must be called before the metrics are updated.
+func SetNamespaceLabels(mode string, top int) {
+	namespaceLabels = &namespaceLabeler{mode: mode, max: top}
+}
+
+// namespaceLabel returns the label value of namespace for the per-namespace metrics
+func namespaceLabel(namespace string) string {
+	return namespaceLabels.value(namespace)
+}
+
+func (l *namespaceLabeler) value(namespace string) string {
+	switch l.mode {
+	case NamespaceLabelsOff:
+		return ""
+	case NamespaceLabelsTop:
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if l.admitted[namespace] {
+			return namespace
+		}
+		if len(l.admitted) >= l.max {
+			return otherNamespace
+		}
+		if l.admitted == nil {
+			l.admitted = map[string]bool{}
+		}
+		l.admitted[namespace] = true
+		return namespace
+	}
+	return namespace
+}
@@ -0,0 +1,27 @@
+package controller
+
+import (
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+)
+
+var _ = ginkgo.Describe("Namespace labels", func() {
+	ginkgo.It("Should keep the label of the first namespaces and bucket the rest", func() {
+		l := &namespaceLabeler{mode: NamespaceLabelsTop, max: 2}
+		gomega.Expect(l.value("a")).To(gomega.Equal("a"))
+		gomega.Expect(l.value("b")).To(gomega.Equal("b"))
+		gomega.Expect(l.value("c")).To(gomega.Equal(otherNamespace))
+		gomega.Expect(l.value("a")).To(gomega.Equal("a"))
+	})
+
+	ginkgo.It("Should drop the label when off and keep it by default", func() {
+		gomega.Expect((&namespaceLabeler{mode: NamespaceLabelsOff}).value("a")).To(gomega.BeEmpty())
+		gomega.Expect((&namespaceLabeler{mode: NamespaceLabelsAll}).value("a")).To(gomega.Equal("a"))
+	})
+
+	ginkgo.It("Should validate the mode", func() {
+		gomega.Expect(ValidateNamespaceLabels(NamespaceLabelsTop, 10)).To(gomega.Succeed())
+		gomega.Expect(ValidateNamespaceLabels(NamespaceLabelsTop, 0)).NotTo(gomega.Succeed())
+		gomega.Expect(ValidateNamespaceLabels("some", 10)).NotTo(gomega.Succeed())
+	})
+})
@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/ext"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// ReferenceCEL is the reference type of the ConfigMaps the --cel-references expressions name
+const ReferenceCEL = "cel"
+
+// celCostLimit bounds the cost of evaluating an expression, so a runaway one can't stall the reconcilers
+const celCostLimit = 1000000
+
+// CompileCELReferences compiles the --cel-references expressions into an extractor of the ConfigMaps they name.
+// Each expression sees the pod template as template, with metadata.annotations, metadata.labels and spec, and
+// evaluates to the name of a ConfigMap or a list of names; empty names are ignored.
+func CompileCELReferences(expressions []string) (ReferenceExtractor, error) {
+	env, err := cel.NewEnv(cel.Variable("template", cel.MapType(cel.StringType, cel.DynType)), ext.Strings())
+	if err != nil {
+		return nil, err
+	}
+	programs := make([]cel.Program, 0, len(expressions))
+	for _, expression := range expressions {
+		ast, issues := env.Compile(expression)
+		if issues.Err() != nil {
+			return nil, fmt.Errorf("invalid CEL reference %q: %w", expression, issues.Err())
+		}
+		switch out := ast.OutputType(); {
+		case out.IsExactType(cel.StringType), out.IsExactType(cel.ListType(cel.StringType)), out.IsExactType(cel.DynType):
+		default:
+			return nil, fmt.Errorf("invalid CEL reference %q: evaluates to %s, expected string or list(string)",
+				expression, out)
+		}
+		program, err := env.Program(ast, cel.CostLimit(celCostLimit))
+		if err != nil {
+			return nil, fmt.Errorf("invalid CEL reference %q: %w", expression, err)
+		}
+		programs = append(programs, program)
+	}
+	return ReferenceExtractorFunc(func(src *ReferenceSource) []string {
+		return evalCELReferences(programs, src)
+	}), nil
+}
+
+// evalCELReferences returns the ConfigMaps programs name for src. An expression failing on a pod template, e.g.
+// reading an annotation it doesn't have, names no ConfigMap for it.
+func evalCELReferences(programs []cel.Program, src *ReferenceSource) []string {
+	spec, err := runtime.DefaultUnstructuredConverter.ToUnstructured(src.Spec)
+	if err != nil {
+		return nil
+	}
+	template := map[string]any{
+		"metadata": map[string]any{"annotations": stringMap(src.Annotations), "labels": stringMap(src.Labels)},
+		"spec":     spec,
+	}
+	var names []string
+	for _, program := range programs {
+		out, _, err := program.Eval(map[string]any{"template": template})
+		if err != nil {
+			continue
+		}
+		if name, ok := out.Value().(string); ok {
+			names = append(names, name)
+			continue
+		}
+		if list, err := out.ConvertToNative(reflect.TypeOf([]string{})); err == nil {
+			names = append(names, list.([]string)...)
+		}
+	}
+	return slices.DeleteFunc(names, func(name string) bool { return name == "" })
+}
+
+// stringMap returns m as a map CEL can index, empty rather than nil
+func stringMap(m map[string]string) map[string]any {
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,75 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+var _ = ginkgo.Describe("CEL references", func() {
+	ginkgo.It("Should reject invalid expressions", func() {
+		_, err := CompileCELReferences([]string{`template.metadata.annotations[`})
+		gomega.Expect(err).To(gomega.MatchError(gomega.ContainSubstring("invalid CEL reference")))
+		_, err = CompileCELReferences([]string{`size(template.metadata.labels)`})
+		gomega.Expect(err).To(gomega.MatchError(gomega.ContainSubstring("expected string or list(string)")))
+	})
+
+	ginkgo.It("Should name the ConfigMaps the expressions evaluate to", func() {
+		extractor, err := CompileCELReferences([]string{
+			`template.metadata.annotations["example.com/config"]`,
+			`template.metadata.labels["app"] + "-settings"`,
+			`template.metadata.annotations["example.com/extra-configs"].split(",")`,
+			`template.spec.containers.map(c, c.name + "-defaults")`,
+		})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+		src := &ReferenceSource{
+			Spec: &testReplicaSet("web-abc", "default", "app-config").Spec.Template.Spec,
+			Annotations: map[string]string{
+				"example.com/config": "web-config", "example.com/extra-configs": "flags,,limits",
+			},
+			Labels: map[string]string{"app": "web"},
+		}
+		gomega.Expect(extractor.ConfigMaps(src)).To(gomega.Equal(
+			[]string{"web-config", "web-settings", "flags", "limits", "app-defaults"}))
+
+		// Expressions reading what a workload doesn't have name no ConfigMap for it
+		src.Annotations, src.Labels = nil, nil
+		gomega.Expect(extractor.ConfigMaps(src)).To(gomega.Equal([]string{"app-defaults"}))
+	})
+
+	ginkgo.It("Should own the ConfigMaps the registered expressions name", func() {
+		registered := referenceExtractors
+		ginkgo.DeferCleanup(func() { referenceExtractors = registered })
+		extractor, err := CompileCELReferences([]string{`template.metadata.annotations["example.com/config"]`})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		RegisterReferenceExtractor(ReferenceCEL, extractor)
+		gomega.Expect(ValidateReferenceTypes([]string{ReferenceCEL})).To(gomega.Succeed())
+
+		ctx := context.Background()
+		rs := testReplicaSet("web-abc", "default", "app-config")
+		rs.CreationTimestamp = metav1.Now()
+		rs.Spec.Template.Annotations = map[string]string{"example.com/config": "web-config"}
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(rs, testConfigMap("app-config", "default"),
+			testConfigMap("web-config", "default")).Build()
+		r := &ReplicaSetReconciler{Client: c, Scheme: scheme.Scheme, Config: &config.OperatorConfig{}}
+
+		_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: rs.Name}})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		var cm corev1.ConfigMap
+		gomega.Expect(c.Get(ctx, types.NamespacedName{Namespace: "default", Name: "web-config"}, &cm)).To(gomega.Succeed())
+		gomega.Expect(cm.OwnerReferences).To(gomega.ConsistOf(gomega.HaveField("Name", rs.Name)))
+
+		r.Config.ReferenceTypes = []string{ReferenceVolume}
+		gomega.Expect(r.extractConfigMapVolumes(rs)).To(gomega.Equal([]string{"app-config"}))
+	})
+})
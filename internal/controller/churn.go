@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"sync"
+	"time"
+)
+
+// Kinds of ownership changes tracked by the churn detector
+const (
+	ownershipAdded   = "added"
+	ownershipRemoved = "removed"
+)
+
+// ChurnDetector tracks the rate of ownership changes per namespace and flags namespaces where it
+// spikes, e.g. a misconfigured filter suddenly making the operator own thousands of ConfigMaps
+type ChurnDetector struct {
+	// Window is the period over which changes are counted
+	Window time.Duration
+
+	// Threshold is the number of changes within a window that counts as a spike
+	Threshold int
+
+	mu      sync.Mutex
+	windows map[string]*churnWindow
+	now     func() time.Time
+}
+
+// churnWindow counts the changes in one namespace since start
+type churnWindow struct {
+	start   time.Time
+	changes int
+	alerted bool
+}
+
+// NewChurnDetector returns a detector flagging more than threshold changes per window,
+// or nil when threshold is not positive
+func NewChurnDetector(threshold int, window time.Duration) *ChurnDetector {
+	if threshold <= 0 || window <= 0 {
+		return nil
+	}
+	return &ChurnDetector{Window: window, Threshold: threshold}
+}
+
+// Observe records an ownership change in namespace and reports whether it makes the namespace
+// cross the threshold. It reports a spike only once per window so alerts don't flood.
+func (d *ChurnDetector) Observe(namespace, change string) (int, bool) {
+	ownershipChangesTotal.WithLabelValues(change).Inc()
+	if d == nil {
+		return 0, false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	if d.now != nil {
+		now = d.now()
+	}
+	if d.windows == nil {
+		d.windows = make(map[string]*churnWindow)
+	}
+	w, ok := d.windows[namespace]
+	if !ok || now.Sub(w.start) >= d.Window {
+		w = &churnWindow{start: now}
+		d.windows[namespace] = w
+	}
+	w.changes++
+	if w.changes < d.Threshold || w.alerted {
+		return w.changes, false
+	}
+	w.alerted = true
+	ownershipChurnAlertsTotal.WithLabelValues(namespaceLabel(namespace)).Inc()
+	return w.changes, true
+}
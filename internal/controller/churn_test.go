@@ -0,0 +1,43 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+var _ = ginkgo.Describe("ChurnDetector", func() {
+	ginkgo.It("Should be disabled without a threshold", func() {
+		gomega.Expect(NewChurnDetector(0, time.Minute)).To(gomega.BeNil())
+
+		var d *ChurnDetector
+		_, spike := d.Observe("default", ownershipAdded)
+		gomega.Expect(spike).To(gomega.BeFalse())
+	})
+
+	ginkgo.It("Should report a spike once per window and namespace", func() {
+		now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		d := NewChurnDetector(3, time.Minute)
+		d.now = func() time.Time { return now }
+		before := testutil.ToFloat64(ownershipChurnAlertsTotal.WithLabelValues("churn-test"))
+
+		var spikes []bool
+		for range 5 {
+			_, spike := d.Observe("churn-test", ownershipAdded)
+			spikes = append(spikes, spike)
+		}
+		gomega.Expect(spikes).To(gomega.Equal([]bool{false, false, true, false, false}))
+
+		_, spike := d.Observe("other", ownershipAdded)
+		gomega.Expect(spike).To(gomega.BeFalse(), "namespaces are counted separately")
+
+		now = now.Add(time.Minute)
+		changes, spike := d.Observe("churn-test", ownershipRemoved)
+		gomega.Expect(changes).To(gomega.Equal(1), "a new window starts from zero")
+		gomega.Expect(spike).To(gomega.BeFalse())
+
+		gomega.Expect(testutil.ToFloat64(ownershipChurnAlertsTotal.WithLabelValues("churn-test"))).To(gomega.Equal(before + 1))
+	})
+})
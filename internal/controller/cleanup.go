@@ -0,0 +1,221 @@
+package controller
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+// CleanupOptions controls which operator-added owner references are removed
+type CleanupOptions struct {
+	// Namespace limits the cleanup to a single namespace; empty means all namespaces
+	Namespace string
+
+	// DryRun only logs what would be removed
+	DryRun bool
+
+	// Kinds limits the cleanup to owner references of these kinds; empty means every kind
+	Kinds []string
+
+	// InScope, if set, limits the cleanup to the ConfigMaps it rejects, e.g. after the filters were narrowed
+	InScope func(ctx context.Context, cm *corev1.ConfigMap) bool
+	// Hold, if set, returns why writes to a namespace must be held back, e.g. by the kill switch; the ConfigMaps
+	// of a held namespace are logged and left alone
+	Hold func(ctx context.Context, namespace string) string
+}
+
+// RemoveManagedOwnerReferences strips the owner references recorded in the provenance
+// annotation from every ConfigMap in scope and returns the number of ConfigMaps changed
+func RemoveManagedOwnerReferences(
+	ctx context.Context,
+	reader client.Reader,
+	writer client.Writer,
+	opts CleanupOptions,
+	logger logr.Logger,
+) (int, error) {
+	var list corev1.ConfigMapList
+	if err := reader.List(ctx, &list, client.InNamespace(opts.Namespace)); err != nil {
+		return 0, err
+	}
+
+	changed := 0
+	for i := range list.Items {
+		cm := &list.Items[i]
+		if opts.InScope != nil && opts.InScope(ctx, cm) {
+			continue
+		}
+		managed := managedOwnerUIDs(cm)
+		uids := managedOwnersOfKinds(cm, managed, opts.Kinds)
+		if len(uids) == 0 {
+			continue
+		}
+
+		if opts.DryRun {
+			logger.Info("DRY-RUN: Would remove operator owner references",
+				"configmap", cm.Name, "namespace", cm.Namespace, "owners", uids)
+			changed++
+			continue
+		}
+		if hold := holdOf(ctx, opts.Hold, cm.Namespace); hold != "" {
+			logger.Info(hold+": Would remove operator owner references",
+				"configmap", cm.Name, "namespace", cm.Namespace, "owners", uids)
+			continue
+		}
+
+		patch := client.MergeFrom(cm.DeepCopy())
+		cm.OwnerReferences = withoutOwners(cm.OwnerReferences, uids)
+		setManagedOwnerUIDs(cm, slices.DeleteFunc(managed, func(uid types.UID) bool {
+			return slices.Contains(uids, uid)
+		}))
+		if err := writer.Patch(ctx, cm, patch); err != nil {
+			logger.Error(err, "Failed to remove operator owner references",
+				"configmap", cm.Name, "namespace", cm.Namespace)
+			return changed, err
+		}
+		logger.Info("Removed operator owner references", "configmap", cm.Name, "namespace", cm.Namespace)
+		ownershipChangesTotal.WithLabelValues(ownershipRemoved).Add(float64(len(uids)))
+		changed++
+	}
+	return changed, nil
+}
+
+// managedOwnersOfKinds returns the UIDs in managed whose owner reference on cm has one of kinds;
+// no kinds returns managed unchanged
+func managedOwnersOfKinds(cm *corev1.ConfigMap, managed []types.UID, kinds []string) []types.UID {
+	if len(kinds) == 0 {
+		return managed
+	}
+	var uids []types.UID
+	for _, ref := range cm.OwnerReferences {
+		if slices.Contains(managed, ref.UID) && slices.Contains(kinds, ref.Kind) {
+			uids = append(uids, ref.UID)
+		}
+	}
+	return uids
+}
+
+// DisabledKinds returns the workload kinds cfg doesn't own ConfigMaps for, whose owner references
+// may have been added while they were enabled
+func DisabledKinds(cfg *config.OperatorConfig) []string {
+	var kinds []string
+	if !cfg.WatchesReplicaSets() {
+		kinds = append(kinds, "ReplicaSet")
+	}
+	for _, kind := range workloadKinds {
+		if !kind.Enabled(cfg) {
+			kinds = append(kinds, kind.Kind)
+		}
+	}
+	return kinds
+}
+
+// InScope reports whether the filters select cm: the namespace regex selects its namespace and no owner rule
+// skips it, or it is bound to a workload with --configmap-bindings. Owner references the operator added to
+// ConfigMaps out of scope are left over from wider filters.
+func (r *ReplicaSetReconciler) InScope(ctx context.Context, cm *corev1.ConfigMap) bool {
+	if !r.shouldProcessNamespace(ctx, cm.Namespace) {
+		return false
+	}
+	if _, _, bound := ownerWorkload(cm); bound && r.Config.ConfigMapBindings {
+		return true
+	}
+	return len(r.OwnerRules) == 0 || r.ownerStrategy(cm.Name) != OwnerSkip
+}
+
+// CleanupRunner removes operator-added owner references once, after the manager starts, e.g. those of disabled
+// workload kinds. It needs leader election, so only the active replica cleans up.
+type CleanupRunner struct {
+	Reader client.Reader
+	// Writer removes the owner references, with the identity the reconcilers write with
+	Writer  client.Writer
+	Options CleanupOptions
+	Log     logr.Logger
+
+	// Scope describes what is cleaned up in the logs
+	Scope string
+}
+
+// Start implements manager.Runnable. A failed cleanup is logged rather than stopping the operator;
+// the next start retries it. ConfigMaps held back by the kill switch or the maintenance windows are retried
+// until they are released.
+func (c *CleanupRunner) Start(ctx context.Context) error {
+	if len(c.Options.Kinds) == 0 && c.Options.InScope == nil {
+		return nil
+	}
+	c.Log.Info("Removing owner references of "+c.Scope, "kinds", c.Options.Kinds, "dryRun", c.Options.DryRun)
+	for {
+		opts := c.Options
+		held := trackHolds(&opts.Hold)
+		changed, err := RemoveManagedOwnerReferences(ctx, c.Reader, c.Writer, opts, c.Log)
+		if err != nil {
+			c.Log.Error(err, "Cleanup of "+c.Scope+" did not complete, it is retried on the next start",
+				"configMaps", changed)
+			return nil
+		}
+		if !*held {
+			c.Log.Info("Cleanup of "+c.Scope+" complete", "configMaps", changed)
+			return nil
+		}
+		c.Log.Info("Cleanup of "+c.Scope+" held back, retrying", "configMaps", changed, "after", pausedRequeueInterval)
+		if !waitHeld(ctx) {
+			return nil
+		}
+	}
+}
+
+// holdOf returns why hold holds back writes to namespace, if set
+func holdOf(ctx context.Context, hold func(context.Context, string) string, namespace string) string {
+	if hold == nil {
+		return ""
+	}
+	return hold(ctx, namespace)
+}
+
+// trackHolds wraps the hold function at hold to ask once per namespace, and returns whether it held back a write
+// that is released later, unlike in dry-run
+func trackHolds(hold *func(context.Context, string) string) *bool {
+	held := false
+	if *hold == nil {
+		return &held
+	}
+	inner, holds := *hold, map[string]string{}
+	*hold = func(ctx context.Context, namespace string) string {
+		reason, ok := holds[namespace]
+		if !ok {
+			reason = inner(ctx, namespace)
+			holds[namespace] = reason
+		}
+		held = held || reason != "" && reason != holdDryRun
+		return reason
+	}
+	return &held
+}
+
+// waitHeld waits pausedRequeueInterval to retry held back writes, and reports false if ctx is done first
+func waitHeld(ctx context.Context) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(pausedRequeueInterval):
+		return true
+	}
+}
+
+// withoutOwners returns refs without the owner references whose UID is in uids
+func withoutOwners(refs []metav1.OwnerReference, uids []types.UID) []metav1.OwnerReference {
+	var kept []metav1.OwnerReference
+	for _, ref := range refs {
+		if !slices.Contains(uids, ref.UID) {
+			kept = append(kept, ref)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,158 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+var _ = ginkgo.Describe("RemoveManagedOwnerReferences", func() {
+	var (
+		ctx        context.Context
+		fakeClient client.Client
+	)
+
+	ginkgo.BeforeEach(func() {
+		ctx = context.Background()
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "test-config",
+				Namespace:   "default",
+				Annotations: map[string]string{ManagedOwnersAnnotation: "rs-uid"},
+				OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "test-rs", UID: "rs-uid"},
+					{APIVersion: "v1", Kind: "Pod", Name: "other", UID: "other-uid"},
+				},
+			},
+		}
+		fakeClient = fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(configMap).Build()
+	})
+
+	ginkgo.It("Should remove only operator-added owner references", func() {
+		changed, err := RemoveManagedOwnerReferences(ctx, fakeClient, fakeClient, CleanupOptions{}, logr.Discard())
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(changed).To(gomega.Equal(1))
+
+		var cm corev1.ConfigMap
+		gomega.Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "test-config", Namespace: "default"}, &cm)).
+			To(gomega.Succeed())
+		gomega.Expect(cm.OwnerReferences).To(gomega.HaveLen(1))
+		gomega.Expect(cm.OwnerReferences[0].UID).To(gomega.Equal(types.UID("other-uid")))
+		gomega.Expect(cm.Annotations).NotTo(gomega.HaveKey(ManagedOwnersAnnotation))
+	})
+
+	ginkgo.It("Should patch through the writer rather than the reader", func() {
+		patched := 0
+		writer := interceptor.NewClient(fakeClient.(client.WithWatch), interceptor.Funcs{
+			Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch,
+				opts ...client.PatchOption) error {
+				patched++
+				return c.Patch(ctx, obj, patch, opts...)
+			},
+		})
+		changed, err := RemoveManagedOwnerReferences(ctx, fakeClient, writer, CleanupOptions{}, logr.Discard())
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(changed).To(gomega.Equal(1))
+		gomega.Expect(patched).To(gomega.Equal(1))
+	})
+
+	ginkgo.It("Should not change anything in dry-run mode", func() {
+		opts := CleanupOptions{DryRun: true}
+		changed, err := RemoveManagedOwnerReferences(ctx, fakeClient, fakeClient, opts, logr.Discard())
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(changed).To(gomega.Equal(1))
+
+		var cm corev1.ConfigMap
+		gomega.Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "test-config", Namespace: "default"}, &cm)).
+			To(gomega.Succeed())
+		gomega.Expect(cm.OwnerReferences).To(gomega.HaveLen(2))
+	})
+
+	ginkgo.It("Should leave the ConfigMaps of held namespaces alone", func() {
+		opts := CleanupOptions{Hold: func(context.Context, string) string { return holdPaused }}
+		held := trackHolds(&opts.Hold)
+		changed, err := RemoveManagedOwnerReferences(ctx, fakeClient, fakeClient, opts, logr.Discard())
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(changed).To(gomega.Equal(0))
+		gomega.Expect(*held).To(gomega.BeTrue())
+
+		var cm corev1.ConfigMap
+		gomega.Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "test-config", Namespace: "default"}, &cm)).
+			To(gomega.Succeed())
+		gomega.Expect(cm.OwnerReferences).To(gomega.HaveLen(2))
+
+		// Dry-run holds are not released, so they are not retried
+		opts = CleanupOptions{Hold: func(context.Context, string) string { return holdDryRun }}
+		held = trackHolds(&opts.Hold)
+		_, err = RemoveManagedOwnerReferences(ctx, fakeClient, fakeClient, opts, logr.Discard())
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(*held).To(gomega.BeFalse())
+	})
+
+	ginkgo.It("Should only remove owner references of the given kinds", func() {
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "mixed",
+				Namespace:   "default",
+				Annotations: map[string]string{ManagedOwnersAnnotation: "rs-uid,pt-uid"},
+				OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "test-rs", UID: "rs-uid"},
+					{APIVersion: "v1", Kind: "PodTemplate", Name: "test-pt", UID: "pt-uid"},
+				},
+			},
+		}
+		gomega.Expect(fakeClient.Create(ctx, configMap)).To(gomega.Succeed())
+
+		opts := CleanupOptions{Kinds: []string{"PodTemplate"}}
+		changed, err := RemoveManagedOwnerReferences(ctx, fakeClient, fakeClient, opts, logr.Discard())
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(changed).To(gomega.Equal(1))
+
+		var cm corev1.ConfigMap
+		gomega.Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "mixed", Namespace: "default"}, &cm)).
+			To(gomega.Succeed())
+		gomega.Expect(cm.OwnerReferences).To(gomega.ConsistOf(gomega.HaveField("Kind", "ReplicaSet")))
+		gomega.Expect(managedOwnerUIDs(&cm)).To(gomega.ConsistOf(types.UID("rs-uid")))
+	})
+
+	ginkgo.It("Should only remove owner references from ConfigMaps out of scope", func() {
+		excluded := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "test-config",
+				Namespace:   "legacy",
+				Annotations: map[string]string{ManagedOwnersAnnotation: "rs-uid"},
+				OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "test-rs", UID: "rs-uid"},
+				},
+			},
+		}
+		gomega.Expect(fakeClient.Create(ctx, excluded)).To(gomega.Succeed())
+		reconciler := &ReplicaSetReconciler{
+			Client: fakeClient, Config: &config.OperatorConfig{NamespaceRegex: []string{"^default$"}},
+		}
+
+		changed, err := RemoveManagedOwnerReferences(ctx, fakeClient, fakeClient, CleanupOptions{InScope: reconciler.InScope},
+			logr.Discard())
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(changed).To(gomega.Equal(1))
+
+		var cm corev1.ConfigMap
+		gomega.Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "test-config", Namespace: "legacy"}, &cm)).
+			To(gomega.Succeed())
+		gomega.Expect(cm.OwnerReferences).To(gomega.BeEmpty())
+		gomega.Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "test-config", Namespace: "default"}, &cm)).
+			To(gomega.Succeed())
+		gomega.Expect(cm.OwnerReferences).To(gomega.HaveLen(2))
+	})
+})
@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// coalesceFieldOwner is the field manager of the coalesced applies
+const coalesceFieldOwner = "configmap-rs-operator"
+
+// coalesceWorkers is how many reconciles each controller runs at once with coalescing, so workloads
+// created together can join the same apply
+const coalesceWorkers = 4
+
+// Coalescer batches the owner references added to the same ConfigMap within Window into a single
+// server-side apply, instead of sequential updates that each risk a conflict
+type Coalescer struct {
+	Window time.Duration
+
+	// CompareOptions are the Argo CD compare options each apply adds, see --argocd-compare-options
+	CompareOptions string
+
+	mu      sync.Mutex
+	batches map[types.NamespacedName]*ownerBatch
+}
+
+// ownerBatch is the owner references waiting for the next apply of a ConfigMap
+type ownerBatch struct {
+	owners []metav1.OwnerReference
+	done   chan struct{}
+	err    error
+}
+
+// NewCoalescer returns a coalescer batching the additions made within window.
+// It returns nil if window isn't positive, which disables coalescing.
+func NewCoalescer(window time.Duration) *Coalescer {
+	if window <= 0 {
+		return nil
+	}
+	return &Coalescer{Window: window, batches: map[types.NamespacedName]*ownerBatch{}}
+}
+
+// Add adds owner to cm with the next apply of cm and waits for it, returning its error. The first addition
+// to a ConfigMap waits for the window to pass and applies the owners added in the meantime with its own.
+func (c *Coalescer) Add(ctx context.Context, w client.Writer, cm *corev1.ConfigMap, owner metav1.OwnerReference) error {
+	key := client.ObjectKeyFromObject(cm)
+	c.mu.Lock()
+	batch, joined := c.batches[key]
+	if !joined {
+		batch = &ownerBatch{done: make(chan struct{})}
+		c.batches[key] = batch
+	}
+	batch.owners = append(batch.owners, owner)
+	c.mu.Unlock()
+
+	if joined {
+		select {
+		case <-batch.done:
+			return batch.err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	select {
+	case <-time.After(c.Window):
+	case <-ctx.Done():
+	}
+	c.mu.Lock()
+	delete(c.batches, key)
+	owners := batch.owners
+	c.mu.Unlock()
+
+	// The apply only sets the operator's own fields, so forcing never takes over what other tools wrote
+	apply := ownerApply(cm, owners)
+	stampCompareOptions(apply, cm, c.CompareOptions)
+	batch.err = w.Patch(ctx, apply, client.Apply,
+		client.FieldOwner(coalesceFieldOwner), client.ForceOwnership)
+	if batch.err == nil {
+		ownersPerApply.Observe(float64(len(owners)))
+	}
+	close(batch.done)
+	return batch.err
+}
+
+// ownerApply returns the apply configuration of cm holding the owner references the operator manages
+// plus owners. Managed references already on cm are repeated, so the apply doesn't remove them.
+func ownerApply(cm *corev1.ConfigMap, owners []metav1.OwnerReference) *corev1.ConfigMap {
+	current := cm.DeepCopy()
+	upgradeSemantics(current)
+	managed := managedOwnerUIDs(current)
+
+	apply := &corev1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: cm.Namespace, Name: cm.Name},
+	}
+	for _, ref := range current.OwnerReferences {
+		if slices.Contains(managed, ref.UID) {
+			apply.OwnerReferences = append(apply.OwnerReferences, ref)
+		}
+	}
+	setManagedOwnerUIDs(apply, managed)
+	for _, owner := range owners {
+		upsertOwnerReference(apply, owner)
+		addManagedOwner(apply, owner.UID)
+	}
+	apply.Annotations[SemanticsVersionAnnotation] = current.Annotations[SemanticsVersionAnnotation]
+	return apply
+}
+
+// addOwner adds owner to obj, a ConfigMap or Secret. ConfigMaps get it in the same apply as the owners other
+// workloads add at the same time when coalescing is enabled.
+func (r *ReplicaSetReconciler) addOwner(ctx context.Context, obj client.Object, owner metav1.OwnerReference) error {
+	cm, isConfigMap := obj.(*corev1.ConfigMap)
+	if isConfigMap && r.Coalescer != nil {
+		return r.Coalescer.Add(ctx, r.writer(), cm, owner)
+	}
+	original := obj.DeepCopyObject().(client.Object)
+	if isConfigMap {
+		upgradeSemantics(cm)
+		stampCompareOptions(cm, cm, r.Config.ArgoCDCompareOptions)
+	}
+	upsertOwnerReference(obj, owner)
+	addManagedOwner(obj, owner.UID)
+	return r.updateObject(ctx, r.writer(), obj, original)
+}
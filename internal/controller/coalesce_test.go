@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+var _ = ginkgo.Describe("Coalescer", func() {
+	ref := func(name string) metav1.OwnerReference {
+		return metav1.OwnerReference{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: name, UID: types.UID(name + "-uid")}
+	}
+
+	ginkgo.It("Should be disabled without a window", func() {
+		gomega.Expect(NewCoalescer(0)).To(gomega.BeNil())
+	})
+
+	ginkgo.It("Should apply the owners added within the window at once", func() {
+		var (
+			mu      sync.Mutex
+			applies []*corev1.ConfigMap
+		)
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithInterceptorFuncs(interceptor.Funcs{
+			Patch: func(_ context.Context, _ client.WithWatch, obj client.Object, patch client.Patch,
+				_ ...client.PatchOption) error {
+				mu.Lock()
+				defer mu.Unlock()
+				gomega.Expect(patch).To(gomega.Equal(client.Apply))
+				applies = append(applies, obj.(*corev1.ConfigMap))
+				return nil
+			},
+		}).Build()
+		coalescer := NewCoalescer(50 * time.Millisecond)
+
+		cm := testConfigMap("shared", "default")
+		var wg sync.WaitGroup
+		for _, name := range []string{"web", "worker"} {
+			wg.Add(1)
+			go func() {
+				defer ginkgo.GinkgoRecover()
+				defer wg.Done()
+				gomega.Expect(coalescer.Add(context.Background(), c, cm, ref(name))).To(gomega.Succeed())
+			}()
+		}
+		wg.Wait()
+
+		gomega.Expect(applies).To(gomega.HaveLen(1))
+		gomega.Expect(applies[0].OwnerReferences).To(gomega.ConsistOf(
+			gomega.HaveField("Name", "web"), gomega.HaveField("Name", "worker")))
+		gomega.Expect(managedOwnerUIDs(applies[0])).To(gomega.ConsistOf(types.UID("web-uid"), types.UID("worker-uid")))
+	})
+
+	ginkgo.It("Should repeat the managed owner references and leave the others out", func() {
+		cm := testConfigMap("shared", "default")
+		cm.OwnerReferences = []metav1.OwnerReference{ref("web"), ref("helm")}
+		setManagedOwnerUIDs(cm, []types.UID{"web-uid"})
+
+		apply := ownerApply(cm, []metav1.OwnerReference{ref("worker")})
+		gomega.Expect(apply.OwnerReferences).To(gomega.ConsistOf(
+			gomega.HaveField("Name", "web"), gomega.HaveField("Name", "worker")))
+		gomega.Expect(apply.Data).To(gomega.BeNil())
+		gomega.Expect(apply.Annotations).To(gomega.HaveKeyWithValue(SemanticsVersionAnnotation, gomega.Not(gomega.BeEmpty())))
+	})
+})
@@ -0,0 +1,207 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// operatorAnnotations are the ConfigMap annotations only the operator writes. ApprovedAnnotation is left out,
+// since people set it.
+var operatorAnnotations = []string{ManagedOwnersAnnotation, PendingOwnersAnnotation, SemanticsVersionAnnotation}
+
+// Conflict is another field manager writing a field of a ConfigMap the operator manages
+type Conflict struct {
+	Namespace string `json:"namespace"`
+	ConfigMap string `json:"configMap"`
+	Manager   string `json:"manager"`
+
+	// Field is ownerReferences or the key of the annotation
+	Field string `json:"field"`
+}
+
+// DefaultFieldManager returns the field manager the API server records for updates made with cfg: the prefix of
+// its user agent, which client-go defaults to the binary name
+func DefaultFieldManager(cfg *rest.Config) string {
+	userAgent := cfg.UserAgent
+	if userAgent == "" {
+		userAgent = rest.DefaultKubernetesUserAgent()
+	}
+	manager, _, _ := strings.Cut(userAgent, "/")
+	return manager
+}
+
+// ScanConflicts returns the fields of the ConfigMaps the operator manages that field managers other than own
+// write, sorted by namespace, ConfigMap and manager: owner references the operator added and its annotations
+func (r *ReplicaSetReconciler) ScanConflicts(ctx context.Context, own []string) ([]Conflict, error) {
+	var configMaps corev1.ConfigMapList
+	if err := r.List(ctx, &configMaps); err != nil {
+		return nil, err
+	}
+	own = append(slices.Clone(own), coalesceFieldOwner)
+	conflicts := []Conflict{}
+	for i := range configMaps.Items {
+		conflicts = append(conflicts, configMapConflicts(&configMaps.Items[i], own)...)
+	}
+	sort.SliceStable(conflicts, func(i, j int) bool {
+		a, b := conflicts[i], conflicts[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.ConfigMap != b.ConfigMap {
+			return a.ConfigMap < b.ConfigMap
+		}
+		return a.Manager < b.Manager
+	})
+	return conflicts, nil
+}
+
+// configMapConflicts returns the conflicts of one ConfigMap; ConfigMaps the operator didn't add owners to have none
+func configMapConflicts(cm *corev1.ConfigMap, own []string) []Conflict {
+	managed := managedOwnerUIDs(cm)
+	if len(managed) == 0 {
+		return nil
+	}
+	var conflicts []Conflict
+	for _, entry := range cm.ManagedFields {
+		if entry.FieldsV1 == nil || slices.Contains(own, entry.Manager) {
+			continue
+		}
+		var fields map[string]map[string]map[string]json.RawMessage
+		if err := json.Unmarshal(entry.FieldsV1.Raw, &fields); err != nil {
+			continue
+		}
+		metadata := fields["f:metadata"]
+		for key := range metadata["f:ownerReferences"] {
+			if uid, ok := ownerReferenceUID(key); ok && slices.Contains(managed, uid) {
+				conflicts = append(conflicts, Conflict{cm.Namespace, cm.Name, entry.Manager, "ownerReferences"})
+				break
+			}
+		}
+		for _, annotation := range operatorAnnotations {
+			if _, ok := metadata["f:annotations"]["f:"+annotation]; ok {
+				conflicts = append(conflicts, Conflict{cm.Namespace, cm.Name, entry.Manager, annotation})
+			}
+		}
+	}
+	return conflicts
+}
+
+// ownerReferenceUID returns the UID of an owner reference key of managed fields, k:{"uid":"..."}
+func ownerReferenceUID(key string) (types.UID, bool) {
+	raw, ok := strings.CutPrefix(key, "k:")
+	if !ok {
+		return "", false
+	}
+	var ref struct {
+		UID types.UID `json:"uid"`
+	}
+	if err := json.Unmarshal([]byte(raw), &ref); err != nil || ref.UID == "" {
+		return "", false
+	}
+	return ref.UID, true
+}
+
+// conflictsStateKey is the state store key of the conflicts already reported
+const conflictsStateKey = "conflicts"
+
+// ConflictScanner scans for conflicting field managers on startup and periodically, such as a second operator
+// installation, Helm hooks or scripts rewriting owner references, exports them per manager as a metric and emits
+// a Warning Event on a ConfigMap the first time a conflict is found on it. Like DriftScanner it needs leader
+// election and keeps the conflicts it reported in the state store.
+type ConflictScanner struct {
+	Reconciler *ReplicaSetReconciler
+	Interval   time.Duration
+	Log        logr.Logger
+
+	// Managers are the field managers of the operator itself
+	Managers []string
+
+	seen map[Conflict]bool
+}
+
+// Start implements manager.Runnable
+func (s *ConflictScanner) Start(ctx context.Context) error {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+	for {
+		if err := s.scan(ctx); err != nil {
+			s.Log.Error(err, "Failed to scan for conflicting field managers")
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// scan runs one scan and reports its conflicts
+func (s *ConflictScanner) scan(ctx context.Context) error {
+	conflicts, err := s.Reconciler.ScanConflicts(ctx, s.Managers)
+	if err != nil {
+		return err
+	}
+
+	if s.seen == nil {
+		s.seen = s.loadSeen(ctx)
+	}
+	counts := map[string]int{}
+	seen := make(map[Conflict]bool, len(conflicts))
+	for _, c := range conflicts {
+		counts[c.Manager]++
+		seen[c] = true
+		if s.seen[c] {
+			continue
+		}
+		s.Log.Info("Conflicting field manager detected", "configmap", c.ConfigMap, "namespace", c.Namespace,
+			"manager", c.Manager, "field", c.Field)
+		var cm corev1.ConfigMap
+		key := types.NamespacedName{Namespace: c.Namespace, Name: c.ConfigMap}
+		if err := s.Reconciler.Get(ctx, key, &cm); err != nil {
+			if client.IgnoreNotFound(err) != nil {
+				s.Log.Error(err, "Failed to read conflicted ConfigMap", "configmap", key)
+			}
+			continue
+		}
+		s.Reconciler.recordEvent(&cm, corev1.EventTypeWarning, "ConflictingManager",
+			"Field manager %s also writes %s, which the operator manages", c.Manager, c.Field)
+	}
+	conflictingManagers.Reset()
+	for manager, n := range counts {
+		conflictingManagers.WithLabelValues(manager).Set(float64(n))
+	}
+	if !maps.Equal(seen, s.seen) && s.Reconciler.State != nil {
+		if err := s.Reconciler.State.Save(ctx, conflictsStateKey, slices.Collect(maps.Keys(seen))); err != nil {
+			s.Log.Error(err, "Failed to save the reported conflicts")
+		}
+	}
+	s.seen = seen
+	return nil
+}
+
+// loadSeen returns the conflicts reported before, by this or an earlier leader
+func (s *ConflictScanner) loadSeen(ctx context.Context) map[Conflict]bool {
+	seen := map[Conflict]bool{}
+	if s.Reconciler.State == nil {
+		return seen
+	}
+	var conflicts []Conflict
+	if _, err := s.Reconciler.State.Load(ctx, conflictsStateKey, &conflicts); err != nil {
+		s.Log.Error(err, "Failed to load the reported conflicts, reporting them again")
+	}
+	for _, c := range conflicts {
+		seen[c] = true
+	}
+	return seen
+}
@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+var _ = ginkgo.Describe("Conflict", func() {
+	managedFields := func(manager, fields string) metav1.ManagedFieldsEntry {
+		return metav1.ManagedFieldsEntry{
+			Manager: manager, Operation: metav1.ManagedFieldsOperationUpdate, APIVersion: "v1",
+			FieldsType: "FieldsV1", FieldsV1: &metav1.FieldsV1{Raw: []byte(fields)},
+		}
+	}
+
+	ginkgo.It("Should report other field managers writing the operator's owner references and annotations", func() {
+		cm := testConfigMap("config", "default")
+		setManagedOwnerUIDs(cm, []types.UID{"web-uid"})
+		cm.ManagedFields = []metav1.ManagedFieldsEntry{
+			managedFields("manager", `{"f:metadata":{"f:annotations":{"f:configmap-rs-operator/managed-owners":{}},`+
+				`"f:ownerReferences":{"k:{\"uid\":\"web-uid\"}":{}}}}`),
+			managedFields("helm", `{"f:data":{"f:key":{}},"f:metadata":{"f:labels":{"f:app":{}}}}`),
+			managedFields("kubectl", `{"f:metadata":{"f:annotations":{"f:configmap-rs-operator/approved":{}}}}`),
+			managedFields("other-operator", `{"f:metadata":{"f:annotations":`+
+				`{"f:configmap-rs-operator/managed-owners":{}},"f:ownerReferences":{"k:{\"uid\":\"web-uid\"}":{}}}}`),
+			managedFields("gc-script", `{"f:metadata":{"f:ownerReferences":{"k:{\"uid\":\"job-uid\"}":{}}}}`),
+		}
+		unmanaged := testConfigMap("unmanaged", "default")
+		unmanaged.ManagedFields = []metav1.ManagedFieldsEntry{
+			managedFields("other-operator", `{"f:metadata":{"f:ownerReferences":{"k:{\"uid\":\"web-uid\"}":{}}}}`),
+		}
+
+		gomega.Expect(configMapConflicts(unmanaged, []string{"manager"})).To(gomega.BeEmpty())
+		gomega.Expect(configMapConflicts(cm, []string{"manager"})).To(gomega.Equal([]Conflict{
+			{Namespace: "default", ConfigMap: "config", Manager: "other-operator", Field: "ownerReferences"},
+			{Namespace: "default", ConfigMap: "config", Manager: "other-operator", Field: ManagedOwnersAnnotation},
+		}))
+	})
+
+	ginkgo.It("Should emit an Event the first time a conflict is found", func() {
+		ctx := context.Background()
+		cm := testConfigMap("config", "default")
+		setManagedOwnerUIDs(cm, []types.UID{"web-uid"})
+		cm.ManagedFields = []metav1.ManagedFieldsEntry{
+			managedFields("other-operator", `{"f:metadata":{"f:ownerReferences":{"k:{\"uid\":\"web-uid\"}":{}}}}`),
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(cm).Build()
+		recorder := record.NewFakeRecorder(10)
+		r := &ReplicaSetReconciler{Client: c, Scheme: scheme.Scheme, Recorder: recorder, Config: &config.OperatorConfig{}}
+		scanner := &ConflictScanner{Reconciler: r, Log: logr.Discard(), Managers: []string{"manager"}}
+
+		gomega.Expect(scanner.scan(ctx)).To(gomega.Succeed())
+		gomega.Expect(recorder.Events).To(gomega.HaveLen(1))
+		gomega.Expect(<-recorder.Events).To(gomega.ContainSubstring("ConflictingManager"))
+
+		// Conflicts already reported aren't reported again
+		gomega.Expect(scanner.scan(ctx)).To(gomega.Succeed())
+		gomega.Expect(recorder.Events).To(gomega.BeEmpty())
+	})
+})
@@ -0,0 +1,57 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// workloadPlaceholder is replaced by the workload's name in --name-convention-pattern
+const workloadPlaceholder = "{workload}"
+
+// ValidateNameConvention returns an error for name convention patterns without the workload placeholder
+func ValidateNameConvention(pattern string) error {
+	if !strings.Contains(pattern, workloadPlaceholder) {
+		return fmt.Errorf("invalid name convention pattern %q: expected it to contain %s", pattern, workloadPlaceholder)
+	}
+	return nil
+}
+
+// replicaSetWorkloadName returns the name the name convention uses for rs: its Deployment's, since the
+// ReplicaSet's own name changes with every revision, or its own without one
+func replicaSetWorkloadName(rs *appsv1.ReplicaSet) string {
+	if ref := deploymentOf(rs); ref != nil {
+		return ref.Name
+	}
+	return rs.Name
+}
+
+// withConventionConfigMap appends to names the ConfigMap --name-convention ties to the workload, if it exists
+// and the workload doesn't mount it already. A missing ConfigMap is not an error: most workloads have none.
+func (r *ReplicaSetReconciler) withConventionConfigMap(
+	ctx context.Context,
+	namespace, workload string,
+	names []string,
+) ([]string, error) {
+	if !r.Config.NameConvention {
+		return names, nil
+	}
+	name := strings.ReplaceAll(r.Config.NameConventionPattern, workloadPlaceholder, workload)
+	if slices.Contains(names, name) {
+		return names, nil
+	}
+	var cm corev1.ConfigMap
+	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &cm); err != nil {
+		if errors.IsNotFound(err) {
+			return names, nil
+		}
+		return nil, err
+	}
+	return append(names, name), nil
+}
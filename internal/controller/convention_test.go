@@ -0,0 +1,50 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+var _ = ginkgo.Describe("Name convention", func() {
+	ginkgo.It("Should reject patterns without the workload placeholder", func() {
+		gomega.Expect(ValidateNameConvention("{workload}-config")).To(gomega.Succeed())
+		gomega.Expect(ValidateNameConvention("app-config")).To(gomega.HaveOccurred())
+	})
+
+	ginkgo.It("Should own the ConfigMap named after the Deployment without mounting it", func() {
+		ctx := context.Background()
+		rs := testRevision("web-5f7c9d", 1, "app-config")
+		rs.CreationTimestamp = metav1.Now()
+		other := testReplicaSet("api-abc", "default")
+		other.CreationTimestamp = metav1.Now()
+		recorder := record.NewFakeRecorder(10)
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(rs, other,
+			testConfigMap("app-config", "default"), testConfigMap("web-config", "default")).Build()
+		r := &ReplicaSetReconciler{Client: c, Scheme: scheme.Scheme, Recorder: recorder,
+			Config: &config.OperatorConfig{NameConvention: true, NameConventionPattern: "{workload}-config"}}
+
+		for _, name := range []string{rs.Name, other.Name} {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: name}})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		}
+		var cm corev1.ConfigMap
+		gomega.Expect(c.Get(ctx, types.NamespacedName{Namespace: "default", Name: "web-config"}, &cm)).To(gomega.Succeed())
+		gomega.Expect(cm.OwnerReferences).To(gomega.ConsistOf(gomega.HaveField("Name", rs.Name)))
+		// api-config doesn't exist, which isn't worth a warning
+		close(recorder.Events)
+		for event := range recorder.Events {
+			gomega.Expect(event).NotTo(gomega.ContainSubstring("ConfigMapNotFound"))
+		}
+	})
+})
@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+// What owns the ConfigMaps mounted by the job template of a CronJob
+const (
+	// CronJobOwnerCronJob adds the CronJob as owner, so the ConfigMaps live as long as the schedule
+	CronJobOwnerCronJob = "cronjob"
+	// CronJobOwnerJob adds each Job the CronJob spawns as owner, so per-run ConfigMaps go with the run's Job
+	CronJobOwnerJob = "job"
+)
+
+// ValidateCronJobOwner returns an error for unknown CronJob owners; empty means CronJobOwnerCronJob
+func ValidateCronJobOwner(owner string) error {
+	switch owner {
+	case "", CronJobOwnerCronJob, CronJobOwnerJob:
+		return nil
+	default:
+		return fmt.Errorf("invalid CronJob owner %q: expected %s or %s", owner, CronJobOwnerCronJob, CronJobOwnerJob)
+	}
+}
+
+// OwnsCronJobs reports whether cfg adds CronJobs as owners of the ConfigMaps of their job template
+func OwnsCronJobs(cfg *config.OperatorConfig) bool {
+	return cfg.CronJobs && cfg.CronJobOwner != CronJobOwnerJob
+}
+
+// OwnsJobs reports whether cfg adds Jobs as owners of the ConfigMaps they mount: every Job with --jobs, or the
+// Jobs spawned by CronJobs with --cronjob-owner=job
+func OwnsJobs(cfg *config.OperatorConfig) bool {
+	return cfg.Jobs || cfg.CronJobs && cfg.CronJobOwner == CronJobOwnerJob
+}
+
+// CronJobKind is the workload kind of CronJobs. Their job template changes in place, so a CronJob is processed
+// again whenever its spec changes.
+var CronJobKind = &WorkloadKind{
+	Kind:         "CronJob",
+	GroupVersion: batchv1.SchemeGroupVersion,
+	New:          func() client.Object { return &batchv1.CronJob{} },
+	NewList:      func() client.ObjectList { return &batchv1.CronJobList{} },
+	PodSpec: func(obj client.Object) (*corev1.PodSpec, error) {
+		return &obj.(*batchv1.CronJob).Spec.JobTemplate.Spec.Template.Spec, nil
+	},
+	Mutable: true,
+	Enabled: OwnsCronJobs,
+}
+
+// CronJobReconciler adds CronJobs as owners of the ConfigMaps their job template mounts, using the same extraction
+// as for ReplicaSets
+type CronJobReconciler struct {
+	// ReplicaSetReconciler provides the namespace filter, write holds, write client and events
+	*ReplicaSetReconciler
+}
+
+// +kubebuilder:rbac:groups=batch,resources=cronjobs,verbs=get;list;watch
+
+func (r *CronJobReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	return r.workload(CronJobKind).Reconcile(ctx, req)
+}
+
+// SetupWithManager sets up the controller with the Manager
+func (r *CronJobReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return r.workload(CronJobKind).SetupWithManager(mgr)
+}
@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+var _ = ginkgo.Describe("CronJobReconciler", func() {
+	template := testReplicaSet("unused", "default", "report-config").Spec.Template
+
+	ownersOf := func(ctx context.Context, c client.Client) []metav1.OwnerReference {
+		var cm corev1.ConfigMap
+		gomega.Expect(c.Get(ctx, types.NamespacedName{Namespace: "default", Name: "report-config"}, &cm)).To(gomega.Succeed())
+		return cm.OwnerReferences
+	}
+
+	ginkgo.It("Should add the CronJob as owner of the ConfigMaps its job template mounts", func() {
+		ctx := context.Background()
+		cj := &batchv1.CronJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "nightly-report", Namespace: "default", UID: "nightly-report-uid"},
+			Spec: batchv1.CronJobSpec{Schedule: "0 2 * * *", JobTemplate: batchv1.JobTemplateSpec{
+				Spec: batchv1.JobSpec{Template: template},
+			}},
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).
+			WithObjects(cj, testConfigMap("report-config", "default")).Build()
+		r := &CronJobReconciler{ReplicaSetReconciler: &ReplicaSetReconciler{Client: c, Scheme: scheme.Scheme,
+			Config: &config.OperatorConfig{CronJobs: true, CronJobOwner: CronJobOwnerCronJob}}}
+
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(cj)})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(ownersOf(ctx, c)).To(gomega.ConsistOf(gomega.And(
+			gomega.HaveField("APIVersion", "batch/v1"),
+			gomega.HaveField("Kind", "CronJob"),
+			gomega.HaveField("Name", "nightly-report"),
+		)))
+	})
+
+	ginkgo.It("Should add each spawned Job as owner with the job CronJob owner", func() {
+		ctx := context.Background()
+		isController := true
+		spawned := &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "nightly-report-29000000", Namespace: "default", UID: "spawned-uid", CreationTimestamp: metav1.Now(),
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: "batch/v1", Kind: "CronJob", Name: "nightly-report", UID: "nightly-report-uid",
+					Controller: &isController,
+				}},
+			},
+			Spec: batchv1.JobSpec{Template: template},
+		}
+		standalone := &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "one-off", Namespace: "default", UID: "one-off-uid", CreationTimestamp: metav1.Now(),
+			},
+			Spec: batchv1.JobSpec{Template: template},
+		}
+		cfg := &config.OperatorConfig{CronJobs: true, CronJobOwner: CronJobOwnerJob}
+		gomega.Expect(OwnsJobs(cfg)).To(gomega.BeTrue())
+		gomega.Expect(OwnsCronJobs(cfg)).To(gomega.BeFalse())
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).
+			WithObjects(spawned, standalone, testConfigMap("report-config", "default")).Build()
+		r := &JobReconciler{ReplicaSetReconciler: &ReplicaSetReconciler{Client: c, Scheme: scheme.Scheme, Config: cfg}}
+
+		for _, job := range []*batchv1.Job{spawned, standalone} {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(job)})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		}
+		gomega.Expect(ownersOf(ctx, c)).To(gomega.ConsistOf(gomega.And(
+			gomega.HaveField("Kind", "Job"),
+			gomega.HaveField("Name", "nightly-report-29000000"),
+		)))
+	})
+
+	ginkgo.It("Should reject unknown CronJob owners", func() {
+		gomega.Expect(ValidateCronJobOwner("schedule")).To(gomega.HaveOccurred())
+		gomega.Expect(ValidateCronJobOwner(CronJobOwnerJob)).To(gomega.Succeed())
+	})
+})
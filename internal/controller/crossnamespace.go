@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// namespacedNamePattern matches values of the form namespace/name, as used to point at config in
+// another namespace by convention
+var namespacedNamePattern = regexp.MustCompile(`^([a-z0-9]([-a-z0-9]*[a-z0-9])?)/([a-z0-9]([-a-z0-9.]*[a-z0-9])?)$`)
+
+// CrossNamespaceReference is an environment variable of a workload naming a ConfigMap in another
+// namespace. Owner references can't cross namespaces, so such ConfigMaps are never managed.
+type CrossNamespaceReference struct {
+	Namespace  string               `json:"namespace"`
+	ReplicaSet string               `json:"replicaSet"`
+	Container  string               `json:"container"`
+	Variable   string               `json:"variable"`
+	ConfigMap  types.NamespacedName `json:"configMap"`
+}
+
+// crossNamespaceCandidates returns the environment variables of rs whose literal value is a
+// namespace/name pair in another namespace; whether such a ConfigMap exists is left to the caller
+func crossNamespaceCandidates(rs *appsv1.ReplicaSet) []CrossNamespaceReference {
+	var refs []CrossNamespaceReference
+	spec := &rs.Spec.Template.Spec
+	containers := append(append([]corev1.Container(nil), spec.InitContainers...), spec.Containers...)
+	for i := range containers {
+		for _, env := range containers[i].Env {
+			m := namespacedNamePattern.FindStringSubmatch(env.Value)
+			if m == nil || m[1] == rs.Namespace {
+				continue
+			}
+			refs = append(refs, CrossNamespaceReference{
+				Namespace:  rs.Namespace,
+				ReplicaSet: rs.Name,
+				Container:  containers[i].Name,
+				Variable:   env.Name,
+				ConfigMap:  types.NamespacedName{Namespace: m[1], Name: m[3]},
+			})
+		}
+	}
+	return refs
+}
+
+// detectCrossNamespace reports the ConfigMaps rs references in other namespaces with a warning Event,
+// so users know why they aren't managed. Lookup failures are logged rather than failing the reconcile.
+func (r *ReplicaSetReconciler) detectCrossNamespace(ctx context.Context, rs *appsv1.ReplicaSet, logger logr.Logger) {
+	for _, ref := range crossNamespaceCandidates(rs) {
+		var cm corev1.ConfigMap
+		if err := r.Get(ctx, ref.ConfigMap, &cm); err != nil {
+			if !errors.IsNotFound(err) {
+				logger.Error(err, "Failed to look up cross-namespace ConfigMap reference", "configmap", ref.ConfigMap)
+			}
+			continue
+		}
+		logger.Info("ConfigMap referenced from another namespace can't be managed",
+			"configmap", ref.ConfigMap, "container", ref.Container, "variable", ref.Variable)
+		crossNamespaceReferencesTotal.WithLabelValues(namespaceLabel(rs.Namespace)).Inc()
+		r.recordEvent(rs, corev1.EventTypeWarning, "CrossNamespaceReference",
+			"Variable %s of container %s references ConfigMap %s in another namespace; owner references "+
+				"can't cross namespaces, so it is not managed", ref.Variable, ref.Container, ref.ConfigMap)
+	}
+}
@@ -0,0 +1,60 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+var _ = ginkgo.Describe("Cross-namespace references", func() {
+	ginkgo.It("Should only consider namespace/name values pointing to other namespaces", func() {
+		rs := testReplicaSet("rs-a", "default")
+		rs.Spec.Template.Spec.Containers[0].Env = []corev1.EnvVar{
+			{Name: "CONFIG", Value: "shared/app-config"},
+			{Name: "LOCAL", Value: "default/app-config"},
+			{Name: "URL", Value: "http://example.com/path"},
+		}
+		gomega.Expect(crossNamespaceCandidates(rs)).To(gomega.Equal([]CrossNamespaceReference{{
+			Namespace: "default", ReplicaSet: "rs-a", Container: "app", Variable: "CONFIG",
+			ConfigMap: types.NamespacedName{Namespace: "shared", Name: "app-config"},
+		}}))
+	})
+
+	ginkgo.It("Should warn about existing ConfigMaps referenced from another namespace", func() {
+		ctx := context.Background()
+		rs := testReplicaSet("rs-a", "default")
+		rs.CreationTimestamp = metav1.Now()
+		rs.Spec.Template.Spec.Containers[0].Env = []corev1.EnvVar{
+			{Name: "CONFIG", Value: "shared/app-config"},
+			{Name: "OTHER", Value: "shared/missing"},
+		}
+		recorder := record.NewFakeRecorder(10)
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(rs, testConfigMap("app-config", "shared")).Build()
+		r := &ReplicaSetReconciler{
+			Client:    c,
+			Scheme:    scheme.Scheme,
+			Config:    &config.OperatorConfig{},
+			StartTime: time.Now().Add(-time.Minute),
+			Recorder:  recorder,
+		}
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "rs-a"}})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(recorder.Events).To(gomega.HaveLen(1))
+		gomega.Expect(<-recorder.Events).To(gomega.ContainSubstring("CrossNamespaceReference"))
+
+		inv, err := r.Inventory(ctx)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(inv.CrossNamespace).To(gomega.ConsistOf(gomega.HaveField("Variable", "CONFIG")))
+	})
+})
@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/jsonpath"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+// ParseCustomKinds parses custom workload kinds of the form group/version/Kind=jsonpath, e.g.
+// example.com/v1/Worker={.spec.template}, where the JSONPath selects the pod template embedded in the objects of the
+// kind; core kinds omit the group. Their objects are read through the unstructured client, and processed again
+// whenever their spec changes.
+func ParseCustomKinds(kinds []string) ([]*WorkloadKind, error) {
+	parsed := make([]*WorkloadKind, 0, len(kinds))
+	for _, kind := range kinds {
+		gvk, path, ok := strings.Cut(kind, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid custom kind %q: expected group/version/Kind=jsonpath", kind)
+		}
+		i := strings.LastIndex(gvk, "/")
+		if i < 0 || gvk[i+1:] == "" {
+			return nil, fmt.Errorf("invalid custom kind %q: expected group/version/Kind=jsonpath", kind)
+		}
+		gv, err := schema.ParseGroupVersion(gvk[:i])
+		if err != nil || gv.Version == "" {
+			return nil, fmt.Errorf("invalid custom kind %q: invalid group/version %q", kind, gvk[:i])
+		}
+		template := jsonpath.New(gvk)
+		if err := template.Parse(path); err != nil {
+			return nil, fmt.Errorf("invalid custom kind %q: %w", kind, err)
+		}
+		parsed = append(parsed, customKind(gv.WithKind(gvk[i+1:]), template))
+	}
+	return parsed, nil
+}
+
+// customKind returns the workload kind of gvk, whose pod template template selects
+func customKind(gvk schema.GroupVersionKind, template *jsonpath.JSONPath) *WorkloadKind {
+	return &WorkloadKind{
+		Kind:         gvk.Kind,
+		GroupVersion: gvk.GroupVersion(),
+		New: func() client.Object {
+			obj := &unstructured.Unstructured{}
+			obj.SetGroupVersionKind(gvk)
+			return obj
+		},
+		NewList: func() client.ObjectList {
+			list := &unstructured.UnstructuredList{}
+			list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+			return list
+		},
+		PodSpec: func(obj client.Object) (*corev1.PodSpec, error) {
+			return customPodSpec(obj.(*unstructured.Unstructured), template)
+		},
+		Mutable: true,
+		Enabled: func(*config.OperatorConfig) bool { return true },
+	}
+}
+
+// customPodSpec returns the pod spec of the pod template template selects in obj
+func customPodSpec(obj *unstructured.Unstructured, template *jsonpath.JSONPath) (*corev1.PodSpec, error) {
+	results, err := template.FindResults(obj.UnstructuredContent())
+	if err != nil {
+		return nil, err
+	}
+	if len(results) != 1 || len(results[0]) != 1 {
+		return nil, fmt.Errorf("pod template path of %s must select a single value", obj.GetKind())
+	}
+	content, ok := results[0][0].Interface().(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("pod template path of %s must select an object", obj.GetKind())
+	}
+	var podTemplate corev1.PodTemplateSpec
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(content, &podTemplate); err != nil {
+		return nil, fmt.Errorf("invalid pod template in %s: %w", obj.GetKind(), err)
+	}
+	return &podTemplate.Spec, nil
+}
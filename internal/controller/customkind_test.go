@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+var _ = ginkgo.Describe("Custom kinds", func() {
+	ginkgo.It("Should reject malformed declarations", func() {
+		for _, kind := range []string{"example.com/v1/Worker", "Worker={.spec.template}", "example.com/v1/={.spec}",
+			"example.com/v1/Worker={.spec.template"} {
+			_, err := ParseCustomKinds([]string{kind})
+			gomega.Expect(err).To(gomega.HaveOccurred(), kind)
+		}
+	})
+
+	ginkgo.It("Should add objects of a custom kind as owners of the ConfigMaps their pod template mounts", func() {
+		ctx := context.Background()
+		kinds, err := ParseCustomKinds([]string{"example.com/v1/Worker={.spec.worker.template}"})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(kinds).To(gomega.HaveLen(1))
+
+		template, err := runtime.DefaultUnstructuredConverter.ToUnstructured(
+			&testReplicaSet("unused", "default", "worker-config").Spec.Template)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		worker := kinds[0].New().(*unstructured.Unstructured)
+		worker.SetName("queue-worker")
+		worker.SetNamespace("default")
+		worker.SetUID("queue-worker-uid")
+		gomega.Expect(unstructured.SetNestedMap(worker.Object, template, "spec", "worker", "template")).To(gomega.Succeed())
+
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).
+			WithObjects(worker, testConfigMap("worker-config", "default")).Build()
+		r := &WorkloadReconciler{
+			ReplicaSetReconciler: &ReplicaSetReconciler{
+				Client: c, Scheme: scheme.Scheme, Config: &config.OperatorConfig{AnnotateWorkloads: true},
+			},
+			Kind: kinds[0],
+		}
+		key := types.NamespacedName{Namespace: "default", Name: "queue-worker"}
+		_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+		var cm corev1.ConfigMap
+		gomega.Expect(c.Get(ctx, types.NamespacedName{Namespace: "default", Name: "worker-config"}, &cm)).To(gomega.Succeed())
+		gomega.Expect(cm.OwnerReferences).To(gomega.ConsistOf(gomega.And(
+			gomega.HaveField("APIVersion", "example.com/v1"),
+			gomega.HaveField("Kind", "Worker"),
+			gomega.HaveField("Name", "queue-worker"),
+		)))
+
+		// The scheme doesn't know the kind, so the worker is annotated as an unstructured object
+		gomega.Expect(c.Get(ctx, key, worker)).To(gomega.Succeed())
+		gomega.Expect(worker.GetAnnotations()).To(gomega.HaveKeyWithValue(BoundConfigMapsAnnotation, "worker-config"))
+	})
+})
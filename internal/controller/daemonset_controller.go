@@ -0,0 +1,44 @@
+package controller
+
+import (
+	"context"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+// DaemonSetKind is the workload kind of DaemonSets. Their pod template changes in place, so a DaemonSet is
+// processed again whenever its spec changes.
+var DaemonSetKind = &WorkloadKind{
+	Kind:         "DaemonSet",
+	GroupVersion: appsv1.SchemeGroupVersion,
+	New:          func() client.Object { return &appsv1.DaemonSet{} },
+	NewList:      func() client.ObjectList { return &appsv1.DaemonSetList{} },
+	PodSpec: func(obj client.Object) (*corev1.PodSpec, error) {
+		return &obj.(*appsv1.DaemonSet).Spec.Template.Spec, nil
+	},
+	Mutable: true,
+	Enabled: func(cfg *config.OperatorConfig) bool { return cfg.DaemonSets },
+}
+
+// DaemonSetReconciler adds DaemonSets as owners of the ConfigMaps their pod template mounts, using the same
+// extraction as for ReplicaSets
+type DaemonSetReconciler struct {
+	// ReplicaSetReconciler provides the namespace filter, write holds, write client and events
+	*ReplicaSetReconciler
+}
+
+// +kubebuilder:rbac:groups=apps,resources=daemonsets,verbs=get;list;watch
+
+func (r *DaemonSetReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	return r.workload(DaemonSetKind).Reconcile(ctx, req)
+}
+
+// SetupWithManager sets up the controller with the Manager
+func (r *DaemonSetReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return r.workload(DaemonSetKind).SetupWithManager(mgr)
+}
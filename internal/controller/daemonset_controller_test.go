@@ -0,0 +1,46 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+var _ = ginkgo.Describe("DaemonSetReconciler", func() {
+	ginkgo.It("Should add the DaemonSet as owner of the ConfigMaps it mounts", func() {
+		ctx := context.Background()
+		ds := &appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "log-shipper", Namespace: "default", UID: "log-shipper-uid"},
+			Spec:       appsv1.DaemonSetSpec{Template: testReplicaSet("unused", "default", "shipper-config").Spec.Template},
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).
+			WithObjects(ds, testConfigMap("shipper-config", "default")).Build()
+		r := &DaemonSetReconciler{
+			ReplicaSetReconciler: &ReplicaSetReconciler{Client: c, Scheme: scheme.Scheme, Config: &config.OperatorConfig{}},
+		}
+
+		key := types.NamespacedName{Namespace: "default", Name: "log-shipper"}
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+		var cm corev1.ConfigMap
+		cmKey := types.NamespacedName{Namespace: "default", Name: "shipper-config"}
+		gomega.Expect(c.Get(ctx, cmKey, &cm)).To(gomega.Succeed())
+		gomega.Expect(cm.OwnerReferences).To(gomega.ConsistOf(gomega.And(
+			gomega.HaveField("APIVersion", "apps/v1"),
+			gomega.HaveField("Kind", "DaemonSet"),
+			gomega.HaveField("Name", "log-shipper"),
+		)))
+		gomega.Expect(managedOwnerUIDs(&cm)).To(gomega.ConsistOf(ds.UID))
+	})
+})
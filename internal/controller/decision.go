@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"context"
+	"sync"
+)
+
+// Actions describing the outcome of a decision
+const (
+	decisionSkipped = "skipped"
+	decisionOwned   = "owned"
+	decisionHeld    = "held"
+	decisionAdded   = "added"
+	decisionFailed  = "failed"
+)
+
+// reasonConfigMapNotFound is the skip reason of references to ConfigMaps that don't exist
+const reasonConfigMapNotFound = "configmap_not_found"
+
+// Decision is the outcome of evaluating one ConfigMap reference of a ReplicaSet, or of a
+// filter that stopped the reconcile before any ConfigMap was evaluated. For the workload kinds,
+// ReplicaSet names the object of Kind; Kind is empty for ReplicaSets.
+type Decision struct {
+	Namespace  string `json:"namespace"`
+	ReplicaSet string `json:"replicaSet"`
+	Kind       string `json:"kind,omitempty"`
+	ConfigMap  string `json:"configMap,omitempty"`
+	Action     string `json:"action"`
+	Reason     string `json:"reason,omitempty"`
+}
+
+// decisionsKey is the context key of the decision collector
+type decisionsKey struct{}
+
+// decisionCollector gathers the decisions made while reconciling
+type decisionCollector struct {
+	mu        sync.Mutex
+	decisions []Decision
+}
+
+// withDecisions returns a context whose reconciles collect their decisions into the returned collector.
+// A context already collecting decisions is returned as is, so nested observers share its collector.
+func withDecisions(ctx context.Context) (context.Context, *decisionCollector) {
+	if collector, ok := ctx.Value(decisionsKey{}).(*decisionCollector); ok {
+		return ctx, collector
+	}
+	collector := &decisionCollector{}
+	return context.WithValue(ctx, decisionsKey{}, collector), collector
+}
+
+// recordDecision adds d to the collector of ctx, if any
+func recordDecision(ctx context.Context, d Decision) {
+	collector, ok := ctx.Value(decisionsKey{}).(*decisionCollector)
+	if !ok {
+		return
+	}
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+	collector.decisions = append(collector.decisions, d)
+}
+
+// list returns the collected decisions
+func (c *decisionCollector) list() []Decision {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Decision(nil), c.decisions...)
+}
@@ -0,0 +1,24 @@
+package controller
+
+import "slices"
+
+// reasonDenylisted is the skip reason of the ConfigMaps the operator never owns
+const reasonDenylisted = "denylisted"
+
+// builtinDenylist are the ConfigMaps Kubernetes and common platforms inject into every namespace. Their
+// controllers recreate them when they are deleted, so owning them would have them garbage collected with the
+// workload and recreated in a loop.
+var builtinDenylist = []string{
+	"kube-root-ca.crt",
+	"openshift-service-ca.crt",
+	"istio-ca-root-cert",
+}
+
+// denylisted reports whether the ConfigMap name is never owned: it is on the built-in denylist, unless
+// --disable-builtin-denylist is set, or --configmap-denylist lists it
+func (r *ReplicaSetReconciler) denylisted(name string) bool {
+	if !r.Config.DisableBuiltinDenylist && slices.Contains(builtinDenylist, name) {
+		return true
+	}
+	return slices.Contains(r.Config.ConfigMapDenylist, name)
+}
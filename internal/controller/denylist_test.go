@@ -0,0 +1,55 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+var _ = ginkgo.Describe("ConfigMap denylist", func() {
+	ginkgo.It("Should never own the injected and denylisted ConfigMaps", func() {
+		ctx := context.Background()
+		rs := testReplicaSet("web-abc", "default", "kube-root-ca.crt", "istio-ca-root-cert", "vendor-ca", "web-config")
+		rs.CreationTimestamp = metav1.Now()
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(rs,
+			testConfigMap("kube-root-ca.crt", "default"), testConfigMap("istio-ca-root-cert", "default"),
+			testConfigMap("vendor-ca", "default"), testConfigMap("web-config", "default")).Build()
+		r := &ReplicaSetReconciler{Client: c, Scheme: scheme.Scheme, Config: &config.OperatorConfig{
+			ConfigMapDenylist: []string{"vendor-ca"},
+		}}
+
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: rs.Name}})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		owners := func(name string) []metav1.OwnerReference {
+			var cm corev1.ConfigMap
+			gomega.Expect(c.Get(ctx, types.NamespacedName{Namespace: "default", Name: name}, &cm)).To(gomega.Succeed())
+			return cm.OwnerReferences
+		}
+		gomega.Expect(owners("kube-root-ca.crt")).To(gomega.BeEmpty())
+		gomega.Expect(owners("istio-ca-root-cert")).To(gomega.BeEmpty())
+		gomega.Expect(owners("vendor-ca")).To(gomega.BeEmpty())
+		gomega.Expect(owners("web-config")).To(gomega.HaveLen(1))
+
+		inv, err := r.Inventory(ctx)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(inv.Skips).To(gomega.HaveKeyWithValue(reasonDenylisted, 3))
+	})
+
+	ginkgo.It("Should own the injected ConfigMaps with the built-in denylist disabled", func() {
+		r := &ReplicaSetReconciler{Config: &config.OperatorConfig{}}
+		gomega.Expect(r.denylisted("openshift-service-ca.crt")).To(gomega.BeTrue())
+		r.Config.DisableBuiltinDenylist = true
+		gomega.Expect(r.denylisted("openshift-service-ca.crt")).To(gomega.BeFalse())
+		r.Config.ConfigMapDenylist = []string{"openshift-service-ca.crt"}
+		gomega.Expect(r.denylisted("openshift-service-ca.crt")).To(gomega.BeTrue())
+	})
+})
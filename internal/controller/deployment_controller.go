@@ -0,0 +1,139 @@
+package controller
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// Precomputation holds the ConfigMaps each Deployment's pod template mounts, computed before the Deployment's
+// ReplicaSets exist
+type Precomputation struct {
+	// Reader reads ConfigMaps the cache hasn't caught up with yet, bypassing it
+	Reader client.Reader
+
+	mu          sync.RWMutex
+	deployments map[types.NamespacedName][]string
+}
+
+// NewPrecomputation returns an empty precomputation reading missed ConfigMaps through reader
+func NewPrecomputation(reader client.Reader) *Precomputation {
+	return &Precomputation{Reader: reader, deployments: make(map[types.NamespacedName][]string)}
+}
+
+func (p *Precomputation) set(deployment types.NamespacedName, configMaps []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.deployments[deployment] = configMaps
+}
+
+func (p *Precomputation) forget(deployment types.NamespacedName) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.deployments, deployment)
+}
+
+// expected returns the ConfigMaps precomputed for the Deployment controlling rs, and false if there are none
+func (p *Precomputation) expected(rs *appsv1.ReplicaSet) ([]string, bool) {
+	owner := metav1.GetControllerOf(rs)
+	if owner == nil || owner.Kind != "Deployment" {
+		return nil, false
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	configMaps, ok := p.deployments[types.NamespacedName{Namespace: rs.Namespace, Name: owner.Name}]
+	return configMaps, ok
+}
+
+// observe counts whether the ConfigMaps of rs were precomputed from its Deployment
+func (p *Precomputation) observe(rs *appsv1.ReplicaSet, configMaps []string) {
+	expected, ok := p.expected(rs)
+	switch {
+	case !ok:
+		precomputedTotal.WithLabelValues("miss").Inc()
+	case slices.Equal(expected, configMaps):
+		precomputedTotal.WithLabelValues("hit").Inc()
+	default:
+		precomputedTotal.WithLabelValues("stale").Inc()
+	}
+}
+
+// getConfigMap reads the ConfigMap key from the cache. A ConfigMap the Deployment of rs was precomputed to mount
+// but the cache doesn't have yet may have been created just before the ReplicaSet, so it is read once more
+// from the API server instead of being skipped. rs is nil for the workload kinds, which aren't precomputed.
+func (r *ReplicaSetReconciler) getConfigMap(
+	ctx context.Context,
+	key types.NamespacedName,
+	rs *appsv1.ReplicaSet,
+	cm *corev1.ConfigMap,
+) error {
+	err := r.Get(ctx, key, cm)
+	if !errors.IsNotFound(err) || rs == nil || r.Precomputed == nil || r.Precomputed.Reader == nil {
+		return err
+	}
+	if expected, ok := r.Precomputed.expected(rs); !ok || !slices.Contains(expected, key.Name) {
+		return err
+	}
+	return r.Precomputed.Reader.Get(ctx, key, cm)
+}
+
+// DeploymentReconciler precomputes the ConfigMaps each Deployment mounts, reading them through the cache so
+// the ConfigMap informer is warm and holds them by the time the Deployment's ReplicaSet is created. It never writes.
+type DeploymentReconciler struct {
+	// ReplicaSetReconciler provides the namespace filter, the cached client and the precomputation
+	*ReplicaSetReconciler
+}
+
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch
+
+func (r *DeploymentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	if !r.shouldProcessNamespace(ctx, req.Namespace) {
+		return ctrl.Result{}, nil
+	}
+
+	var deployment appsv1.Deployment
+	if err := r.Get(ctx, req.NamespacedName, &deployment); err != nil {
+		if errors.IsNotFound(err) {
+			r.Precomputed.forget(req.NamespacedName)
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	template := &deployment.Spec.Template
+	configMaps := withExtraConfigMaps(r.podConfigMaps(&template.Spec, template),
+		deployment.Annotations, template.Annotations)
+	for _, name := range configMaps {
+		var cm corev1.ConfigMap
+		err := r.Get(ctx, types.NamespacedName{Namespace: req.Namespace, Name: name}, &cm)
+		if err != nil && !errors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+	}
+	r.Precomputed.set(req.NamespacedName, configMaps)
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager watches every Deployment, including those that exist at startup, and template changes
+func (r *DeploymentReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&appsv1.Deployment{}).
+		WithEventFilter(predicate.Funcs{
+			CreateFunc:  func(event.CreateEvent) bool { return true },
+			UpdateFunc:  func(e event.UpdateEvent) bool { return e.ObjectNew.GetGeneration() != e.ObjectOld.GetGeneration() },
+			DeleteFunc:  func(event.DeleteEvent) bool { return true },
+			GenericFunc: func(event.GenericEvent) bool { return false },
+		}).
+		WithOptions(r.controllerOptions()).
+		Complete(r.tracked("deployment", r))
+}
@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+var _ = ginkgo.Describe("DeploymentReconciler", func() {
+	key := types.NamespacedName{Namespace: "default", Name: "web"}
+
+	newDeployment := func() *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace, UID: "web-uid"},
+			Spec:       appsv1.DeploymentSpec{Template: testReplicaSet("unused", "default", "cm-a").Spec.Template},
+		}
+	}
+	newReplicaSet := func() *appsv1.ReplicaSet {
+		isController := true
+		rs := testReplicaSet("web-abc", "default", "cm-a")
+		rs.CreationTimestamp = metav1.Now()
+		rs.OwnerReferences = []metav1.OwnerReference{{
+			APIVersion: "apps/v1", Kind: "Deployment", Name: "web", UID: "web-uid", Controller: &isController,
+		}}
+		return rs
+	}
+
+	ginkgo.It("Should read a precomputed ConfigMap the cache hasn't seen yet from the API server", func() {
+		ctx := context.Background()
+		rs := newReplicaSet()
+		cache := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(newDeployment(), rs).Build()
+		api := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(testConfigMap("cm-a", "default")).Build()
+		r := &ReplicaSetReconciler{Client: cache, Scheme: scheme.Scheme, Config: &config.OperatorConfig{},
+			Writer: api, Precomputed: NewPrecomputation(api)}
+
+		_, err := (&DeploymentReconciler{ReplicaSetReconciler: r}).Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		expected, ok := r.Precomputed.expected(rs)
+		gomega.Expect(ok).To(gomega.BeTrue())
+		gomega.Expect(expected).To(gomega.Equal([]string{"cm-a"}))
+
+		_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: rs.Name}})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		var cm corev1.ConfigMap
+		gomega.Expect(api.Get(ctx, types.NamespacedName{Namespace: "default", Name: "cm-a"}, &cm)).To(gomega.Succeed())
+		gomega.Expect(cm.OwnerReferences).To(gomega.HaveLen(1))
+	})
+
+	ginkgo.It("Should forget deleted Deployments", func() {
+		ctx := context.Background()
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+		r := &ReplicaSetReconciler{Client: c, Scheme: scheme.Scheme, Config: &config.OperatorConfig{},
+			Precomputed: NewPrecomputation(c)}
+		r.Precomputed.set(key, []string{"cm-a"})
+
+		_, err := (&DeploymentReconciler{ReplicaSetReconciler: r}).Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		_, ok := r.Precomputed.expected(newReplicaSet())
+		gomega.Expect(ok).To(gomega.BeFalse())
+	})
+})
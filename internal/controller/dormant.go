@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"context"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// ignoreReason returns why rs is left alone regardless of its ConfigMaps: its workload name isn't selected, it
+// hasn't opted in or has opted out, it is owned by a kind --skip-owner-kinds lists, or dormant; or an empty string
+// if it is processed
+func (r *ReplicaSetReconciler) ignoreReason(ctx context.Context, rs *appsv1.ReplicaSet) (string, error) {
+	if reason := r.filterReason(rs, replicaSetWorkloadName(rs), rs.Spec.Template.Annotations); reason != "" {
+		return reason, nil
+	}
+	if dormant, err := r.dormant(ctx, rs); err != nil || !dormant {
+		return "", err
+	}
+	return dropReasonDormant, nil
+}
+
+// filterReason returns why obj, part of the workload named name, is left alone by the workload name filter, the
+// opt-in and opt-out annotations of obj and its pod template, or --skip-owner-kinds; or an empty string. Every
+// kind is filtered this way, while only ReplicaSets can be dormant.
+func (r *ReplicaSetReconciler) filterReason(obj metav1.Object, name string, template map[string]string) string {
+	switch {
+	case !r.selectsWorkloadName(name):
+		return dropReasonWorkloadName
+	case !r.optedIn(obj.GetAnnotations(), template):
+		return dropReasonOptIn
+	case optedOut(obj.GetAnnotations(), template):
+		return dropReasonOptOut
+	case r.skippedOwner(obj) != nil:
+		return dropReasonOwnerKind
+	}
+	return ""
+}
+
+// dormant reports whether --skip-dormant leaves rs alone: it is scaled to zero, or controlled by a paused
+// Deployment. A Deployment that no longer exists doesn't make rs dormant.
+func (r *ReplicaSetReconciler) dormant(ctx context.Context, rs *appsv1.ReplicaSet) (bool, error) {
+	if !r.Config.SkipDormant {
+		return false, nil
+	}
+	if replicas(rs) == 0 {
+		return true, nil
+	}
+	ref := deploymentOf(rs)
+	if ref == nil {
+		return false, nil
+	}
+	var deployment appsv1.Deployment
+	if err := r.Get(ctx, types.NamespacedName{Namespace: rs.Namespace, Name: ref.Name}, &deployment); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return deployment.UID == ref.UID && deployment.Spec.Paused, nil
+}
@@ -0,0 +1,58 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+var _ = ginkgo.Describe("Dormant workloads", func() {
+	ginkgo.It("Should leave alone ReplicaSets scaled to zero and those of paused Deployments", func() {
+		ctx := context.Background()
+		scaledDown := testRevision("web-1", 0, "web-config")
+		scaledDown.CreationTimestamp = metav1.Now()
+		paused := testReplicaSet("api-1", "default", "api-config")
+		paused.CreationTimestamp = metav1.Now()
+		isController := true
+		paused.OwnerReferences = []metav1.OwnerReference{{
+			APIVersion: "apps/v1", Kind: "Deployment", Name: "api", UID: "api-uid", Controller: &isController,
+		}}
+		deployment := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default", UID: "api-uid"},
+			Spec:       appsv1.DeploymentSpec{Paused: true},
+		}
+		running := testReplicaSet("db-1", "default", "db-config")
+		running.CreationTimestamp = metav1.Now()
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(scaledDown, paused, running, deployment,
+			testConfigMap("web-config", "default"), testConfigMap("api-config", "default"),
+			testConfigMap("db-config", "default")).Build()
+		r := &ReplicaSetReconciler{Client: c, Scheme: scheme.Scheme, Config: &config.OperatorConfig{SkipDormant: true}}
+
+		for _, name := range []string{"web-1", "api-1", "db-1"} {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: name}})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		}
+		owners := func(name string) []metav1.OwnerReference {
+			var cm corev1.ConfigMap
+			gomega.Expect(c.Get(ctx, types.NamespacedName{Namespace: "default", Name: name}, &cm)).To(gomega.Succeed())
+			return cm.OwnerReferences
+		}
+		gomega.Expect(owners("web-config")).To(gomega.BeEmpty())
+		gomega.Expect(owners("api-config")).To(gomega.BeEmpty())
+		gomega.Expect(owners("db-config")).To(gomega.HaveLen(1))
+
+		inv, err := r.Inventory(ctx)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(inv.Skips).To(gomega.HaveKeyWithValue(dropReasonDormant, 2))
+	})
+})
@@ -0,0 +1,236 @@
+package controller
+
+import (
+	"cmp"
+	"context"
+	"maps"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Kinds of drift between the provenance annotation and the owner references of a ConfigMap
+const (
+	// DriftReferenceRemoved is a recorded owner that still exists but whose owner reference was removed
+	DriftReferenceRemoved = "reference_removed"
+
+	// DriftUIDChanged is an operator-added owner reference whose owner was recreated under the same name,
+	// so the reference points to an object that no longer exists and the ConfigMap is due for collection
+	DriftUIDChanged = "uid_changed"
+
+	// DriftAnnotationOrphaned is a recorded owner UID with neither an owner reference nor a live owner
+	DriftAnnotationOrphaned = "annotation_orphaned"
+)
+
+// driftTypes lists the drift kinds, so the metric reports zero for the kinds a scan didn't find
+var driftTypes = []string{DriftReferenceRemoved, DriftUIDChanged, DriftAnnotationOrphaned}
+
+// Drift is a disagreement between what the operator recorded adding to a ConfigMap and its owner references
+type Drift struct {
+	Namespace string    `json:"namespace"`
+	ConfigMap string    `json:"configMap"`
+	Type      string    `json:"type"`
+	UID       types.UID `json:"uid"`
+
+	// Owner is the kind/name of the owner, empty when no live object or reference carries the UID
+	Owner string `json:"owner,omitempty"`
+}
+
+// liveOwners indexes the objects the operator adds as owners by UID and by kind, namespace and name
+type liveOwners struct {
+	byUID  map[types.UID]metav1.OwnerReference
+	byName map[string]types.UID
+}
+
+func ownerKey(kind, namespace, name string) string {
+	return kind + "/" + namespace + "/" + name
+}
+
+func (o *liveOwners) add(apiVersion, kind string, obj metav1.Object) {
+	o.byUID[obj.GetUID()] = metav1.OwnerReference{
+		APIVersion: apiVersion, Kind: kind, Name: obj.GetName(), UID: obj.GetUID(),
+	}
+	o.byName[ownerKey(kind, obj.GetNamespace(), obj.GetName())] = obj.GetUID()
+}
+
+// listOwners returns the live objects of the kinds the operator adds as owners of ConfigMaps
+func (r *ReplicaSetReconciler) listOwners(ctx context.Context) (*liveOwners, error) {
+	owners := &liveOwners{byUID: map[types.UID]metav1.OwnerReference{}, byName: map[string]types.UID{}}
+	if r.Config.WatchesReplicaSets() {
+		var replicaSets appsv1.ReplicaSetList
+		if err := r.List(ctx, &replicaSets); err != nil {
+			return nil, err
+		}
+		for i := range replicaSets.Items {
+			owners.add(appsv1.SchemeGroupVersion.String(), "ReplicaSet", &replicaSets.Items[i])
+		}
+	}
+	if r.ownsThroughDeployments() {
+		var deployments appsv1.DeploymentList
+		if err := r.List(ctx, &deployments); err != nil {
+			return nil, err
+		}
+		for i := range deployments.Items {
+			owners.add(appsv1.SchemeGroupVersion.String(), "Deployment", &deployments.Items[i])
+		}
+	}
+	return r.listWorkloadOwners(ctx, owners)
+}
+
+// listWorkloadOwners adds the live objects of the enabled workload kinds to owners
+func (r *ReplicaSetReconciler) listWorkloadOwners(ctx context.Context, owners *liveOwners) (*liveOwners, error) {
+	for _, kind := range EnabledWorkloadKinds(r.Config) {
+		objs, err := kind.list(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range objs {
+			owners.add(kind.GroupVersion.String(), kind.Kind, obj)
+		}
+	}
+	return owners, nil
+}
+
+// ScanDrift compares the provenance annotation of every ConfigMap with its owner references and the live
+// owners, and returns the drifts sorted by namespace and ConfigMap
+func (r *ReplicaSetReconciler) ScanDrift(ctx context.Context) ([]Drift, error) {
+	var configMaps corev1.ConfigMapList
+	if err := r.List(ctx, &configMaps); err != nil {
+		return nil, err
+	}
+	owners, err := r.listOwners(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	drifts := []Drift{}
+	for i := range configMaps.Items {
+		drifts = append(drifts, configMapDrift(&configMaps.Items[i], owners)...)
+	}
+	sort.SliceStable(drifts, func(i, j int) bool {
+		a, b := drifts[i], drifts[j]
+		return a.Namespace < b.Namespace || a.Namespace == b.Namespace && a.ConfigMap < b.ConfigMap
+	})
+	return drifts, nil
+}
+
+// configMapDrift returns the drifts of one ConfigMap
+func configMapDrift(cm *corev1.ConfigMap, owners *liveOwners) []Drift {
+	var drifts []Drift
+	for _, uid := range managedOwnerUIDs(cm) {
+		drift := Drift{Namespace: cm.Namespace, ConfigMap: cm.Name, UID: uid}
+		i := slices.IndexFunc(cm.OwnerReferences, func(ref metav1.OwnerReference) bool { return ref.UID == uid })
+		if i < 0 {
+			drift.Type = DriftAnnotationOrphaned
+			if owner, ok := owners.byUID[uid]; ok {
+				drift.Type, drift.Owner = DriftReferenceRemoved, owner.Kind+"/"+owner.Name
+			}
+			drifts = append(drifts, drift)
+			continue
+		}
+		ref := cm.OwnerReferences[i]
+		live, ok := owners.byName[ownerKey(ref.Kind, cm.Namespace, ref.Name)]
+		if ok && live != uid {
+			drift.Type, drift.Owner = DriftUIDChanged, ref.Kind+"/"+ref.Name
+			drifts = append(drifts, drift)
+		}
+	}
+	return drifts
+}
+
+// driftsStateKey is the state store key of the drifts already reported
+const driftsStateKey = "drifts"
+
+// DriftScanner periodically scans for drift, exports the drift per kind as a metric and emits a Warning
+// Event on a ConfigMap the first time a drift is found on it. It needs leader election, so Events aren't
+// duplicated across replicas, and keeps the drifts it reported in the state store, so a new leader doesn't
+// report them again.
+type DriftScanner struct {
+	Reconciler *ReplicaSetReconciler
+	Interval   time.Duration
+	Log        logr.Logger
+
+	seen map[Drift]bool
+}
+
+// Start implements manager.Runnable
+func (s *DriftScanner) Start(ctx context.Context) error {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+	for {
+		if err := s.scan(ctx); err != nil {
+			s.Log.Error(err, "Failed to scan for drift")
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// scan runs one scan and reports its drifts
+func (s *DriftScanner) scan(ctx context.Context) error {
+	drifts, err := s.Reconciler.ScanDrift(ctx)
+	if err != nil {
+		return err
+	}
+
+	if s.seen == nil {
+		s.seen = s.loadSeen(ctx)
+	}
+	counts := map[string]int{}
+	seen := make(map[Drift]bool, len(drifts))
+	for _, d := range drifts {
+		counts[d.Type]++
+		seen[d] = true
+		if s.seen[d] {
+			continue
+		}
+		s.Log.Info("Ownership drift detected", "configmap", d.ConfigMap, "namespace", d.Namespace,
+			"type", d.Type, "uid", d.UID, "owner", d.Owner)
+		var cm corev1.ConfigMap
+		key := types.NamespacedName{Namespace: d.Namespace, Name: d.ConfigMap}
+		if err := s.Reconciler.Get(ctx, key, &cm); err != nil {
+			if client.IgnoreNotFound(err) != nil {
+				s.Log.Error(err, "Failed to read drifted ConfigMap", "configmap", key)
+			}
+			continue
+		}
+		s.Reconciler.recordEvent(&cm, corev1.EventTypeWarning, "OwnershipDrift",
+			"Owner %s (%s) recorded by the operator drifted: %s", d.UID, cmp.Or(d.Owner, "no live owner"), d.Type)
+	}
+	for _, t := range driftTypes {
+		ownershipDrifts.WithLabelValues(t).Set(float64(counts[t]))
+	}
+	if !maps.Equal(seen, s.seen) && s.Reconciler.State != nil {
+		if err := s.Reconciler.State.Save(ctx, driftsStateKey, slices.Collect(maps.Keys(seen))); err != nil {
+			s.Log.Error(err, "Failed to save the reported drifts")
+		}
+	}
+	s.seen = seen
+	return nil
+}
+
+// loadSeen returns the drifts reported before, by this or an earlier leader
+func (s *DriftScanner) loadSeen(ctx context.Context) map[Drift]bool {
+	seen := map[Drift]bool{}
+	if s.Reconciler.State == nil {
+		return seen
+	}
+	var drifts []Drift
+	if _, err := s.Reconciler.State.Load(ctx, driftsStateKey, &drifts); err != nil {
+		s.Log.Error(err, "Failed to load the reported drifts, reporting them again")
+	}
+	for _, d := range drifts {
+		seen[d] = true
+	}
+	return seen
+}
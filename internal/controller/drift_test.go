@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+	"github.com/matanbaruch/configmap-rs-operator/internal/state"
+)
+
+var _ = ginkgo.Describe("Drift", func() {
+	ginkgo.It("Should report removed references, changed UIDs and orphaned annotations", func() {
+		ctx := context.Background()
+		owned := func(name string, refs []metav1.OwnerReference, uids ...types.UID) *corev1.ConfigMap {
+			cm := testConfigMap(name, "default")
+			cm.OwnerReferences = refs
+			setManagedOwnerUIDs(cm, uids)
+			return cm
+		}
+		ref := func(name string, uid types.UID) metav1.OwnerReference {
+			return metav1.OwnerReference{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: name, UID: uid}
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+			testReplicaSet("web", "default"), testReplicaSet("api", "default"),
+			owned("consistent", []metav1.OwnerReference{ref("web", "web-uid")}, "web-uid"),
+			owned("removed", nil, "web-uid"),
+			owned("recreated", []metav1.OwnerReference{ref("api", "old-api-uid")}, "old-api-uid"),
+			owned("orphaned", nil, "gone-uid"),
+		).Build()
+		recorder := record.NewFakeRecorder(10)
+		r := &ReplicaSetReconciler{
+			Client: c, Scheme: scheme.Scheme, Recorder: recorder, Config: &config.OperatorConfig{},
+			State: &state.MemoryStore{},
+		}
+
+		drifts, err := r.ScanDrift(ctx)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(drifts).To(gomega.Equal([]Drift{
+			{Namespace: "default", ConfigMap: "orphaned", Type: DriftAnnotationOrphaned, UID: "gone-uid"},
+			{Namespace: "default", ConfigMap: "recreated", Type: DriftUIDChanged, UID: "old-api-uid", Owner: "ReplicaSet/api"},
+			{Namespace: "default", ConfigMap: "removed", Type: DriftReferenceRemoved, UID: "web-uid", Owner: "ReplicaSet/web"},
+		}))
+
+		scanner := &DriftScanner{Reconciler: r, Log: logr.Discard()}
+		gomega.Expect(scanner.scan(ctx)).To(gomega.Succeed())
+		gomega.Expect(recorder.Events).To(gomega.HaveLen(3))
+		gomega.Expect(<-recorder.Events).To(gomega.ContainSubstring("OwnershipDrift"))
+
+		// Drifts already reported aren't reported again
+		gomega.Expect(scanner.scan(ctx)).To(gomega.Succeed())
+		gomega.Expect(recorder.Events).To(gomega.HaveLen(2))
+
+		// Nor by a new leader sharing the state store
+		next := &DriftScanner{Reconciler: r, Log: logr.Discard()}
+		gomega.Expect(next.scan(ctx)).To(gomega.Succeed())
+		gomega.Expect(recorder.Events).To(gomega.HaveLen(2))
+	})
+})
+
+var _ = ginkgo.Describe("Repair", func() {
+	ginkgo.It("Should re-add removed references and drop orphaned annotations", func() {
+		ctx := context.Background()
+		removed := testConfigMap("removed", "default")
+		setManagedOwnerUIDs(removed, []types.UID{"web-uid"})
+		orphaned := testConfigMap("orphaned", "default")
+		setManagedOwnerUIDs(orphaned, []types.UID{"gone-uid"})
+		other := testConfigMap("other", "team-a")
+		setManagedOwnerUIDs(other, []types.UID{"gone-uid"})
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).
+			WithObjects(testReplicaSet("web", "default"), removed, orphaned, other).Build()
+		r := &ReplicaSetReconciler{Client: c, Scheme: scheme.Scheme, Config: &config.OperatorConfig{}}
+
+		repaired, err := r.Repair(ctx, RepairOptions{Namespace: "default", DryRun: true}, logr.Discard())
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(repaired).To(gomega.HaveLen(2))
+		gomega.Expect(c.Get(ctx, types.NamespacedName{Namespace: "default", Name: "removed"}, removed)).To(gomega.Succeed())
+		gomega.Expect(removed.OwnerReferences).To(gomega.BeEmpty())
+
+		repaired, err = r.Repair(ctx, RepairOptions{Namespace: "default"}, logr.Discard())
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(repaired).To(gomega.HaveLen(2))
+		gomega.Expect(c.Get(ctx, types.NamespacedName{Namespace: "default", Name: "removed"}, removed)).To(gomega.Succeed())
+		gomega.Expect(removed.OwnerReferences).To(gomega.ConsistOf(gomega.And(
+			gomega.HaveField("Kind", "ReplicaSet"), gomega.HaveField("Name", "web"),
+			gomega.HaveField("UID", types.UID("web-uid")))))
+		gomega.Expect(c.Get(ctx, types.NamespacedName{Namespace: "default", Name: "orphaned"}, orphaned)).To(gomega.Succeed())
+		gomega.Expect(orphaned.Annotations).NotTo(gomega.HaveKey(ManagedOwnersAnnotation))
+
+		drifts, err := r.ScanDrift(ctx)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(drifts).To(gomega.ConsistOf(gomega.HaveField("Namespace", "team-a")))
+	})
+
+	ginkgo.It("Should reject drift types it can't repair", func() {
+		gomega.Expect(ValidateRepairTypes([]string{DriftUIDChanged})).NotTo(gomega.Succeed())
+	})
+})
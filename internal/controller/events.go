@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/flowcontrol"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+// filteringRecorder applies the operator's event configuration on top of a record.EventRecorder
+type filteringRecorder struct {
+	recorder record.EventRecorder
+	types    map[string]bool
+	limiter  flowcontrol.RateLimiter
+}
+
+// ValidateEventTypes returns an error for types --event-types doesn't accept; a misspelled type would silently
+// drop every Event of the type meant
+func ValidateEventTypes(types []string) error {
+	for _, t := range types {
+		if t != corev1.EventTypeNormal && t != corev1.EventTypeWarning {
+			return fmt.Errorf("invalid Event type %q: expected %s or %s", t, corev1.EventTypeNormal,
+				corev1.EventTypeWarning)
+		}
+	}
+	return nil
+}
+
+// NewEventRecorder wraps recorder so that Events are dropped when disabled, filtered by type
+// and rate limited according to the operator configuration
+func NewEventRecorder(recorder record.EventRecorder, cfg *config.OperatorConfig) record.EventRecorder {
+	r := &filteringRecorder{recorder: recorder}
+	switch {
+	case !cfg.EventsEnabled:
+		// An empty allow-list drops every Event
+		r.types = map[string]bool{}
+	case len(cfg.EventTypes) > 0:
+		r.types = make(map[string]bool, len(cfg.EventTypes))
+		for _, t := range cfg.EventTypes {
+			r.types[t] = true
+		}
+	}
+	if cfg.EventQPS > 0 {
+		r.limiter = flowcontrol.NewTokenBucketRateLimiter(float32(cfg.EventQPS), max(cfg.EventBurst, 1))
+	}
+	return r
+}
+
+func (r *filteringRecorder) allow(eventtype string) bool {
+	if r.types != nil && !r.types[eventtype] {
+		return false
+	}
+	return r.limiter == nil || r.limiter.TryAccept()
+}
+
+func (r *filteringRecorder) Event(object runtime.Object, eventtype, reason, message string) {
+	if r.allow(eventtype) {
+		r.recorder.Event(object, eventtype, reason, message)
+	}
+}
+
+func (r *filteringRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	if r.allow(eventtype) {
+		r.recorder.Eventf(object, eventtype, reason, messageFmt, args...)
+	}
+}
+
+func (r *filteringRecorder) AnnotatedEventf(
+	object runtime.Object,
+	annotations map[string]string,
+	eventtype, reason, messageFmt string,
+	args ...interface{},
+) {
+	if r.allow(eventtype) {
+		r.recorder.AnnotatedEventf(object, annotations, eventtype, reason, messageFmt, args...)
+	}
+}
@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+var _ = ginkgo.Describe("EventRecorder", func() {
+	var (
+		fakeRecorder *record.FakeRecorder
+		configMap    *corev1.ConfigMap
+	)
+
+	ginkgo.BeforeEach(func() {
+		fakeRecorder = record.NewFakeRecorder(10)
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-config", Namespace: "default"},
+		}
+	})
+
+	ginkgo.It("Should drop all Events when disabled", func() {
+		recorder := NewEventRecorder(fakeRecorder, &config.OperatorConfig{EventsEnabled: false})
+		recorder.Event(configMap, corev1.EventTypeWarning, "Test", "message")
+
+		gomega.Expect(fakeRecorder.Events).To(gomega.BeEmpty())
+	})
+
+	ginkgo.It("Should only emit configured Event types", func() {
+		recorder := NewEventRecorder(fakeRecorder, &config.OperatorConfig{
+			EventsEnabled: true,
+			EventTypes:    []string{corev1.EventTypeWarning},
+		})
+		recorder.Event(configMap, corev1.EventTypeNormal, "Test", "normal")
+		recorder.Event(configMap, corev1.EventTypeWarning, "Test", "warning")
+
+		gomega.Expect(fakeRecorder.Events).To(gomega.HaveLen(1))
+		gomega.Expect(<-fakeRecorder.Events).To(gomega.ContainSubstring("warning"))
+	})
+
+	ginkgo.It("Should reject unknown Event types", func() {
+		gomega.Expect(ValidateEventTypes(nil)).To(gomega.Succeed())
+		gomega.Expect(ValidateEventTypes([]string{corev1.EventTypeNormal, corev1.EventTypeWarning})).To(gomega.Succeed())
+		gomega.Expect(ValidateEventTypes([]string{"warning"})).To(gomega.MatchError(gomega.ContainSubstring("warning")))
+	})
+
+	ginkgo.It("Should rate limit Events beyond the burst", func() {
+		recorder := NewEventRecorder(fakeRecorder, &config.OperatorConfig{
+			EventsEnabled: true,
+			EventQPS:      0.001,
+			EventBurst:    2,
+		})
+		for i := 0; i < 5; i++ {
+			recorder.Eventf(configMap, corev1.EventTypeNormal, "Test", "event %d", i)
+		}
+
+		gomega.Expect(fakeRecorder.Events).To(gomega.HaveLen(2))
+	})
+})
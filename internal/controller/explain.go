@@ -0,0 +1,184 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Explanation is the decision trace for a single ConfigMap
+type Explanation struct {
+	ConfigMap types.NamespacedName `json:"configMap"`
+
+	// Exists reports whether the ConfigMap exists; workloads may reference missing ConfigMaps
+	Exists bool `json:"exists"`
+
+	// Owners lists the current owner references, marking those added by the operator
+	Owners []ExplainedOwner `json:"owners,omitempty"`
+
+	// Checks are the namespace-wide checks that apply to every workload
+	Checks []ExplainedCheck `json:"checks"`
+
+	// Workloads lists every workload referencing the ConfigMap with the decision taken for it
+	Workloads []ExplainedWorkload `json:"workloads"`
+}
+
+// ExplainedOwner is an owner reference of the explained ConfigMap
+type ExplainedOwner struct {
+	Kind    string `json:"kind"`
+	Name    string `json:"name"`
+	Managed bool   `json:"managed"`
+}
+
+// ExplainedCheck is a filter or safety check and its outcome
+type ExplainedCheck struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// ExplainedWorkload is a workload referencing the explained ConfigMap
+type ExplainedWorkload struct {
+	Kind     string           `json:"kind"`
+	Name     string           `json:"name"`
+	Checks   []ExplainedCheck `json:"checks"`
+	Decision string           `json:"decision"`
+	Reason   string           `json:"reason"`
+}
+
+// Explain returns the full decision trace for the ConfigMap key without writing anything: which
+// workloads reference it, which filters and safety checks apply, and whether ownership is added
+func (r *ReplicaSetReconciler) Explain(ctx context.Context, key types.NamespacedName) (*Explanation, error) {
+	e := &Explanation{ConfigMap: key}
+
+	var cm corev1.ConfigMap
+	switch err := r.Get(ctx, key, &cm); {
+	case err == nil:
+		e.Exists = true
+		managed := managedOwnerUIDs(&cm)
+		for _, ref := range cm.OwnerReferences {
+			e.Owners = append(e.Owners, ExplainedOwner{
+				Kind: ref.Kind, Name: ref.Name, Managed: slices.Contains(managed, ref.UID),
+			})
+		}
+	case !errors.IsNotFound(err):
+		return nil, err
+	}
+
+	inScope := r.shouldProcessNamespace(ctx, key.Namespace)
+	detail := fmt.Sprintf("namespace regex %v", r.Config.NamespaceRegex)
+	if len(r.Config.NamespaceExclude) > 0 {
+		detail += fmt.Sprintf(", excluding %v", r.Config.NamespaceExclude)
+	}
+	e.Checks = append(e.Checks, ExplainedCheck{Name: dropReasonNamespace, Passed: inScope, Detail: detail})
+	hold := r.holdReason(ctx, key.Namespace, time.Now(), logr.Discard())
+	e.Checks = append(e.Checks, ExplainedCheck{Name: "writes_allowed", Passed: hold == "", Detail: hold})
+
+	var list appsv1.ReplicaSetList
+	if err := r.List(ctx, &list, client.InNamespace(key.Namespace)); err != nil {
+		return nil, err
+	}
+	for i := range list.Items {
+		rs := &list.Items[i]
+		if !slices.Contains(r.extractConfigMapVolumes(rs), key.Name) {
+			continue
+		}
+		optional, err := r.optionalSkipReason(ctx, rs, key.Name)
+		if err != nil {
+			return nil, err
+		}
+		e.Workloads = append(e.Workloads, r.explainWorkload(ctx, rs, &cm, e.Exists, inScope, hold, optional))
+	}
+	return e, nil
+}
+
+// explainWorkload evaluates the checks of a single workload in the order the reconciler applies them;
+// optional is the skip reason of an optional reference, if any
+func (r *ReplicaSetReconciler) explainWorkload(
+	ctx context.Context,
+	rs *appsv1.ReplicaSet,
+	cm *corev1.ConfigMap,
+	exists, inScope bool,
+	hold, optional string,
+) ExplainedWorkload {
+	w := ExplainedWorkload{Kind: "ReplicaSet", Name: rs.Name}
+	decide := func(decision, reason string) ExplainedWorkload {
+		w.Decision, w.Reason = decision, reason
+		return w
+	}
+
+	if !inScope {
+		return decide(decisionSkipped, "namespace is not selected by the namespace regex")
+	}
+
+	createdAfterStart := !rs.CreationTimestamp.Time.Before(r.StartTime)
+	w.Checks = append(w.Checks, ExplainedCheck{
+		Name: dropReasonStartTime, Passed: createdAfterStart,
+		Detail: fmt.Sprintf("created %s, operator started %s",
+			rs.CreationTimestamp.UTC().Format(time.RFC3339), r.StartTime.UTC().Format(time.RFC3339)),
+	})
+	if !createdAfterStart {
+		return decide(decisionSkipped, "ReplicaSet was created before the operator started")
+	}
+
+	if !exists {
+		return decide(decisionSkipped, "ConfigMap does not exist")
+	}
+
+	if len(r.OwnerRules) > 0 {
+		strategy := r.ownerStrategy(cm.Name)
+		w.Checks = append(w.Checks, ExplainedCheck{Name: reasonOwnerRule, Passed: strategy != OwnerSkip, Detail: strategy})
+		if strategy == OwnerSkip {
+			return decide(decisionSkipped, "an owner rule excludes the ConfigMap")
+		}
+	}
+
+	owned := r.isOwnerReferencePresent(cm, rs)
+	w.Checks = append(w.Checks, ExplainedCheck{Name: "not_already_owned", Passed: !owned})
+	if owned {
+		return decide(decisionOwned, "ReplicaSet already owns the ConfigMap")
+	}
+
+	if r.Config.MaxExistingOwners > 0 {
+		tooMany := r.blockedByOwnerLimit(ctx, cm, rs)
+		w.Checks = append(w.Checks, ExplainedCheck{
+			Name: "existing_owners", Passed: !tooMany,
+			Detail: fmt.Sprintf("%d owner references, at most %d", len(cm.OwnerReferences), r.Config.MaxExistingOwners),
+		})
+		if tooMany {
+			return decide(decisionSkipped, "ConfigMap already has too many owners")
+		}
+	}
+
+	if r.Config.ConsumedKeysOnly {
+		consumed := consumesConfigMap(&rs.Spec.Template.Spec, cm)
+		w.Checks = append(w.Checks, ExplainedCheck{Name: "keys_consumed", Passed: consumed})
+		if !consumed {
+			return decide(decisionSkipped, "ReplicaSet consumes none of the ConfigMap's keys")
+		}
+	}
+
+	if r.Config.OptionalReferences != "" && r.Config.OptionalReferences != OptionalOwn {
+		w.Checks = append(w.Checks, ExplainedCheck{Name: "optional_reference", Passed: optional == "", Detail: optional})
+		if optional != "" {
+			return decide(decisionSkipped, "optional reference not owned with --optional-references="+
+				r.Config.OptionalReferences)
+		}
+	}
+
+	if hold != "" {
+		return decide(decisionHeld, "writes are held: "+hold)
+	}
+	if r.Config.RequireApproval {
+		return decide(decisionHeld, "the owner reference awaits approval")
+	}
+	return decide(decisionAdded, "ReplicaSet mounts the ConfigMap and passed every check")
+}
@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+var _ = ginkgo.Describe("Explain", func() {
+	var ctx context.Context
+	var key types.NamespacedName
+
+	ginkgo.BeforeEach(func() {
+		ctx = context.Background()
+		key = types.NamespacedName{Namespace: "default", Name: "cm-a"}
+	})
+
+	newReconciler := func(cfg *config.OperatorConfig, objs ...client.Object) *ReplicaSetReconciler {
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objs...).Build()
+		return &ReplicaSetReconciler{Client: c, Scheme: scheme.Scheme, Config: cfg}
+	}
+
+	ginkgo.It("Should list only the workloads referencing the ConfigMap", func() {
+		r := newReconciler(&config.OperatorConfig{},
+			testReplicaSet("rs-a", "default", "cm-a"), testReplicaSet("rs-b", "default", "cm-b"),
+			testConfigMap("cm-a", "default"))
+
+		e, err := r.Explain(ctx, key)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(e.Exists).To(gomega.BeTrue())
+		gomega.Expect(e.Workloads).To(gomega.HaveLen(1))
+		gomega.Expect(e.Workloads[0].Name).To(gomega.Equal("rs-a"))
+		gomega.Expect(e.Workloads[0].Decision).To(gomega.Equal(decisionAdded))
+	})
+
+	ginkgo.It("Should not write anything", func() {
+		r := newReconciler(&config.OperatorConfig{},
+			testReplicaSet("rs-a", "default", "cm-a"), testConfigMap("cm-a", "default"))
+
+		_, err := r.Explain(ctx, key)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		e, err := r.Explain(ctx, key)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(e.Owners).To(gomega.BeEmpty())
+	})
+
+	ginkgo.It("Should report owners already present", func() {
+		r := newReconciler(&config.OperatorConfig{},
+			testReplicaSet("rs-a", "default", "cm-a"), testConfigMap("cm-a", "default"))
+		_, err := r.reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "rs-a"}})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+		e, err := r.Explain(ctx, key)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(e.Owners).To(gomega.ConsistOf(ExplainedOwner{Kind: "ReplicaSet", Name: "rs-a", Managed: true}))
+		gomega.Expect(e.Workloads[0].Decision).To(gomega.Equal(decisionOwned))
+	})
+
+	ginkgo.It("Should explain holds and filters", func() {
+		r := newReconciler(&config.OperatorConfig{DryRun: true},
+			testReplicaSet("rs-a", "default", "cm-a"), testConfigMap("cm-a", "default"))
+		e, err := r.Explain(ctx, key)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(e.Workloads[0].Decision).To(gomega.Equal(decisionHeld))
+		gomega.Expect(e.Checks).To(gomega.ContainElement(ExplainedCheck{Name: "writes_allowed", Detail: holdDryRun}))
+
+		r.Config = &config.OperatorConfig{NamespaceRegex: []string{"^prod-"}}
+		e, err = r.Explain(ctx, key)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(e.Workloads[0].Decision).To(gomega.Equal(decisionSkipped))
+	})
+
+	ginkgo.It("Should explain references to a missing ConfigMap", func() {
+		r := newReconciler(&config.OperatorConfig{}, testReplicaSet("rs-a", "default", "cm-a"))
+		e, err := r.Explain(ctx, key)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(e.Exists).To(gomega.BeFalse())
+		gomega.Expect(e.Workloads[0].Decision).To(gomega.Equal(decisionSkipped))
+	})
+})
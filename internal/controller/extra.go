@@ -0,0 +1,34 @@
+package controller
+
+import (
+	"slices"
+	"strings"
+)
+
+// ExtraConfigMapsAnnotation lists, comma-separated, the ConfigMaps a workload uses without mounting them, e.g.
+// read through the Kubernetes API, so they are owned like mounted ones
+const ExtraConfigMapsAnnotation = "configmap-rs-operator/extra-configmaps"
+
+// withExtraConfigMaps appends to names the ConfigMaps the ExtraConfigMapsAnnotation in each of annotations lists,
+// skipping those already in names
+func withExtraConfigMaps(names []string, annotations ...map[string]string) []string {
+	for _, a := range annotations {
+		for _, name := range strings.Split(a[ExtraConfigMapsAnnotation], ",") {
+			if name = strings.TrimSpace(name); name != "" && !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// withExtraReference adds ReferenceExtra to the sorted refs if the ExtraConfigMapsAnnotation in one of annotations
+// lists the ConfigMap name
+func withExtraReference(refs []string, name string, annotations ...map[string]string) []string {
+	if !slices.Contains(withExtraConfigMaps(nil, annotations...), name) {
+		return refs
+	}
+	refs = append(refs, ReferenceExtra)
+	slices.Sort(refs)
+	return refs
+}
@@ -0,0 +1,43 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+var _ = ginkgo.Describe("Extra ConfigMaps", func() {
+	ginkgo.It("Should own the ConfigMaps declared in the annotation of the ReplicaSet and its pod template", func() {
+		ctx := context.Background()
+		rs := testReplicaSet("web-abc", "default", "app-config")
+		rs.CreationTimestamp = metav1.Now()
+		rs.Annotations = map[string]string{ExtraConfigMapsAnnotation: "feature-flags, app-config"}
+		rs.Spec.Template.Annotations = map[string]string{ExtraConfigMapsAnnotation: "routing-table"}
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(rs, testConfigMap("app-config", "default"),
+			testConfigMap("feature-flags", "default"), testConfigMap("routing-table", "default")).Build()
+		r := &ReplicaSetReconciler{Client: c, Scheme: scheme.Scheme, Config: &config.OperatorConfig{}}
+
+		gomega.Expect(r.extractConfigMapVolumes(rs)).To(
+			gomega.Equal([]string{"app-config", "feature-flags", "routing-table"}))
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: rs.Name}})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		for _, name := range []string{"feature-flags", "routing-table"} {
+			var cm corev1.ConfigMap
+			gomega.Expect(c.Get(ctx, types.NamespacedName{Namespace: "default", Name: name}, &cm)).To(gomega.Succeed())
+			gomega.Expect(cm.OwnerReferences).To(gomega.ConsistOf(gomega.HaveField("Name", rs.Name)), name)
+		}
+
+		users, err := r.WhoUses(ctx, types.NamespacedName{Namespace: "default", Name: "routing-table"})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(users).To(gomega.ConsistOf(gomega.HaveField("References", []string{ReferenceExtra})))
+	})
+})
@@ -0,0 +1,166 @@
+package controller
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// HandoffReconciler hands the ConfigMaps of a Deployment's old ReplicaSet over to the ReplicaSets of the same
+// Deployment still running when a rollout scales it down to zero. The ReplicaSet reconciler only handles creations,
+// so a ReplicaSet created while the operator was down, or held back then, never owns the ConfigMaps it shares with
+// the old revision, which are garbage collected once revisionHistoryLimit prunes the old ReplicaSet.
+type HandoffReconciler struct {
+	// ReplicaSetReconciler provides the namespace filter, the holds and the ConfigMap processing
+	*ReplicaSetReconciler
+}
+
+// retired reports whether the update scaled a ReplicaSet controlled by a Deployment down to zero
+func retired(oldObj, newObj client.Object) bool {
+	oldRS, ok := oldObj.(*appsv1.ReplicaSet)
+	if !ok {
+		return false
+	}
+	newRS, ok := newObj.(*appsv1.ReplicaSet)
+	if !ok {
+		return false
+	}
+	return replicas(oldRS) > 0 && replicas(newRS) == 0 && deploymentOf(newRS) != nil
+}
+
+// deploymentOf returns the controller reference of rs if it is a Deployment
+func deploymentOf(rs *appsv1.ReplicaSet) *metav1.OwnerReference {
+	if ref := metav1.GetControllerOf(rs); ref != nil && ref.Kind == "Deployment" {
+		return ref
+	}
+	return nil
+}
+
+// successors returns the ReplicaSets of the Deployment of rs with replicas, other than rs, which aren't ignored as
+// a reconcile would ignore them, e.g. opted out by annotation
+func (r *ReplicaSetReconciler) successors(ctx context.Context, rs *appsv1.ReplicaSet) ([]appsv1.ReplicaSet, error) {
+	deployment := deploymentOf(rs)
+	if deployment == nil {
+		return nil, nil
+	}
+	var replicaSets appsv1.ReplicaSetList
+	if err := r.List(ctx, &replicaSets, client.InNamespace(rs.Namespace)); err != nil {
+		return nil, err
+	}
+	var successors []appsv1.ReplicaSet
+	for _, sibling := range replicaSets.Items {
+		ref := deploymentOf(&sibling)
+		if sibling.UID == rs.UID || ref == nil || ref.UID != deployment.UID || replicas(&sibling) == 0 {
+			continue
+		}
+		if reason, err := r.ignoreReason(ctx, &sibling); err != nil {
+			return nil, err
+		} else if reason == "" {
+			successors = append(successors, sibling)
+		}
+	}
+	return successors, nil
+}
+
+// ownedBySibling reports whether a ReplicaSet of the same Deployment as rs already owns cm, so owning it with rs
+// too extends an existing ownership across revisions instead of coupling the ConfigMap to another workload
+func (r *ReplicaSetReconciler) ownedBySibling(ctx context.Context, cm *corev1.ConfigMap, rs *appsv1.ReplicaSet) bool {
+	deployment := deploymentOf(rs)
+	if deployment == nil {
+		return false
+	}
+	for _, ref := range cm.OwnerReferences {
+		if ref.Kind != "ReplicaSet" || ref.UID == rs.UID {
+			continue
+		}
+		var sibling appsv1.ReplicaSet
+		if err := r.Get(ctx, types.NamespacedName{Namespace: cm.Namespace, Name: ref.Name}, &sibling); err != nil {
+			continue
+		}
+		if owner := deploymentOf(&sibling); sibling.UID == ref.UID && owner != nil && owner.UID == deployment.UID {
+			return true
+		}
+	}
+	return false
+}
+
+// blockedByOwnerLimit reports whether --max-existing-owners keeps rs, nil for the workload kinds, from owning cm.
+// With --rollout-handoff the limit doesn't apply to ConfigMaps an earlier revision of the same Deployment owns.
+func (r *ReplicaSetReconciler) blockedByOwnerLimit(
+	ctx context.Context, cm *corev1.ConfigMap, rs *appsv1.ReplicaSet,
+) bool {
+	if !r.tooManyOwners(cm) {
+		return false
+	}
+	return !r.Config.RolloutHandoff || rs == nil || !r.ownedBySibling(ctx, cm, rs)
+}
+
+func (r *HandoffReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithValues("replicaset", req.NamespacedName)
+	if !r.shouldProcessNamespace(ctx, req.Namespace) {
+		recordFiltered(dropReasonNamespace)
+		return ctrl.Result{}, nil
+	}
+
+	var rs appsv1.ReplicaSet
+	if err := r.Get(ctx, req.NamespacedName, &rs); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	successors, err := r.successors(ctx, &rs)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if len(successors) == 0 {
+		rolloutHandoffsTotal.WithLabelValues("no_successor").Inc()
+		return ctrl.Result{}, nil
+	}
+
+	// The successors may predate the operator, so the ConfigMaps they share with rs are owned as if they were new
+	retiredNames := r.extractConfigMapVolumes(&rs)
+	now := time.Now()
+	holdReason := r.holdReason(ctx, rs.Namespace, now, logger)
+	for i := range successors {
+		successor := &successors[i]
+		var shared []string
+		for _, name := range r.extractConfigMapVolumes(successor) {
+			if slices.Contains(retiredNames, name) {
+				shared = append(shared, name)
+			}
+		}
+		if len(shared) == 0 {
+			continue
+		}
+		logger.V(1).Info("Handing ConfigMaps over to the next revision", "successor", successor.Name, "configmaps", shared)
+		if err := r.processConfigMaps(ctx, replicaSetWorkload(successor), shared, holdReason, logger); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	rolloutHandoffsTotal.WithLabelValues("ok").Inc()
+	return r.heldResult(holdReason, now), nil
+}
+
+// SetupWithManager watches ReplicaSets, reconciling them when a rollout scales them down to zero. Like rollbacks,
+// rollouts are too irregular for the liveness check, so the controller isn't tracked.
+func (r *HandoffReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("handoff").
+		For(&appsv1.ReplicaSet{}).
+		WithEventFilter(predicate.Funcs{
+			CreateFunc:  func(event.CreateEvent) bool { return false },
+			UpdateFunc:  func(e event.UpdateEvent) bool { return retired(e.ObjectOld, e.ObjectNew) },
+			DeleteFunc:  func(event.DeleteEvent) bool { return false },
+			GenericFunc: func(event.GenericEvent) bool { return false },
+		}).
+		WithOptions(r.controllerOptions()).
+		Complete(r)
+}
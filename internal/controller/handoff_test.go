@@ -0,0 +1,92 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+// testRevision returns a ReplicaSet of the web Deployment with the given UID
+func testRevision(name string, replicas int32, configMaps ...string) *appsv1.ReplicaSet {
+	isController := true
+	rs := testReplicaSet(name, "default", configMaps...)
+	rs.UID = types.UID(name + "-uid")
+	rs.Spec.Replicas = int32Ptr(replicas)
+	rs.OwnerReferences = []metav1.OwnerReference{{
+		APIVersion: "apps/v1", Kind: "Deployment", Name: "web", UID: "web-uid", Controller: &isController,
+	}}
+	return rs
+}
+
+var _ = ginkgo.Describe("Rollout handoff", func() {
+	ginkgo.It("Should only react to Deployment ReplicaSets scaling down to zero", func() {
+		running, retiredRS := testRevision("web-1", 2), testRevision("web-1", 0)
+		gomega.Expect(retired(running, retiredRS)).To(gomega.BeTrue())
+		gomega.Expect(retired(retiredRS, running)).To(gomega.BeFalse())
+		gomega.Expect(retired(running, running)).To(gomega.BeFalse())
+
+		// Without a Deployment there is no next revision to hand the ConfigMaps over to
+		orphan := testReplicaSet("web", "default")
+		orphan.Spec.Replicas = int32Ptr(0)
+		gomega.Expect(retired(testReplicaSet("web", "default"), orphan)).To(gomega.BeFalse())
+	})
+
+	ginkgo.It("Should hand the shared ConfigMaps over to the running revision past the owner limit", func() {
+		ctx := context.Background()
+		old := testRevision("web-1", 0, "app-config", "old-only")
+		cm := testConfigMap("app-config", "default")
+		cm.OwnerReferences = []metav1.OwnerReference{
+			{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "web-1", UID: "web-1-uid"},
+			{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "api-1", UID: "api-1-uid"},
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+			old, testRevision("web-2", 3, "app-config"), cm, testConfigMap("old-only", "default"),
+		).Build()
+		r := &HandoffReconciler{ReplicaSetReconciler: &ReplicaSetReconciler{
+			Client: c, Scheme: scheme.Scheme,
+			Config: &config.OperatorConfig{RolloutHandoff: true, MaxExistingOwners: 1},
+			// The new revision was created while the operator was down
+			StartTime: time.Now(),
+		}}
+
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "web-1"}})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+		gomega.Expect(c.Get(ctx, types.NamespacedName{Namespace: "default", Name: "app-config"}, cm)).To(gomega.Succeed())
+		gomega.Expect(cm.OwnerReferences).To(gomega.ConsistOf(
+			gomega.HaveField("Name", "web-1"), gomega.HaveField("Name", "api-1"), gomega.HaveField("Name", "web-2")))
+		var oldOnly corev1.ConfigMap
+		gomega.Expect(c.Get(ctx, types.NamespacedName{Namespace: "default", Name: "old-only"}, &oldOnly)).To(gomega.Succeed())
+		gomega.Expect(oldOnly.OwnerReferences).To(gomega.BeEmpty())
+	})
+	ginkgo.It("Should not hand ConfigMaps over to an ignored revision", func() {
+		ctx := context.Background()
+		skipped := testRevision("web-2", 3, "app-config")
+		skipped.Spec.Template.Annotations = map[string]string{SkipAnnotation: "true"}
+		unselected := testRevision("web-3", 1, "app-config")
+		unselected.Spec.Template.Annotations = map[string]string{EnabledAnnotation: "false"}
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+			testRevision("web-1", 0, "app-config"), skipped, unselected, testConfigMap("app-config", "default"),
+		).Build()
+		r := &HandoffReconciler{ReplicaSetReconciler: &ReplicaSetReconciler{
+			Client: c, Scheme: scheme.Scheme, Config: &config.OperatorConfig{RolloutHandoff: true, OptIn: true},
+		}}
+
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "web-1"}})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		var cm corev1.ConfigMap
+		gomega.Expect(c.Get(ctx, types.NamespacedName{Namespace: "default", Name: "app-config"}, &cm)).To(gomega.Succeed())
+		gomega.Expect(cm.OwnerReferences).To(gomega.BeEmpty())
+	})
+})
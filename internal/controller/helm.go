@@ -0,0 +1,43 @@
+package controller
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// reasonHelmManaged is the skip reason of the ConfigMaps Helm manages or keeps on uninstall
+const reasonHelmManaged = "helm_managed"
+
+// Helm ownership metadata
+const (
+	helmManagedByLabel           = "app.kubernetes.io/managed-by"
+	helmResourcePolicyAnnotation = "helm.sh/resource-policy"
+)
+
+// helmManaged reports whether cm is part of a Helm release, labeled app.kubernetes.io/managed-by: Helm, or kept by
+// Helm on uninstall with helm.sh/resource-policy: keep. Owning it would have helm uninstall and the garbage
+// collector delete it both, or the garbage collector delete what Helm was told to keep.
+func helmManaged(cm *corev1.ConfigMap) bool {
+	return strings.EqualFold(cm.Labels[helmManagedByLabel], "Helm") ||
+		cm.Annotations[helmResourcePolicyAnnotation] == "keep"
+}
+
+// helmProtected reports whether cm is skipped as Helm-managed, which it is unless --own-helm-managed is set
+func (r *ReplicaSetReconciler) helmProtected(cm *corev1.ConfigMap) bool {
+	return !r.Config.OwnHelmManaged && helmManaged(cm)
+}
+
+// protectedReason returns why the ConfigMap name is never owned, whatever references it: it is denylisted, or cm
+// is managed by Helm or Argo CD; or an empty string. cm is nil if the ConfigMap doesn't exist.
+func (r *ReplicaSetReconciler) protectedReason(name string, cm *corev1.ConfigMap) string {
+	switch {
+	case r.denylisted(name):
+		return reasonDenylisted
+	case cm != nil && r.helmProtected(cm):
+		return reasonHelmManaged
+	case cm != nil && r.argoCDProtected(cm):
+		return reasonArgoCDManaged
+	}
+	return ""
+}
@@ -0,0 +1,60 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+var _ = ginkgo.Describe("Helm-managed ConfigMaps", func() {
+	newConfigMaps := func() []*corev1.ConfigMap {
+		released := testConfigMap("released-config", "default")
+		released.Labels = map[string]string{"app.kubernetes.io/managed-by": "Helm"}
+		kept := testConfigMap("kept-config", "default")
+		kept.Annotations = map[string]string{"helm.sh/resource-policy": "keep"}
+		return []*corev1.ConfigMap{released, kept, testConfigMap("web-config", "default")}
+	}
+
+	reconcile := func(cfg *config.OperatorConfig) map[string]int {
+		ctx := context.Background()
+		replicaSet := testReplicaSet("web-abc", "default", "released-config", "kept-config", "web-config")
+		replicaSet.CreationTimestamp = metav1.Now()
+		builder := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(replicaSet)
+		for _, cm := range newConfigMaps() {
+			builder = builder.WithObjects(cm)
+		}
+		c := builder.Build()
+		r := &ReplicaSetReconciler{Client: c, Scheme: scheme.Scheme, Config: cfg}
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "web-abc"}})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+		owners := map[string]int{}
+		for _, name := range []string{"released-config", "kept-config", "web-config"} {
+			var cm corev1.ConfigMap
+			gomega.Expect(c.Get(ctx, types.NamespacedName{Namespace: "default", Name: name}, &cm)).To(gomega.Succeed())
+			owners[name] = len(cm.OwnerReferences)
+		}
+		return owners
+	}
+
+	ginkgo.It("Should skip the ConfigMaps Helm manages or keeps by default", func() {
+		gomega.Expect(reconcile(&config.OperatorConfig{})).To(gomega.Equal(map[string]int{
+			"released-config": 0, "kept-config": 0, "web-config": 1,
+		}))
+	})
+
+	ginkgo.It("Should own them with --own-helm-managed", func() {
+		gomega.Expect(reconcile(&config.OperatorConfig{OwnHelmManaged: true})).To(gomega.Equal(map[string]int{
+			"released-config": 1, "kept-config": 1, "web-config": 1,
+		}))
+	})
+})
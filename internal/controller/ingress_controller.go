@@ -0,0 +1,167 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// certManagerGroup is the API group of cert-manager; Secrets it issues are left to it
+const certManagerGroup = "cert-manager.io"
+
+// IngressTLSReconciler adds Ingresses as owners of the TLS Secrets they reference, so the certificates of
+// short-lived Ingresses, e.g. of preview environments, are garbage collected with them
+type IngressTLSReconciler struct {
+	// ReplicaSetReconciler provides the namespace filter, write holds, write client and events
+	*ReplicaSetReconciler
+
+	// SecretReader reads Secrets directly from the API server, so Secrets are never cached
+	SecretReader client.Reader
+}
+
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;update;patch
+
+func (r *IngressTLSReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithValues("ingress", req.NamespacedName)
+	if !r.shouldProcessNamespace(ctx, req.Namespace) {
+		recordFiltered(dropReasonNamespace)
+		return ctrl.Result{}, nil
+	}
+
+	var ing networkingv1.Ingress
+	if err := r.Get(ctx, req.NamespacedName, &ing); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if ing.CreationTimestamp.Time.Before(r.StartTime) {
+		recordFiltered(dropReasonStartTime)
+		return ctrl.Result{}, nil
+	}
+	if issuedByCertManager(&ing) {
+		logger.V(1).Info("Skipping Ingress whose certificates are issued by cert-manager")
+		return ctrl.Result{}, nil
+	}
+
+	now := time.Now()
+	holdReason := r.holdReason(ctx, ing.Namespace, now, logger)
+	for _, name := range ingressTLSSecrets(&ing) {
+		if err := r.processSecret(ctx, &ing, name, holdReason, logger); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	return r.heldResult(holdReason, now), nil
+}
+
+// ingressTLSSecrets returns the distinct Secrets referenced by the TLS section of ing
+func ingressTLSSecrets(ing *networkingv1.Ingress) []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, tls := range ing.Spec.TLS {
+		if tls.SecretName != "" && !seen[tls.SecretName] {
+			seen[tls.SecretName] = true
+			names = append(names, tls.SecretName)
+		}
+	}
+	return names
+}
+
+// issuedByCertManager reports whether cert-manager's ingress-shim issues the certificates of ing
+func issuedByCertManager(ing *networkingv1.Ingress) bool {
+	for key := range ing.Annotations {
+		if strings.HasPrefix(key, certManagerGroup+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// managedByCertManager reports whether cert-manager issued secret
+func managedByCertManager(secret *corev1.Secret) bool {
+	if _, ok := secret.Annotations[certManagerGroup+"/certificate-name"]; ok {
+		return true
+	}
+	for _, ref := range secret.OwnerReferences {
+		if strings.HasPrefix(ref.APIVersion, certManagerGroup+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// processSecret adds ing as an owner of its TLS Secret name. As for ConfigMaps, the reference is proposed with
+// --require-approval and patched with --patch-only.
+func (r *IngressTLSReconciler) processSecret(
+	ctx context.Context,
+	ing *networkingv1.Ingress,
+	name, holdReason string,
+	logger logr.Logger,
+) error {
+	var secret corev1.Secret
+	if err := r.SecretReader.Get(ctx, types.NamespacedName{Namespace: ing.Namespace, Name: name}, &secret); err != nil {
+		if errors.IsNotFound(err) {
+			logger.V(1).Info("TLS Secret not found", "secret", name)
+			return nil
+		}
+		logger.Error(err, "Failed to get TLS Secret", "secret", name)
+		return err
+	}
+	if managedByCertManager(&secret) {
+		logger.V(1).Info("Skipping TLS Secret issued by cert-manager", "secret", name)
+		return nil
+	}
+	for _, ref := range secret.OwnerReferences {
+		if ref.UID == ing.UID {
+			return nil
+		}
+	}
+	if holdReason != "" {
+		logger.Info(holdReason+": Would add OwnerReference", "secret", name, "ingress", ing.Name)
+		return nil
+	}
+	owner := metav1.OwnerReference{
+		APIVersion: networkingv1.SchemeGroupVersion.String(), Kind: "Ingress", Name: ing.Name, UID: ing.UID,
+	}
+	if r.Config.RequireApproval {
+		return r.proposeOwner(ctx, &secret, owner, logger)
+	}
+
+	if err := r.addOwner(ctx, &secret, owner); err != nil {
+		logger.Error(err, "Failed to update TLS Secret with owner reference", "secret", name)
+		r.recordEvent(&secret, corev1.EventTypeWarning, "OwnerReferenceFailed",
+			"Failed to add owner reference to Ingress %s: %v", ing.Name, err)
+		return err
+	}
+
+	logger.Info("Added OwnerReference to TLS Secret", "secret", name, "ingress", ing.Name)
+	r.recordEvent(&secret, corev1.EventTypeNormal, "OwnerReferenceAdded",
+		"Added owner reference to Ingress %s", ing.Name)
+	r.observeChurn(ing.Namespace, ownershipAdded, logger)
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager. Like ReplicaSets, only Ingresses created
+// after the operator started are processed.
+func (r *IngressTLSReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&networkingv1.Ingress{}).
+		WithEventFilter(predicate.Funcs{
+			CreateFunc:  func(e event.CreateEvent) bool { return e.Object.GetCreationTimestamp().After(r.StartTime) },
+			UpdateFunc:  func(event.UpdateEvent) bool { return false },
+			DeleteFunc:  func(event.DeleteEvent) bool { return false },
+			GenericFunc: func(event.GenericEvent) bool { return false },
+		}).
+		WithOptions(r.controllerOptions()).
+		Complete(r.tracked("ingress", r))
+}
@@ -0,0 +1,126 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+	"github.com/matanbaruch/configmap-rs-operator/internal/schedule"
+)
+
+var _ = ginkgo.Describe("IngressTLSReconciler", func() {
+	var ctx context.Context
+
+	ginkgo.BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	testIngress := func(annotations map[string]string, secrets ...string) *networkingv1.Ingress {
+		ing := &networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{
+			Name: "preview", Namespace: "default", UID: "preview-uid",
+			Annotations: annotations, CreationTimestamp: metav1.Now(),
+		}}
+		for _, name := range secrets {
+			ing.Spec.TLS = append(ing.Spec.TLS, networkingv1.IngressTLS{SecretName: name})
+		}
+		return ing
+	}
+	testSecret := func(name string, annotations map[string]string) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Annotations: annotations}}
+	}
+
+	reconcile := func(cfg *config.OperatorConfig, objs ...client.Object) client.Client {
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objs...).Build()
+		r := &IngressTLSReconciler{
+			ReplicaSetReconciler: &ReplicaSetReconciler{Client: c, Scheme: scheme.Scheme, Config: cfg},
+			SecretReader:         c,
+		}
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "preview"}})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		return c
+	}
+	owners := func(c client.Client, name string) []metav1.OwnerReference {
+		var secret corev1.Secret
+		gomega.Expect(c.Get(ctx, types.NamespacedName{Namespace: "default", Name: name}, &secret)).To(gomega.Succeed())
+		return secret.OwnerReferences
+	}
+
+	ginkgo.It("Should add the Ingress as owner of its TLS Secrets", func() {
+		c := reconcile(&config.OperatorConfig{}, testIngress(nil, "tls", "missing"), testSecret("tls", nil))
+		gomega.Expect(owners(c, "tls")).To(gomega.ConsistOf(gomega.HaveField("Name", "preview")))
+	})
+
+	ginkgo.It("Should leave Secrets issued by cert-manager alone", func() {
+		c := reconcile(&config.OperatorConfig{},
+			testIngress(nil, "issued"),
+			testSecret("issued", map[string]string{"cert-manager.io/certificate-name": "preview"}))
+		gomega.Expect(owners(c, "issued")).To(gomega.BeEmpty())
+
+		c = reconcile(&config.OperatorConfig{},
+			testIngress(map[string]string{"cert-manager.io/cluster-issuer": "letsencrypt"}, "tls"),
+			testSecret("tls", nil))
+		gomega.Expect(owners(c, "tls")).To(gomega.BeEmpty())
+	})
+
+	ginkgo.It("Should propose the owner reference with --require-approval", func() {
+		c := reconcile(&config.OperatorConfig{RequireApproval: true}, testIngress(nil, "tls"), testSecret("tls", nil))
+		gomega.Expect(owners(c, "tls")).To(gomega.BeEmpty())
+		var secret corev1.Secret
+		gomega.Expect(c.Get(ctx, types.NamespacedName{Namespace: "default", Name: "tls"}, &secret)).To(gomega.Succeed())
+		gomega.Expect(pendingOwners(&secret)).To(gomega.ConsistOf(gomega.And(
+			gomega.HaveField("Kind", "Ingress"), gomega.HaveField("UID", types.UID("preview-uid")))))
+	})
+
+	ginkgo.It("Should patch TLS Secrets with --patch-only", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(testIngress(nil, "tls"), testSecret("tls", nil)).
+			WithInterceptorFuncs(interceptor.Funcs{
+				Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+					return fmt.Errorf("%s is forbidden: cannot update", obj.GetName())
+				},
+			}).Build()
+		r := &IngressTLSReconciler{
+			ReplicaSetReconciler: &ReplicaSetReconciler{
+				Client: c, Scheme: scheme.Scheme, Config: &config.OperatorConfig{PatchOnly: true},
+			},
+			SecretReader: c,
+		}
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "preview"}})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(owners(c, "tls")).To(gomega.ConsistOf(gomega.HaveField("Name", "preview")))
+	})
+
+	ginkgo.It("Should only log in dry-run mode", func() {
+		c := reconcile(&config.OperatorConfig{DryRun: true}, testIngress(nil, "tls"), testSecret("tls", nil))
+		gomega.Expect(owners(c, "tls")).To(gomega.BeEmpty())
+	})
+	ginkgo.It("Should requeue for the next maintenance window", func() {
+		window, err := schedule.Parse([]string{"0 0 1 1 * 1m"}, time.UTC)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).
+			WithObjects(testIngress(nil, "tls"), testSecret("tls", nil)).Build()
+		r := &IngressTLSReconciler{
+			ReplicaSetReconciler: &ReplicaSetReconciler{
+				Client: c, Scheme: scheme.Scheme, Config: &config.OperatorConfig{}, MaintenanceWindow: window,
+			},
+			SecretReader: c,
+		}
+		key := types.NamespacedName{Namespace: "default", Name: "preview"}
+		result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(result.RequeueAfter).To(gomega.BeNumerically(">", 0))
+		gomega.Expect(owners(c, "tls")).To(gomega.BeEmpty())
+	})
+})
@@ -0,0 +1,332 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// InventoryKey is the data key of the inventory report ConfigMap
+const InventoryKey = "inventory.json"
+
+// InventoryGeneratedAnnotation records when the inventory report ConfigMap was last regenerated; it
+// is kept out of the data so the data only changes when the inventory does
+const InventoryGeneratedAnnotation = "configmap-rs-operator/inventory-generated-at"
+
+// Inventory is a snapshot of the ownership state of the cluster
+type Inventory struct {
+	// Managed lists the ConfigMaps carrying owner references added by the operator
+	Managed []ManagedConfigMap `json:"managed"`
+
+	// Orphans lists the ConfigMaps in scope that no ReplicaSet mounts and nothing owns
+	Orphans []types.NamespacedName `json:"orphans"`
+
+	// Skips counts the ConfigMap references of ReplicaSets that are not owned, by reason
+	Skips map[string]int `json:"skips"`
+
+	// CrossNamespace lists the ConfigMaps ReplicaSets reference by convention in other namespaces,
+	// which can't be managed
+	CrossNamespace []CrossNamespaceReference `json:"crossNamespace"`
+
+	// Missing lists the required ConfigMap references unresolved past --missing-reference-window
+	Missing []MissingReference `json:"missing"`
+
+	// Pending lists the owner references dry-run or --require-approval hold back, for review before they are written
+	Pending []PendingChange `json:"pending"`
+
+	// OwnerRules reports what each owner rule matches in the namespaces in scope
+	OwnerRules []OwnerRuleStatus `json:"ownerRules,omitempty"`
+}
+
+// PendingChange is an owner reference the operator would add if dry-run or approval didn't hold it back
+type PendingChange struct {
+	Namespace string `json:"namespace"`
+	ConfigMap string `json:"configMap"`
+
+	// Owner is the kind/name of the owner the reference would point to
+	Owner string `json:"owner"`
+}
+
+// managedConfigMap returns the owners the operator added to cm, and false if there are none
+func managedConfigMap(cm *corev1.ConfigMap) (ManagedConfigMap, bool) {
+	managed := managedOwnerUIDs(cm)
+	if len(managed) == 0 {
+		return ManagedConfigMap{}, false
+	}
+	entry := ManagedConfigMap{Namespace: cm.Namespace, Name: cm.Name}
+	for _, ref := range cm.OwnerReferences {
+		if slices.Contains(managed, ref.UID) {
+			entry.Owners = append(entry.Owners, ref.Kind+"/"+ref.Name)
+		}
+	}
+	return entry, true
+}
+
+// Inventory scans every ReplicaSet and ConfigMap and returns the ownership snapshot. References are
+// skipped for the same reasons the reconciler records in its decisions.
+func (r *ReplicaSetReconciler) Inventory(ctx context.Context) (*Inventory, error) {
+	var configMaps corev1.ConfigMapList
+	if err := r.List(ctx, &configMaps); err != nil {
+		return nil, err
+	}
+	var replicaSets appsv1.ReplicaSetList
+	if err := r.List(ctx, &replicaSets); err != nil {
+		return nil, err
+	}
+
+	inv := &Inventory{
+		Managed:        []ManagedConfigMap{},
+		Orphans:        []types.NamespacedName{},
+		Skips:          map[string]int{},
+		CrossNamespace: []CrossNamespaceReference{},
+		Missing:        []MissingReference{},
+		Pending:        []PendingChange{},
+	}
+	byKey := make(map[types.NamespacedName]*corev1.ConfigMap, len(configMaps.Items))
+	for i := range configMaps.Items {
+		cm := &configMaps.Items[i]
+		byKey[types.NamespacedName{Namespace: cm.Namespace, Name: cm.Name}] = cm
+		if entry, ok := managedConfigMap(cm); ok {
+			inv.Managed = append(inv.Managed, entry)
+		}
+	}
+
+	now := time.Now()
+	holds := map[string]string{}
+	referenced := map[types.NamespacedName]bool{}
+	rules := r.newOwnerRuleMatches()
+	for i := range replicaSets.Items {
+		rs := &replicaSets.Items[i]
+		for _, ref := range crossNamespaceCandidates(rs) {
+			if _, exists := byKey[ref.ConfigMap]; exists && r.shouldProcessNamespace(ctx, rs.Namespace) {
+				inv.CrossNamespace = append(inv.CrossNamespace, ref)
+			}
+		}
+		for _, name := range r.extractConfigMapVolumes(rs) {
+			key := types.NamespacedName{Namespace: rs.Namespace, Name: name}
+			referenced[key] = true
+			if r.shouldProcessNamespace(ctx, rs.Namespace) {
+				rules.observe(r, rs, name)
+			}
+			cm, exists := byKey[key]
+			if exists && r.isOwnerReferencePresent(cm, rs) {
+				continue
+			}
+			if ref, ok := r.missingReference(ctx, rs, name, now); ok && !exists {
+				inv.Missing = append(inv.Missing, ref)
+			}
+			reason, err := r.skipReason(ctx, rs, name, cm, now, holds)
+			if err != nil {
+				return nil, err
+			}
+			if reason != "" {
+				inv.Skips[reason]++
+			}
+			if reason == holdDryRun || reason == holdApproval {
+				owner := r.ownerFor(rs, name)
+				inv.Pending = append(inv.Pending, PendingChange{
+					Namespace: rs.Namespace, ConfigMap: name, Owner: owner.Kind + "/" + owner.Name,
+				})
+			}
+		}
+	}
+
+	for key, cm := range byKey {
+		if !referenced[key] && len(cm.OwnerReferences) == 0 && r.shouldProcessNamespace(ctx, key.Namespace) {
+			inv.Orphans = append(inv.Orphans, key)
+		}
+	}
+
+	inv.OwnerRules = rules.statuses(r.OwnerRules)
+
+	sort.Slice(inv.Managed, func(i, j int) bool {
+		a, b := inv.Managed[i], inv.Managed[j]
+		return a.Namespace < b.Namespace || a.Namespace == b.Namespace && a.Name < b.Name
+	})
+	sort.Slice(inv.Orphans, func(i, j int) bool {
+		a, b := inv.Orphans[i], inv.Orphans[j]
+		return a.Namespace < b.Namespace || a.Namespace == b.Namespace && a.Name < b.Name
+	})
+	sort.Slice(inv.Missing, func(i, j int) bool {
+		a, b := inv.Missing[i], inv.Missing[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.ReplicaSet < b.ReplicaSet || a.ReplicaSet == b.ReplicaSet && a.ConfigMap < b.ConfigMap
+	})
+	sort.Slice(inv.Pending, func(i, j int) bool {
+		a, b := inv.Pending[i], inv.Pending[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.ConfigMap < b.ConfigMap || a.ConfigMap == b.ConfigMap && a.Owner < b.Owner
+	})
+	sort.SliceStable(inv.CrossNamespace, func(i, j int) bool {
+		a, b := inv.CrossNamespace[i], inv.CrossNamespace[j]
+		return a.Namespace < b.Namespace || a.Namespace == b.Namespace && a.ReplicaSet < b.ReplicaSet
+	})
+	return inv, nil
+}
+
+// skipReason returns why the reference of rs to the ConfigMap name, which it doesn't own, is not owned,
+// or an empty string if the reconciler would add the owner reference. cm is nil if the ConfigMap doesn't
+// exist, and holds caches the hold reason per namespace.
+func (r *ReplicaSetReconciler) skipReason(
+	ctx context.Context,
+	rs *appsv1.ReplicaSet,
+	name string,
+	cm *corev1.ConfigMap,
+	now time.Time,
+	holds map[string]string,
+) (string, error) {
+	if !r.shouldProcessNamespace(ctx, rs.Namespace) {
+		return dropReasonNamespace, nil
+	}
+	if reason, err := r.ignoreReason(ctx, rs); err != nil || reason != "" {
+		return reason, err
+	}
+	if reason := r.protectedReason(name, cm); reason != "" {
+		return reason, nil
+	}
+	switch {
+	case rs.CreationTimestamp.Time.Before(r.StartTime):
+		return dropReasonStartTime, nil
+	case cm == nil:
+		return reasonConfigMapNotFound, nil
+	case r.ownerFor(rs, name) == nil:
+		return reasonOwnerRule, nil
+	case r.blockedByOwnerLimit(ctx, cm, rs):
+		return reasonTooManyOwners, nil
+	case r.Config.ConsumedKeysOnly && !consumesConfigMap(&rs.Spec.Template.Spec, cm):
+		return reasonKeysNotConsumed, nil
+	}
+	if reason, err := r.optionalSkipReason(ctx, rs, name); err != nil || reason != "" {
+		return reason, err
+	}
+	return r.inventoryHold(ctx, rs.Namespace, now, holds), nil
+}
+
+// inventoryHold returns the hold in effect in namespace, or approval when writes are allowed but queued for it,
+// caching the hold reason per namespace in holds
+func (r *ReplicaSetReconciler) inventoryHold(
+	ctx context.Context,
+	namespace string,
+	now time.Time,
+	holds map[string]string,
+) string {
+	hold, ok := holds[namespace]
+	if !ok {
+		hold = r.holdReason(ctx, namespace, now, logr.Discard())
+		holds[namespace] = hold
+	}
+	if hold == "" && r.Config.RequireApproval {
+		return holdApproval
+	}
+	return hold
+}
+
+// InventoryReporter periodically writes the inventory into a ConfigMap, so GitOps and audit tooling
+// can consume a stable snapshot. It needs leader election, so only the active replica writes.
+type InventoryReporter struct {
+	Reconciler *ReplicaSetReconciler
+	Target     types.NamespacedName
+	Interval   time.Duration
+	Log        logr.Logger
+}
+
+// NewInventoryReporter returns a reporter writing to the namespace/name ConfigMap ref every interval.
+// It returns nil if ref is empty, which disables the report.
+func NewInventoryReporter(
+	r *ReplicaSetReconciler,
+	ref string,
+	interval time.Duration,
+	logger logr.Logger,
+) (*InventoryReporter, error) {
+	if ref == "" {
+		return nil, nil
+	}
+	namespace, name, ok := strings.Cut(ref, "/")
+	if !ok || namespace == "" || name == "" {
+		return nil, fmt.Errorf("invalid inventory ConfigMap %q: expected namespace/name", ref)
+	}
+	if interval <= 0 {
+		return nil, fmt.Errorf("invalid inventory interval %s: must be positive", interval)
+	}
+	return &InventoryReporter{
+		Reconciler: r,
+		Target:     types.NamespacedName{Namespace: namespace, Name: name},
+		Interval:   interval,
+		Log:        logger,
+	}, nil
+}
+
+// Start implements manager.Runnable
+func (p *InventoryReporter) Start(ctx context.Context) error {
+	ticker := time.NewTicker(p.Interval)
+	defer ticker.Stop()
+	for {
+		if err := p.report(ctx); err != nil {
+			p.Log.Error(err, "Failed to write inventory report", "configmap", p.Target)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// report writes the current inventory into the target ConfigMap, leaving it untouched when the
+// inventory didn't change
+func (p *InventoryReporter) report(ctx context.Context) error {
+	inv, err := p.Reconciler.Inventory(ctx)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(inv, "", "  ")
+	if err != nil {
+		return err
+	}
+	generated := time.Now().UTC().Format(time.RFC3339)
+
+	var cm corev1.ConfigMap
+	err = p.Reconciler.Get(ctx, p.Target, &cm)
+	if errors.IsNotFound(err) {
+		cm = corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   p.Target.Namespace,
+				Name:        p.Target.Name,
+				Annotations: map[string]string{InventoryGeneratedAnnotation: generated},
+			},
+			Data: map[string]string{InventoryKey: string(data)},
+		}
+		return p.Reconciler.writer().Create(ctx, &cm)
+	}
+	if err != nil {
+		return err
+	}
+	if cm.Data[InventoryKey] == string(data) {
+		return nil
+	}
+	original := cm.DeepCopy()
+	if cm.Data == nil {
+		cm.Data = map[string]string{}
+	}
+	if cm.Annotations == nil {
+		cm.Annotations = map[string]string{}
+	}
+	cm.Data[InventoryKey] = string(data)
+	cm.Annotations[InventoryGeneratedAnnotation] = generated
+	return p.Reconciler.updateObject(ctx, p.Reconciler.writer(), &cm, original)
+}
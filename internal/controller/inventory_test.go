@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+var _ = ginkgo.Describe("Inventory", func() {
+	var ctx context.Context
+	var r *ReplicaSetReconciler
+
+	ginkgo.BeforeEach(func() {
+		ctx = context.Background()
+		old := testReplicaSet("rs-old", "team-a", "cm-old")
+		old.CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Hour))
+		rs := testReplicaSet("rs-a", "team-a", "cm-a", "cm-missing")
+		rs.CreationTimestamp = metav1.Now()
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+			rs, testConfigMap("cm-a", "team-a"),
+			old, testConfigMap("cm-old", "team-a"),
+			testConfigMap("unused", "team-a"),
+			testReplicaSet("rs-b", "kube-system", "cm-b"), testConfigMap("cm-b", "kube-system"),
+			testConfigMap("unused", "kube-system"),
+		).Build()
+		r = &ReplicaSetReconciler{
+			Client:    c,
+			Scheme:    scheme.Scheme,
+			Config:    &config.OperatorConfig{NamespaceRegex: []string{"^team-"}},
+			StartTime: time.Now().Add(-time.Minute),
+		}
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "team-a", Name: "rs-a"}})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+	})
+
+	ginkgo.It("Should report managed ConfigMaps, orphans and skips by reason", func() {
+		inv, err := r.Inventory(ctx)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(inv.Managed).To(gomega.Equal([]ManagedConfigMap{
+			{Namespace: "team-a", Name: "cm-a", Owners: []string{"ReplicaSet/rs-a"}},
+		}))
+		gomega.Expect(inv.Orphans).To(gomega.Equal([]types.NamespacedName{{Namespace: "team-a", Name: "unused"}}))
+		gomega.Expect(inv.Skips).To(gomega.Equal(map[string]int{
+			dropReasonNamespace:     1,
+			dropReasonStartTime:     1,
+			reasonConfigMapNotFound: 1,
+		}))
+	})
+
+	ginkgo.It("Should list the owner references dry-run holds back as pending", func() {
+		rs := testReplicaSet("rs-c", "team-a", "cm-c", "cm-a")
+		rs.CreationTimestamp = metav1.Now()
+		gomega.Expect(r.Create(ctx, rs)).To(gomega.Succeed())
+		gomega.Expect(r.Create(ctx, testConfigMap("cm-c", "team-a"))).To(gomega.Succeed())
+		r.Config.DryRun = true
+
+		inv, err := r.Inventory(ctx)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(inv.Pending).To(gomega.Equal([]PendingChange{
+			{Namespace: "team-a", ConfigMap: "cm-a", Owner: "ReplicaSet/rs-c"},
+			{Namespace: "team-a", ConfigMap: "cm-c", Owner: "ReplicaSet/rs-c"},
+		}))
+		gomega.Expect(inv.Skips).To(gomega.HaveKeyWithValue(holdDryRun, 2))
+	})
+
+	ginkgo.It("Should write the report and leave it untouched while nothing changes", func() {
+		reporter, err := NewInventoryReporter(r, "ops/inventory", time.Hour, logr.Discard())
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(reporter.report(ctx)).To(gomega.Succeed())
+
+		var cm corev1.ConfigMap
+		gomega.Expect(r.Get(ctx, reporter.Target, &cm)).To(gomega.Succeed())
+		var inv Inventory
+		gomega.Expect(json.Unmarshal([]byte(cm.Data[InventoryKey]), &inv)).To(gomega.Succeed())
+		gomega.Expect(inv.Managed).To(gomega.HaveLen(1))
+
+		version := cm.ResourceVersion
+		gomega.Expect(reporter.report(ctx)).To(gomega.Succeed())
+		gomega.Expect(r.Get(ctx, reporter.Target, &cm)).To(gomega.Succeed())
+		gomega.Expect(cm.ResourceVersion).To(gomega.Equal(version))
+	})
+
+	ginkgo.It("Should write the report with the write client", func() {
+		writer := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+		r.Writer = writer
+		reporter, err := NewInventoryReporter(r, "ops/inventory", time.Hour, logr.Discard())
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(reporter.report(ctx)).To(gomega.Succeed())
+
+		gomega.Expect(writer.Get(ctx, reporter.Target, &corev1.ConfigMap{})).To(gomega.Succeed())
+		gomega.Expect(r.Get(ctx, reporter.Target, &corev1.ConfigMap{})).NotTo(gomega.Succeed())
+	})
+
+	ginkgo.It("Should reject invalid targets", func() {
+		reporter, err := NewInventoryReporter(r, "", time.Hour, logr.Discard())
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(reporter).To(gomega.BeNil())
+		_, err = NewInventoryReporter(r, "inventory", time.Hour, logr.Discard())
+		gomega.Expect(err).To(gomega.HaveOccurred())
+	})
+})
@@ -0,0 +1,56 @@
+package controller
+
+import (
+	"context"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+// JobKind is the workload kind of Jobs. The pod template of a Job is immutable, so like PodTemplates a Job is
+// processed once, when created.
+var JobKind = &WorkloadKind{
+	Kind:         "Job",
+	GroupVersion: batchv1.SchemeGroupVersion,
+	New:          func() client.Object { return &batchv1.Job{} },
+	NewList:      func() client.ObjectList { return &batchv1.JobList{} },
+	PodSpec: func(obj client.Object) (*corev1.PodSpec, error) {
+		return &obj.(*batchv1.Job).Spec.Template.Spec, nil
+	},
+	Enabled: OwnsJobs,
+	Accepts: ownsJob,
+}
+
+// ownsJob reports whether the ConfigMaps of job are owned: those of every Job with --jobs, otherwise only those of
+// Jobs spawned by a CronJob
+func ownsJob(cfg *config.OperatorConfig, job client.Object) bool {
+	if cfg.Jobs {
+		return true
+	}
+	owner := metav1.GetControllerOf(job)
+	return owner != nil && owner.Kind == "CronJob"
+}
+
+// JobReconciler adds Jobs as owners of the ConfigMaps their pod template mounts, using the same extraction as for
+// ReplicaSets, so the ConfigMaps generated for a run are deleted with its Job, e.g. once its TTL after finishing
+// expires
+type JobReconciler struct {
+	// ReplicaSetReconciler provides the namespace filter, write holds, write client and events
+	*ReplicaSetReconciler
+}
+
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch
+
+func (r *JobReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	return r.workload(JobKind).Reconcile(ctx, req)
+}
+
+// SetupWithManager sets up the controller with the Manager
+func (r *JobReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return r.workload(JobKind).SetupWithManager(mgr)
+}
@@ -0,0 +1,46 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+var _ = ginkgo.Describe("JobReconciler", func() {
+	ginkgo.It("Should add the Job as owner of the ConfigMaps it mounts", func() {
+		ctx := context.Background()
+		job := &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "ci-run-42", Namespace: "default", UID: "ci-run-42-uid", CreationTimestamp: metav1.Now(),
+			},
+			Spec: batchv1.JobSpec{Template: testReplicaSet("unused", "default", "ci-run-42-env").Spec.Template},
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).
+			WithObjects(job, testConfigMap("ci-run-42-env", "default")).Build()
+		r := &JobReconciler{ReplicaSetReconciler: &ReplicaSetReconciler{Client: c, Scheme: scheme.Scheme,
+			Config: &config.OperatorConfig{Jobs: true}}}
+
+		key := types.NamespacedName{Namespace: "default", Name: "ci-run-42"}
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+		var cm corev1.ConfigMap
+		gomega.Expect(c.Get(ctx, types.NamespacedName{Namespace: "default", Name: "ci-run-42-env"}, &cm)).To(gomega.Succeed())
+		gomega.Expect(cm.OwnerReferences).To(gomega.ConsistOf(gomega.And(
+			gomega.HaveField("APIVersion", "batch/v1"),
+			gomega.HaveField("Kind", "Job"),
+			gomega.HaveField("Name", "ci-run-42"),
+		)))
+		gomega.Expect(managedOwnerUIDs(&cm)).To(gomega.ConsistOf(job.UID))
+	})
+})
@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// reasonKeysNotConsumed is the skip reason of ConfigMaps none of whose keys a workload consumes
+const reasonKeysNotConsumed = "keys_not_consumed"
+
+// consumedKeys returns the keys of the ConfigMap name that the containers of spec consume, and true
+// when a volume mounts or projects it whole or an envFrom source imports it, in which case every key is consumed
+func consumedKeys(spec *corev1.PodSpec, name string) ([]string, bool) {
+	var keys []string
+	containers := append(append([]corev1.Container(nil), spec.InitContainers...), spec.Containers...)
+	for i := range containers {
+		container := &containers[i]
+		for _, source := range container.EnvFrom {
+			if source.ConfigMapRef != nil && source.ConfigMapRef.Name == name {
+				return nil, true
+			}
+		}
+		for _, mount := range container.VolumeMounts {
+			for j := range spec.Volumes {
+				if spec.Volumes[j].Name != mount.Name {
+					continue
+				}
+				mounted, all := volumeKeys(&spec.Volumes[j], name, mount.SubPath)
+				if all {
+					return nil, true
+				}
+				keys = append(keys, mounted...)
+			}
+		}
+		for _, env := range container.Env {
+			if ref := env.ValueFrom; ref != nil && ref.ConfigMapKeyRef != nil && ref.ConfigMapKeyRef.Name == name {
+				keys = append(keys, ref.ConfigMapKeyRef.Key)
+			}
+		}
+	}
+	return keys, false
+}
+
+// volumeKeys returns the keys of the ConfigMap name a mount of volume with the given subPath exposes, whether the
+// volume is the ConfigMap or projects it, and true when it exposes every key
+func volumeKeys(volume *corev1.Volume, name, subPath string) ([]string, bool) {
+	if volume.ConfigMap != nil && volume.ConfigMap.Name == name {
+		return mountedKeys(volume.ConfigMap.Items, subPath)
+	}
+	if volume.Projected == nil {
+		return nil, false
+	}
+	var keys []string
+	for _, source := range volume.Projected.Sources {
+		if source.ConfigMap == nil || source.ConfigMap.Name != name {
+			continue
+		}
+		mounted, all := mountedKeys(source.ConfigMap.Items, subPath)
+		if all {
+			return nil, true
+		}
+		keys = append(keys, mounted...)
+	}
+	return keys, false
+}
+
+// mountedKeys returns the keys a volume mount with the given subPath exposes of a ConfigMap whose items are
+// listed, and true when it exposes every key
+func mountedKeys(items []corev1.KeyToPath, subPath string) ([]string, bool) {
+	if len(items) == 0 {
+		if subPath == "" {
+			return nil, true
+		}
+		key, _, _ := strings.Cut(subPath, "/")
+		return []string{key}, false
+	}
+	var keys []string
+	for _, item := range items {
+		if subPath == "" || subPath == item.Path || strings.HasPrefix(subPath, item.Path+"/") {
+			keys = append(keys, item.Key)
+		}
+	}
+	return keys, false
+}
+
+// consumesConfigMap reports whether the containers of spec consume at least one key cm has
+func consumesConfigMap(spec *corev1.PodSpec, cm *corev1.ConfigMap) bool {
+	keys, all := consumedKeys(spec, cm.Name)
+	if all {
+		return true
+	}
+	for _, key := range keys {
+		if _, ok := cm.Data[key]; ok {
+			return true
+		}
+		if _, ok := cm.BinaryData[key]; ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+var _ = ginkgo.Describe("Key-level consumption", func() {
+	var cm *corev1.ConfigMap
+
+	ginkgo.BeforeEach(func() {
+		cm = testConfigMap("shared", "default")
+		cm.Data = map[string]string{"app.conf": "a", "other.conf": "b"}
+	})
+
+	ginkgo.It("Should treat a whole-volume mount as consuming every key", func() {
+		rs := testReplicaSet("rs-a", "default", "shared")
+		gomega.Expect(consumesConfigMap(&rs.Spec.Template.Spec, cm)).To(gomega.BeTrue())
+	})
+
+	ginkgo.It("Should only count the projected items and subPaths", func() {
+		rs := testReplicaSet("rs-a", "default", "shared")
+		spec := &rs.Spec.Template.Spec
+		spec.Volumes[0].ConfigMap.Items = []corev1.KeyToPath{{Key: "missing.conf", Path: "missing.conf"}}
+		gomega.Expect(consumesConfigMap(spec, cm)).To(gomega.BeFalse())
+
+		spec.Volumes[0].ConfigMap.Items = append(spec.Volumes[0].ConfigMap.Items,
+			corev1.KeyToPath{Key: "app.conf", Path: "conf/app.conf"})
+		gomega.Expect(consumesConfigMap(spec, cm)).To(gomega.BeTrue())
+
+		spec.Containers[0].VolumeMounts[0].SubPath = "missing.conf"
+		gomega.Expect(consumesConfigMap(spec, cm)).To(gomega.BeFalse())
+
+		spec.Volumes[0].ConfigMap.Items = nil
+		spec.Containers[0].VolumeMounts[0].SubPath = "app.conf"
+		gomega.Expect(consumesConfigMap(spec, cm)).To(gomega.BeTrue())
+	})
+
+	ginkgo.It("Should count env key references", func() {
+		rs := testReplicaSet("rs-a", "default", "shared")
+		spec := &rs.Spec.Template.Spec
+		spec.Containers[0].VolumeMounts[0].SubPath = "missing.conf"
+		spec.Containers[0].Env = []corev1.EnvVar{{Name: "OTHER", ValueFrom: &corev1.EnvVarSource{
+			ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "shared"}, Key: "other.conf",
+			},
+		}}}
+		gomega.Expect(consumesConfigMap(spec, cm)).To(gomega.BeTrue())
+	})
+
+	ginkgo.It("Should not own ConfigMaps whose keys are not consumed when enabled", func() {
+		ctx := context.Background()
+		rs := testReplicaSet("rs-a", "default", "shared")
+		rs.CreationTimestamp = metav1.Now()
+		rs.Spec.Template.Spec.Containers[0].VolumeMounts[0].SubPath = "missing.conf"
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(rs, cm).Build()
+		r := &ReplicaSetReconciler{Client: c, Scheme: scheme.Scheme, Config: &config.OperatorConfig{ConsumedKeysOnly: true}}
+
+		ctx, collector := withDecisions(ctx)
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "rs-a"}})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(collector.list()).To(gomega.ConsistOf(gomega.HaveField("Reason", reasonKeysNotConsumed)))
+
+		var got corev1.ConfigMap
+		gomega.Expect(c.Get(ctx, types.NamespacedName{Namespace: "default", Name: "shared"}, &got)).To(gomega.Succeed())
+		gomega.Expect(got.OwnerReferences).To(gomega.BeEmpty())
+	})
+})
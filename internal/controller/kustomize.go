@@ -0,0 +1,210 @@
+package controller
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// kustomizeHashLength is the length of the content hash a kustomize configMapGenerator appends to names
+	kustomizeHashLength = 10
+
+	// kustomizeHashAlphabet are the characters of the hashes kustomize appends: hex digits, with those that could
+	// spell words or look alike swapped for consonants
+	kustomizeHashAlphabet = "bcdfghkmt2456789"
+)
+
+// kustomizeBase returns the name a kustomize configMapGenerator appended a hash to to make name, and false if name
+// has no such hash suffix
+func kustomizeBase(name string) (string, bool) {
+	base, hash, ok := cutLast(name, "-")
+	if !ok || base == "" || len(hash) != kustomizeHashLength {
+		return "", false
+	}
+	for _, c := range hash {
+		if !strings.ContainsRune(kustomizeHashAlphabet, c) {
+			return "", false
+		}
+	}
+	return base, true
+}
+
+// cutLast slices s around the last instance of sep, like strings.Cut does around the first
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
+
+// SupersededConfigMaps returns the generations of kustomize ConfigMaps in scope superseded by a newer one: those
+// with the same name before the hash suffix as a ConfigMap a workload references, created before it, which no
+// workload references and nothing owns. Workloads are listed with reader, whichever kinds are watched.
+func (r *ReplicaSetReconciler) SupersededConfigMaps(
+	ctx context.Context,
+	reader client.Reader,
+) ([]*corev1.ConfigMap, error) {
+	var configMaps corev1.ConfigMapList
+	if err := r.List(ctx, &configMaps); err != nil {
+		return nil, err
+	}
+	referenced, err := r.referencedConfigMaps(ctx, reader)
+	if err != nil {
+		return nil, err
+	}
+
+	generations := map[types.NamespacedName][]*corev1.ConfigMap{}
+	for i := range configMaps.Items {
+		cm := &configMaps.Items[i]
+		if base, ok := kustomizeBase(cm.Name); ok && r.shouldProcessNamespace(ctx, cm.Namespace) {
+			key := types.NamespacedName{Namespace: cm.Namespace, Name: base}
+			generations[key] = append(generations[key], cm)
+		}
+	}
+	var superseded []*corev1.ConfigMap
+	for _, generation := range generations {
+		superseded = append(superseded, supersededGenerations(generation, referenced)...)
+	}
+	sort.Slice(superseded, func(i, j int) bool {
+		a, b := superseded[i], superseded[j]
+		return a.Namespace < b.Namespace || a.Namespace == b.Namespace && a.Name < b.Name
+	})
+	return superseded, nil
+}
+
+// supersededGenerations returns the ConfigMaps of generation, which share a name before the hash suffix, created
+// before its newest referenced one, which nothing references or owns
+func supersededGenerations(
+	generation []*corev1.ConfigMap,
+	referenced map[types.NamespacedName]bool,
+) []*corev1.ConfigMap {
+	var newest time.Time
+	for _, cm := range generation {
+		if referenced[client.ObjectKeyFromObject(cm)] && cm.CreationTimestamp.Time.After(newest) {
+			newest = cm.CreationTimestamp.Time
+		}
+	}
+	var superseded []*corev1.ConfigMap
+	for _, cm := range generation {
+		if !referenced[client.ObjectKeyFromObject(cm)] && len(cm.OwnerReferences) == 0 &&
+			cm.CreationTimestamp.Time.Before(newest) {
+			superseded = append(superseded, cm)
+		}
+	}
+	return superseded
+}
+
+// referencedConfigMaps returns the ConfigMaps an object of any pod-bearing kind references in any way, whether or
+// not the operator watches that kind or owns ConfigMaps for that way: a generation still mounted by an unwatched
+// CronJob must not be deleted
+func (r *ReplicaSetReconciler) referencedConfigMaps(
+	ctx context.Context,
+	reader client.Reader,
+) (map[types.NamespacedName]bool, error) {
+	referenced := map[types.NamespacedName]bool{}
+	// pod has the metadata of the pod template, and annotations may declare extra ConfigMaps
+	mark := func(namespace string, spec *corev1.PodSpec, pod metav1.Object, annotations ...map[string]string) {
+		src := &ReferenceSource{Spec: spec, Annotations: pod.GetAnnotations(), Labels: pod.GetLabels(), Unmounted: true}
+		for _, name := range withExtraConfigMaps(podConfigMapReferences(src, nil), annotations...) {
+			referenced[types.NamespacedName{Namespace: namespace, Name: name}] = true
+		}
+	}
+	var replicaSets appsv1.ReplicaSetList
+	if err := reader.List(ctx, &replicaSets); err != nil {
+		return nil, err
+	}
+	for i := range replicaSets.Items {
+		rs := &replicaSets.Items[i]
+		template := &rs.Spec.Template
+		mark(rs.Namespace, &template.Spec, template, rs.Annotations, template.Annotations)
+	}
+	// Custom kinds are validated on startup
+	custom, _ := ParseCustomKinds(r.Config.CustomKinds)
+	for _, kind := range append(append([]*WorkloadKind{}, workloadKinds...), custom...) {
+		objs, err := kind.list(ctx, reader)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range objs {
+			spec, err := kind.PodSpec(obj)
+			if err != nil {
+				return nil, err
+			}
+			mark(obj.GetNamespace(), spec, obj, obj.GetAnnotations())
+		}
+	}
+	return referenced, nil
+}
+
+// KustomizeCleaner periodically deletes the generations of kustomize ConfigMaps superseded by a newer one, which
+// pile up with every change to a configMapGenerator. It needs leader election, like the other scans.
+type KustomizeCleaner struct {
+	Reconciler *ReplicaSetReconciler
+	// Reader lists the workloads of every kind, which the cache only holds for the watched ones
+	Reader   client.Reader
+	Interval time.Duration
+	Log      logr.Logger
+}
+
+// Start implements manager.Runnable
+func (s *KustomizeCleaner) Start(ctx context.Context) error {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+	for {
+		if err := s.clean(ctx); err != nil {
+			s.Log.Error(err, "Failed to clean up superseded kustomize ConfigMaps")
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// clean deletes the superseded ConfigMaps, unless writes are held back in their namespace
+func (s *KustomizeCleaner) clean(ctx context.Context) error {
+	superseded, err := s.Reconciler.SupersededConfigMaps(ctx, s.Reader)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	holds := map[string]string{}
+	for _, cm := range superseded {
+		hold, ok := holds[cm.Namespace]
+		if !ok {
+			hold = s.Reconciler.holdReason(ctx, cm.Namespace, now, s.Log)
+			holds[cm.Namespace] = hold
+		}
+		if reason := s.Reconciler.protectedReason(cm.Name, cm); reason != "" {
+			s.Log.V(1).Info("Keeping protected superseded kustomize ConfigMap", "configmap", cm.Name,
+				"namespace", cm.Namespace, "reason", reason)
+			continue
+		}
+		if hold != "" {
+			s.Log.Info(hold+": Would delete superseded kustomize ConfigMap", "configmap", cm.Name,
+				"namespace", cm.Namespace)
+			continue
+		}
+		// The preconditions keep a ConfigMap that changed since it was listed, e.g. got an owner
+		precondition := client.Preconditions{UID: &cm.UID, ResourceVersion: &cm.ResourceVersion}
+		if err := s.Reconciler.writer().Delete(ctx, cm, precondition); client.IgnoreNotFound(err) != nil {
+			kustomizeCleanupsTotal.WithLabelValues("failed").Inc()
+			s.Log.Error(err, "Failed to delete superseded kustomize ConfigMap", "configmap", cm.Name,
+				"namespace", cm.Namespace)
+			continue
+		}
+		kustomizeCleanupsTotal.WithLabelValues("deleted").Inc()
+		s.Log.Info("Deleted superseded kustomize ConfigMap", "configmap", cm.Name, "namespace", cm.Namespace)
+	}
+	return nil
+}
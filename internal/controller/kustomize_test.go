@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+var _ = ginkgo.Describe("Kustomize generations", func() {
+	ginkgo.It("Should recognize the hash suffix of generated ConfigMaps", func() {
+		base, ok := kustomizeBase("app-config-5bm2k8mt9d")
+		gomega.Expect(ok).To(gomega.BeTrue())
+		gomega.Expect(base).To(gomega.Equal("app-config"))
+
+		for _, name := range []string{"app-config", "my-deployment", "app-config-5bm2k8mt9", "-5bm2k8mt9d"} {
+			_, ok := kustomizeBase(name)
+			gomega.Expect(ok).To(gomega.BeFalse(), name)
+		}
+	})
+
+	ginkgo.It("Should delete the generations superseded by a referenced one", func() {
+		ctx := context.Background()
+		now := time.Now()
+		generation := func(name string, age time.Duration) *corev1.ConfigMap {
+			cm := testConfigMap(name, "default")
+			cm.CreationTimestamp = metav1.NewTime(now.Add(-age))
+			return cm
+		}
+		owned := generation("app-config-c9dk2b78mh", 3*time.Hour)
+		owned.OwnerReferences = []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "web-old"}}
+		objs := []client.Object{
+			testReplicaSet("web-abc", "default", "app-config-5bm2k8mt9d"),
+			generation("app-config-g2ffh47ctc", 2*time.Hour),
+			owned,
+			generation("app-config-5bm2k8mt9d", time.Hour),
+			generation("app-config-t4k9b8d2fm", time.Minute),
+			generation("other-config-g2ffh47ctc", 2*time.Hour),
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objs...).Build()
+		r := &ReplicaSetReconciler{Client: c, Scheme: scheme.Scheme, Config: &config.OperatorConfig{}}
+
+		superseded, err := r.SupersededConfigMaps(ctx, c)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(superseded).To(gomega.ConsistOf(gomega.HaveField("Name", "app-config-g2ffh47ctc")))
+
+		r.Config.DryRun = true
+		cleaner := &KustomizeCleaner{Reconciler: r, Reader: c, Log: logr.Discard()}
+		gomega.Expect(cleaner.clean(ctx)).To(gomega.Succeed())
+		key := types.NamespacedName{Namespace: "default", Name: "app-config-g2ffh47ctc"}
+		gomega.Expect(c.Get(ctx, key, &corev1.ConfigMap{})).To(gomega.Succeed())
+
+		r.Config.DryRun = false
+		gomega.Expect(cleaner.clean(ctx)).To(gomega.Succeed())
+		gomega.Expect(c.Get(ctx, key, &corev1.ConfigMap{})).NotTo(gomega.Succeed())
+		var left corev1.ConfigMapList
+		gomega.Expect(c.List(ctx, &left)).To(gomega.Succeed())
+		gomega.Expect(left.Items).To(gomega.HaveLen(4))
+	})
+	ginkgo.It("Should keep generations unwatched kinds reference and protected ones", func() {
+		ctx := context.Background()
+		now := time.Now()
+		generation := func(name string, age time.Duration) *corev1.ConfigMap {
+			cm := testConfigMap(name, "default")
+			cm.CreationTimestamp = metav1.NewTime(now.Add(-age))
+			return cm
+		}
+		helm := generation("app-config-t4k9b8d2fm", 3*time.Hour)
+		helm.Labels = map[string]string{helmManagedByLabel: "Helm"}
+		// CronJobs aren't watched, but the generation the nightly run mounts is still in use
+		nightly := &batchv1.CronJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "default"},
+			Spec: batchv1.CronJobSpec{Schedule: "0 2 * * *", JobTemplate: batchv1.JobTemplateSpec{
+				Spec: batchv1.JobSpec{Template: testReplicaSet("nightly", "default", "app-config-c9dk2b78mh").Spec.Template},
+			}},
+		}
+		objs := []client.Object{
+			testReplicaSet("web-abc", "default", "app-config-5bm2k8mt9d"),
+			nightly,
+			helm,
+			generation("app-config-c9dk2b78mh", 3*time.Hour),
+			generation("app-config-g2ffh47ctc", 2*time.Hour),
+			generation("app-config-5bm2k8mt9d", time.Hour),
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objs...).Build()
+		r := &ReplicaSetReconciler{Client: c, Scheme: scheme.Scheme, Config: &config.OperatorConfig{}}
+
+		superseded, err := r.SupersededConfigMaps(ctx, c)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(superseded).To(gomega.ConsistOf(
+			gomega.HaveField("Name", "app-config-g2ffh47ctc"), gomega.HaveField("Name", helm.Name)))
+
+		cleaner := &KustomizeCleaner{Reconciler: r, Reader: c, Log: logr.Discard()}
+		gomega.Expect(cleaner.clean(ctx)).To(gomega.Succeed())
+		var left corev1.ConfigMapList
+		gomega.Expect(c.List(ctx, &left)).To(gomega.Succeed())
+		gomega.Expect(left.Items).To(gomega.ConsistOf(gomega.HaveField("Name", helm.Name),
+			gomega.HaveField("Name", "app-config-c9dk2b78mh"), gomega.HaveField("Name", "app-config-5bm2k8mt9d")))
+	})
+})
@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// ReconcileTracker records per controller when a reconcile last succeeded, so a stuck informer or a wedged
+// queue is noticed while the process itself stays healthy
+type ReconcileTracker struct {
+	// MaxStaleness fails the health check when a controller's last successful reconcile is older; 0 disables it
+	MaxStaleness time.Duration
+
+	mu   sync.RWMutex
+	last map[string]time.Time
+	now  func() time.Time
+}
+
+// NewReconcileTracker returns a tracker whose health check fails after maxStaleness without a successful reconcile
+func NewReconcileTracker(maxStaleness time.Duration) *ReconcileTracker {
+	return &ReconcileTracker{MaxStaleness: maxStaleness, last: make(map[string]time.Time)}
+}
+
+func (t *ReconcileTracker) clock() time.Time {
+	if t.now != nil {
+		return t.now()
+	}
+	return time.Now()
+}
+
+// observe records a successful reconcile of controller
+func (t *ReconcileTracker) observe(controller string) {
+	now := t.clock()
+	lastSuccessfulReconcile.WithLabelValues(controller).Set(float64(now.Unix()))
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.last[controller] = now
+}
+
+// LastSuccess returns when each controller last reconciled successfully
+func (t *ReconcileTracker) LastSuccess() map[string]time.Time {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	last := make(map[string]time.Time, len(t.last))
+	for controller, at := range t.last {
+		last[controller] = at
+	}
+	return last
+}
+
+// Check is a healthz checker failing when a controller hasn't reconciled successfully for MaxStaleness.
+// Controllers that haven't succeeded since startup are not checked: without events, e.g. on a quiet cluster
+// or for a kind that has no objects, there is nothing to reconcile.
+func (t *ReconcileTracker) Check(_ *http.Request) error {
+	if t.MaxStaleness <= 0 {
+		return nil
+	}
+	now := t.clock()
+	var stale []string
+	for controller, at := range t.LastSuccess() {
+		if age := now.Sub(at); age > t.MaxStaleness {
+			stale = append(stale, fmt.Sprintf("%s (%s ago)", controller, age.Truncate(time.Second)))
+		}
+	}
+	if len(stale) == 0 {
+		return nil
+	}
+	sort.Strings(stale)
+	return fmt.Errorf("no successful reconcile within %s: %s", t.MaxStaleness, strings.Join(stale, ", "))
+}
+
+// trackedReconciler records the successful reconciles of a controller's reconciler
+type trackedReconciler struct {
+	reconcile.Reconciler
+	controller string
+	tracker    *ReconcileTracker
+}
+
+func (r *trackedReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	result, err := r.Reconciler.Reconcile(ctx, req)
+	if err == nil {
+		r.tracker.observe(r.controller)
+	}
+	return result, err
+}
+
+// tracked wraps the reconciler of controller to record its successful reconciles when a tracker is set
+func (r *ReplicaSetReconciler) tracked(controller string, rec reconcile.Reconciler) reconcile.Reconciler {
+	if r.Tracker == nil {
+		return rec
+	}
+	return &trackedReconciler{Reconciler: rec, controller: controller, tracker: r.Tracker}
+}
@@ -0,0 +1,55 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+var _ = ginkgo.Describe("ReconcileTracker", func() {
+	ginkgo.It("Should record successful reconciles per controller", func() {
+		now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+		tracker := NewReconcileTracker(time.Hour)
+		tracker.now = func() time.Time { return now }
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+		r := &ReplicaSetReconciler{Client: c, Scheme: scheme.Scheme, Config: &config.OperatorConfig{}, Tracker: tracker}
+
+		_, err := r.tracked("replicaset", r).Reconcile(context.Background(),
+			ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "missing"}})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(tracker.LastSuccess()).To(gomega.Equal(map[string]time.Time{"replicaset": now}))
+	})
+
+	ginkgo.It("Should fail the health check once a controller's last success is too old", func() {
+		now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+		tracker := NewReconcileTracker(time.Hour)
+		tracker.now = func() time.Time { return now }
+		tracker.observe("replicaset")
+		tracker.observe("podtemplate")
+
+		now = now.Add(30 * time.Minute)
+		tracker.observe("podtemplate")
+		gomega.Expect(tracker.Check(nil)).To(gomega.Succeed())
+
+		now = now.Add(45 * time.Minute)
+		err := tracker.Check(nil)
+		gomega.Expect(err).To(gomega.MatchError(gomega.ContainSubstring("replicaset (1h15m0s ago)")))
+		gomega.Expect(err.Error()).NotTo(gomega.ContainSubstring("podtemplate"))
+	})
+
+	ginkgo.It("Should not check without a maximum staleness", func() {
+		tracker := NewReconcileTracker(0)
+		tracker.now = func() time.Time { return time.Unix(0, 0) }
+		tracker.observe("replicaset")
+		tracker.now = time.Now
+		gomega.Expect(tracker.Check(nil)).To(gomega.Succeed())
+	})
+})
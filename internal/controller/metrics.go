@@ -0,0 +1,339 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const metricsNamespace = "configmap_rs_operator"
+
+// Reasons for which an event or reconcile request is dropped before any work is done
+const (
+	dropReasonNamespace        = "namespace_filter"
+	dropReasonStartTime        = "start_time"
+	dropReasonOwnerKind        = "owner_kind"
+	dropReasonDormant          = "dormant"
+	dropReasonWorkloadName     = "workload_name"
+	dropReasonOptIn            = "not_opted_in"
+	dropReasonOptOut           = "opted_out"
+	dropReasonPredicateUpdate  = "predicate_update"
+	dropReasonPredicateDelete  = "predicate_delete"
+	dropReasonPredicateGeneric = "predicate_generic"
+)
+
+// Error classes used to label reconcile errors
+const (
+	errorReasonConflict      = "conflict"
+	errorReasonNotFound      = "not_found"
+	errorReasonForbidden     = "forbidden"
+	errorReasonTimeout       = "timeout"
+	errorReasonWebhookDenied = "webhook_denied"
+	errorReasonOther         = "other"
+)
+
+// Reasons used to label requeues
+const (
+	requeueReasonError       = "error"
+	requeueReasonPaused      = "paused"
+	requeueReasonMaintenance = "maintenance_window"
+)
+
+var (
+	// filteredTotal counts events and requests dropped by the operator's filters, by reason
+	filteredTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "filtered_total",
+			Help:      "Number of events or reconcile requests dropped by the operator's filters, by reason.",
+		},
+		[]string{"reason"},
+	)
+
+	// configMapsPerWorkload observes how many ConfigMaps each reconciled workload references
+	configMapsPerWorkload = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "configmaps_per_workload",
+			Help:      "Number of ConfigMaps referenced by each reconciled workload.",
+			Buckets:   []float64{0, 1, 2, 3, 5, 8, 13, 21, 34, 55},
+		},
+		[]string{"kind"},
+	)
+
+	// reconcileErrorsTotal counts failed reconciles by error class
+	reconcileErrorsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "reconcile_errors_total",
+			Help:      "Number of failed reconciles, by error class.",
+		},
+		[]string{"reason"},
+	)
+
+	// requeuesTotal counts requeued reconcile requests by reason
+	requeuesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "requeues_total",
+			Help:      "Number of reconcile requests requeued, by reason.",
+		},
+		[]string{"reason"},
+	)
+
+	// ownershipChangesTotal counts owner references added or removed by the operator
+	ownershipChangesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "ownership_changes_total",
+			Help:      "Number of owner references added or removed by the operator, by change.",
+		},
+		[]string{"change"},
+	)
+
+	// ownershipChurnAlertsTotal counts ownership churn spikes by namespace
+	ownershipChurnAlertsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "ownership_churn_alerts_total",
+			Help:      "Number of times the ownership change rate in a namespace crossed the churn threshold.",
+		},
+		[]string{"namespace"},
+	)
+
+	// shadowComparisonsTotal counts workloads compared against the active operator in shadow mode
+	shadowComparisonsTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "shadow_comparisons_total",
+			Help:      "Number of workloads whose shadow decisions were compared with the active operator's writes.",
+		},
+	)
+
+	// shadowDivergencesTotal counts shadow decisions that differ from the active operator's writes
+	shadowDivergencesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "shadow_divergences_total",
+			Help:      "Number of shadow decisions that differ from the active operator's writes, by type.",
+		},
+		[]string{"type"},
+	)
+
+	// crossNamespaceReferencesTotal counts ConfigMaps referenced by convention from another namespace
+	crossNamespaceReferencesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "cross_namespace_references_total",
+			Help:      "Number of ConfigMaps referenced from a workload in another namespace, by workload namespace.",
+		},
+		[]string{"namespace"},
+	)
+
+	// backfillNamespaces reports the namespaces completed and remaining in the current single pass
+	backfillNamespaces = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "backfill_namespaces",
+			Help:      "Number of namespaces completed and remaining in the current single pass, by state.",
+		},
+		[]string{"state"},
+	)
+
+	// backfillProcessed reports the ReplicaSets reconciled by the current single pass, across restarts
+	backfillProcessed = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "backfill_processed",
+			Help:      "Number of ReplicaSets reconciled by the current single pass, including resumed runs.",
+		},
+	)
+
+	// backfillETASeconds estimates the time left in the current single pass
+	backfillETASeconds = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "backfill_eta_seconds",
+			Help:      "Estimated number of seconds left in the current single pass.",
+		},
+	)
+
+	// precomputedTotal counts reconciled ReplicaSets by whether their Deployment's ConfigMaps were precomputed
+	precomputedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "precomputed_total",
+			Help:      "Number of reconciled ReplicaSets whose Deployment's ConfigMaps were precomputed, by result.",
+		},
+		[]string{"result"},
+	)
+
+	// lastSuccessfulReconcile records per controller when a reconcile last succeeded
+	lastSuccessfulReconcile = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "last_successful_reconcile_timestamp_seconds",
+			Help:      "Unix time of the last successful reconcile, by controller.",
+		},
+		[]string{"controller"},
+	)
+
+	// watchLastEvent records per watched kind when the informer last delivered an event
+	watchLastEvent = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "watch_last_event_timestamp_seconds",
+			Help:      "Unix time the informer of a kind last delivered a watch event, by kind.",
+		},
+		[]string{"kind"},
+	)
+
+	// watchStallsTotal counts the watch stalls detected by the watchdog
+	watchStallsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "watch_stalls_total",
+			Help:      "Number of times the informer of a kind went silent for abnormally long, by kind.",
+		},
+		[]string{"kind"},
+	)
+
+	// ownershipDrifts reports the drifts found by the last drift scan
+	ownershipDrifts = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "ownership_drifts",
+			Help:      "Number of drifts between recorded and actual owner references found by the last scan, by type.",
+		},
+		[]string{"type"},
+	)
+
+	// rolloutHandoffsTotal counts the ReplicaSets whose ConfigMaps were handed over when a rollout retired them,
+	// by result
+	rolloutHandoffsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "rollout_handoffs_total",
+			Help:      "Number of ReplicaSets scaled down to zero by a rollout whose ConfigMaps were handed over, by result.",
+		},
+		[]string{"result"},
+	)
+
+	// secretOwnerReferencesTotal counts the owner references added to Secrets with --secrets-enabled, by result
+	secretOwnerReferencesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "secret_owner_references_total",
+			Help:      "Number of owner references added to Secrets referenced by ReplicaSets, by result.",
+		},
+		[]string{"result"},
+	)
+
+	// kustomizeCleanupsTotal counts the superseded kustomize ConfigMaps the cleanup deleted, by result
+	kustomizeCleanupsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "kustomize_cleanups_total",
+			Help:      "Number of superseded kustomize ConfigMaps deleted, by result.",
+		},
+		[]string{"result"},
+	)
+
+	// rollbackRevalidationsTotal counts the reactivated ReplicaSets revalidated, by result
+	rollbackRevalidationsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "rollback_revalidations_total",
+			Help:      "Number of ReplicaSets revalidated after scaling up from zero, by result.",
+		},
+		[]string{"result"},
+	)
+
+	// ownersPerApply observes how many owner references each coalesced apply adds to a ConfigMap
+	ownersPerApply = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "owner_references_per_apply",
+			Help:      "Number of owner references each coalesced server-side apply adds to a ConfigMap.",
+			Buckets:   []float64{1, 2, 3, 5, 8, 13},
+		},
+	)
+
+	// conflictingManagers reports the conflicts found by the last conflict scan
+	conflictingManagers = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "conflicting_field_managers",
+			Help:      "Number of operator-managed fields other field managers write found by the last scan, by manager.",
+		},
+		[]string{"manager"},
+	)
+
+	// missingReferences reports the unresolved ConfigMap references found by the last missing-reference scan
+	missingReferences = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "missing_configmap_references",
+			Help:      "Number of required ConfigMap references unresolved past the window in the last scan, by namespace.",
+		},
+		[]string{"namespace"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(filteredTotal, configMapsPerWorkload, reconcileErrorsTotal, requeuesTotal,
+		ownershipChangesTotal, ownershipChurnAlertsTotal, shadowComparisonsTotal, shadowDivergencesTotal,
+		crossNamespaceReferencesTotal, backfillNamespaces, backfillProcessed, backfillETASeconds,
+		precomputedTotal, lastSuccessfulReconcile, watchLastEvent, watchStallsTotal,
+		ownershipDrifts, ownersPerApply, rollbackRevalidationsTotal, rolloutHandoffsTotal, conflictingManagers,
+		missingReferences, secretOwnerReferencesTotal, kustomizeCleanupsTotal)
+}
+
+// recordError counts a failed reconcile and the resulting requeue
+func recordError(err error) {
+	reconcileErrorsTotal.WithLabelValues(classifyError(err)).Inc()
+	requeuesTotal.WithLabelValues(requeueReasonError).Inc()
+}
+
+// recordRequeue counts a requeue that was not caused by an error
+func recordRequeue(reason string) {
+	requeuesTotal.WithLabelValues(reason).Inc()
+}
+
+// classifyError maps an error to a low-cardinality class, separating transient churn
+// (conflicts, timeouts) from systemic failures (forbidden, webhook denials)
+func classifyError(err error) string {
+	switch {
+	case isWebhookDenied(err):
+		return errorReasonWebhookDenied
+	case apierrors.IsConflict(err):
+		return errorReasonConflict
+	case apierrors.IsNotFound(err):
+		return errorReasonNotFound
+	case apierrors.IsForbidden(err):
+		return errorReasonForbidden
+	case apierrors.IsTimeout(err), apierrors.IsServerTimeout(err), errors.Is(err, context.DeadlineExceeded):
+		return errorReasonTimeout
+	default:
+		return errorReasonOther
+	}
+}
+
+// isWebhookDenied reports whether an admission webhook rejected the request
+func isWebhookDenied(err error) bool {
+	var status apierrors.APIStatus
+	if !errors.As(err, &status) {
+		return false
+	}
+	message := status.Status().Message
+	return strings.Contains(message, "admission webhook") && strings.Contains(message, "denied the request")
+}
+
+// recordFiltered increments the drop counter for reason
+func recordFiltered(reason string) {
+	filteredTotal.WithLabelValues(reason).Inc()
+}
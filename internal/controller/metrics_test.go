@@ -0,0 +1,76 @@
+package controller
+
+import (
+	"context"
+	"errors"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+var _ = ginkgo.Describe("Metrics", func() {
+	ginkgo.It("Should count requests dropped by the namespace filter", func() {
+		reconciler := &ReplicaSetReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).Build(),
+			Scheme: scheme.Scheme,
+			Config: &config.OperatorConfig{NamespaceRegex: []string{"^production$"}},
+		}
+		before := testutil.ToFloat64(filteredTotal.WithLabelValues(dropReasonNamespace))
+
+		_, err := reconciler.Reconcile(context.Background(), reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: "test-rs", Namespace: "default"},
+		})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+		gomega.Expect(testutil.ToFloat64(filteredTotal.WithLabelValues(dropReasonNamespace))).To(gomega.Equal(before + 1))
+	})
+
+	ginkgo.It("Should observe the number of ConfigMaps per workload", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).
+			WithObjects(testReplicaSet("test-rs", "default", "a", "b", "c")).Build()
+		reconciler := &ReplicaSetReconciler{Client: c, Scheme: scheme.Scheme, Config: &config.OperatorConfig{}}
+		histogram := func() *dto.Histogram {
+			var m dto.Metric
+			observer := configMapsPerWorkload.WithLabelValues("ReplicaSet").(prometheus.Metric)
+			gomega.Expect(observer.Write(&m)).To(gomega.Succeed())
+			return m.GetHistogram()
+		}
+		before := histogram()
+
+		_, err := reconciler.Reconcile(context.Background(), reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: "test-rs", Namespace: "default"},
+		})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+		after := histogram()
+		gomega.Expect(after.GetSampleCount()).To(gomega.Equal(before.GetSampleCount() + 1))
+		gomega.Expect(after.GetSampleSum()).To(gomega.Equal(before.GetSampleSum() + 3))
+	})
+
+	ginkgo.DescribeTable("Should classify errors by reason",
+		func(err error, reason string) {
+			gomega.Expect(classifyError(err)).To(gomega.Equal(reason))
+		},
+		ginkgo.Entry("conflict", apierrors.NewConflict(cmResource, "cm", errors.New("stale")), errorReasonConflict),
+		ginkgo.Entry("not found", apierrors.NewNotFound(cmResource, "cm"), errorReasonNotFound),
+		ginkgo.Entry("forbidden", apierrors.NewForbidden(cmResource, "cm", errors.New("rbac")), errorReasonForbidden),
+		ginkgo.Entry("timeout", apierrors.NewTimeoutError("slow", 1), errorReasonTimeout),
+		ginkgo.Entry("deadline", context.DeadlineExceeded, errorReasonTimeout),
+		ginkgo.Entry("webhook denied", apierrors.NewBadRequest(
+			`admission webhook "validate.example.com" denied the request: no`), errorReasonWebhookDenied),
+		ginkgo.Entry("other", errors.New("boom"), errorReasonOther),
+	)
+})
+
+var cmResource = schema.GroupResource{Resource: "configmaps"}
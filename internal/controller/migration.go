@@ -0,0 +1,222 @@
+package controller
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// SemanticsVersionAnnotation records which version of the ownership semantics the operator-written
+// metadata of a ConfigMap follows, so it can be upgraded when the semantics change between releases
+const SemanticsVersionAnnotation = "configmap-rs-operator/semantics-version"
+
+// defaultMigrationBatchSize is the number of ConfigMaps listed per page during a migration
+const defaultMigrationBatchSize = 100
+
+// Migration upgrades the operator-written metadata of a ConfigMap to Version
+type Migration struct {
+	// Version is the semantics version the migration upgrades to; migrations run in order
+	Version int
+
+	// Name describes the migration in logs
+	Name string
+
+	// Apply upgrades cm in place
+	Apply func(cm *corev1.ConfigMap)
+}
+
+// migrations lists every change of the ownership semantics, in order. Version N is at index N-1.
+var migrations = []Migration{
+	{Version: 1, Name: "record-legacy-owners", Apply: recordLegacyOwners},
+}
+
+// SemanticsVersion returns the ownership semantics version written by this release
+func SemanticsVersion() int {
+	return len(migrations)
+}
+
+// semanticsVersion returns the version recorded on cm; unversioned metadata is version 0
+func semanticsVersion(cm *corev1.ConfigMap) int {
+	version, err := strconv.Atoi(cm.Annotations[SemanticsVersionAnnotation])
+	if err != nil {
+		return 0
+	}
+	return version
+}
+
+// upgradeSemantics applies the pending migrations to cm and stamps the current version.
+// It returns the names of the applied migrations; metadata from a newer release is left untouched.
+func upgradeSemantics(cm *corev1.ConfigMap) []string {
+	version := semanticsVersion(cm)
+	if version >= SemanticsVersion() {
+		return nil
+	}
+	var applied []string
+	for _, m := range migrations[version:] {
+		m.Apply(cm)
+		applied = append(applied, m.Name)
+	}
+	if cm.Annotations == nil {
+		cm.Annotations = make(map[string]string)
+	}
+	cm.Annotations[SemanticsVersionAnnotation] = strconv.Itoa(SemanticsVersion())
+	return applied
+}
+
+// recordLegacyOwners records ReplicaSet owner references written before the provenance annotation
+// existed. The operator only ever adds non-controller references, so controller references are left out.
+func recordLegacyOwners(cm *corev1.ConfigMap) {
+	if len(managedOwnerUIDs(cm)) > 0 {
+		return
+	}
+	for _, ref := range cm.OwnerReferences {
+		if ref.Kind == "ReplicaSet" && (ref.Controller == nil || !*ref.Controller) {
+			addManagedOwner(cm, ref.UID)
+		}
+	}
+}
+
+// isOperatorManaged reports whether cm carries metadata the operator may have written
+func isOperatorManaged(cm *corev1.ConfigMap) bool {
+	if len(managedOwnerUIDs(cm)) > 0 {
+		return true
+	}
+	return slices.ContainsFunc(cm.OwnerReferences, func(ref metav1.OwnerReference) bool {
+		return ref.Kind == "ReplicaSet"
+	})
+}
+
+// MigrationOptions controls a semantics migration run
+type MigrationOptions struct {
+	// Namespace limits the migration to a single namespace; empty means all namespaces
+	Namespace string
+
+	// DryRun only logs what would be migrated
+	DryRun bool
+
+	// BatchSize is the number of ConfigMaps listed per page (default: 100)
+	BatchSize int64
+
+	// BatchInterval is the pause between pages, to spread the load on the API server
+	BatchInterval time.Duration
+	// Hold, if set, returns why writes to a namespace must be held back, e.g. by the kill switch; the ConfigMaps
+	// of a held namespace are logged and migrated by a later run
+	Hold func(ctx context.Context, namespace string) string
+}
+
+// MigrationResult summarizes a migration run
+type MigrationResult struct {
+	Scanned  int `json:"scanned"`
+	Migrated int `json:"migrated"`
+}
+
+// MigrateConfigMaps upgrades the operator-written metadata of every ConfigMap in scope to the current
+// semantics version. Progress is recorded on each ConfigMap, so an interrupted run resumes where it
+// stopped when started again, and a ConfigMap changed concurrently is simply picked up by the next run.
+func MigrateConfigMaps(
+	ctx context.Context,
+	reader client.Reader,
+	writer client.Writer,
+	opts MigrationOptions,
+	logger logr.Logger,
+) (MigrationResult, error) {
+	var result MigrationResult
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultMigrationBatchSize
+	}
+
+	continueToken := ""
+	for {
+		var list corev1.ConfigMapList
+		if err := reader.List(ctx, &list, client.InNamespace(opts.Namespace),
+			client.Limit(batchSize), client.Continue(continueToken)); err != nil {
+			return result, err
+		}
+
+		for i := range list.Items {
+			cm := &list.Items[i]
+			result.Scanned++
+			if !isOperatorManaged(cm) || semanticsVersion(cm) >= SemanticsVersion() {
+				continue
+			}
+
+			original := cm.DeepCopy()
+			applied := upgradeSemantics(cm)
+			if opts.DryRun {
+				logger.Info("DRY-RUN: Would migrate ConfigMap", "configmap", cm.Name, "namespace", cm.Namespace,
+					"from", semanticsVersion(original), "migrations", applied)
+				result.Migrated++
+				continue
+			}
+
+			if hold := holdOf(ctx, opts.Hold, cm.Namespace); hold != "" {
+				logger.Info(hold+": Would migrate ConfigMap", "configmap", cm.Name, "namespace", cm.Namespace,
+					"from", semanticsVersion(original), "migrations", applied)
+				continue
+			}
+
+			patch := client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{})
+			if err := writer.Patch(ctx, cm, patch); err != nil {
+				logger.Error(err, "Failed to migrate ConfigMap", "configmap", cm.Name, "namespace", cm.Namespace)
+				return result, err
+			}
+			logger.Info("Migrated ConfigMap", "configmap", cm.Name, "namespace", cm.Namespace,
+				"from", semanticsVersion(original), "migrations", applied)
+			result.Migrated++
+		}
+
+		continueToken = list.Continue
+		if continueToken == "" {
+			return result, nil
+		}
+		if opts.BatchInterval > 0 {
+			select {
+			case <-ctx.Done():
+				return result, ctx.Err()
+			case <-time.After(opts.BatchInterval):
+			}
+		}
+	}
+}
+
+// MigrationRunner migrates previously written metadata once, after the manager starts.
+// It needs leader election, so only the active replica migrates.
+type MigrationRunner struct {
+	Reader  client.Reader
+	Writer  client.Writer
+	Options MigrationOptions
+	Log     logr.Logger
+}
+
+// Start implements manager.Runnable. A failed migration is logged rather than stopping the
+// operator; the next start resumes it. ConfigMaps held back by the kill switch or the maintenance windows are
+// retried until they are released.
+func (m *MigrationRunner) Start(ctx context.Context) error {
+	m.Log.Info("Starting semantics migration", "version", SemanticsVersion(), "dryRun", m.Options.DryRun)
+	for {
+		opts := m.Options
+		held := trackHolds(&opts.Hold)
+		result, err := MigrateConfigMaps(ctx, m.Reader, m.Writer, opts, m.Log)
+		if err != nil {
+			m.Log.Error(err, "Semantics migration did not complete, it resumes on the next start",
+				"scanned", result.Scanned, "migrated", result.Migrated)
+			return nil
+		}
+		if !*held {
+			m.Log.Info("Semantics migration complete", "scanned", result.Scanned, "migrated", result.Migrated)
+			return nil
+		}
+		m.Log.Info("Semantics migration held back, retrying", "scanned", result.Scanned,
+			"migrated", result.Migrated, "after", pausedRequeueInterval)
+		if !waitHeld(ctx) {
+			return nil
+		}
+	}
+}
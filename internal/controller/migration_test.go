@@ -0,0 +1,83 @@
+package controller
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = ginkgo.Describe("Semantics migration", func() {
+	ctx := context.Background()
+	isController := true
+
+	legacy := func(name string) *corev1.ConfigMap {
+		cm := testConfigMap(name, "default")
+		cm.OwnerReferences = []metav1.OwnerReference{
+			{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "rs", UID: "rs-uid"},
+			{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "ctrl", UID: "ctrl-uid", Controller: &isController},
+		}
+		return cm
+	}
+
+	ginkgo.It("Should record legacy owners and stamp the current version", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).
+			WithObjects(legacy("legacy"), testConfigMap("unrelated", "default")).Build()
+
+		result, err := MigrateConfigMaps(ctx, c, c, MigrationOptions{BatchSize: 1}, logr.Discard())
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(result).To(gomega.Equal(MigrationResult{Scanned: 2, Migrated: 1}))
+
+		var cm corev1.ConfigMap
+		gomega.Expect(c.Get(ctx, types.NamespacedName{Name: "legacy", Namespace: "default"}, &cm)).To(gomega.Succeed())
+		gomega.Expect(managedOwnerUIDs(&cm)).To(gomega.Equal([]types.UID{"rs-uid"}))
+		gomega.Expect(cm.Annotations[SemanticsVersionAnnotation]).To(gomega.Equal(strconv.Itoa(SemanticsVersion())))
+
+		gomega.Expect(c.Get(ctx, types.NamespacedName{Name: "unrelated", Namespace: "default"}, &cm)).To(gomega.Succeed())
+		gomega.Expect(cm.Annotations).NotTo(gomega.HaveKey(SemanticsVersionAnnotation))
+
+		result, err = MigrateConfigMaps(ctx, c, c, MigrationOptions{}, logr.Discard())
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(result.Migrated).To(gomega.Equal(0), "migrated ConfigMaps are not migrated again")
+	})
+
+	ginkgo.It("Should not write in dry-run mode", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(legacy("legacy")).Build()
+
+		result, err := MigrateConfigMaps(ctx, c, c, MigrationOptions{DryRun: true}, logr.Discard())
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(result.Migrated).To(gomega.Equal(1))
+
+		var cm corev1.ConfigMap
+		gomega.Expect(c.Get(ctx, client.ObjectKey{Name: "legacy", Namespace: "default"}, &cm)).To(gomega.Succeed())
+		gomega.Expect(cm.Annotations).To(gomega.BeEmpty())
+	})
+
+	ginkgo.It("Should not write to held namespaces", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(legacy("legacy")).Build()
+
+		opts := MigrationOptions{Hold: func(context.Context, string) string { return holdMaintenance }}
+		result, err := MigrateConfigMaps(ctx, c, c, opts, logr.Discard())
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(result.Migrated).To(gomega.Equal(0))
+
+		var cm corev1.ConfigMap
+		gomega.Expect(c.Get(ctx, client.ObjectKey{Name: "legacy", Namespace: "default"}, &cm)).To(gomega.Succeed())
+		gomega.Expect(cm.Annotations).To(gomega.BeEmpty())
+	})
+
+	ginkgo.It("Should leave metadata from a newer release untouched", func() {
+		cm := legacy("newer")
+		cm.Annotations = map[string]string{SemanticsVersionAnnotation: strconv.Itoa(SemanticsVersion() + 1)}
+		gomega.Expect(upgradeSemantics(cm)).To(gomega.BeEmpty())
+		gomega.Expect(managedOwnerUIDs(cm)).To(gomega.BeEmpty())
+	})
+})
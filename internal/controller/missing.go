@@ -0,0 +1,129 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// MissingReference is a required ConfigMap reference of a ReplicaSet that stayed unresolved for longer than
+// --missing-reference-window, usually a broken deployment whose pods can't start
+type MissingReference struct {
+	Namespace  string `json:"namespace"`
+	ReplicaSet string `json:"replicaSet"`
+	ConfigMap  string `json:"configMap"`
+
+	// Since is when the ReplicaSet was created; the reference has been unresolved at least since then
+	Since time.Time `json:"since"`
+}
+
+// warnMissingConfigMap records a Warning Event on the workload obj, whose pod spec references the ConfigMap name
+// that doesn't exist. Optional references are expected to be absent at times and aren't reported.
+func (r *ReplicaSetReconciler) warnMissingConfigMap(obj client.Object, spec *corev1.PodSpec, name string) {
+	if isOptionalReference(spec, name) {
+		return
+	}
+	r.recordEvent(obj, corev1.EventTypeWarning, "ConfigMapNotFound",
+		"Referenced ConfigMap %s does not exist in namespace %s", name, obj.GetNamespace())
+}
+
+// missingReference returns the reference of rs to the ConfigMap name, which doesn't exist, if it counts as
+// unresolved: the reference is required, the ReplicaSet is in scope, wants replicas, and was created longer
+// than the window ago, so a ConfigMap created right after its workload isn't reported
+func (r *ReplicaSetReconciler) missingReference(
+	ctx context.Context,
+	rs *appsv1.ReplicaSet,
+	name string,
+	now time.Time,
+) (MissingReference, bool) {
+	if !r.shouldProcessNamespace(ctx, rs.Namespace) || replicas(rs) == 0 ||
+		now.Sub(rs.CreationTimestamp.Time) < r.Config.MissingReferenceWindow ||
+		isOptionalReference(&rs.Spec.Template.Spec, name) {
+		return MissingReference{}, false
+	}
+	return MissingReference{
+		Namespace: rs.Namespace, ReplicaSet: rs.Name, ConfigMap: name, Since: rs.CreationTimestamp.UTC(),
+	}, true
+}
+
+// MissingReferences scans every ReplicaSet for required references to ConfigMaps that stayed missing for longer
+// than --missing-reference-window
+func (r *ReplicaSetReconciler) MissingReferences(ctx context.Context) ([]MissingReference, error) {
+	var configMaps corev1.ConfigMapList
+	if err := r.List(ctx, &configMaps); err != nil {
+		return nil, err
+	}
+	var replicaSets appsv1.ReplicaSetList
+	if err := r.List(ctx, &replicaSets); err != nil {
+		return nil, err
+	}
+	exists := make(map[types.NamespacedName]bool, len(configMaps.Items))
+	for i := range configMaps.Items {
+		exists[client.ObjectKeyFromObject(&configMaps.Items[i])] = true
+	}
+
+	now := time.Now()
+	missing := []MissingReference{}
+	for i := range replicaSets.Items {
+		rs := &replicaSets.Items[i]
+		for _, name := range r.extractConfigMapVolumes(rs) {
+			if exists[types.NamespacedName{Namespace: rs.Namespace, Name: name}] {
+				continue
+			}
+			if ref, ok := r.missingReference(ctx, rs, name, now); ok {
+				missing = append(missing, ref)
+			}
+		}
+	}
+	return missing, nil
+}
+
+// MissingReferenceScanner periodically counts the unresolved ConfigMap references per namespace as a metric, so
+// broken deployments are discoverable cluster-wide. It only reads, but runs on the leader like the other scans.
+type MissingReferenceScanner struct {
+	Reconciler *ReplicaSetReconciler
+	Interval   time.Duration
+	Log        logr.Logger
+}
+
+// Start implements manager.Runnable
+func (s *MissingReferenceScanner) Start(ctx context.Context) error {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+	for {
+		if err := s.scan(ctx); err != nil {
+			s.Log.Error(err, "Failed to scan for missing ConfigMap references")
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// scan runs one scan and exports its counts
+func (s *MissingReferenceScanner) scan(ctx context.Context) error {
+	missing, err := s.Reconciler.MissingReferences(ctx)
+	if err != nil {
+		return err
+	}
+	counts := map[string]int{}
+	for _, ref := range missing {
+		counts[namespaceLabel(ref.Namespace)]++
+	}
+	missingReferences.Reset()
+	for namespace, n := range counts {
+		missingReferences.WithLabelValues(namespace).Set(float64(n))
+	}
+	if len(missing) > 0 {
+		s.Log.V(1).Info("Found unresolved ConfigMap references", "references", len(missing),
+			"namespaces", len(counts))
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+var _ = ginkgo.Describe("Missing ConfigMaps", func() {
+	ginkgo.It("Should warn about missing ConfigMaps the workload requires", func() {
+		ctx := context.Background()
+		optional := true
+		rs := testReplicaSet("web", "default", "app-config", "overrides", "present")
+		rs.Spec.Template.Spec.Volumes[1].ConfigMap.Optional = &optional
+		recorder := record.NewFakeRecorder(10)
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(rs, testConfigMap("present", "default")).Build()
+		r := &ReplicaSetReconciler{Client: c, Scheme: scheme.Scheme, Config: &config.OperatorConfig{}, Recorder: recorder}
+
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "web"}})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(<-recorder.Events).To(gomega.And(
+			gomega.ContainSubstring("Warning ConfigMapNotFound"), gomega.ContainSubstring("app-config")))
+		gomega.Expect(<-recorder.Events).To(gomega.ContainSubstring("OwnerReferenceAdded"))
+		gomega.Expect(recorder.Events).To(gomega.BeEmpty())
+	})
+
+	ginkgo.It("Should report references missing past the window in the inventory and metrics", func() {
+		ctx := context.Background()
+		old := testReplicaSet("old", "missing-test", "gone", "present")
+		old.CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Hour))
+		fresh := testReplicaSet("fresh", "missing-test", "gone")
+		fresh.CreationTimestamp = metav1.Now()
+		scaledDown := testReplicaSet("scaled-down", "missing-test", "gone")
+		scaledDown.CreationTimestamp = old.CreationTimestamp
+		scaledDown.Spec.Replicas = int32Ptr(0)
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).
+			WithObjects(old, fresh, scaledDown, testConfigMap("present", "missing-test")).Build()
+		r := &ReplicaSetReconciler{Client: c, Scheme: scheme.Scheme,
+			Config: &config.OperatorConfig{MissingReferenceWindow: 5 * time.Minute}}
+
+		inv, err := r.Inventory(ctx)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(inv.Missing).To(gomega.ConsistOf(gomega.And(
+			gomega.HaveField("ReplicaSet", "old"), gomega.HaveField("ConfigMap", "gone"))))
+
+		scanner := &MissingReferenceScanner{Reconciler: r, Log: logr.Discard()}
+		gomega.Expect(scanner.scan(ctx)).To(gomega.Succeed())
+		gomega.Expect(testutil.ToFloat64(missingReferences.WithLabelValues("missing-test"))).To(gomega.Equal(1.0))
+	})
+})
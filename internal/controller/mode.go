@@ -0,0 +1,39 @@
+package controller
+
+import "fmt"
+
+// What a process of the operator runs, so the webhook server and the controllers can be deployed, scaled and
+// upgraded apart from the same binary
+const (
+	// ModeAll runs the controllers and the webhook server
+	ModeAll = "all"
+	// ModeController runs the controllers, their background runnables and the administrative endpoints
+	ModeController = "controller"
+	// ModeWebhook runs the webhook server only; every replica serves admission, so it takes no part in leader
+	// election. It is rejected until the operator registers admission handlers.
+	ModeWebhook = "webhook"
+)
+
+// ValidateMode returns an error for unknown modes and for ModeWebhook, whose webhook server would serve no
+// admission handlers; empty means ModeAll
+func ValidateMode(mode string) error {
+	switch mode {
+	case "", ModeAll, ModeController:
+		return nil
+	case ModeWebhook:
+		return fmt.Errorf("mode %q is not supported: the operator registers no admission handlers, "+
+			"use the policy subcommand for admission policies", mode)
+	default:
+		return fmt.Errorf("invalid mode %q: expected %s or %s", mode, ModeController, ModeAll)
+	}
+}
+
+// ServesWebhooks reports whether mode runs the webhook server
+func ServesWebhooks(mode string) bool {
+	return mode != ModeController
+}
+
+// RunsControllers reports whether mode runs the controllers
+func RunsControllers(mode string) bool {
+	return mode != ModeWebhook
+}
@@ -0,0 +1,24 @@
+package controller
+
+import (
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+)
+
+var _ = ginkgo.Describe("Mode", func() {
+	ginkgo.It("Should reject unknown modes and the webhook mode", func() {
+		gomega.Expect(ValidateMode("admission")).To(gomega.HaveOccurred())
+		gomega.Expect(ValidateMode(ModeWebhook)).To(gomega.MatchError(gomega.ContainSubstring("no admission handlers")))
+		for _, mode := range []string{"", ModeAll, ModeController} {
+			gomega.Expect(ValidateMode(mode)).To(gomega.Succeed())
+		}
+	})
+
+	ginkgo.It("Should split the webhook server from the controllers", func() {
+		gomega.Expect(ServesWebhooks(ModeAll) && RunsControllers(ModeAll)).To(gomega.BeTrue())
+		gomega.Expect(ServesWebhooks(ModeController)).To(gomega.BeFalse())
+		gomega.Expect(RunsControllers(ModeController)).To(gomega.BeTrue())
+		gomega.Expect(ServesWebhooks(ModeWebhook)).To(gomega.BeTrue())
+		gomega.Expect(RunsControllers(ModeWebhook)).To(gomega.BeFalse())
+	})
+})
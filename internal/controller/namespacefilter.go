@@ -0,0 +1,211 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Syntaxes of the --namespace-regex patterns, selected with --namespace-match
+const (
+	// NamespaceMatchAuto reads the patterns as globs when they look like globs, and as regexes otherwise
+	NamespaceMatchAuto = "auto"
+
+	// NamespaceMatchRegex reads the patterns as regular expressions matching anywhere in the name
+	NamespaceMatchRegex = "regex"
+
+	// NamespaceMatchGlob reads the patterns as globs matching the whole name, where * matches any run of
+	// characters, ? matches one character and a leading ! excludes the names the rest of the pattern matches
+	NamespaceMatchGlob = "glob"
+)
+
+// regexOnlyChars are the characters only regular expressions use; namespace names never contain them
+const regexOnlyChars = `^$.+()[]{}|\`
+
+// NamespacePatterns returns the include and exclude regular expressions of the --namespace-regex patterns read
+// in the syntax of mode. In auto mode the patterns are globs if one of them uses *, ? or a leading ! and none
+// uses a character only regular expressions use, so plain names and existing regexes keep their meaning.
+func NamespacePatterns(patterns []string, mode string) (include, exclude []string, err error) {
+	switch mode {
+	case "", NamespaceMatchAuto:
+		if !looksLikeGlobs(patterns) {
+			return patterns, nil, nil
+		}
+	case NamespaceMatchRegex:
+		return patterns, nil, nil
+	case NamespaceMatchGlob:
+	default:
+		return nil, nil, fmt.Errorf("invalid namespace match %q: expected %s, %s or %s",
+			mode, NamespaceMatchAuto, NamespaceMatchRegex, NamespaceMatchGlob)
+	}
+	for _, pattern := range patterns {
+		if negated, ok := strings.CutPrefix(pattern, "!"); ok {
+			exclude = append(exclude, globRegex(negated))
+		} else {
+			include = append(include, globRegex(pattern))
+		}
+	}
+	return include, exclude, nil
+}
+
+// looksLikeGlobs reports whether patterns use glob syntax and nothing specific to regular expressions
+func looksLikeGlobs(patterns []string) bool {
+	glob := false
+	for _, pattern := range patterns {
+		if strings.ContainsAny(pattern, regexOnlyChars) {
+			return false
+		}
+		if strings.ContainsAny(pattern, "*?") || strings.HasPrefix(pattern, "!") {
+			glob = true
+		}
+	}
+	return glob
+}
+
+// globRegex returns the anchored regular expression matching the names the glob matches
+func globRegex(glob string) string {
+	quoted := regexp.QuoteMeta(glob)
+	quoted = strings.ReplaceAll(quoted, `\*`, ".*")
+	quoted = strings.ReplaceAll(quoted, `\?`, ".")
+	return "^" + quoted + "$"
+}
+
+// NamespaceFilter selects namespaces with the --namespace-regex patterns, compiled once, leaving out those
+// labeled with the DisabledNamespaceLabel. The result is cached per namespace and kept up to date by the
+// Namespace informer, so checking an event is a map lookup.
+type NamespaceFilter struct {
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+
+	mu       sync.RWMutex
+	matches  map[string]bool
+	disabled map[string]bool
+}
+
+// NewNamespaceFilter compiles the include and exclude patterns. A namespace is selected when it matches an
+// include pattern, or there are none, and matches no exclude pattern.
+func NewNamespaceFilter(include, exclude []string) (*NamespaceFilter, error) {
+	f := &NamespaceFilter{matches: map[string]bool{}, disabled: map[string]bool{}}
+	var err error
+	if f.include, err = compileNamespacePatterns(include); err != nil {
+		return nil, err
+	}
+	if f.exclude, err = compileNamespacePatterns(exclude); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func compileNamespacePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid namespace regex %q: %w", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// selectsAll reports whether the filter has no patterns and so selects every namespace
+func (f *NamespaceFilter) selectsAll() bool {
+	return len(f.include) == 0 && len(f.exclude) == 0
+}
+
+// Matches reports whether namespace is selected, evaluating the patterns only the first time it is seen
+func (f *NamespaceFilter) Matches(namespace string) bool {
+	f.mu.RLock()
+	matched, ok := f.matches[namespace]
+	disabled := f.disabled[namespace]
+	f.mu.RUnlock()
+	if disabled {
+		return false
+	}
+	if f.selectsAll() {
+		return true
+	}
+	if ok {
+		return matched
+	}
+	return f.add(namespace)
+}
+
+// add evaluates the patterns for namespace and caches the result
+func (f *NamespaceFilter) add(namespace string) bool {
+	matched := len(f.include) == 0
+	for _, re := range f.include {
+		if re.MatchString(namespace) {
+			matched = true
+			break
+		}
+	}
+	for _, re := range f.exclude {
+		if matched && re.MatchString(namespace) {
+			matched = false
+		}
+	}
+	f.mu.Lock()
+	f.matches[namespace] = matched
+	f.mu.Unlock()
+	return matched
+}
+
+// forget drops the cached result of a deleted namespace
+func (f *NamespaceFilter) forget(namespace string) {
+	f.mu.Lock()
+	delete(f.matches, namespace)
+	delete(f.disabled, namespace)
+	f.mu.Unlock()
+}
+
+// label records whether ns opted out with the DisabledNamespaceLabel
+func (f *NamespaceFilter) label(ns client.Object) {
+	f.mu.Lock()
+	if ns.GetLabels()[DisabledNamespaceLabel] == "true" {
+		f.disabled[ns.GetName()] = true
+	} else {
+		delete(f.disabled, ns.GetName())
+	}
+	f.mu.Unlock()
+}
+
+// Watch keeps the cache in step with the Namespace informer: namespaces are evaluated when they are created,
+// before their first workload event, relabeled when they change and dropped when they are deleted
+func (f *NamespaceFilter) Watch(ctx context.Context, informers cache.Informers) error {
+	informer, err := informers.GetInformer(ctx, &corev1.Namespace{})
+	if err != nil {
+		return err
+	}
+	_, err = informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if ns, ok := obj.(client.Object); ok {
+				f.label(ns)
+				if !f.selectsAll() {
+					f.add(ns.GetName())
+				}
+			}
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			if ns, ok := obj.(client.Object); ok {
+				f.label(ns)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if ns, ok := obj.(client.Object); ok {
+				f.forget(ns.GetName())
+			}
+		},
+	})
+	return err
+}
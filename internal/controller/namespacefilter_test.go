@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = ginkgo.Describe("NamespaceFilter", func() {
+	ginkgo.It("Should select every namespace without patterns", func() {
+		f, err := NewNamespaceFilter(nil, nil)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(f.Matches("anything")).To(gomega.BeTrue())
+		gomega.Expect(f.matches).To(gomega.BeEmpty())
+	})
+
+	ginkgo.It("Should cache the result per namespace until it is deleted", func() {
+		f, err := NewNamespaceFilter([]string{"^prod-", "^staging$"}, nil)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(f.Matches("prod-a")).To(gomega.BeTrue())
+		gomega.Expect(f.Matches("staging")).To(gomega.BeTrue())
+		gomega.Expect(f.Matches("dev")).To(gomega.BeFalse())
+		gomega.Expect(f.matches).To(gomega.Equal(map[string]bool{"prod-a": true, "staging": true, "dev": false}))
+
+		f.forget("dev")
+		gomega.Expect(f.matches).NotTo(gomega.HaveKey("dev"))
+	})
+
+	ginkgo.It("Should leave out the namespaces labeled as disabled until the label is removed", func() {
+		f, err := NewNamespaceFilter(nil, nil)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name: "team-a", Labels: map[string]string{DisabledNamespaceLabel: "true"},
+		}}
+		f.label(ns)
+		gomega.Expect(f.Matches("team-a")).To(gomega.BeFalse())
+		gomega.Expect(f.Matches("team-b")).To(gomega.BeTrue())
+
+		ns.Labels[DisabledNamespaceLabel] = "false"
+		f.label(ns)
+		gomega.Expect(f.Matches("team-a")).To(gomega.BeTrue())
+		f.label(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name: "team-b", Labels: map[string]string{DisabledNamespaceLabel: "true"},
+		}})
+		f.forget("team-b")
+		gomega.Expect(f.disabled).To(gomega.BeEmpty())
+	})
+
+	ginkgo.It("Should reject invalid patterns", func() {
+		_, err := NewNamespaceFilter([]string{"("}, nil)
+		gomega.Expect(err).To(gomega.MatchError(gomega.ContainSubstring("invalid namespace regex")))
+	})
+
+	ginkgo.It("Should read glob patterns with exclusions", func() {
+		include, exclude, err := NamespacePatterns([]string{"team-*", "!team-?-sandbox"}, NamespaceMatchAuto)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(include).To(gomega.Equal([]string{"^team-.*$"}))
+		gomega.Expect(exclude).To(gomega.Equal([]string{"^team-.-sandbox$"}))
+
+		f, err := NewNamespaceFilter(include, exclude)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(f.Matches("team-a")).To(gomega.BeTrue())
+		gomega.Expect(f.Matches("team-a-sandbox")).To(gomega.BeFalse())
+		gomega.Expect(f.Matches("my-team-a")).To(gomega.BeFalse())
+
+		include, exclude, err = NamespacePatterns([]string{"!kube-*"}, NamespaceMatchGlob)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(namespaceMatches(include, exclude, "default")).To(gomega.BeTrue())
+		gomega.Expect(namespaceMatches(include, exclude, "kube-system")).To(gomega.BeFalse())
+	})
+
+	ginkgo.It("Should keep reading regexes and plain names as regexes in auto mode", func() {
+		for _, patterns := range [][]string{{"^team-.*"}, {"team-a"}, {"prod|staging"}} {
+			include, exclude, err := NamespacePatterns(patterns, NamespaceMatchAuto)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(include).To(gomega.Equal(patterns))
+			gomega.Expect(exclude).To(gomega.BeEmpty())
+		}
+
+		// A plain name next to a glob is read as a glob matching exactly that name
+		include, _, err := NamespacePatterns([]string{"prod", "stag*"}, NamespaceMatchAuto)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(include).To(gomega.Equal([]string{"^prod$", "^stag.*$"}))
+
+		_, _, err = NamespacePatterns(nil, "wildcard")
+		gomega.Expect(err).To(gomega.MatchError(gomega.ContainSubstring("invalid namespace match")))
+	})
+})
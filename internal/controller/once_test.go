@@ -0,0 +1,35 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+var _ = ginkgo.Describe("RunOnce", func() {
+	ginkgo.It("Should reconcile every existing ReplicaSet", func() {
+		ctx := context.Background()
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+			testReplicaSet("rs-a", "default", "cm-a"), testConfigMap("cm-a", "default"),
+			testReplicaSet("rs-b", "other", "cm-b"), testConfigMap("cm-b", "other"),
+		).Build()
+		reconciler := &ReplicaSetReconciler{Client: c, Scheme: scheme.Scheme, Config: &config.OperatorConfig{}}
+
+		processed, err := reconciler.RunOnce(ctx)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(processed).To(gomega.Equal(2))
+
+		for _, key := range []types.NamespacedName{{Name: "cm-a", Namespace: "default"}, {Name: "cm-b", Namespace: "other"}} {
+			var cm corev1.ConfigMap
+			gomega.Expect(c.Get(ctx, key, &cm)).To(gomega.Succeed())
+			gomega.Expect(cm.OwnerReferences).To(gomega.HaveLen(1))
+		}
+	})
+})
@@ -0,0 +1,19 @@
+package controller
+
+// EnabledAnnotation opts a workload in with --opt-in when set to "true" on the workload or, for ReplicaSets, on
+// their pod template
+const EnabledAnnotation = "configmap-rs-operator/enabled"
+
+// optedIn reports whether the ConfigMaps of a workload with annotations are owned: --opt-in is off, or one of
+// annotations sets the EnabledAnnotation to "true"
+func (r *ReplicaSetReconciler) optedIn(annotations ...map[string]string) bool {
+	if !r.Config.OptIn {
+		return true
+	}
+	for _, a := range annotations {
+		if a[EnabledAnnotation] == "true" {
+			return true
+		}
+	}
+	return false
+}
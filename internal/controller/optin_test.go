@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+var _ = ginkgo.Describe("Opt-in mode", func() {
+	ginkgo.It("Should only own the ConfigMaps of the workloads that opted in", func() {
+		ctx := context.Background()
+		annotated := testReplicaSet("web-abc", "default", "web-config")
+		annotated.CreationTimestamp = metav1.Now()
+		annotated.Annotations = map[string]string{EnabledAnnotation: "true"}
+		template := testReplicaSet("api-abc", "default", "api-config")
+		template.CreationTimestamp = metav1.Now()
+		template.Spec.Template.Annotations = map[string]string{EnabledAnnotation: "true"}
+		disabled := testReplicaSet("db-abc", "default", "db-config")
+		disabled.CreationTimestamp = metav1.Now()
+		disabled.Annotations = map[string]string{EnabledAnnotation: "false"}
+		sts := &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "postgres", Namespace: "default", UID: "postgres-uid"},
+			Spec:       appsv1.StatefulSetSpec{Template: testReplicaSet("unused", "default", "cluster-config").Spec.Template},
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(annotated, template, disabled, sts,
+			testConfigMap("web-config", "default"), testConfigMap("api-config", "default"),
+			testConfigMap("db-config", "default"), testConfigMap("cluster-config", "default")).Build()
+		r := &ReplicaSetReconciler{Client: c, Scheme: scheme.Scheme, Config: &config.OperatorConfig{OptIn: true}}
+
+		for _, name := range []string{"web-abc", "api-abc", "db-abc"} {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: name}})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		}
+		_, err := r.workload(StatefulSetKind).Reconcile(ctx,
+			ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "postgres"}})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		owners := func(name string) []metav1.OwnerReference {
+			var cm corev1.ConfigMap
+			gomega.Expect(c.Get(ctx, types.NamespacedName{Namespace: "default", Name: name}, &cm)).To(gomega.Succeed())
+			return cm.OwnerReferences
+		}
+		gomega.Expect(owners("web-config")).To(gomega.HaveLen(1))
+		gomega.Expect(owners("api-config")).To(gomega.HaveLen(1))
+		gomega.Expect(owners("db-config")).To(gomega.BeEmpty())
+		gomega.Expect(owners("cluster-config")).To(gomega.BeEmpty())
+
+		inv, err := r.Inventory(ctx)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(inv.Skips).To(gomega.HaveKeyWithValue(dropReasonOptIn, 1))
+	})
+})
@@ -0,0 +1,162 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Handling of ConfigMap references marked optional
+const (
+	// OptionalOwn owns optional references like any other
+	OptionalOwn = "own"
+	// OptionalSkip never owns optional references
+	OptionalSkip = "skip"
+	// OptionalConservative owns optional references unless another workload also mounts the ConfigMap
+	OptionalConservative = "conservative"
+)
+
+// Skip reasons of optional references
+const (
+	reasonOptional       = "optional_reference"
+	reasonOptionalShared = "optional_shared"
+)
+
+// ValidateOptionalReferences returns an error for unknown optional reference handling modes
+func ValidateOptionalReferences(mode string) error {
+	switch mode {
+	case "", OptionalOwn, OptionalSkip, OptionalConservative:
+		return nil
+	default:
+		return fmt.Errorf("invalid optional references handling %q: expected %s, %s or %s",
+			mode, OptionalOwn, OptionalSkip, OptionalConservative)
+	}
+}
+
+// isOptionalReference reports whether every volume or projection, envFrom source and env variable of spec
+// referencing the ConfigMap name is marked optional; a single required reference makes the ConfigMap required.
+// Volumes count whether containers mount them or not, since the kubelet sets up every volume of a pod.
+func isOptionalReference(spec *corev1.PodSpec, name string) bool {
+	flags := referenceOptionalFlags(spec, name)
+	for _, optional := range flags {
+		if optional == nil || !*optional {
+			return false
+		}
+	}
+	return len(flags) > 0
+}
+
+// referenceOptionalFlags returns the optional field of every reference of spec to the ConfigMap name, nil where
+// it is unset
+func referenceOptionalFlags(spec *corev1.PodSpec, name string) []*bool {
+	var flags []*bool
+	for i := range spec.Volumes {
+		flags = append(flags, volumeOptionalFlags(&spec.Volumes[i], name)...)
+	}
+	containers := append(append([]corev1.Container(nil), spec.InitContainers...), spec.Containers...)
+	for i := range containers {
+		container := &containers[i]
+		for _, source := range container.EnvFrom {
+			if ref := source.ConfigMapRef; ref != nil && ref.Name == name {
+				flags = append(flags, ref.Optional)
+			}
+		}
+		for _, variable := range container.Env {
+			if ref := variable.ValueFrom; ref != nil && ref.ConfigMapKeyRef != nil && ref.ConfigMapKeyRef.Name == name {
+				flags = append(flags, ref.ConfigMapKeyRef.Optional)
+			}
+		}
+	}
+	return flags
+}
+
+// volumeOptionalFlags returns the optional field of the ConfigMap name in volume, whether the volume is the
+// ConfigMap or projects it, nil where it is unset
+func volumeOptionalFlags(volume *corev1.Volume, name string) []*bool {
+	var flags []*bool
+	if volume.ConfigMap != nil && volume.ConfigMap.Name == name {
+		flags = append(flags, volume.ConfigMap.Optional)
+	}
+	if volume.Projected == nil {
+		return flags
+	}
+	for _, source := range volume.Projected.Sources {
+		if source.ConfigMap != nil && source.ConfigMap.Name == name {
+			flags = append(flags, source.ConfigMap.Optional)
+		}
+	}
+	return flags
+}
+
+// optionalSkipReason returns why the optional reference of rs to the ConfigMap name is not owned, or an
+// empty string if it is owned like any other reference
+func (r *ReplicaSetReconciler) optionalSkipReason(
+	ctx context.Context,
+	rs *appsv1.ReplicaSet,
+	name string,
+) (string, error) {
+	mode := r.Config.OptionalReferences
+	if mode == "" || mode == OptionalOwn || !isOptionalReference(&rs.Spec.Template.Spec, name) {
+		return "", nil
+	}
+	if mode == OptionalSkip {
+		return reasonOptional, nil
+	}
+	shared, err := r.mountedByOtherWorkload(ctx, rs, name)
+	if err != nil || !shared {
+		return "", err
+	}
+	return reasonOptionalShared, nil
+}
+
+// optionalReason returns why the optional reference of w to the ConfigMap name is not owned, or an empty
+// string. Other workloads sharing the ConfigMap are only known for ReplicaSets, so both stricter modes skip the
+// optional references of the workload kinds.
+func (r *ReplicaSetReconciler) optionalReason(ctx context.Context, w *podWorkload, name string) (string, error) {
+	if w.rs != nil {
+		return r.optionalSkipReason(ctx, w.rs, name)
+	}
+	if mode := r.Config.OptionalReferences; mode == "" || mode == OptionalOwn || !isOptionalReference(w.spec, name) {
+		return "", nil
+	}
+	return reasonOptional, nil
+}
+
+// mountedByOtherWorkload reports whether a ReplicaSet of another workload in the namespace of rs mounts
+// the ConfigMap name; ReplicaSets with the same controller, i.e. revisions of one Deployment, don't count
+func (r *ReplicaSetReconciler) mountedByOtherWorkload(
+	ctx context.Context,
+	rs *appsv1.ReplicaSet,
+	name string,
+) (bool, error) {
+	var list appsv1.ReplicaSetList
+	if err := r.List(ctx, &list, client.InNamespace(rs.Namespace)); err != nil {
+		return false, err
+	}
+	workload := workloadOf(rs)
+	for i := range list.Items {
+		other := &list.Items[i]
+		if workloadOf(other) == workload {
+			continue
+		}
+		for _, mounted := range r.extractConfigMapVolumes(other) {
+			if mounted == name {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// workloadOf identifies the workload rs belongs to: its controller, or rs itself if it has none
+func workloadOf(rs *appsv1.ReplicaSet) types.UID {
+	if owner := metav1.GetControllerOf(rs); owner != nil {
+		return owner.UID
+	}
+	return rs.UID
+}
@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+var _ = ginkgo.Describe("Optional references", func() {
+	// optionalReplicaSet returns a ReplicaSet of deployment mounting the ConfigMap "tuning" as optional
+	optionalReplicaSet := func(name, deployment string) *appsv1.ReplicaSet {
+		rs := testReplicaSet(name, "default", "tuning")
+		optional, controller := true, true
+		rs.Spec.Template.Spec.Volumes[0].ConfigMap.Optional = &optional
+		rs.OwnerReferences = []metav1.OwnerReference{{
+			APIVersion: "apps/v1", Kind: "Deployment", Name: deployment,
+			UID: types.UID(deployment + "-uid"), Controller: &controller,
+		}}
+		return rs
+	}
+	reconciler := func(mode string, objs ...client.Object) *ReplicaSetReconciler {
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objs...).Build()
+		cfg := &config.OperatorConfig{OptionalReferences: mode}
+		return &ReplicaSetReconciler{Client: c, Scheme: scheme.Scheme, Config: cfg}
+	}
+
+	ginkgo.It("Should treat a ConfigMap as optional only if every reference is", func() {
+		rs := optionalReplicaSet("rs-a", "app")
+		gomega.Expect(isOptionalReference(&rs.Spec.Template.Spec, "tuning")).To(gomega.BeTrue())
+		required := testReplicaSet("rs-b", "default", "tuning")
+		gomega.Expect(isOptionalReference(&required.Spec.Template.Spec, "tuning")).To(gomega.BeFalse())
+	})
+
+	ginkgo.It("Should own, skip or conservatively own optional references", func() {
+		ctx := context.Background()
+		rs := optionalReplicaSet("app-1", "app")
+		previous := optionalReplicaSet("app-0", "app")
+		other := optionalReplicaSet("other-1", "other")
+
+		reason, err := reconciler(OptionalOwn, rs).optionalSkipReason(ctx, rs, "tuning")
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(reason).To(gomega.BeEmpty())
+
+		reason, err = reconciler(OptionalSkip, rs).optionalSkipReason(ctx, rs, "tuning")
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(reason).To(gomega.Equal(reasonOptional))
+
+		reason, err = reconciler(OptionalConservative, rs, previous).optionalSkipReason(ctx, rs, "tuning")
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(reason).To(gomega.BeEmpty())
+
+		reason, err = reconciler(OptionalConservative, rs, previous, other).optionalSkipReason(ctx, rs, "tuning")
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(reason).To(gomega.Equal(reasonOptionalShared))
+	})
+
+	ginkgo.It("Should reject unknown modes", func() {
+		gomega.Expect(ValidateOptionalReferences("sometimes")).To(gomega.HaveOccurred())
+		gomega.Expect(ValidateOptionalReferences(OptionalConservative)).To(gomega.Succeed())
+	})
+})
@@ -0,0 +1,19 @@
+package controller
+
+// SkipAnnotation opts a workload out when set to "true" on the workload or, for ReplicaSets, on their pod
+// template, even with --opt-in and an EnabledAnnotation
+const SkipAnnotation = "configmap-rs-operator/skip"
+
+// DisabledNamespaceLabel opts every workload of a namespace out when set to "true" on the namespace, whatever
+// --namespace-regex selects
+const DisabledNamespaceLabel = "configmap-rs-operator/disabled"
+
+// optedOut reports whether one of the annotations of a workload sets the SkipAnnotation to "true"
+func optedOut(annotations ...map[string]string) bool {
+	for _, a := range annotations {
+		if a[SkipAnnotation] == "true" {
+			return true
+		}
+	}
+	return false
+}
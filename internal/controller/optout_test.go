@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+var _ = ginkgo.Describe("Opting out", func() {
+	ginkgo.It("Should leave alone the workloads annotated to be skipped", func() {
+		ctx := context.Background()
+		skipped := testReplicaSet("web-abc", "default", "web-config")
+		skipped.CreationTimestamp = metav1.Now()
+		skipped.Annotations = map[string]string{EnabledAnnotation: "true", SkipAnnotation: "true"}
+		template := testReplicaSet("api-abc", "default", "api-config")
+		template.CreationTimestamp = metav1.Now()
+		template.Spec.Template.Annotations = map[string]string{SkipAnnotation: "true"}
+		running := testReplicaSet("db-abc", "default", "db-config")
+		running.CreationTimestamp = metav1.Now()
+		running.Annotations = map[string]string{SkipAnnotation: "false"}
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(skipped, template, running,
+			testConfigMap("web-config", "default"), testConfigMap("api-config", "default"),
+			testConfigMap("db-config", "default")).Build()
+		r := &ReplicaSetReconciler{Client: c, Scheme: scheme.Scheme, Config: &config.OperatorConfig{}}
+
+		for _, name := range []string{"web-abc", "api-abc", "db-abc"} {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: name}})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		}
+		owners := func(name string) []metav1.OwnerReference {
+			var cm corev1.ConfigMap
+			gomega.Expect(c.Get(ctx, types.NamespacedName{Namespace: "default", Name: name}, &cm)).To(gomega.Succeed())
+			return cm.OwnerReferences
+		}
+		gomega.Expect(owners("web-config")).To(gomega.BeEmpty())
+		gomega.Expect(owners("api-config")).To(gomega.BeEmpty())
+		gomega.Expect(owners("db-config")).To(gomega.HaveLen(1))
+
+		inv, err := r.Inventory(ctx)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(inv.Skips).To(gomega.HaveKeyWithValue(dropReasonOptOut, 2))
+	})
+	ginkgo.It("Should leave disabled namespaces alone without the namespace filter, as in --once and the CLI", func() {
+		ctx := context.Background()
+		disabled := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name: "frozen", Labels: map[string]string{DisabledNamespaceLabel: "true"},
+		}}
+		newClient := func() client.Client {
+			return fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(disabled,
+				testReplicaSet("web-abc", "frozen", "web-config"), testConfigMap("web-config", "frozen"),
+				testReplicaSet("db-abc", "default", "db-config"), testConfigMap("db-config", "default")).Build()
+		}
+		owners := func(c client.Client, namespace, name string) []metav1.OwnerReference {
+			var cm corev1.ConfigMap
+			gomega.Expect(c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &cm)).To(gomega.Succeed())
+			return cm.OwnerReferences
+		}
+
+		c := newClient()
+		r := &ReplicaSetReconciler{Client: c, Scheme: scheme.Scheme, Config: &config.OperatorConfig{}}
+		_, err := r.RunOnce(ctx)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(owners(c, "frozen", "web-config")).To(gomega.BeEmpty())
+		gomega.Expect(owners(c, "default", "db-config")).To(gomega.HaveLen(1))
+
+		// The CLI subcommands build the same reconciler, e.g. adopt
+		c = newClient()
+		r = &ReplicaSetReconciler{Client: c, Scheme: scheme.Scheme, Config: &config.OperatorConfig{}}
+		decisions, err := r.Adopt(ctx, AdoptOptions{})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(decisions).To(gomega.ContainElement(Decision{
+			Namespace: "frozen", ReplicaSet: "web-abc", Action: decisionSkipped, Reason: dropReasonNamespace,
+		}))
+		gomega.Expect(owners(c, "frozen", "web-config")).To(gomega.BeEmpty())
+	})
+})
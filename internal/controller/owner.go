@@ -0,0 +1,211 @@
+package controller
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Owner strategies selectable per ConfigMap name with --owner-rules
+const (
+	// OwnerReplicaSet adds the ReplicaSet as owner, suiting ConfigMaps whose name changes with their content
+	OwnerReplicaSet = "replicaset"
+
+	// OwnerDeployment adds the ReplicaSet's Deployment as owner, suiting stable-named ConfigMaps that
+	// outlive individual rollouts; ReplicaSets without a Deployment are added themselves
+	OwnerDeployment = "deployment"
+
+	// OwnerSkip adds no owner
+	OwnerSkip = "skip"
+)
+
+// reasonOwnerRule is the skip reason of ConfigMaps an owner rule excludes
+const reasonOwnerRule = "owner_rule"
+
+// reasonTooManyOwners is the skip reason of ConfigMaps carrying more owner references than --max-existing-owners
+const reasonTooManyOwners = "too_many_owners"
+
+// OwnerRule selects the owner strategy of the ConfigMaps whose name matches Pattern
+type OwnerRule struct {
+	Pattern  *regexp.Regexp
+	Strategy string
+}
+
+// ValidateOwnerTarget returns an error for owner targets other than OwnerReplicaSet and OwnerDeployment; empty means
+// OwnerReplicaSet
+func ValidateOwnerTarget(target string) error {
+	switch target {
+	case "", OwnerReplicaSet, OwnerDeployment:
+		return nil
+	default:
+		return fmt.Errorf("invalid owner target %q: expected %s or %s", target, OwnerReplicaSet, OwnerDeployment)
+	}
+}
+
+// ParseOwnerRules parses rules of the form pattern=strategy. The first rule whose pattern matches a
+// ConfigMap's name applies; ConfigMaps no rule matches are owned according to --owner-target.
+func ParseOwnerRules(rules []string) ([]OwnerRule, error) {
+	parsed := make([]OwnerRule, 0, len(rules))
+	for _, rule := range rules {
+		pattern, strategy, ok := strings.Cut(rule, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid owner rule %q: expected pattern=strategy", rule)
+		}
+		switch strategy {
+		case OwnerReplicaSet, OwnerDeployment, OwnerSkip:
+		default:
+			return nil, fmt.Errorf("invalid owner rule %q: strategy must be %s, %s or %s",
+				rule, OwnerReplicaSet, OwnerDeployment, OwnerSkip)
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid owner rule %q: %w", rule, err)
+		}
+		parsed = append(parsed, OwnerRule{Pattern: re, Strategy: strategy})
+	}
+	return parsed, nil
+}
+
+// ownerRuleIndex returns the index of the first rule matching the ConfigMap name, or -1 if none does
+func (r *ReplicaSetReconciler) ownerRuleIndex(name string) int {
+	for i, rule := range r.OwnerRules {
+		if rule.Pattern.MatchString(name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// ownerStrategy returns the strategy of the first rule matching the ConfigMap name, or the owner target
+func (r *ReplicaSetReconciler) ownerStrategy(name string) string {
+	if i := r.ownerRuleIndex(name); i >= 0 {
+		return r.OwnerRules[i].Strategy
+	}
+	if r.Config.OwnerTarget == OwnerDeployment {
+		return OwnerDeployment
+	}
+	return OwnerReplicaSet
+}
+
+// ownsThroughDeployments reports whether some ConfigMaps may be owned by a Deployment: the Deployment of their
+// ReplicaSet, or one they are bound to with --configmap-bindings
+func (r *ReplicaSetReconciler) ownsThroughDeployments() bool {
+	if r.Config.ConfigMapBindings {
+		return true
+	}
+	return r.Config.WatchesReplicaSets() && (r.Config.OwnerTarget == OwnerDeployment ||
+		slices.ContainsFunc(r.OwnerRules, func(rule OwnerRule) bool { return rule.Strategy == OwnerDeployment }))
+}
+
+// OwnerRuleStatus reports what an owner rule matches, so rule authors can tell whether it applies to anything
+type OwnerRuleStatus struct {
+	// Rule is the rule as configured, pattern=strategy
+	Rule string `json:"rule"`
+
+	// Active is true when the rule is the first match of at least one ConfigMap reference
+	Active bool `json:"active"`
+
+	// Workloads and ConfigMaps count the ReplicaSets and ConfigMaps whose references the rule decides
+	Workloads  int `json:"workloads"`
+	ConfigMaps int `json:"configMaps"`
+}
+
+// ownerRuleMatches tallies the references each owner rule decides, as the first rule matching them
+type ownerRuleMatches struct {
+	workloads  []map[types.NamespacedName]bool
+	configMaps []map[types.NamespacedName]bool
+}
+
+func (r *ReplicaSetReconciler) newOwnerRuleMatches() *ownerRuleMatches {
+	m := &ownerRuleMatches{}
+	for range r.OwnerRules {
+		m.workloads = append(m.workloads, map[types.NamespacedName]bool{})
+		m.configMaps = append(m.configMaps, map[types.NamespacedName]bool{})
+	}
+	return m
+}
+
+// observe records the reference of rs to the ConfigMap name
+func (m *ownerRuleMatches) observe(r *ReplicaSetReconciler, rs *appsv1.ReplicaSet, name string) {
+	if i := r.ownerRuleIndex(name); i >= 0 {
+		m.workloads[i][types.NamespacedName{Namespace: rs.Namespace, Name: rs.Name}] = true
+		m.configMaps[i][types.NamespacedName{Namespace: rs.Namespace, Name: name}] = true
+	}
+}
+
+// statuses returns the status of every rule, in rule order
+func (m *ownerRuleMatches) statuses(rules []OwnerRule) []OwnerRuleStatus {
+	statuses := make([]OwnerRuleStatus, 0, len(rules))
+	for i, rule := range rules {
+		statuses = append(statuses, OwnerRuleStatus{
+			Rule:       rule.Pattern.String() + "=" + rule.Strategy,
+			Active:     len(m.configMaps[i]) > 0,
+			Workloads:  len(m.workloads[i]),
+			ConfigMaps: len(m.configMaps[i]),
+		})
+	}
+	return statuses
+}
+
+// ownerFor returns the owner reference rs warrants on the ConfigMap name, or nil when the owner rules
+// skip the ConfigMap. The reference isn't a controller reference and doesn't block owner deletion.
+func (r *ReplicaSetReconciler) ownerFor(rs *appsv1.ReplicaSet, name string) *metav1.OwnerReference {
+	return ownerByStrategy(rs, r.ownerStrategy(name))
+}
+
+// targetOwner returns the owner reference --owner-target picks for rs, ignoring the owner rules, which match
+// ConfigMap names
+func (r *ReplicaSetReconciler) targetOwner(rs *appsv1.ReplicaSet) metav1.OwnerReference {
+	if r.Config.OwnerTarget == OwnerDeployment {
+		return *ownerByStrategy(rs, OwnerDeployment)
+	}
+	return *ownerByStrategy(rs, OwnerReplicaSet)
+}
+
+// ownerByStrategy returns the owner reference strategy picks for rs, or nil for OwnerSkip. OwnerDeployment falls
+// back to rs itself when no Deployment controls it.
+func ownerByStrategy(rs *appsv1.ReplicaSet, strategy string) *metav1.OwnerReference {
+	switch strategy {
+	case OwnerSkip:
+		return nil
+	case OwnerDeployment:
+		if ref := metav1.GetControllerOf(rs); ref != nil && ref.Kind == "Deployment" {
+			return &metav1.OwnerReference{APIVersion: ref.APIVersion, Kind: ref.Kind, Name: ref.Name, UID: ref.UID}
+		}
+	}
+	return &metav1.OwnerReference{
+		APIVersion: appsv1.SchemeGroupVersion.String(), Kind: "ReplicaSet", Name: rs.Name, UID: rs.UID,
+	}
+}
+
+// upsertOwnerReference adds ref to obj, replacing a reference to an earlier object of the same kind and name
+func upsertOwnerReference(obj metav1.Object, ref metav1.OwnerReference) {
+	refs := obj.GetOwnerReferences()
+	for i, existing := range refs {
+		if existing.Kind == ref.Kind && existing.Name == ref.Name && existing.APIVersion == ref.APIVersion {
+			refs[i] = ref
+			obj.SetOwnerReferences(refs)
+			return
+		}
+	}
+	obj.SetOwnerReferences(append(refs, ref))
+}
+
+// tooManyOwners reports whether obj carries more owner references than --max-existing-owners, which usually
+// means it is shared so widely that coupling its lifecycle to one more workload is wrong
+func (r *ReplicaSetReconciler) tooManyOwners(obj metav1.Object) bool {
+	return r.Config.MaxExistingOwners > 0 && len(obj.GetOwnerReferences()) > r.Config.MaxExistingOwners
+}
+
+// warnTooManyOwners records that no owner reference to the kind/name owner was added to cm
+func (r *ReplicaSetReconciler) warnTooManyOwners(cm *corev1.ConfigMap, kind, name string) {
+	r.recordEvent(cm, corev1.EventTypeWarning, "TooManyOwners",
+		"Not adding owner reference to %s %s: the ConfigMap already has %d owner references", kind, name,
+		len(cm.OwnerReferences))
+}
@@ -0,0 +1,160 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+var _ = ginkgo.Describe("Owner rules", func() {
+	ginkgo.It("Should reject malformed rules", func() {
+		for _, rule := range []string{"no-strategy", "^app=pod", "(=skip"} {
+			_, err := ParseOwnerRules([]string{rule})
+			gomega.Expect(err).To(gomega.HaveOccurred(), rule)
+		}
+	})
+
+	ginkgo.It("Should pick the owner of each ConfigMap by the first matching rule", func() {
+		ctx := context.Background()
+		isController := true
+		rs := testReplicaSet("web-abc", "default", "app-config-5f7c9d", "app-settings", "shared-ca")
+		rs.CreationTimestamp = metav1.Now()
+		rs.OwnerReferences = []metav1.OwnerReference{{
+			APIVersion: "apps/v1", Kind: "Deployment", Name: "web", UID: "web-uid", Controller: &isController,
+		}}
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(rs,
+			testConfigMap("app-config-5f7c9d", "default"), testConfigMap("app-settings", "default"),
+			testConfigMap("shared-ca", "default"),
+		).Build()
+		rules, err := ParseOwnerRules([]string{"-[a-z0-9]{6}$=replicaset", "^shared-=skip", ".*=deployment"})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		r := &ReplicaSetReconciler{Client: c, Scheme: scheme.Scheme, Config: &config.OperatorConfig{}, OwnerRules: rules}
+
+		_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: rs.Name}})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+		owners := func(name string) []metav1.OwnerReference {
+			var cm corev1.ConfigMap
+			gomega.Expect(c.Get(ctx, types.NamespacedName{Namespace: "default", Name: name}, &cm)).To(gomega.Succeed())
+			return cm.OwnerReferences
+		}
+		gomega.Expect(owners("app-config-5f7c9d")).To(gomega.ConsistOf(gomega.And(
+			gomega.HaveField("Kind", "ReplicaSet"), gomega.HaveField("Name", "web-abc"))))
+		gomega.Expect(owners("app-settings")).To(gomega.ConsistOf(gomega.And(
+			gomega.HaveField("Kind", "Deployment"), gomega.HaveField("UID", types.UID("web-uid")),
+			gomega.HaveField("Controller", gomega.BeNil()))))
+		gomega.Expect(owners("shared-ca")).To(gomega.BeEmpty())
+	})
+
+	ginkgo.It("Should own the ConfigMaps no rule matches with the Deployment when it is the owner target", func() {
+		ctx := context.Background()
+		isController := true
+		rs := testReplicaSet("web-abc", "default", "app-config-5f7c9d", "app-settings")
+		rs.CreationTimestamp = metav1.Now()
+		rs.OwnerReferences = []metav1.OwnerReference{{
+			APIVersion: "apps/v1", Kind: "Deployment", Name: "web", UID: "web-uid", Controller: &isController,
+		}}
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(rs,
+			testConfigMap("app-config-5f7c9d", "default"), testConfigMap("app-settings", "default"),
+		).Build()
+		rules, err := ParseOwnerRules([]string{"-[a-z0-9]{6}$=replicaset"})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		r := &ReplicaSetReconciler{Client: c, Scheme: scheme.Scheme, OwnerRules: rules,
+			Config: &config.OperatorConfig{OwnerTarget: OwnerDeployment}}
+
+		_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: rs.Name}})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+		owners := func(name string) []metav1.OwnerReference {
+			var cm corev1.ConfigMap
+			gomega.Expect(c.Get(ctx, types.NamespacedName{Namespace: "default", Name: name}, &cm)).To(gomega.Succeed())
+			return cm.OwnerReferences
+		}
+		gomega.Expect(owners("app-config-5f7c9d")).To(gomega.ConsistOf(gomega.HaveField("Kind", "ReplicaSet")))
+		gomega.Expect(owners("app-settings")).To(gomega.ConsistOf(gomega.And(
+			gomega.HaveField("Kind", "Deployment"), gomega.HaveField("Name", "web"))))
+		gomega.Expect(ValidateOwnerTarget("skip")).To(gomega.HaveOccurred())
+	})
+
+	ginkgo.It("Should report what each rule matches in the inventory", func() {
+		rs := testReplicaSet("web-abc", "default", "app-config-5f7c9d", "shared-ca")
+		other := testReplicaSet("api-abc", "default", "shared-ca")
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(rs, other,
+			testConfigMap("app-config-5f7c9d", "default"), testConfigMap("shared-ca", "default")).Build()
+		rules, err := ParseOwnerRules([]string{"^shared-=skip", "^legacy-=deployment", ".*=replicaset"})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		r := &ReplicaSetReconciler{Client: c, Scheme: scheme.Scheme, Config: &config.OperatorConfig{}, OwnerRules: rules}
+
+		inv, err := r.Inventory(context.Background())
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(inv.OwnerRules).To(gomega.Equal([]OwnerRuleStatus{
+			{Rule: "^shared-=skip", Active: true, Workloads: 2, ConfigMaps: 1},
+			{Rule: "^legacy-=deployment"},
+			{Rule: ".*=replicaset", Active: true, Workloads: 1, ConfigMaps: 1},
+		}))
+	})
+
+	ginkgo.It("Should leave alone ReplicaSets owned by a skipped kind", func() {
+		ctx := context.Background()
+		rollout := testReplicaSet("web-abc", "default", "app-config")
+		rollout.CreationTimestamp = metav1.Now()
+		rollout.OwnerReferences = []metav1.OwnerReference{{APIVersion: "argoproj.io/v1alpha1", Kind: "Rollout", Name: "web"}}
+		other := testReplicaSet("api-abc", "default", "api-config")
+		other.CreationTimestamp = metav1.Now()
+		other.OwnerReferences = []metav1.OwnerReference{{APIVersion: "example.com/v1", Kind: "Rollout", Name: "api"}}
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(rollout, other,
+			testConfigMap("app-config", "default"), testConfigMap("api-config", "default")).Build()
+		r := &ReplicaSetReconciler{Client: c, Scheme: scheme.Scheme,
+			Config: &config.OperatorConfig{SkipOwnerKinds: []string{"Rollout.argoproj.io"}}}
+
+		for _, name := range []string{"web-abc", "api-abc"} {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: name}})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		}
+		var cm corev1.ConfigMap
+		gomega.Expect(c.Get(ctx, types.NamespacedName{Namespace: "default", Name: "app-config"}, &cm)).To(gomega.Succeed())
+		gomega.Expect(cm.OwnerReferences).To(gomega.BeEmpty())
+		gomega.Expect(c.Get(ctx, types.NamespacedName{Namespace: "default", Name: "api-config"}, &cm)).To(gomega.Succeed())
+		gomega.Expect(cm.OwnerReferences).To(gomega.HaveLen(1))
+
+		r.Config.SkipOwnerKinds = []string{"Rollout"}
+		gomega.Expect(r.skippedOwner(other)).NotTo(gomega.BeNil())
+	})
+
+	ginkgo.It("Should skip ConfigMaps that already have too many owners with a warning", func() {
+		ctx := context.Background()
+		rs := testReplicaSet("web-abc", "default", "shared")
+		rs.CreationTimestamp = metav1.Now()
+		cm := testConfigMap("shared", "default")
+		for _, name := range []string{"a", "b", "c"} {
+			cm.OwnerReferences = append(cm.OwnerReferences, metav1.OwnerReference{
+				APIVersion: "apps/v1", Kind: "ReplicaSet", Name: name, UID: types.UID(name + "-uid"),
+			})
+		}
+		recorder := record.NewFakeRecorder(10)
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(rs, cm).Build()
+		r := &ReplicaSetReconciler{Client: c, Scheme: scheme.Scheme, Recorder: recorder,
+			Config: &config.OperatorConfig{MaxExistingOwners: 2}}
+
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: rs.Name}})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(c.Get(ctx, types.NamespacedName{Namespace: "default", Name: "shared"}, cm)).To(gomega.Succeed())
+		gomega.Expect(cm.OwnerReferences).To(gomega.HaveLen(3))
+		gomega.Expect(recorder.Events).To(gomega.HaveLen(1))
+		gomega.Expect(<-recorder.Events).To(gomega.ContainSubstring("TooManyOwners"))
+
+		inv, err := r.Inventory(ctx)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(inv.Skips).To(gomega.HaveKeyWithValue(reasonTooManyOwners, 1))
+	})
+})
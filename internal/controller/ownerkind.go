@@ -0,0 +1,26 @@
+package controller
+
+import (
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// skippedOwner returns the owner reference of obj whose kind --skip-owner-kinds lists, or nil. Entries are a kind,
+// matching it in any group, or kind.group, e.g. Rollout.argoproj.io.
+func (r *ReplicaSetReconciler) skippedOwner(obj metav1.Object) *metav1.OwnerReference {
+	for i, ref := range obj.GetOwnerReferences() {
+		gv, err := schema.ParseGroupVersion(ref.APIVersion)
+		if err != nil {
+			continue
+		}
+		for _, entry := range r.Config.SkipOwnerKinds {
+			kind, group, grouped := strings.Cut(entry, ".")
+			if kind == ref.Kind && (!grouped || group == gv.Group) {
+				return &obj.GetOwnerReferences()[i]
+			}
+		}
+	}
+	return nil
+}
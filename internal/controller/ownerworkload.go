@@ -0,0 +1,207 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+// OwnerWorkloadAnnotation binds the ConfigMap carrying it to a workload in its namespace, given as Kind/name, e.g.
+// Deployment/my-app. With --configmap-bindings the workload is added as owner, even if the ConfigMap was created
+// before it.
+const OwnerWorkloadAnnotation = "configmap-rs-operator/owner-workload"
+
+// bindingTarget is a kind ConfigMaps can be bound to with the OwnerWorkloadAnnotation
+type bindingTarget struct {
+	GroupVersion schema.GroupVersion
+	New          func() client.Object
+}
+
+// bindingTargets returns the kinds ConfigMaps can be bound to by kind: Deployments, the ReplicaSets when they are
+// watched, and the enabled workload kinds
+func bindingTargets(cfg *config.OperatorConfig) map[string]bindingTarget {
+	targets := map[string]bindingTarget{
+		"Deployment": {GroupVersion: appsv1.SchemeGroupVersion, New: func() client.Object { return &appsv1.Deployment{} }},
+	}
+	if cfg.WatchesReplicaSets() {
+		targets["ReplicaSet"] = bindingTarget{
+			GroupVersion: appsv1.SchemeGroupVersion, New: func() client.Object { return &appsv1.ReplicaSet{} },
+		}
+	}
+	for _, kind := range EnabledWorkloadKinds(cfg) {
+		targets[kind.Kind] = bindingTarget{GroupVersion: kind.GroupVersion, New: kind.New}
+	}
+	return targets
+}
+
+// ownerWorkload returns the kind and name of the workload the OwnerWorkloadAnnotation of cm binds it to, and false
+// if cm doesn't carry a well-formed annotation
+func ownerWorkload(cm client.Object) (kind, name string, ok bool) {
+	value, found := cm.GetAnnotations()[OwnerWorkloadAnnotation]
+	if !found {
+		return "", "", false
+	}
+	kind, name, ok = strings.Cut(strings.TrimSpace(value), "/")
+	return kind, name, ok && kind != "" && name != "" && !strings.Contains(name, "/")
+}
+
+// ConfigMapBindingReconciler adds the workloads ConfigMaps name in their OwnerWorkloadAnnotation as their owners,
+// the reverse of the other reconcilers, for ConfigMaps generated before their workload exists
+type ConfigMapBindingReconciler struct {
+	// ReplicaSetReconciler provides the namespace filter, write holds, write client and events
+	*ReplicaSetReconciler
+}
+
+func (r *ConfigMapBindingReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithValues("configmap", req.NamespacedName)
+	if !r.shouldProcessNamespace(ctx, req.Namespace) {
+		recordFiltered(dropReasonNamespace)
+		return ctrl.Result{}, nil
+	}
+
+	var cm corev1.ConfigMap
+	if err := r.Get(ctx, req.NamespacedName, &cm); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if _, found := cm.Annotations[OwnerWorkloadAnnotation]; !found {
+		return ctrl.Result{}, nil
+	}
+	kind, name, ok := ownerWorkload(&cm)
+	target, known := bindingTargets(r.Config)[kind]
+	if !ok || !known {
+		r.recordEvent(&cm, corev1.EventTypeWarning, "InvalidOwnerWorkload",
+			"Annotation %s=%q doesn't name a workload as Kind/name of a watched kind", OwnerWorkloadAnnotation,
+			cm.Annotations[OwnerWorkloadAnnotation])
+		return ctrl.Result{}, nil
+	}
+
+	workload := target.New()
+	if err := r.Get(ctx, types.NamespacedName{Namespace: cm.Namespace, Name: name}, workload); err != nil {
+		if errors.IsNotFound(err) {
+			// The workload's creation triggers the ConfigMap again
+			logger.V(1).Info("Bound workload doesn't exist yet", "workload", kind+"/"+name)
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	now := time.Now()
+	holdReason := r.holdReason(ctx, cm.Namespace, now, logger)
+	owner := metav1.OwnerReference{
+		APIVersion: target.GroupVersion.String(), Kind: kind, Name: name, UID: workload.GetUID(),
+	}
+	if err := r.bind(ctx, &cm, owner, holdReason, logger); err != nil {
+		return ctrl.Result{}, err
+	}
+	return r.heldResult(holdReason, now), nil
+}
+
+// bind adds owner to cm unless it already owns it, it is denylisted or writes are held back. The binding is
+// explicit, so owner rules and --max-existing-owners don't apply.
+func (r *ConfigMapBindingReconciler) bind(
+	ctx context.Context,
+	cm *corev1.ConfigMap,
+	owner metav1.OwnerReference,
+	holdReason string,
+	logger logr.Logger,
+) error {
+	if r.denylisted(cm.Name) {
+		logger.Info("Skipping denylisted ConfigMap", "workload", owner.Kind+"/"+owner.Name)
+		return nil
+	}
+	for _, ref := range cm.OwnerReferences {
+		if ref.UID == owner.UID {
+			return nil
+		}
+	}
+	if holdReason != "" {
+		logger.Info(holdReason+": Would add OwnerReference", "workload", owner.Kind+"/"+owner.Name)
+		return nil
+	}
+	if r.Config.RequireApproval {
+		return r.proposeOwner(ctx, cm, owner, logger)
+	}
+
+	if err := r.addOwner(ctx, cm, owner); err != nil {
+		logger.Error(err, "Failed to update ConfigMap with owner reference", "workload", owner.Kind+"/"+owner.Name)
+		r.recordEvent(cm, corev1.EventTypeWarning, "OwnerReferenceFailed",
+			"Failed to add owner reference to %s %s: %v", owner.Kind, owner.Name, err)
+		return err
+	}
+
+	logger.Info("Added OwnerReference to bound ConfigMap", "workload", owner.Kind+"/"+owner.Name)
+	r.recordEvent(cm, corev1.EventTypeNormal, "OwnerReferenceAdded", "Added owner reference to %s %s",
+		owner.Kind, owner.Name)
+	r.observeChurn(cm.Namespace, ownershipAdded, logger)
+	return nil
+}
+
+// boundTo returns a map function enqueueing the ConfigMaps bound to an object of kind, so a ConfigMap created
+// before its workload is reconciled again once the workload exists
+func (r *ConfigMapBindingReconciler) boundTo(kind string) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		var configMaps corev1.ConfigMapList
+		if err := r.List(ctx, &configMaps, client.InNamespace(obj.GetNamespace())); err != nil {
+			log.FromContext(ctx).Error(err, "Failed to list the ConfigMaps bound to a new workload",
+				"workload", kind+"/"+obj.GetName())
+			return nil
+		}
+		var requests []reconcile.Request
+		for i := range configMaps.Items {
+			if k, name, ok := ownerWorkload(&configMaps.Items[i]); ok && k == kind && name == obj.GetName() {
+				requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+					Namespace: obj.GetNamespace(), Name: configMaps.Items[i].Name,
+				}})
+			}
+		}
+		return requests
+	}
+}
+
+// SetupWithManager watches the ConfigMaps carrying the OwnerWorkloadAnnotation, including those that exist at
+// startup and those whose annotation changes, and the creation of the workloads they can be bound to
+func (r *ConfigMapBindingReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	annotated := func(obj client.Object) bool {
+		_, found := obj.GetAnnotations()[OwnerWorkloadAnnotation]
+		return found
+	}
+	b := ctrl.NewControllerManagedBy(mgr).
+		Named("configmapbinding").
+		For(&corev1.ConfigMap{}, builder.WithPredicates(predicate.Funcs{
+			CreateFunc: func(e event.CreateEvent) bool { return annotated(e.Object) },
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				return annotated(e.ObjectNew) && e.ObjectNew.GetAnnotations()[OwnerWorkloadAnnotation] !=
+					e.ObjectOld.GetAnnotations()[OwnerWorkloadAnnotation]
+			},
+			DeleteFunc:  func(event.DeleteEvent) bool { return false },
+			GenericFunc: func(event.GenericEvent) bool { return false },
+		}))
+	created := predicate.Funcs{
+		CreateFunc:  func(event.CreateEvent) bool { return true },
+		UpdateFunc:  func(event.UpdateEvent) bool { return false },
+		DeleteFunc:  func(event.DeleteEvent) bool { return false },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+	}
+	for kind, target := range bindingTargets(r.Config) {
+		b = b.Watches(target.New(), handler.EnqueueRequestsFromMapFunc(r.boundTo(kind)), builder.WithPredicates(created))
+	}
+	return b.WithOptions(r.controllerOptions()).Complete(r)
+}
@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+var _ = ginkgo.Describe("ConfigMap bindings", func() {
+	ginkgo.It("Should own a ConfigMap created before its workload once the workload exists", func() {
+		ctx := context.Background()
+		cm := testConfigMap("generated", "default")
+		cm.Annotations = map[string]string{OwnerWorkloadAnnotation: "Deployment/web"}
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(cm).Build()
+		r := &ConfigMapBindingReconciler{ReplicaSetReconciler: &ReplicaSetReconciler{
+			Client: c, Scheme: scheme.Scheme, Config: &config.OperatorConfig{ConfigMapBindings: true},
+		}}
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "generated"}}
+
+		_, err := r.Reconcile(ctx, req)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(c.Get(ctx, req.NamespacedName, cm)).To(gomega.Succeed())
+		gomega.Expect(cm.OwnerReferences).To(gomega.BeEmpty())
+
+		deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", UID: "web-uid"}}
+		gomega.Expect(c.Create(ctx, deployment)).To(gomega.Succeed())
+		gomega.Expect(r.boundTo("Deployment")(ctx, deployment)).To(gomega.ConsistOf(req))
+		gomega.Expect(r.boundTo("StatefulSet")(ctx, deployment)).To(gomega.BeEmpty())
+
+		_, err = r.Reconcile(ctx, req)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(c.Get(ctx, req.NamespacedName, cm)).To(gomega.Succeed())
+		gomega.Expect(cm.OwnerReferences).To(gomega.ConsistOf(gomega.And(
+			gomega.HaveField("Kind", "Deployment"), gomega.HaveField("UID", types.UID("web-uid")))))
+		gomega.Expect(r.InScope(ctx, cm)).To(gomega.BeTrue())
+	})
+
+	ginkgo.It("Should warn about annotations naming no watched workload", func() {
+		ctx := context.Background()
+		bindings := map[string]string{"no-kind": "web", "no-name": "Deployment/", "unknown-kind": "Rollout/web"}
+		builder := fake.NewClientBuilder().WithScheme(scheme.Scheme)
+		for name, value := range bindings {
+			cm := testConfigMap(name, "default")
+			cm.Annotations = map[string]string{OwnerWorkloadAnnotation: value}
+			builder = builder.WithObjects(cm)
+		}
+		recorder := record.NewFakeRecorder(10)
+		r := &ConfigMapBindingReconciler{ReplicaSetReconciler: &ReplicaSetReconciler{
+			Client: builder.Build(), Scheme: scheme.Scheme, Recorder: recorder,
+			Config: &config.OperatorConfig{ConfigMapBindings: true},
+		}}
+
+		for name := range bindings {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: name}})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(<-recorder.Events).To(gomega.ContainSubstring("InvalidOwnerWorkload"), name)
+		}
+	})
+})
@@ -0,0 +1,17 @@
+package controller
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// updateObject writes obj, a ConfigMap or Secret, through w. With --patch-only it sends the changes made since
+// original as a merge patch instead, so the operator needs no update permission. The patch carries the resource
+// version of original, so it fails on an object changed in the meantime just like an update.
+func (r *ReplicaSetReconciler) updateObject(ctx context.Context, w client.Writer, obj, original client.Object) error {
+	if r.Config.PatchOnly {
+		return w.Patch(ctx, obj, client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{}))
+	}
+	return w.Update(ctx, obj)
+}
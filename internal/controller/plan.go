@@ -0,0 +1,244 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Plan is a reviewed set of ConfigMap changes: the plan subcommand writes it instead of making the changes of a
+// command, and the apply subcommand makes exactly those changes
+type Plan struct {
+	Created time.Time `json:"created"`
+
+	// Command is the command line the plan was made for, e.g. "adopt --namespace team-a"
+	Command string   `json:"command"`
+	Changes []Change `json:"changes"`
+}
+
+// Change is the planned change of the owner references and operator annotations of one ConfigMap
+type Change struct {
+	Namespace string `json:"namespace"`
+	ConfigMap string `json:"configMap"`
+
+	// ResourceVersion is the version the change was planned against; a ConfigMap changed since fails the apply
+	ResourceVersion string `json:"resourceVersion"`
+
+	Added   []metav1.OwnerReference `json:"added,omitempty"`
+	Removed []metav1.OwnerReference `json:"removed,omitempty"`
+
+	// Annotations are the operator annotations after the change; the others are removed
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// Planner wraps a client so that ConfigMap writes are captured as a Plan instead of made. Later reads of a
+// captured ConfigMap return its planned state, so commands writing a ConfigMap several times plan one change.
+type Planner struct {
+	client.Client
+
+	mu       sync.Mutex
+	original map[types.NamespacedName]*corev1.ConfigMap
+	planned  map[types.NamespacedName]*corev1.ConfigMap
+}
+
+// NewPlanner returns a Planner reading through c
+func NewPlanner(c client.Client) *Planner {
+	return &Planner{
+		Client:   c,
+		original: map[types.NamespacedName]*corev1.ConfigMap{},
+		planned:  map[types.NamespacedName]*corev1.ConfigMap{},
+	}
+}
+
+// Get implements client.Reader, returning the planned state of captured ConfigMaps
+func (p *Planner) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	if cm, ok := obj.(*corev1.ConfigMap); ok {
+		p.mu.Lock()
+		planned, ok := p.planned[key]
+		p.mu.Unlock()
+		if ok {
+			planned.DeepCopyInto(cm)
+			return nil
+		}
+	}
+	return p.Client.Get(ctx, key, obj, opts...)
+}
+
+// List implements client.Reader, returning the planned state of captured ConfigMaps
+func (p *Planner) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	if err := p.Client.List(ctx, list, opts...); err != nil {
+		return err
+	}
+	if configMaps, ok := list.(*corev1.ConfigMapList); ok {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		for i := range configMaps.Items {
+			if planned, ok := p.planned[client.ObjectKeyFromObject(&configMaps.Items[i])]; ok {
+				planned.DeepCopyInto(&configMaps.Items[i])
+			}
+		}
+	}
+	return nil
+}
+
+// Update implements client.Writer, capturing ConfigMap updates
+func (p *Planner) Update(ctx context.Context, obj client.Object, _ ...client.UpdateOption) error {
+	return p.capture(ctx, obj)
+}
+
+// Patch implements client.Writer, capturing merge patches of ConfigMaps, whose obj already holds the result
+func (p *Planner) Patch(ctx context.Context, obj client.Object, patch client.Patch, _ ...client.PatchOption) error {
+	if patch.Type() == types.ApplyPatchType {
+		return fmt.Errorf("planning server-side applies is not supported")
+	}
+	return p.capture(ctx, obj)
+}
+
+// Create implements client.Writer; planned commands only change existing ConfigMaps
+func (p *Planner) Create(_ context.Context, obj client.Object, _ ...client.CreateOption) error {
+	return fmt.Errorf("planning the creation of %T %s is not supported", obj, client.ObjectKeyFromObject(obj))
+}
+
+// Delete implements client.Writer; planned commands only change existing ConfigMaps
+func (p *Planner) Delete(_ context.Context, obj client.Object, _ ...client.DeleteOption) error {
+	return fmt.Errorf("planning the deletion of %T %s is not supported", obj, client.ObjectKeyFromObject(obj))
+}
+
+// capture records the state obj is written in as the planned state of its ConfigMap
+func (p *Planner) capture(ctx context.Context, obj client.Object) error {
+	cm, ok := obj.(*corev1.ConfigMap)
+	if !ok {
+		return fmt.Errorf("planning writes of %T is not supported", obj)
+	}
+	key := client.ObjectKeyFromObject(cm)
+	p.mu.Lock()
+	_, captured := p.original[key]
+	p.mu.Unlock()
+	if !captured {
+		var original corev1.ConfigMap
+		if err := p.Client.Get(ctx, key, &original); err != nil {
+			return err
+		}
+		p.mu.Lock()
+		p.original[key] = &original
+		p.mu.Unlock()
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.planned[key] = cm.DeepCopy()
+	return nil
+}
+
+// Plan returns the changes captured so far for command, sorted by namespace and ConfigMap
+func (p *Planner) Plan(command string) *Plan {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	plan := &Plan{Created: time.Now().UTC(), Command: command, Changes: []Change{}}
+	for key, planned := range p.planned {
+		original := p.original[key]
+		change := Change{
+			Namespace: key.Namespace, ConfigMap: key.Name, ResourceVersion: original.ResourceVersion,
+			Added:       ownersMissing(planned.OwnerReferences, original.OwnerReferences),
+			Removed:     ownersMissing(original.OwnerReferences, planned.OwnerReferences),
+			Annotations: operatorAnnotationsOf(planned),
+		}
+		if len(change.Added) == 0 && len(change.Removed) == 0 &&
+			maps.Equal(change.Annotations, operatorAnnotationsOf(original)) {
+			continue
+		}
+		plan.Changes = append(plan.Changes, change)
+	}
+	sort.Slice(plan.Changes, func(i, j int) bool {
+		a, b := plan.Changes[i], plan.Changes[j]
+		return a.Namespace < b.Namespace || a.Namespace == b.Namespace && a.ConfigMap < b.ConfigMap
+	})
+	return plan
+}
+
+// ownersMissing returns the owner references of refs whose UID isn't in others
+func ownersMissing(refs, others []metav1.OwnerReference) []metav1.OwnerReference {
+	var missing []metav1.OwnerReference
+	for _, ref := range refs {
+		if !slices.ContainsFunc(others, func(other metav1.OwnerReference) bool { return other.UID == ref.UID }) {
+			missing = append(missing, ref)
+		}
+	}
+	return missing
+}
+
+// operatorAnnotationsOf returns the operator annotations of cm
+func operatorAnnotationsOf(cm *corev1.ConfigMap) map[string]string {
+	annotations := map[string]string{}
+	for _, key := range operatorAnnotations {
+		if value, ok := cm.Annotations[key]; ok {
+			annotations[key] = value
+		}
+	}
+	return annotations
+}
+
+// ApplyPlan makes the changes of plan. It first checks every ConfigMap against the version the plan was made
+// against and every added owner against the live objects, and fails without writing anything if the cluster
+// drifted since. It returns the number of ConfigMaps changed.
+func (r *ReplicaSetReconciler) ApplyPlan(ctx context.Context, plan *Plan) (int, error) {
+	configMaps := make([]*corev1.ConfigMap, len(plan.Changes))
+	var drifted []error
+	for i, change := range plan.Changes {
+		cm := &corev1.ConfigMap{}
+		key := types.NamespacedName{Namespace: change.Namespace, Name: change.ConfigMap}
+		if err := r.Get(ctx, key, cm); err != nil {
+			drifted = append(drifted, fmt.Errorf("ConfigMap %s: %w", key, err))
+			continue
+		}
+		if cm.ResourceVersion != change.ResourceVersion {
+			drifted = append(drifted, fmt.Errorf("ConfigMap %s changed since the plan was made", key))
+		}
+		for _, ref := range change.Added {
+			exists, err := r.ownerExists(ctx, change.Namespace, ref)
+			if err != nil {
+				return 0, err
+			}
+			if !exists {
+				drifted = append(drifted, fmt.Errorf("owner %s/%s of ConfigMap %s no longer exists", ref.Kind, ref.Name, key))
+			}
+		}
+		configMaps[i] = cm
+	}
+	if len(drifted) > 0 {
+		return 0, fmt.Errorf("the cluster drifted since the plan was made, make a new plan: %w", errors.Join(drifted...))
+	}
+
+	for i, change := range plan.Changes {
+		cm := configMaps[i]
+		original := cm.DeepCopy()
+		cm.OwnerReferences = ownersMissing(cm.OwnerReferences, change.Removed)
+		for _, ref := range change.Added {
+			upsertOwnerReference(cm, ref)
+		}
+		for _, key := range operatorAnnotations {
+			delete(cm.Annotations, key)
+		}
+		if len(change.Annotations) > 0 && cm.Annotations == nil {
+			cm.Annotations = map[string]string{}
+		}
+		maps.Copy(cm.Annotations, change.Annotations)
+		// The resource version of the check makes the patch fail if the ConfigMap changed in the meantime
+		patch := client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{})
+		if err := r.writer().Patch(ctx, cm, patch); err != nil {
+			return i, fmt.Errorf("failed to apply the change of ConfigMap %s/%s: %w", cm.Namespace, cm.Name, err)
+		}
+		ownershipChangesTotal.WithLabelValues(ownershipAdded).Add(float64(len(change.Added)))
+		ownershipChangesTotal.WithLabelValues(ownershipRemoved).Add(float64(len(change.Removed)))
+	}
+	return len(plan.Changes), nil
+}
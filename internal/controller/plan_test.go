@@ -0,0 +1,80 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+var _ = ginkgo.Describe("Plan", func() {
+	var (
+		ctx context.Context
+		c   client.Client
+		key = types.NamespacedName{Namespace: "default", Name: "shared"}
+	)
+
+	ginkgo.BeforeEach(func() {
+		ctx = context.Background()
+		c = fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+			testReplicaSet("web", "default", "shared"), testReplicaSet("api", "default", "shared"),
+			testConfigMap("shared", "default"),
+		).Build()
+	})
+
+	plan := func() *Plan {
+		planner := NewPlanner(c)
+		r := &ReplicaSetReconciler{Client: planner, Scheme: scheme.Scheme, Config: &config.OperatorConfig{}}
+		_, err := r.Adopt(ctx, AdoptOptions{Namespace: "default"})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		return planner.Plan("adopt --namespace default")
+	}
+
+	ginkgo.It("Should plan the changes of a command without making them", func() {
+		p := plan()
+		gomega.Expect(p.Changes).To(gomega.HaveLen(1))
+		gomega.Expect(p.Changes[0].Added).To(gomega.ConsistOf(
+			gomega.HaveField("UID", types.UID("web-uid")), gomega.HaveField("UID", types.UID("api-uid"))))
+		gomega.Expect(p.Changes[0].Annotations).To(gomega.HaveKey(ManagedOwnersAnnotation))
+
+		var cm corev1.ConfigMap
+		gomega.Expect(c.Get(ctx, key, &cm)).To(gomega.Succeed())
+		gomega.Expect(cm.OwnerReferences).To(gomega.BeEmpty())
+	})
+
+	ginkgo.It("Should apply exactly the planned changes", func() {
+		p := plan()
+		r := &ReplicaSetReconciler{Client: c, Scheme: scheme.Scheme}
+		applied, err := r.ApplyPlan(ctx, p)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(applied).To(gomega.Equal(1))
+
+		var cm corev1.ConfigMap
+		gomega.Expect(c.Get(ctx, key, &cm)).To(gomega.Succeed())
+		gomega.Expect(cm.OwnerReferences).To(gomega.HaveLen(2))
+		gomega.Expect(managedOwnerUIDs(&cm)).To(gomega.ConsistOf(types.UID("web-uid"), types.UID("api-uid")))
+	})
+
+	ginkgo.It("Should fail without writing if the cluster drifted since the plan", func() {
+		p := plan()
+		gomega.Expect(c.Delete(ctx, testReplicaSet("api", "default"))).To(gomega.Succeed())
+		var cm corev1.ConfigMap
+		gomega.Expect(c.Get(ctx, key, &cm)).To(gomega.Succeed())
+		cm.Data = map[string]string{"key": "changed"}
+		gomega.Expect(c.Update(ctx, &cm)).To(gomega.Succeed())
+
+		r := &ReplicaSetReconciler{Client: c, Scheme: scheme.Scheme}
+		_, err := r.ApplyPlan(ctx, p)
+		gomega.Expect(err).To(gomega.MatchError(gomega.And(
+			gomega.ContainSubstring("changed since the plan"), gomega.ContainSubstring("ReplicaSet/api"))))
+		gomega.Expect(c.Get(ctx, key, &cm)).To(gomega.Succeed())
+		gomega.Expect(cm.OwnerReferences).To(gomega.BeEmpty())
+	})
+})
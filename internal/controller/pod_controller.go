@@ -0,0 +1,50 @@
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+// PodKind is the workload kind of bare Pods. The volumes of a Pod are immutable, so like PodTemplates a Pod is
+// processed once, when created.
+var PodKind = &WorkloadKind{
+	Kind:         "Pod",
+	GroupVersion: corev1.SchemeGroupVersion,
+	New:          func() client.Object { return &corev1.Pod{} },
+	NewList:      func() client.ObjectList { return &corev1.PodList{} },
+	PodSpec: func(obj client.Object) (*corev1.PodSpec, error) {
+		return &obj.(*corev1.Pod).Spec, nil
+	},
+	Enabled: func(cfg *config.OperatorConfig) bool { return cfg.Pods },
+	Accepts: func(_ *config.OperatorConfig, obj client.Object) bool { return isBarePod(obj) },
+}
+
+// isBarePod reports whether pod has no controller
+func isBarePod(pod client.Object) bool {
+	return metav1.GetControllerOf(pod) == nil
+}
+
+// PodReconciler adds bare Pods, those without a controller, as owners of the ConfigMaps they mount, using the same
+// extraction as for ReplicaSets, so per-run ConfigMaps of tooling creating naked Pods are deleted with the Pod.
+// Pods with a controller are left to the controller's kind.
+type PodReconciler struct {
+	// ReplicaSetReconciler provides the namespace filter, write holds, write client and events
+	*ReplicaSetReconciler
+}
+
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
+
+func (r *PodReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	return r.workload(PodKind).Reconcile(ctx, req)
+}
+
+// SetupWithManager sets up the controller with the Manager
+func (r *PodReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return r.workload(PodKind).SetupWithManager(mgr)
+}
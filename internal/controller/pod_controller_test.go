@@ -0,0 +1,57 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+var _ = ginkgo.Describe("PodReconciler", func() {
+	ginkgo.It("Should add bare Pods, but not controlled ones, as owners of the ConfigMaps they mount", func() {
+		ctx := context.Background()
+		spec := testReplicaSet("unused", "default", "run-config").Spec.Template.Spec
+		isController := true
+		bare := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "spark-driver", Namespace: "default", UID: "spark-driver-uid", CreationTimestamp: metav1.Now(),
+			},
+			Spec: spec,
+		}
+		controlled := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "web-abc-xyz", Namespace: "default", UID: "web-pod-uid", CreationTimestamp: metav1.Now(),
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "web-abc", UID: "web-abc-uid", Controller: &isController,
+				}},
+			},
+			Spec: spec,
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).
+			WithObjects(bare, controlled, testConfigMap("run-config", "default")).Build()
+		r := &PodReconciler{ReplicaSetReconciler: &ReplicaSetReconciler{Client: c, Scheme: scheme.Scheme,
+			Config: &config.OperatorConfig{Pods: true}}}
+
+		for _, pod := range []*corev1.Pod{bare, controlled} {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(pod)})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		}
+
+		var cm corev1.ConfigMap
+		gomega.Expect(c.Get(ctx, types.NamespacedName{Namespace: "default", Name: "run-config"}, &cm)).To(gomega.Succeed())
+		gomega.Expect(cm.OwnerReferences).To(gomega.ConsistOf(gomega.And(
+			gomega.HaveField("APIVersion", "v1"),
+			gomega.HaveField("Kind", "Pod"),
+			gomega.HaveField("Name", "spark-driver"),
+		)))
+	})
+})
@@ -0,0 +1,42 @@
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+// PodTemplateKind is the workload kind of standalone PodTemplates. Their pod spec is processed once, when created.
+var PodTemplateKind = &WorkloadKind{
+	Kind:         "PodTemplate",
+	GroupVersion: corev1.SchemeGroupVersion,
+	New:          func() client.Object { return &corev1.PodTemplate{} },
+	NewList:      func() client.ObjectList { return &corev1.PodTemplateList{} },
+	PodSpec: func(obj client.Object) (*corev1.PodSpec, error) {
+		return &obj.(*corev1.PodTemplate).Template.Spec, nil
+	},
+	Enabled: func(cfg *config.OperatorConfig) bool { return cfg.PodTemplates },
+}
+
+// PodTemplateReconciler adds standalone PodTemplates as owners of the ConfigMaps their pod spec mounts,
+// using the same extraction as for ReplicaSets
+type PodTemplateReconciler struct {
+	// ReplicaSetReconciler provides the namespace filter, write holds, write client and events
+	*ReplicaSetReconciler
+}
+
+// +kubebuilder:rbac:groups="",resources=podtemplates,verbs=get;list;watch
+
+func (r *PodTemplateReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	return r.workload(PodTemplateKind).Reconcile(ctx, req)
+}
+
+// SetupWithManager sets up the controller with the Manager. Like ReplicaSets, only PodTemplates created
+// after the operator started are processed.
+func (r *PodTemplateReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return r.workload(PodTemplateKind).SetupWithManager(mgr)
+}
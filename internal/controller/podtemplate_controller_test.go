@@ -0,0 +1,44 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+var _ = ginkgo.Describe("PodTemplateReconciler", func() {
+	ginkgo.It("Should add the PodTemplate as owner of the ConfigMaps it mounts", func() {
+		ctx := context.Background()
+		pt := &corev1.PodTemplate{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "job-template", Namespace: "default", UID: "job-template-uid", CreationTimestamp: metav1.Now(),
+			},
+			Template: testReplicaSet("unused", "default", "cm-a").Spec.Template,
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(pt, testConfigMap("cm-a", "default")).Build()
+		r := &PodTemplateReconciler{
+			ReplicaSetReconciler: &ReplicaSetReconciler{Client: c, Scheme: scheme.Scheme, Config: &config.OperatorConfig{}},
+		}
+
+		key := types.NamespacedName{Namespace: "default", Name: "job-template"}
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+		var cm corev1.ConfigMap
+		gomega.Expect(c.Get(ctx, types.NamespacedName{Namespace: "default", Name: "cm-a"}, &cm)).To(gomega.Succeed())
+		gomega.Expect(cm.OwnerReferences).To(gomega.ConsistOf(gomega.And(
+			gomega.HaveField("Kind", "PodTemplate"),
+			gomega.HaveField("Name", "job-template"),
+		)))
+		gomega.Expect(managedOwnerUIDs(&cm)).To(gomega.ConsistOf(pt.UID))
+	})
+})
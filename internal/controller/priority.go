@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/priorityqueue"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// requeuePriority is the priority of requeued and retried requests; fresh events keep the default of 0
+const requeuePriority = handler.LowPriority
+
+// tieredQueue is a priority queue that demotes requeued and retried requests below fresh events, so work
+// held by the kill switch or a maintenance window, or failing with errors, never delays owning the ConfigMaps
+// of a workload that was just created and could be garbage collected quickly
+type tieredQueue struct {
+	priorityqueue.PriorityQueue[reconcile.Request]
+}
+
+// newTieredQueue implements controller.Options.NewQueue
+func newTieredQueue(
+	name string,
+	rateLimiter workqueue.TypedRateLimiter[reconcile.Request],
+) workqueue.TypedRateLimitingInterface[reconcile.Request] {
+	return &tieredQueue{priorityqueue.New(name, func(o *priorityqueue.Opts[reconcile.Request]) {
+		o.RateLimiter = rateLimiter
+	})}
+}
+
+// AddWithOpts adds items, demoting them when they are delayed or rate limited, which only requeues are
+func (q *tieredQueue) AddWithOpts(o priorityqueue.AddOpts, items ...reconcile.Request) {
+	if o.After > 0 || o.RateLimited {
+		o.Priority = min(o.Priority, requeuePriority)
+	}
+	q.PriorityQueue.AddWithOpts(o, items...)
+}
+
+// AddAfter adds item after duration at the requeue priority
+func (q *tieredQueue) AddAfter(item reconcile.Request, duration time.Duration) {
+	q.AddWithOpts(priorityqueue.AddOpts{After: duration}, item)
+}
+
+// AddRateLimited adds item after the rate limiter allows it, at the requeue priority
+func (q *tieredQueue) AddRateLimited(item reconcile.Request) {
+	q.AddWithOpts(priorityqueue.AddOpts{RateLimited: true}, item)
+}
+
+// controllerOptions returns the options shared by the operator's controllers
+func (r *ReplicaSetReconciler) controllerOptions() controller.Options {
+	var opts controller.Options
+	if r.Config.PrioritizeLiveEvents {
+		opts.NewQueue = newTieredQueue
+	}
+	if r.Coalescer != nil {
+		opts.MaxConcurrentReconciles = coalesceWorkers
+	}
+	return opts
+}
@@ -0,0 +1,32 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/controller/priorityqueue"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = ginkgo.Describe("Tiered queue", func() {
+	ginkgo.It("Should hand out fresh events before requeued work", func() {
+		q := newTieredQueue("test", workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]()).(*tieredQueue)
+		defer q.ShutDown()
+
+		held := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "held"}}
+		live := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "live"}}
+		q.AddAfter(held, time.Millisecond)
+		gomega.Eventually(q.Len).Should(gomega.Equal(1))
+		q.AddWithOpts(priorityqueue.AddOpts{}, live)
+
+		item, priority, _ := q.GetWithPriority()
+		gomega.Expect(item).To(gomega.Equal(live))
+		gomega.Expect(priority).To(gomega.BeZero())
+		item, priority, _ = q.GetWithPriority()
+		gomega.Expect(item).To(gomega.Equal(held))
+		gomega.Expect(priority).To(gomega.Equal(requeuePriority))
+	})
+})
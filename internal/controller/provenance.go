@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ManagedOwnersAnnotation records the UIDs of the owner references added by the operator,
+// so they can be told apart from owner references set by other controllers
+const ManagedOwnersAnnotation = "configmap-rs-operator/managed-owners"
+
+// managedOwnerUIDs returns the owner UIDs the operator recorded on obj
+func managedOwnerUIDs(obj client.Object) []types.UID {
+	value := obj.GetAnnotations()[ManagedOwnersAnnotation]
+	if value == "" {
+		return nil
+	}
+	var uids []types.UID
+	for _, uid := range strings.Split(value, ",") {
+		if uid = strings.TrimSpace(uid); uid != "" {
+			uids = append(uids, types.UID(uid))
+		}
+	}
+	return uids
+}
+
+// ManagedOwnerUIDs returns the owner UIDs the operator recorded on obj, for callers outside the package
+func ManagedOwnerUIDs(obj client.Object) []types.UID {
+	return managedOwnerUIDs(obj)
+}
+
+// setManagedOwnerUIDs stores uids in the provenance annotation, removing it when empty
+func setManagedOwnerUIDs(obj client.Object, uids []types.UID) {
+	annotations := obj.GetAnnotations()
+	if len(uids) == 0 {
+		delete(annotations, ManagedOwnersAnnotation)
+		obj.SetAnnotations(annotations)
+		return
+	}
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	values := make([]string, 0, len(uids))
+	for _, uid := range uids {
+		values = append(values, string(uid))
+	}
+	annotations[ManagedOwnersAnnotation] = strings.Join(values, ",")
+	obj.SetAnnotations(annotations)
+}
+
+// addManagedOwner records uid as an operator-added owner of obj
+func addManagedOwner(obj client.Object, uid types.UID) {
+	uids := managedOwnerUIDs(obj)
+	if slices.Contains(uids, uid) {
+		return
+	}
+	setManagedOwnerUIDs(obj, append(uids, uid))
+}
@@ -0,0 +1,194 @@
+package controller
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+// maxRecordingSize bounds a single recording line when replaying
+const maxRecordingSize = 16 << 20
+
+// Recording is one reconcile captured for replay: the objects it read, in the state it read them,
+// and the decisions it made
+type Recording struct {
+	Time          time.Time            `json:"time"`
+	OperatorStart time.Time            `json:"operatorStart"`
+	Request       types.NamespacedName `json:"request"`
+	ReplicaSets   []appsv1.ReplicaSet  `json:"replicaSets,omitempty"`
+	Namespaces    []corev1.Namespace   `json:"namespaces,omitempty"`
+	ConfigMaps    []corev1.ConfigMap   `json:"configMaps,omitempty"`
+	Decisions     []Decision           `json:"decisions"`
+	Error         string               `json:"error,omitempty"`
+}
+
+// objects returns the captured objects, ready to seed a fake client
+func (rec *Recording) objects() []client.Object {
+	var objs []client.Object
+	for i := range rec.ReplicaSets {
+		objs = append(objs, &rec.ReplicaSets[i])
+	}
+	for i := range rec.Namespaces {
+		objs = append(objs, &rec.Namespaces[i])
+	}
+	for i := range rec.ConfigMaps {
+		objs = append(objs, &rec.ConfigMaps[i])
+	}
+	for _, obj := range objs {
+		obj.SetResourceVersion("")
+	}
+	return objs
+}
+
+// hold returns the reason writes were held for by the kill switch or a maintenance window while
+// recording; unlike dry-run, that state is not part of the recorded objects
+func (rec *Recording) hold() string {
+	for _, d := range rec.Decisions {
+		if d.Action == decisionHeld && d.Reason != holdDryRun {
+			return d.Reason
+		}
+	}
+	return ""
+}
+
+// RecordingWriter writes recordings as JSON lines
+type RecordingWriter struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewRecordingWriter returns a RecordingWriter writing to w
+func NewRecordingWriter(w io.Writer) *RecordingWriter {
+	return &RecordingWriter{enc: json.NewEncoder(w)}
+}
+
+// Write appends rec to the stream
+func (w *RecordingWriter) Write(rec Recording) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.enc.Encode(rec)
+}
+
+// capturingClient records the first state of every object read through it
+type capturingClient struct {
+	client.Client
+
+	mu   sync.Mutex
+	seen map[string]bool
+	rec  *Recording
+}
+
+func (c *capturingClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	if err := c.Client.Get(ctx, key, obj, opts...); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	id := fmt.Sprintf("%T/%s", obj, key)
+	if c.seen[id] {
+		return nil
+	}
+	c.seen[id] = true
+	switch o := obj.(type) {
+	case *appsv1.ReplicaSet:
+		c.rec.ReplicaSets = append(c.rec.ReplicaSets, *o.DeepCopy())
+	case *corev1.Namespace:
+		c.rec.Namespaces = append(c.rec.Namespaces, *o.DeepCopy())
+	case *corev1.ConfigMap:
+		c.rec.ConfigMaps = append(c.rec.ConfigMaps, *o.DeepCopy())
+	}
+	return nil
+}
+
+// reconcileRecorded reconciles req while capturing its inputs and decisions into a recording
+func (r *ReplicaSetReconciler) reconcileRecorded(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	rec := Recording{Time: time.Now().UTC(), OperatorStart: r.StartTime, Request: req.NamespacedName}
+	recorded := *r
+	recorded.Client = &capturingClient{Client: r.Client, seen: map[string]bool{}, rec: &rec}
+
+	ctx, collector := withDecisions(ctx)
+	result, err := recorded.reconcile(ctx, req)
+	if err != nil {
+		recordError(err)
+		rec.Error = err.Error()
+	}
+	rec.Decisions = collector.list()
+	if writeErr := r.Recordings.Write(rec); writeErr != nil {
+		ctrl.LoggerFrom(ctx).Error(writeErr, "Failed to write recording", "replicaset", req.NamespacedName)
+	}
+	return result, err
+}
+
+// ReplayResult summarizes a replay
+type ReplayResult struct {
+	Replayed int `json:"replayed"`
+	Diverged int `json:"diverged"`
+}
+
+// Replay feeds every recording read from in through a reconciler configured with cfg against a fake
+// client seeded with the recorded objects, and calls diverged for each reconcile whose decisions differ
+// from the recorded ones
+func Replay(
+	ctx context.Context,
+	in io.Reader,
+	cfg *config.OperatorConfig,
+	diverged func(rec Recording, got []Decision),
+) (ReplayResult, error) {
+	var result ReplayResult
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		return result, err
+	}
+
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxRecordingSize)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var rec Recording
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return result, fmt.Errorf("invalid recording %d: %w", result.Replayed+1, err)
+		}
+
+		reconciler := &ReplicaSetReconciler{
+			Client:    fake.NewClientBuilder().WithScheme(scheme).WithObjects(rec.objects()...).Build(),
+			Scheme:    scheme,
+			Config:    cfg,
+			StartTime: rec.OperatorStart,
+			hold:      rec.hold(),
+		}
+		replayCtx, collector := withDecisions(ctx)
+		_, _ = reconciler.reconcile(replayCtx, ctrl.Request{NamespacedName: rec.Request})
+		result.Replayed++
+
+		got := collector.list()
+		if len(got) == 0 && len(rec.Decisions) == 0 || reflect.DeepEqual(got, rec.Decisions) {
+			continue
+		}
+		result.Diverged++
+		diverged(rec, got)
+	}
+	if err := scanner.Err(); err != nil && !errors.Is(err, io.EOF) {
+		return result, err
+	}
+	return result, nil
+}
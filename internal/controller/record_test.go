@@ -0,0 +1,58 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+var _ = ginkgo.Describe("Record and replay", func() {
+	ctx := context.Background()
+
+	record := func() *bytes.Buffer {
+		var buf bytes.Buffer
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+			testReplicaSet("test-rs", "default", "present", "missing"), testConfigMap("present", "default"),
+		).Build()
+		reconciler := &ReplicaSetReconciler{
+			Client: c, Scheme: scheme.Scheme, Config: &config.OperatorConfig{},
+			Recordings: NewRecordingWriter(&buf),
+		}
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: "test-rs", Namespace: "default"},
+		})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		return &buf
+	}
+
+	ginkgo.It("Should reproduce the recorded decisions", func() {
+		result, err := Replay(ctx, record(), &config.OperatorConfig{}, func(Recording, []Decision) {
+			ginkgo.Fail("unexpected divergence")
+		})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(result).To(gomega.Equal(ReplayResult{Replayed: 1}))
+	})
+
+	ginkgo.It("Should report decisions changed by a different configuration", func() {
+		var got []Decision
+		result, err := Replay(ctx, record(), &config.OperatorConfig{DryRun: true}, func(rec Recording, replayed []Decision) {
+			gomega.Expect(rec.Decisions).To(gomega.ContainElement(gomega.HaveField("Action", decisionAdded)))
+			got = replayed
+		})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(result.Diverged).To(gomega.Equal(1))
+		gomega.Expect(got).To(gomega.ConsistOf(
+			Decision{Namespace: "default", ReplicaSet: "test-rs", ConfigMap: "present", Action: decisionHeld, Reason: holdDryRun},
+			Decision{Namespace: "default", ReplicaSet: "test-rs", ConfigMap: "missing",
+				Action: decisionSkipped, Reason: "configmap_not_found"},
+		))
+	})
+})
@@ -0,0 +1,193 @@
+package controller
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ReferenceSource is what a ReferenceExtractor finds ConfigMap references in
+type ReferenceSource struct {
+	// Spec is the pod spec of the workload
+	Spec *corev1.PodSpec
+
+	// Annotations and Labels are those of the pod template for ReplicaSets and Deployments, and of the object
+	// itself for the workload kinds, which only expose their pod spec
+	Annotations map[string]string
+	Labels      map[string]string
+
+	// Unmounted includes the volumes no container mounts, with --unmounted-volumes
+	Unmounted bool
+}
+
+// ReferenceExtractor finds the ConfigMaps a workload references in one way, such as mounting them as volumes.
+// Forks add ways, e.g. ConfigMaps named in the pod annotations of an agent injector, with
+// RegisterReferenceExtractor instead of patching the reconcilers.
+type ReferenceExtractor interface {
+	// ConfigMaps returns the names of the ConfigMaps src references, in the namespace of the workload
+	ConfigMaps(src *ReferenceSource) []string
+}
+
+// ReferenceExtractorFunc adapts a function to a ReferenceExtractor
+type ReferenceExtractorFunc func(src *ReferenceSource) []string
+
+// ConfigMaps implements ReferenceExtractor
+func (f ReferenceExtractorFunc) ConfigMaps(src *ReferenceSource) []string {
+	return f(src)
+}
+
+// namedExtractor is a ReferenceExtractor with the reference type --reference-types selects it by
+type namedExtractor struct {
+	ReferenceExtractor
+	name string
+}
+
+// referenceExtractors are the registered extractors, in the order their references are listed
+var referenceExtractors = []namedExtractor{
+	{ReferenceExtractorFunc(volumeReferences), ReferenceVolume},
+	{ReferenceExtractorFunc(projectedReferences), ReferenceProjected},
+	{ReferenceExtractorFunc(envFromReferences), ReferenceEnvFrom},
+	{ReferenceExtractorFunc(envReferences), ReferenceEnv},
+}
+
+// RegisterReferenceExtractor adds e as the way of referencing ConfigMaps called name, which --reference-types
+// then accepts; like the built-in ways, it is used unless --reference-types leaves it out. It must be called
+// before the operator starts, e.g. from an init function, and panics if name is already registered.
+func RegisterReferenceExtractor(name string, e ReferenceExtractor) {
+	if slices.Contains(referenceTypes(), name) {
+		panic(fmt.Sprintf("reference extractor %q already registered", name))
+	}
+	referenceExtractors = append(referenceExtractors, namedExtractor{ReferenceExtractor: e, name: name})
+}
+
+// referenceTypes returns the ways of referencing a ConfigMap the operator owns ConfigMaps for, all of them unless
+// --reference-types lists some
+func referenceTypes() []string {
+	types := make([]string, 0, len(referenceExtractors))
+	for _, e := range referenceExtractors {
+		types = append(types, e.name)
+	}
+	return types
+}
+
+// ValidateReferenceTypes returns an error for reference types --reference-types doesn't accept
+func ValidateReferenceTypes(types []string) error {
+	valid := referenceTypes()
+	for _, t := range types {
+		if !slices.Contains(valid, t) {
+			return fmt.Errorf("invalid reference type %q: expected one of %s", t, strings.Join(valid, ", "))
+		}
+	}
+	return nil
+}
+
+// podConfigMapReferences returns the ConfigMaps src references in one of the ways types lists, every way if
+// empty, in the order the ways were registered
+func podConfigMapReferences(src *ReferenceSource, types []string) []string {
+	var names []string
+	for _, e := range referenceExtractors {
+		if len(types) == 0 || slices.Contains(types, e.name) {
+			names = append(names, e.ConfigMaps(src)...)
+		}
+	}
+	return distinct(names)
+}
+
+// distinct returns names without duplicates, in the order they first appear
+func distinct(names []string) []string {
+	var unique []string
+	for _, name := range names {
+		if !slices.Contains(unique, name) {
+			unique = append(unique, name)
+		}
+	}
+	return unique
+}
+
+// volumeReferences returns the ConfigMaps mounted as volumes, followed by those of the volumes no container
+// mounts with src.Unmounted
+func volumeReferences(src *ReferenceSource) []string {
+	names := podConfigMapVolumes(src.Spec)
+	if !src.Unmounted {
+		return names
+	}
+	for i := range src.Spec.Volumes {
+		if volume := &src.Spec.Volumes[i]; volume.ConfigMap != nil {
+			names = append(names, volume.ConfigMap.Name)
+		}
+	}
+	return names
+}
+
+// projectedReferences returns the ConfigMaps projected into the volumes containers mount, or into every volume
+// with src.Unmounted
+func projectedReferences(src *ReferenceSource) []string {
+	var names []string
+	for i := range src.Spec.Volumes {
+		volume := &src.Spec.Volumes[i]
+		if volume.Projected == nil || !src.Unmounted && !isMountedVolume(src.Spec, volume.Name) {
+			continue
+		}
+		for _, source := range volume.Projected.Sources {
+			if source.ConfigMap != nil {
+				names = append(names, source.ConfigMap.Name)
+			}
+		}
+	}
+	return names
+}
+
+// envFromReferences returns the ConfigMaps the envFrom sources of the containers import
+func envFromReferences(src *ReferenceSource) []string {
+	var names []string
+	for _, container := range podContainers(src.Spec) {
+		for _, source := range container.EnvFrom {
+			if source.ConfigMapRef != nil {
+				names = append(names, source.ConfigMapRef.Name)
+			}
+		}
+	}
+	return names
+}
+
+// envReferences returns the ConfigMaps the env variables of the containers read keys of
+func envReferences(src *ReferenceSource) []string {
+	var names []string
+	for _, container := range podContainers(src.Spec) {
+		for _, variable := range container.Env {
+			if ref := variable.ValueFrom; ref != nil && ref.ConfigMapKeyRef != nil {
+				names = append(names, ref.ConfigMapKeyRef.Name)
+			}
+		}
+	}
+	return names
+}
+
+// podContainers returns the containers of spec followed by its init containers
+func podContainers(spec *corev1.PodSpec) []corev1.Container {
+	return append(append([]corev1.Container(nil), spec.Containers...), spec.InitContainers...)
+}
+
+// isMountedVolume reports whether a container or init container of spec mounts the volume name
+func isMountedVolume(spec *corev1.PodSpec, name string) bool {
+	for _, container := range podContainers(spec) {
+		for _, mount := range container.VolumeMounts {
+			if mount.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// podConfigMaps returns the ConfigMaps spec, whose pod template has the annotations and labels of meta, references
+// in the ways --reference-types lists, including the volumes no container mounts with --unmounted-volumes
+func (r *ReplicaSetReconciler) podConfigMaps(spec *corev1.PodSpec, meta metav1.Object) []string {
+	src := &ReferenceSource{
+		Spec: spec, Annotations: meta.GetAnnotations(), Labels: meta.GetLabels(), Unmounted: r.Config.UnmountedVolumes,
+	}
+	return podConfigMapReferences(src, r.Config.ReferenceTypes)
+}
@@ -0,0 +1,181 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+var _ = ginkgo.Describe("Reference types", func() {
+	envFrom := func(name string) corev1.EnvFromSource {
+		return corev1.EnvFromSource{ConfigMapRef: &corev1.ConfigMapEnvSource{
+			LocalObjectReference: corev1.LocalObjectReference{Name: name},
+		}}
+	}
+
+	ginkgo.It("Should own the ConfigMaps containers and init containers import with envFrom", func() {
+		ctx := context.Background()
+		rs := testReplicaSet("web-abc", "default", "app-config")
+		rs.CreationTimestamp = metav1.Now()
+		spec := &rs.Spec.Template.Spec
+		spec.Containers[0].EnvFrom = []corev1.EnvFromSource{envFrom("app-env")}
+		spec.InitContainers = []corev1.Container{
+			{Name: "migrate", EnvFrom: []corev1.EnvFromSource{envFrom("db-env"), envFrom("app-config")}},
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(rs, testConfigMap("app-config", "default"),
+			testConfigMap("app-env", "default"), testConfigMap("db-env", "default")).Build()
+		r := &ReplicaSetReconciler{Client: c, Scheme: scheme.Scheme, Config: &config.OperatorConfig{}}
+
+		gomega.Expect(r.extractConfigMapVolumes(rs)).To(gomega.Equal([]string{"app-config", "app-env", "db-env"}))
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: rs.Name}})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		for _, name := range []string{"app-config", "app-env", "db-env"} {
+			var cm corev1.ConfigMap
+			gomega.Expect(c.Get(ctx, types.NamespacedName{Namespace: "default", Name: name}, &cm)).To(gomega.Succeed())
+			gomega.Expect(cm.OwnerReferences).To(gomega.ConsistOf(gomega.HaveField("Name", rs.Name)), name)
+		}
+	})
+
+	ginkgo.It("Should own the ConfigMaps env variables read keys of, honouring their optional flag", func() {
+		optional := true
+		keyRef := func(name string, optional *bool) corev1.EnvVar {
+			return corev1.EnvVar{Name: "VALUE", ValueFrom: &corev1.EnvVarSource{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: name}, Key: "value", Optional: optional,
+			}}}
+		}
+		spec := &testReplicaSet("web-abc", "default").Spec.Template.Spec
+		spec.Containers[0].Env = []corev1.EnvVar{
+			{Name: "PLAIN", Value: "1"}, keyRef("logging", nil), keyRef("tuning", &optional),
+		}
+
+		gomega.Expect(podConfigMapReferences(&ReferenceSource{Spec: spec}, nil)).To(
+			gomega.Equal([]string{"logging", "tuning"}))
+		gomega.Expect(podConfigMapReferences(&ReferenceSource{Spec: spec}, []string{ReferenceEnvFrom})).To(gomega.BeEmpty())
+		gomega.Expect(isOptionalReference(spec, "tuning")).To(gomega.BeTrue())
+		gomega.Expect(isOptionalReference(spec, "logging")).To(gomega.BeFalse())
+	})
+
+	ginkgo.It("Should own the ConfigMaps projected into mounted volumes with the keys they project", func() {
+		optional := true
+		spec := &testReplicaSet("web-abc", "default").Spec.Template.Spec
+		spec.Volumes = []corev1.Volume{{Name: "settings", VolumeSource: corev1.VolumeSource{
+			Projected: &corev1.ProjectedVolumeSource{Sources: []corev1.VolumeProjection{
+				{ConfigMap: &corev1.ConfigMapProjection{LocalObjectReference: corev1.LocalObjectReference{Name: "defaults"}}},
+				{ConfigMap: &corev1.ConfigMapProjection{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "overrides"},
+					Items:                []corev1.KeyToPath{{Key: "limits", Path: "limits.yaml"}},
+					Optional:             &optional,
+				}},
+				{Secret: &corev1.SecretProjection{LocalObjectReference: corev1.LocalObjectReference{Name: "credentials"}}},
+			}},
+		}}}
+		spec.Containers[0].VolumeMounts = []corev1.VolumeMount{{Name: "settings", MountPath: "/etc/app"}}
+
+		gomega.Expect(podConfigMapReferences(&ReferenceSource{Spec: spec}, nil)).To(
+			gomega.Equal([]string{"defaults", "overrides"}))
+		gomega.Expect(podConfigMapReferences(&ReferenceSource{Spec: spec}, []string{ReferenceVolume})).To(gomega.BeEmpty())
+		_, all := consumedKeys(spec, "defaults")
+		gomega.Expect(all).To(gomega.BeTrue())
+		keys, all := consumedKeys(spec, "overrides")
+		gomega.Expect(all).To(gomega.BeFalse())
+		gomega.Expect(keys).To(gomega.Equal([]string{"limits"}))
+		gomega.Expect(isOptionalReference(spec, "overrides")).To(gomega.BeTrue())
+		gomega.Expect(isOptionalReference(spec, "defaults")).To(gomega.BeFalse())
+	})
+
+	ginkgo.It("Should own the ConfigMaps of unmounted volumes only with --unmounted-volumes", func() {
+		ctx := context.Background()
+		optional := true
+		rs := testReplicaSet("web-abc", "default", "app-config")
+		rs.CreationTimestamp = metav1.Now()
+		spec := &rs.Spec.Template.Spec
+		spec.Volumes = append(spec.Volumes,
+			corev1.Volume{Name: "sidecar", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "sidecar-config"}, Optional: &optional,
+			}}},
+			corev1.Volume{Name: "bundle", VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{
+				Sources: []corev1.VolumeProjection{{ConfigMap: &corev1.ConfigMapProjection{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "bundle-config"},
+				}}},
+			}}})
+
+		gomega.Expect(podConfigMapReferences(&ReferenceSource{Spec: spec}, nil)).To(gomega.Equal([]string{"app-config"}))
+		gomega.Expect(podConfigMapReferences(&ReferenceSource{Spec: spec, Unmounted: true}, []string{ReferenceProjected})).To(
+			gomega.Equal([]string{"bundle-config"}))
+		gomega.Expect(isOptionalReference(spec, "sidecar-config")).To(gomega.BeTrue())
+
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(rs, testConfigMap("app-config", "default"),
+			testConfigMap("sidecar-config", "default"), testConfigMap("bundle-config", "default")).Build()
+		r := &ReplicaSetReconciler{
+			Client: c, Scheme: scheme.Scheme, Config: &config.OperatorConfig{UnmountedVolumes: true},
+		}
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: rs.Name}})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		for _, name := range []string{"app-config", "sidecar-config", "bundle-config"} {
+			var cm corev1.ConfigMap
+			gomega.Expect(c.Get(ctx, types.NamespacedName{Namespace: "default", Name: name}, &cm)).To(gomega.Succeed())
+			gomega.Expect(cm.OwnerReferences).To(gomega.ConsistOf(gomega.HaveField("Name", rs.Name)), name)
+		}
+	})
+
+	ginkgo.It("Should only extract the listed reference types", func() {
+		spec := &testReplicaSet("web-abc", "default", "app-config").Spec.Template.Spec
+		spec.Containers[0].EnvFrom = []corev1.EnvFromSource{envFrom("app-env")}
+
+		gomega.Expect(podConfigMapReferences(&ReferenceSource{Spec: spec}, []string{ReferenceVolume})).To(
+			gomega.Equal([]string{"app-config"}))
+		gomega.Expect(podConfigMapReferences(&ReferenceSource{Spec: spec}, []string{ReferenceEnvFrom})).To(
+			gomega.Equal([]string{"app-env"}))
+	})
+
+	ginkgo.It("Should treat an envFrom source as consuming every key and honour its optional flag", func() {
+		optional := true
+		spec := &corev1.PodSpec{Containers: []corev1.Container{{Name: "app", EnvFrom: []corev1.EnvFromSource{
+			{ConfigMapRef: &corev1.ConfigMapEnvSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "overrides"}, Optional: &optional,
+			}},
+			envFrom("app-env"),
+		}}}}
+
+		_, all := consumedKeys(spec, "app-env")
+		gomega.Expect(all).To(gomega.BeTrue())
+		gomega.Expect(isOptionalReference(spec, "overrides")).To(gomega.BeTrue())
+		gomega.Expect(isOptionalReference(spec, "app-env")).To(gomega.BeFalse())
+	})
+
+	ginkgo.It("Should use the registered reference extractors", func() {
+		registered := referenceExtractors
+		ginkgo.DeferCleanup(func() { referenceExtractors = registered })
+		RegisterReferenceExtractor("vault", ReferenceExtractorFunc(func(src *ReferenceSource) []string {
+			if name, ok := src.Annotations["vault.hashicorp.com/agent-configmap"]; ok {
+				return []string{name}
+			}
+			return nil
+		}))
+		gomega.Expect(func() { RegisterReferenceExtractor(ReferenceEnv, ReferenceExtractorFunc(envReferences)) }).To(
+			gomega.Panic())
+
+		src := &ReferenceSource{
+			Spec:        &testReplicaSet("web-abc", "default", "app-config").Spec.Template.Spec,
+			Annotations: map[string]string{"vault.hashicorp.com/agent-configmap": "agent-config"},
+		}
+		gomega.Expect(ValidateReferenceTypes([]string{"vault"})).To(gomega.Succeed())
+		gomega.Expect(podConfigMapReferences(src, nil)).To(gomega.Equal([]string{"app-config", "agent-config"}))
+		gomega.Expect(podConfigMapReferences(src, []string{ReferenceVolume})).To(gomega.Equal([]string{"app-config"}))
+	})
+
+	ginkgo.It("Should reject unknown reference types", func() {
+		gomega.Expect(ValidateReferenceTypes(nil)).To(gomega.Succeed())
+		gomega.Expect(ValidateReferenceTypes(referenceTypes())).To(gomega.Succeed())
+		gomega.Expect(ValidateReferenceTypes([]string{"secret"})).To(gomega.MatchError(gomega.ContainSubstring("secret")))
+	})
+})
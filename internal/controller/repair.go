@@ -0,0 +1,114 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// repairableDrifts lists the drift types Repair can fix. A changed UID isn't repaired: the ConfigMap belonged
+// to an owner that is gone, and the garbage collector is already deleting it.
+var repairableDrifts = []string{DriftReferenceRemoved, DriftAnnotationOrphaned}
+
+// RepairOptions controls which drifts Repair fixes
+type RepairOptions struct {
+	// Namespace limits the repair to a single namespace; empty means all namespaces
+	Namespace string
+
+	// Types limits the repair to these drift types; empty means every repairable type
+	Types []string
+
+	// DryRun only logs what would be repaired
+	DryRun bool
+}
+
+// ValidateRepairTypes returns an error unless every type is a drift type Repair can fix
+func ValidateRepairTypes(repairTypes []string) error {
+	for _, t := range repairTypes {
+		if !slices.Contains(repairableDrifts, t) {
+			return fmt.Errorf("invalid drift type %q: expected %s or %s", t, DriftReferenceRemoved, DriftAnnotationOrphaned)
+		}
+	}
+	return nil
+}
+
+// Repair scans for drift and fixes it: owner references of recorded owners that still exist are added back, and
+// UIDs of owners that are gone are removed from the provenance annotation. It returns the drifts it repaired, or
+// would repair in dry-run mode.
+func (r *ReplicaSetReconciler) Repair(ctx context.Context, opts RepairOptions, logger logr.Logger) ([]Drift, error) {
+	if err := ValidateRepairTypes(opts.Types); err != nil {
+		return nil, err
+	}
+	repairTypes := opts.Types
+	if len(repairTypes) == 0 {
+		repairTypes = repairableDrifts
+	}
+	drifts, err := r.ScanDrift(ctx)
+	if err != nil {
+		return nil, err
+	}
+	owners, err := r.listOwners(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byConfigMap := map[types.NamespacedName][]Drift{}
+	var keys []types.NamespacedName
+	for _, d := range drifts {
+		if opts.Namespace != "" && d.Namespace != opts.Namespace || !slices.Contains(repairTypes, d.Type) {
+			continue
+		}
+		key := types.NamespacedName{Namespace: d.Namespace, Name: d.ConfigMap}
+		if _, ok := byConfigMap[key]; !ok {
+			keys = append(keys, key)
+		}
+		byConfigMap[key] = append(byConfigMap[key], d)
+	}
+
+	repaired := []Drift{}
+	for _, key := range keys {
+		if opts.DryRun {
+			logger.Info("DRY-RUN: Would repair ownership drift", "configmap", key.Name, "namespace", key.Namespace,
+				"drifts", byConfigMap[key])
+			repaired = append(repaired, byConfigMap[key]...)
+			continue
+		}
+		if err := r.repairConfigMap(ctx, key, byConfigMap[key], owners); err != nil {
+			return repaired, fmt.Errorf("unable to repair ConfigMap %s: %w", key, err)
+		}
+		logger.Info("Repaired ownership drift", "configmap", key.Name, "namespace", key.Namespace)
+		repaired = append(repaired, byConfigMap[key]...)
+	}
+	return repaired, nil
+}
+
+// repairConfigMap fixes the drifts of the ConfigMap key with a single patch
+func (r *ReplicaSetReconciler) repairConfigMap(
+	ctx context.Context,
+	key types.NamespacedName,
+	drifts []Drift,
+	owners *liveOwners,
+) error {
+	var cm corev1.ConfigMap
+	if err := r.Get(ctx, key, &cm); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	patch := client.MergeFrom(cm.DeepCopy())
+	managed := managedOwnerUIDs(&cm)
+	for _, d := range drifts {
+		switch d.Type {
+		case DriftReferenceRemoved:
+			upsertOwnerReference(&cm, owners.byUID[d.UID])
+			ownershipChangesTotal.WithLabelValues(ownershipAdded).Inc()
+		case DriftAnnotationOrphaned:
+			managed = slices.DeleteFunc(managed, func(uid types.UID) bool { return uid == d.UID })
+		}
+	}
+	setManagedOwnerUIDs(&cm, managed)
+	return r.writer().Patch(ctx, &cm, patch)
+}
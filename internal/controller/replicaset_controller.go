@@ -3,42 +3,129 @@ package controller
 import (
 	"context"
 	"regexp"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
 	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+	"github.com/matanbaruch/configmap-rs-operator/internal/pause"
+	"github.com/matanbaruch/configmap-rs-operator/internal/schedule"
+	"github.com/matanbaruch/configmap-rs-operator/internal/state"
 )
 
+// DryRunAnnotation on a Namespace overrides the global dry-run setting for that namespace:
+// "true" forces dry-run, "false" explicitly allows writes even when the operator runs in dry-run mode
+const DryRunAnnotation = "configmap-rs-operator/dry-run"
+
+// Reasons for holding back a write; the reason prefixes the "Would add" log line
+const (
+	holdDryRun      = "DRY-RUN"
+	holdMaintenance = "MAINTENANCE-WINDOW"
+	holdPaused      = "PAUSED"
+)
+
+// pausedRequeueInterval is how often held work is retried while the kill switch is engaged
+const pausedRequeueInterval = time.Minute
+
 // ReplicaSetReconciler reconciles a ReplicaSet object
 type ReplicaSetReconciler struct {
 	client.Client
 	Scheme    *runtime.Scheme
 	Config    *config.OperatorConfig
 	StartTime time.Time
+	Recorder  record.EventRecorder
+
+	// MaintenanceWindow restricts writes to its windows; nil means writes are always allowed
+	MaintenanceWindow *schedule.Schedule
+
+	// Pause is the cluster-wide kill switch; nil means the operator can't be paused
+	Pause *pause.Switch
+
+	// Writer performs all mutations, e.g. with an impersonated identity; nil uses Client
+	Writer client.Writer
+
+	// Churn flags namespaces whose ownership change rate spikes; nil disables the guardrail
+	Churn *ChurnDetector
+
+	// Shadow runs the reconciler read-only and compares its decisions with the active operator's writes
+	Shadow *ShadowReport
+
+	// Recordings receives every reconcile with its inputs and decisions, for replay; nil disables recording
+	Recordings *RecordingWriter
+
+	// Activity keeps the recent decisions and errors for the dashboard; nil disables it
+	Activity *ActivityLog
+
+	// Precomputed holds the ConfigMaps of Deployments computed before their ReplicaSets exist; nil disables it
+	Precomputed *Precomputation
+
+	// OwnerRules select the owner strategy per ConfigMap name; without rules ReplicaSets own their ConfigMaps
+	OwnerRules []OwnerRule
+
+	// Tracker records when each controller last reconciled successfully; nil disables it
+	Tracker *ReconcileTracker
+
+	// Namespaces caches which namespaces the namespace regex selects; nil evaluates the regex on every check
+	Namespaces *NamespaceFilter
+
+	// State shares bookkeeping such as backfill progress across replicas and restarts; nil keeps none
+	State state.Store
+
+	// Coalescer batches the owner references added to the same ConfigMap into one apply; nil updates right away
+	Coalescer *Coalescer
+
+	// hold forces writes to be held for the given reason, to replay recordings made while they were
+	hold string
 }
 
 // +kubebuilder:rbac:groups=apps,resources=replicasets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
 
 func (r *ReplicaSetReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	var collector *decisionCollector
+	if r.Activity != nil {
+		ctx, collector = withDecisions(ctx)
+	}
+
+	var result ctrl.Result
+	var err error
+	if r.Recordings != nil {
+		result, err = r.reconcileRecorded(ctx, req)
+	} else if result, err = r.reconcile(ctx, req); err != nil {
+		recordError(err)
+	}
+
+	if collector != nil {
+		r.Activity.record(req.NamespacedName, collector.list(), err)
+	}
+	return result, err
+}
+
+func (r *ReplicaSetReconciler) reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := log.FromContext(ctx).WithValues("replicaset", req.NamespacedName)
 
 	// Check if namespace matches our selection criteria
-	if !r.shouldProcessNamespace(req.Namespace) {
+	if !r.shouldProcessNamespace(ctx, req.Namespace) {
 		logger.V(1).Info("Skipping ReplicaSet in unmatched namespace", "namespace", req.Namespace)
+		recordFiltered(dropReasonNamespace)
+		recordDecision(ctx, Decision{Namespace: req.Namespace, ReplicaSet: req.Name,
+			Action: decisionSkipped, Reason: dropReasonNamespace})
 		return ctrl.Result{}, nil
 	}
 
@@ -47,82 +134,242 @@ func (r *ReplicaSetReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	if err := r.Get(ctx, req.NamespacedName, &rs); err != nil {
 		if errors.IsNotFound(err) {
 			logger.V(1).Info("ReplicaSet not found, ignoring since object must be deleted")
+			recordDecision(ctx, Decision{Namespace: req.Namespace, ReplicaSet: req.Name,
+				Action: decisionSkipped, Reason: "replicaset_not_found"})
 			return ctrl.Result{}, nil
 		}
 		logger.Error(err, "Failed to get ReplicaSet")
 		return ctrl.Result{}, err
 	}
 
-	// Only process ReplicaSets created after the operator started
-	// This prevents processing existing ReplicaSets when the operator starts
-	creationTime := rs.CreationTimestamp.Time
-	if creationTime.Before(r.StartTime) {
-		logger.Info("Skipping ReplicaSet created before operator start",
-			"name", rs.Name,
-			"created", creationTime.Format(time.RFC3339),
-			"operatorStart", r.StartTime.Format(time.RFC3339))
+	if reason, err := r.dropReason(ctx, &rs, logger); err != nil {
+		logger.Error(err, "Failed to check whether to process the ReplicaSet")
+		return ctrl.Result{}, err
+	} else if reason != "" {
+		recordFiltered(reason)
+		recordDecision(ctx, Decision{Namespace: req.Namespace, ReplicaSet: req.Name,
+			Action: decisionSkipped, Reason: reason})
 		return ctrl.Result{}, nil
 	}
 
+	// Extract ConfigMaps referenced as volumes, and the one named after the workload by convention
+	configMapNames, err := r.withConventionConfigMap(ctx, rs.Namespace, replicaSetWorkloadName(&rs),
+		r.extractConfigMapVolumes(&rs))
+	if err != nil {
+		logger.Error(err, "Failed to get the ConfigMap named by convention")
+		return ctrl.Result{}, err
+	}
+	creationTime := rs.CreationTimestamp.Time
 	if r.Config.Debug {
 		logger.Info("Processing recently created ReplicaSet",
 			"name", rs.Name,
 			"namespace", rs.Namespace,
 			"age", time.Since(creationTime),
 			"created", creationTime.Format(time.RFC3339),
-			"operatorStart", r.StartTime.Format(time.RFC3339))
+			"operatorStart", r.StartTime.Format(time.RFC3339),
+			"configmaps", configMapNames)
+	}
+	configMapsPerWorkload.WithLabelValues("ReplicaSet").Observe(float64(len(configMapNames)))
+	if r.Precomputed != nil {
+		r.Precomputed.observe(&rs, configMapNames)
 	}
 
-	// Extract ConfigMaps referenced as volumes
-	configMapNames := r.extractConfigMapVolumes(&rs)
+	// In shadow mode, give the active operator time to act, then compare instead of writing
+	if r.Shadow != nil {
+		if wait := r.Shadow.Delay - time.Since(creationTime); wait > 0 {
+			return ctrl.Result{RequeueAfter: wait}, nil
+		}
+		return ctrl.Result{}, r.compareShadow(ctx, &rs, configMapNames, logger)
+	}
+
+	r.detectCrossNamespace(ctx, &rs, logger)
+
 	if len(configMapNames) == 0 {
 		logger.V(1).Info("No ConfigMaps found in ReplicaSet volumes")
 		return ctrl.Result{}, nil
 	}
 
-	if r.Config.Debug {
-		logger.Info("Found ConfigMaps in volumes", "configmaps", configMapNames)
+	// When writes are held back decisions are still evaluated and logged
+	now := time.Now()
+	holdReason := r.holdReason(ctx, rs.Namespace, now, logger)
+
+	if err := r.processConfigMaps(ctx, replicaSetWorkload(&rs), configMapNames, holdReason, logger); err != nil {
+		return ctrl.Result{}, err
 	}
 
-	// Process each ConfigMap
-	for _, cmName := range configMapNames {
-		if err := r.processConfigMap(ctx, rs.Namespace, cmName, &rs, logger); err != nil {
-			return ctrl.Result{}, err
-		}
+	switch holdReason {
+	case holdPaused:
+		logger.Info("Operator is paused, deferring writes")
+	case holdMaintenance:
+		next := r.MaintenanceWindow.Next(now)
+		logger.Info("Outside maintenance window, deferring writes", "nextWindow", next.Format(time.RFC3339))
+	}
+	return r.heldResult(holdReason, now), nil
+}
+
+// dropReason returns why rs is dropped before any work is done, or an empty string if it is processed
+func (r *ReplicaSetReconciler) dropReason(
+	ctx context.Context,
+	rs *appsv1.ReplicaSet,
+	logger logr.Logger,
+) (string, error) {
+	// Leave alone ReplicaSets of controllers that manage their own ConfigMaps and, with --skip-dormant, idle ones
+	if reason, err := r.ignoreReason(ctx, rs); err != nil {
+		return "", err
+	} else if reason != "" {
+		logger.V(1).Info("Skipping ignored ReplicaSet", "reason", reason)
+		return reason, nil
 	}
 
-	return ctrl.Result{}, nil
+	// Only process ReplicaSets created after the operator started
+	// This prevents processing existing ReplicaSets when the operator starts
+	creationTime := rs.CreationTimestamp.Time
+	if creationTime.Before(r.StartTime) {
+		logger.Info("Skipping ReplicaSet created before operator start",
+			"name", rs.Name,
+			"created", creationTime.Format(time.RFC3339),
+			"operatorStart", r.StartTime.Format(time.RFC3339))
+		return dropReasonStartTime, nil
+	}
+	return "", nil
 }
 
-func (r *ReplicaSetReconciler) shouldProcessNamespace(namespace string) bool {
-	if len(r.Config.NamespaceRegex) == 0 {
-		return true
+// holdReason returns why writes must be held back right now, or an empty string if they are allowed.
+// Dry-run takes precedence since held work is not requeued for it.
+func (r *ReplicaSetReconciler) holdReason(ctx context.Context, namespace string, now time.Time, logger logr.Logger) string {
+	if r.isDryRun(ctx, namespace, logger) {
+		return holdDryRun
+	}
+	if r.hold != "" {
+		return r.hold
 	}
+	paused, err := r.Pause.Paused(ctx)
+	if err != nil {
+		// Fail safe: an unreadable kill switch must not allow writes
+		logger.Error(err, "Failed to read pause state, holding writes")
+		return holdPaused
+	}
+	if paused {
+		return holdPaused
+	}
+	if !r.MaintenanceWindow.Active(now) {
+		return holdMaintenance
+	}
+	return ""
+}
 
-	for _, pattern := range r.Config.NamespaceRegex {
-		matched, err := regexp.MatchString(pattern, namespace)
-		if err != nil {
-			// Log error but don't fail reconciliation
-			continue
-		}
-		if matched {
+// WriteHold returns why writes to namespace must be held back right now, for the runners writing outside a
+// reconcile
+func (r *ReplicaSetReconciler) WriteHold(ctx context.Context, namespace string) string {
+	return r.holdReason(ctx, namespace, time.Now(), log.FromContext(ctx))
+}
+
+// heldResult returns the result retrying work held back for holdReason: every pausedRequeueInterval while the kill
+// switch is engaged, and when the next window opens outside the maintenance windows
+func (r *ReplicaSetReconciler) heldResult(holdReason string, now time.Time) ctrl.Result {
+	switch holdReason {
+	case holdPaused:
+		recordRequeue(requeueReasonPaused)
+		return ctrl.Result{RequeueAfter: pausedRequeueInterval}
+	case holdMaintenance:
+		recordRequeue(requeueReasonMaintenance)
+		return ctrl.Result{RequeueAfter: r.MaintenanceWindow.Next(now).Sub(now)}
+	}
+	return ctrl.Result{}
+}
+
+func (r *ReplicaSetReconciler) shouldProcessNamespace(ctx context.Context, namespace string) bool {
+	if r.Namespaces != nil {
+		return r.Namespaces.Matches(namespace)
+	}
+	return namespaceMatches(r.Config.NamespaceRegex, r.Config.NamespaceExclude, namespace) &&
+		!r.namespaceDisabled(ctx, namespace)
+}
+
+// namespaceDisabled reads whether namespace opted out with the DisabledNamespaceLabel, for the reconcilers run
+// without the NamespaceFilter, such as --once and the CLI. A namespace that can't be read counts as opted out,
+// so an error never allows writes.
+func (r *ReplicaSetReconciler) namespaceDisabled(ctx context.Context, namespace string) bool {
+	var ns corev1.Namespace
+	if err := r.Get(ctx, types.NamespacedName{Name: namespace}, &ns); err != nil {
+		return !errors.IsNotFound(err)
+	}
+	return ns.Labels[DisabledNamespaceLabel] == "true"
+}
+
+// namespaceMatches reports whether namespace matches one of the include patterns, or there are none, and
+// none of the exclude patterns
+func namespaceMatches(include, exclude []string, namespace string) bool {
+	return (len(include) == 0 || matchesAny(include, namespace)) && !matchesAny(exclude, namespace)
+}
+
+// compiledPatterns caches the namespace and workload name patterns by source, so each is compiled once rather
+// than on every event. Patterns that don't compile are cached as nil.
+var compiledPatterns sync.Map
+
+// compiledPattern returns pattern compiled, or nil if it doesn't compile
+func compiledPattern(pattern string) *regexp.Regexp {
+	if re, ok := compiledPatterns.Load(pattern); ok {
+		return re.(*regexp.Regexp)
+	}
+	// A pattern that doesn't compile never matches
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		re = nil
+	}
+	compiledPatterns.Store(pattern, re)
+	return re
+}
+
+// matchesAny reports whether name, a namespace or workload name, matches one of patterns
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if re := compiledPattern(pattern); re != nil && re.MatchString(name) {
 			return true
 		}
 	}
 	return false
 }
 
+// isDryRun returns the effective dry-run setting for namespace, honoring the namespace's DryRunAnnotation
+func (r *ReplicaSetReconciler) isDryRun(ctx context.Context, namespace string, logger logr.Logger) bool {
+	var ns corev1.Namespace
+	if err := r.Get(ctx, types.NamespacedName{Name: namespace}, &ns); err != nil {
+		if !errors.IsNotFound(err) {
+			logger.Error(err, "Failed to get Namespace, using global dry-run setting", "namespace", namespace)
+		}
+		return r.Config.DryRun
+	}
+
+	switch ns.Annotations[DryRunAnnotation] {
+	case "true":
+		return true
+	case "false":
+		return false
+	default:
+		return r.Config.DryRun
+	}
+}
+
+// extractConfigMapVolumes returns the ConfigMaps rs references in the ways --reference-types lists, mounted as
+// volumes by default, and those the ExtraConfigMapsAnnotation of the ReplicaSet or its pod template declares
 func (r *ReplicaSetReconciler) extractConfigMapVolumes(rs *appsv1.ReplicaSet) []string {
+	template := &rs.Spec.Template
+	return withExtraConfigMaps(r.podConfigMaps(&template.Spec, template), rs.Annotations, template.Annotations)
+}
+
+// podConfigMapVolumes returns the ConfigMaps mounted as volumes by the containers of spec
+func podConfigMapVolumes(spec *corev1.PodSpec) []string {
 	var configMapNames []string
 	configMapSet := make(map[string]bool)
 
 	// Check all containers in all pods
-	for i := range rs.Spec.Template.Spec.Containers {
-		container := &rs.Spec.Template.Spec.Containers[i]
+	for i := range spec.Containers {
+		container := &spec.Containers[i]
 		for _, volumeMount := range container.VolumeMounts {
 			// Find corresponding volume
-			for j := range rs.Spec.Template.Spec.Volumes {
-				volume := &rs.Spec.Template.Spec.Volumes[j]
+			for j := range spec.Volumes {
+				volume := &spec.Volumes[j]
 				if volume.Name == volumeMount.Name && volume.ConfigMap != nil {
 					if !configMapSet[volume.ConfigMap.Name] {
 						configMapSet[volume.ConfigMap.Name] = true
@@ -134,11 +381,11 @@ func (r *ReplicaSetReconciler) extractConfigMapVolumes(rs *appsv1.ReplicaSet) []
 	}
 
 	// Check init containers as well
-	for i := range rs.Spec.Template.Spec.InitContainers {
-		container := &rs.Spec.Template.Spec.InitContainers[i]
+	for i := range spec.InitContainers {
+		container := &spec.InitContainers[i]
 		for _, volumeMount := range container.VolumeMounts {
-			for j := range rs.Spec.Template.Spec.Volumes {
-				volume := &rs.Spec.Template.Spec.Volumes[j]
+			for j := range spec.Volumes {
+				volume := &spec.Volumes[j]
 				if volume.Name == volumeMount.Name && volume.ConfigMap != nil {
 					if !configMapSet[volume.ConfigMap.Name] {
 						configMapSet[volume.ConfigMap.Name] = true
@@ -152,56 +399,233 @@ func (r *ReplicaSetReconciler) extractConfigMapVolumes(rs *appsv1.ReplicaSet) []
 	return configMapNames
 }
 
-func (r *ReplicaSetReconciler) processConfigMap(
+// podWorkload is an object whose pod spec references ConfigMaps: a ReplicaSet, or an object of a workload kind
+type podWorkload struct {
+	obj  client.Object
+	spec *corev1.PodSpec
+
+	// self is the owner reference to obj
+	self metav1.OwnerReference
+
+	// rs is obj as a ReplicaSet, nil for the workload kinds. Owner rules may pick the Deployment of a ReplicaSet,
+	// and rollout handoff, precomputed ConfigMaps and shared optional references are only known for ReplicaSets.
+	rs *appsv1.ReplicaSet
+}
+
+// replicaSetWorkload returns rs as a podWorkload
+func replicaSetWorkload(rs *appsv1.ReplicaSet) *podWorkload {
+	return &podWorkload{
+		obj:  rs,
+		spec: &rs.Spec.Template.Spec,
+		self: metav1.OwnerReference{
+			APIVersion: appsv1.SchemeGroupVersion.String(), Kind: "ReplicaSet", Name: rs.Name, UID: rs.UID,
+		},
+		rs: rs,
+	}
+}
+
+// key is the lowercase kind of the workload, which keys it in logs
+func (w *podWorkload) key() string {
+	return strings.ToLower(w.self.Kind)
+}
+
+// decision returns the decision about the reference of w to the ConfigMap name, before it is made
+func (w *podWorkload) decision(name string) Decision {
+	d := Decision{Namespace: w.obj.GetNamespace(), ReplicaSet: w.obj.GetName(), ConfigMap: name}
+	if w.rs == nil {
+		d.Kind = w.self.Kind
+	}
+	return d
+}
+
+// ownerOf returns the owner reference w warrants on the ConfigMap name, or nil when the owner rules skip it
+func (r *ReplicaSetReconciler) ownerOf(w *podWorkload, name string) *metav1.OwnerReference {
+	if w.rs != nil {
+		return r.ownerFor(w.rs, name)
+	}
+	if r.ownerStrategy(name) == OwnerSkip {
+		return nil
+	}
+	owner := w.self
+	return &owner
+}
+
+// processConfigMaps processes each ConfigMap w mounts, then annotates the owners they were bound to
+func (r *ReplicaSetReconciler) processConfigMaps(
 	ctx context.Context,
-	namespace, name string,
-	rs *appsv1.ReplicaSet,
+	w *podWorkload,
+	names []string,
+	holdReason string,
 	logger logr.Logger,
 ) error {
+	bindings := &workloadBindings{}
+	for _, name := range names {
+		owner, err := r.processConfigMap(ctx, w, name, holdReason, logger)
+		if err != nil {
+			return err
+		}
+		bindings.add(owner, name)
+	}
+	return r.annotateWorkloads(ctx, w.obj.GetNamespace(), bindings, holdReason, logger)
+}
+
+// processConfigMap adds the owner reference w warrants to the ConfigMap name and returns the owner the
+// ConfigMap is bound to, or nil when it isn't. ReplicaSets and the workload kinds share it, so every kind follows
+// the same rules and records the same decisions.
+func (r *ReplicaSetReconciler) processConfigMap(
+	ctx context.Context,
+	w *podWorkload,
+	name string,
+	holdReason string,
+	logger logr.Logger,
+) (*metav1.OwnerReference, error) {
+	decision := w.decision(name)
+	skip := func(reason string) (*metav1.OwnerReference, error) {
+		decision.Action, decision.Reason = decisionSkipped, reason
+		recordDecision(ctx, decision)
+		return nil, nil
+	}
+
 	// Get the ConfigMap
 	var cm corev1.ConfigMap
-	cmKey := types.NamespacedName{Name: name, Namespace: namespace}
-	if err := r.Get(ctx, cmKey, &cm); err != nil {
+	cmKey := types.NamespacedName{Name: name, Namespace: w.obj.GetNamespace()}
+	if err := r.getConfigMap(ctx, cmKey, w.rs, &cm); err != nil {
 		if errors.IsNotFound(err) {
 			logger.V(1).Info("ConfigMap not found", "configmap", name)
-			return nil
+			r.warnMissingConfigMap(w.obj, w.spec, name)
+			return skip(reasonConfigMapNotFound)
 		}
 		logger.Error(err, "Failed to get ConfigMap", "configmap", name)
-		return err
+		return nil, err
+	}
+	if reason := r.protectedReason(name, &cm); reason != "" {
+		logger.V(1).Info("Skipping protected ConfigMap", "configmap", name, "reason", reason)
+		return skip(reason)
+	}
+
+	// Owner rules pick the owner per ConfigMap name, or exclude the ConfigMap
+	owner := r.ownerOf(w, name)
+	if owner == nil {
+		logger.V(1).Info("Skipping ConfigMap excluded by an owner rule", "configmap", name)
+		return skip(reasonOwnerRule)
 	}
 
-	// Check if ReplicaSet is already an owner
-	if r.isOwnerReferencePresent(&cm, rs) {
+	// Check if the workload, or the owner the rules pick for it, is already an owner
+	if hasOwnerReference(&cm, *owner) {
 		if r.Config.Debug {
-			logger.Info("OwnerReference already exists", "configmap", name, "replicaset", rs.Name)
+			logger.Info("OwnerReference already exists", "configmap", name, w.key(), w.obj.GetName())
 		}
-		return nil
+		decision.Action = decisionOwned
+		recordDecision(ctx, decision)
+		return owner, nil
 	}
 
-	if r.Config.DryRun {
-		logger.Info("DRY-RUN: Would add OwnerReference", "configmap", name, "replicaset", rs.Name)
-		return nil
+	if r.blockedByOwnerLimit(ctx, &cm, w.rs) {
+		logger.Info("Skipping ConfigMap with too many owners", "configmap", name, "owners", len(cm.OwnerReferences))
+		r.warnTooManyOwners(&cm, owner.Kind, owner.Name)
+		return skip(reasonTooManyOwners)
 	}
 
-	// Add owner reference
-	if err := controllerutil.SetOwnerReference(rs, &cm, r.Scheme); err != nil {
-		logger.Error(err, "Failed to set owner reference", "configmap", name, "replicaset", rs.Name)
-		return err
+	// In the stricter mode, a volume mounting none of the ConfigMap's keys doesn't couple it to the workload
+	if r.Config.ConsumedKeysOnly && !consumesConfigMap(w.spec, &cm) {
+		logger.V(1).Info("Skipping ConfigMap whose keys are not consumed", "configmap", name)
+		return skip(reasonKeysNotConsumed)
 	}
 
-	// Update the ConfigMap
-	if err := r.Update(ctx, &cm); err != nil {
-		logger.Error(err, "Failed to update ConfigMap with owner reference", "configmap", name)
-		return err
+	reason, err := r.optionalReason(ctx, w, name)
+	if err != nil {
+		logger.Error(err, "Failed to evaluate optional ConfigMap reference", "configmap", name)
+		return nil, err
+	}
+	if reason != "" {
+		logger.V(1).Info("Skipping optional ConfigMap reference", "configmap", name, "reason", reason)
+		return skip(reason)
 	}
 
-	logger.Info("Added OwnerReference to ConfigMap", "configmap", name, "replicaset", rs.Name)
-	return nil
+	if holdReason != "" {
+		logger.Info(holdReason+": Would add OwnerReference", "configmap", name, w.key(), w.obj.GetName())
+		decision.Action, decision.Reason = decisionHeld, holdReason
+		recordDecision(ctx, decision)
+		return nil, nil
+	}
+	return r.writeOwner(ctx, w, &cm, *owner, decision, logger)
+}
+
+// writeOwner proposes owner for cm with --require-approval, or adds it, records the decision and returns the
+// owner cm is bound to
+func (r *ReplicaSetReconciler) writeOwner(
+	ctx context.Context,
+	w *podWorkload,
+	cm *corev1.ConfigMap,
+	owner metav1.OwnerReference,
+	decision Decision,
+	logger logr.Logger,
+) (*metav1.OwnerReference, error) {
+	// Change-controlled clusters queue the owner reference until someone approves it
+	if r.Config.RequireApproval {
+		decision.Action, decision.Reason = decisionHeld, holdApproval
+		recordDecision(ctx, decision)
+		return nil, r.proposeOwner(ctx, cm, owner, logger)
+	}
+
+	// Add the owner reference, bringing metadata written by earlier releases up to date
+	if err := r.addOwner(ctx, cm, owner); err != nil {
+		logger.Error(err, "Failed to update ConfigMap with owner reference", "configmap", cm.Name)
+		r.recordEvent(cm, corev1.EventTypeWarning, "OwnerReferenceFailed",
+			"Failed to add owner reference to %s %s: %v", owner.Kind, owner.Name, err)
+		decision.Action, decision.Reason = decisionFailed, classifyError(err)
+		recordDecision(ctx, decision)
+		return nil, err
+	}
+
+	logger.Info("Added OwnerReference to ConfigMap", "configmap", cm.Name, w.key(), w.obj.GetName(),
+		"owner", owner.Kind)
+	r.recordEvent(cm, corev1.EventTypeNormal, "OwnerReferenceAdded",
+		"Added owner reference to %s %s", owner.Kind, owner.Name)
+	decision.Action = decisionAdded
+	recordDecision(ctx, decision)
+	r.observeChurn(w.obj.GetNamespace(), ownershipAdded, logger)
+	return &owner, nil
+}
+
+// observeChurn records an ownership change and warns when the namespace's change rate spikes
+func (r *ReplicaSetReconciler) observeChurn(namespace, change string, logger logr.Logger) {
+	changes, spike := r.Churn.Observe(namespace, change)
+	if !spike {
+		return
+	}
+	logger.Info("Ownership churn spike, check the operator configuration", "namespace", namespace,
+		"changes", changes, "window", r.Churn.Window.String())
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}
+	r.recordEvent(ns, corev1.EventTypeWarning, "OwnershipChurnSpike",
+		"%d ConfigMap ownership changes within %s; check the operator configuration", changes, r.Churn.Window)
+}
+
+// writer returns the client used for mutations
+func (r *ReplicaSetReconciler) writer() client.Writer {
+	if r.Writer != nil {
+		return r.Writer
+	}
+	return r.Client
+}
+
+// recordEvent emits an Event when a recorder is configured
+func (r *ReplicaSetReconciler) recordEvent(obj runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	if r.Recorder == nil {
+		return
+	}
+	r.Recorder.Eventf(obj, eventtype, reason, messageFmt, args...)
 }
 
 func (r *ReplicaSetReconciler) isOwnerReferencePresent(cm *corev1.ConfigMap, rs *appsv1.ReplicaSet) bool {
-	for _, ownerRef := range cm.OwnerReferences {
-		if ownerRef.Kind == "ReplicaSet" && ownerRef.Name == rs.Name && ownerRef.UID == rs.UID {
+	owner := r.ownerFor(rs, cm.Name)
+	return owner != nil && hasOwnerReference(cm, *owner)
+}
+
+// hasOwnerReference reports whether obj already references owner
+func hasOwnerReference(obj metav1.Object, owner metav1.OwnerReference) bool {
+	for _, ownerRef := range obj.GetOwnerReferences() {
+		if ownerRef.Kind == owner.Kind && ownerRef.Name == owner.Name && ownerRef.UID == owner.UID {
 			return true
 		}
 	}
@@ -218,18 +642,25 @@ func (r *ReplicaSetReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			if !ok {
 				return false
 			}
-			return rs.CreationTimestamp.After(r.StartTime)
+			if !rs.CreationTimestamp.After(r.StartTime) {
+				recordFiltered(dropReasonStartTime)
+				return false
+			}
+			return true
 		},
 		UpdateFunc: func(e event.UpdateEvent) bool {
 			// Don't process UPDATE events - we only care about new ReplicaSets
+			recordFiltered(dropReasonPredicateUpdate)
 			return false
 		},
 		DeleteFunc: func(e event.DeleteEvent) bool {
 			// Don't process DELETE events - Kubernetes GC handles cleanup automatically
+			recordFiltered(dropReasonPredicateDelete)
 			return false
 		},
 		GenericFunc: func(e event.GenericEvent) bool {
 			// Don't process generic events
+			recordFiltered(dropReasonPredicateGeneric)
 			return false
 		},
 	}
@@ -237,5 +668,6 @@ func (r *ReplicaSetReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&appsv1.ReplicaSet{}).
 		WithEventFilter(replicaSetPredicate).
-		Complete(r)
+		WithOptions(r.controllerOptions()).
+		Complete(r.tracked("replicaset", r))
 }
@@ -3,6 +3,7 @@ package controller
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/onsi/ginkgo/v2"
 	"github.com/onsi/gomega"
@@ -17,6 +18,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+	"github.com/matanbaruch/configmap-rs-operator/internal/pause"
+	"github.com/matanbaruch/configmap-rs-operator/internal/schedule"
 )
 
 var _ = ginkgo.Describe("ReplicaSetController", func() {
@@ -134,6 +137,7 @@ var _ = ginkgo.Describe("ReplicaSetController", func() {
 			gomega.Expect(updatedConfigMap.OwnerReferences).To(gomega.HaveLen(1))
 			gomega.Expect(updatedConfigMap.OwnerReferences[0].Name).To(gomega.Equal("test-rs"))
 			gomega.Expect(updatedConfigMap.OwnerReferences[0].Kind).To(gomega.Equal("ReplicaSet"))
+			gomega.Expect(updatedConfigMap.Annotations).To(gomega.HaveKeyWithValue(ManagedOwnersAnnotation, "test-uid"))
 		})
 
 		ginkgo.It("Should not add owner reference when ConfigMap doesn't exist", func() {
@@ -376,6 +380,74 @@ var _ = ginkgo.Describe("ReplicaSetController", func() {
 			gomega.Expect(err).NotTo(gomega.HaveOccurred())
 			gomega.Expect(updatedConfigMap.OwnerReferences).To(gomega.BeEmpty())
 		})
+
+		ginkgo.It("Should honor the namespace dry-run annotation", func() {
+			reconciler.Config.DryRun = true
+			namespace := &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "staged",
+					Annotations: map[string]string{DryRunAnnotation: "false"},
+				},
+			}
+			gomega.Expect(fakeClient.Create(ctx, namespace)).To(gomega.Succeed())
+			gomega.Expect(fakeClient.Create(ctx, testConfigMap("test-config", "staged"))).To(gomega.Succeed())
+			gomega.Expect(fakeClient.Create(ctx, testReplicaSet("test-rs", "staged", "test-config"))).To(gomega.Succeed())
+
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: "test-rs", Namespace: "staged"},
+			})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+			var updatedConfigMap corev1.ConfigMap
+			gomega.Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "test-config", Namespace: "staged"},
+				&updatedConfigMap)).To(gomega.Succeed())
+			gomega.Expect(updatedConfigMap.OwnerReferences).To(gomega.HaveLen(1))
+		})
+
+		ginkgo.It("Should hold writes while the kill switch is engaged", func() {
+			control := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "control", Namespace: "ops"},
+				Data:       map[string]string{pause.PausedKey: "true"},
+			}
+			gomega.Expect(fakeClient.Create(ctx, control)).To(gomega.Succeed())
+			reconciler.Pause = &pause.Switch{
+				Client: fakeClient,
+				Key:    types.NamespacedName{Namespace: "ops", Name: "control"},
+			}
+			gomega.Expect(fakeClient.Create(ctx, testConfigMap("test-config", "default"))).To(gomega.Succeed())
+			gomega.Expect(fakeClient.Create(ctx, testReplicaSet("test-rs", "default", "test-config"))).To(gomega.Succeed())
+
+			result, err := reconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: "test-rs", Namespace: "default"},
+			})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(result.RequeueAfter).To(gomega.Equal(pausedRequeueInterval))
+
+			var updatedConfigMap corev1.ConfigMap
+			gomega.Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "test-config", Namespace: "default"},
+				&updatedConfigMap)).To(gomega.Succeed())
+			gomega.Expect(updatedConfigMap.OwnerReferences).To(gomega.BeEmpty())
+		})
+
+		ginkgo.It("Should defer writes and requeue outside the maintenance window", func() {
+			window, err := schedule.Parse([]string{"0 0 1 1 * 1m"}, time.UTC)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			reconciler.MaintenanceWindow = window
+
+			gomega.Expect(fakeClient.Create(ctx, testConfigMap("test-config", "default"))).To(gomega.Succeed())
+			gomega.Expect(fakeClient.Create(ctx, testReplicaSet("test-rs", "default", "test-config"))).To(gomega.Succeed())
+
+			result, err := reconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: "test-rs", Namespace: "default"},
+			})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(result.RequeueAfter).To(gomega.BeNumerically(">", 0))
+
+			var updatedConfigMap corev1.ConfigMap
+			gomega.Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "test-config", Namespace: "default"},
+				&updatedConfigMap)).To(gomega.Succeed())
+			gomega.Expect(updatedConfigMap.OwnerReferences).To(gomega.BeEmpty())
+		})
 	})
 })
 
@@ -387,3 +459,41 @@ func TestReplicaSetController(t *testing.T) {
 func int32Ptr(i int32) *int32 {
 	return &i
 }
+
+// testReplicaSet builds a ReplicaSet whose single container mounts each named ConfigMap as a volume
+func testReplicaSet(name, namespace string, configMaps ...string) *appsv1.ReplicaSet {
+	rs := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			UID:       types.UID(name + "-uid"),
+		},
+		Spec: appsv1.ReplicaSetSpec{
+			Replicas: int32Ptr(1),
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": name}},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": name}},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "app", Image: "test-image"}},
+				},
+			},
+		},
+	}
+	podSpec := &rs.Spec.Template.Spec
+	for _, cm := range configMaps {
+		podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+			Name: cm,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: cm}},
+			},
+		})
+		podSpec.Containers[0].VolumeMounts = append(podSpec.Containers[0].VolumeMounts,
+			corev1.VolumeMount{Name: cm, MountPath: "/etc/" + cm})
+	}
+	return rs
+}
+
+// testConfigMap builds an empty ConfigMap
+func testConfigMap(name, namespace string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+}
@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// RollbackReconciler revalidates ReplicaSets that scale up from zero again, as `kubectl rollout undo` does with
+// the ReplicaSet of an earlier revision. The ReplicaSet reconciler only handles creations, so without it a
+// reactivated ReplicaSet is never checked: it may predate the operator, and its ConfigMaps may be gone.
+type RollbackReconciler struct {
+	// ReplicaSetReconciler provides the namespace filter, the holds and the ConfigMap processing
+	*ReplicaSetReconciler
+}
+
+// reactivated reports whether the update scaled a ReplicaSet up from zero
+func reactivated(oldObj, newObj client.Object) bool {
+	oldRS, ok := oldObj.(*appsv1.ReplicaSet)
+	if !ok {
+		return false
+	}
+	newRS, ok := newObj.(*appsv1.ReplicaSet)
+	if !ok {
+		return false
+	}
+	return replicas(oldRS) == 0 && replicas(newRS) > 0
+}
+
+// replicas returns the desired replicas of rs, which default to one
+func replicas(rs *appsv1.ReplicaSet) int32 {
+	if rs.Spec.Replicas == nil {
+		return 1
+	}
+	return *rs.Spec.Replicas
+}
+
+func (r *RollbackReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithValues("replicaset", req.NamespacedName)
+	if !r.shouldProcessNamespace(ctx, req.Namespace) {
+		recordFiltered(dropReasonNamespace)
+		return ctrl.Result{}, nil
+	}
+
+	var rs appsv1.ReplicaSet
+	if err := r.Get(ctx, req.NamespacedName, &rs); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if reason, err := r.ignoreReason(ctx, &rs); err != nil {
+		return ctrl.Result{}, err
+	} else if reason != "" {
+		recordFiltered(reason)
+		return ctrl.Result{}, nil
+	}
+	configMapNames, err := r.withConventionConfigMap(ctx, rs.Namespace, replicaSetWorkloadName(&rs),
+		r.extractConfigMapVolumes(&rs))
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	var missing, present []string
+	for _, name := range configMapNames {
+		var cm corev1.ConfigMap
+		err := r.Get(ctx, types.NamespacedName{Namespace: rs.Namespace, Name: name}, &cm)
+		switch {
+		case errors.IsNotFound(err):
+			missing = append(missing, name)
+		case err != nil:
+			return ctrl.Result{}, err
+		default:
+			present = append(present, name)
+		}
+	}
+	if len(missing) > 0 {
+		logger.Info("Reactivated ReplicaSet mounts ConfigMaps that no longer exist", "configmaps", missing)
+		rollbackRevalidationsTotal.WithLabelValues("configmap_missing").Inc()
+		r.recordEvent(&rs, corev1.EventTypeWarning, "RollbackConfigMapMissing",
+			"Reactivated ReplicaSet mounts ConfigMaps that no longer exist, possibly garbage collected: %s",
+			strings.Join(missing, ", "))
+	} else {
+		rollbackRevalidationsTotal.WithLabelValues("ok").Inc()
+	}
+
+	// The ReplicaSet may predate the operator, so its remaining ConfigMaps are owned as if it were new
+	now := time.Now()
+	holdReason := r.holdReason(ctx, rs.Namespace, now, logger)
+	if err := r.processConfigMaps(ctx, replicaSetWorkload(&rs), present, holdReason, logger); err != nil {
+		return ctrl.Result{}, err
+	}
+	return r.heldResult(holdReason, now), nil
+}
+
+// SetupWithManager watches ReplicaSets, reconciling them when they scale up from zero. Rollbacks are rare, so the
+// controller isn't tracked for the liveness check.
+func (r *RollbackReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("rollback").
+		For(&appsv1.ReplicaSet{}).
+		WithEventFilter(predicate.Funcs{
+			CreateFunc:  func(event.CreateEvent) bool { return false },
+			UpdateFunc:  func(e event.UpdateEvent) bool { return reactivated(e.ObjectOld, e.ObjectNew) },
+			DeleteFunc:  func(event.DeleteEvent) bool { return false },
+			GenericFunc: func(event.GenericEvent) bool { return false },
+		}).
+		WithOptions(r.controllerOptions()).
+		Complete(r)
+}
@@ -0,0 +1,49 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+var _ = ginkgo.Describe("Rollback", func() {
+	ginkgo.It("Should only react to ReplicaSets scaling up from zero", func() {
+		scaledDown, scaledUp := testReplicaSet("web", "default"), testReplicaSet("web", "default")
+		scaledDown.Spec.Replicas = int32Ptr(0)
+		gomega.Expect(reactivated(scaledDown, scaledUp)).To(gomega.BeTrue())
+		gomega.Expect(reactivated(scaledUp, scaledDown)).To(gomega.BeFalse())
+		gomega.Expect(reactivated(scaledUp, scaledUp)).To(gomega.BeFalse())
+	})
+
+	ginkgo.It("Should own the ConfigMaps of a reactivated ReplicaSet and warn about missing ones", func() {
+		ctx := context.Background()
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+			testConfigMap("app-config", "default"), testReplicaSet("web", "default", "app-config", "gone-config"),
+		).Build()
+		recorder := record.NewFakeRecorder(10)
+		r := &RollbackReconciler{ReplicaSetReconciler: &ReplicaSetReconciler{
+			Client: c, Scheme: scheme.Scheme, Recorder: recorder, Config: &config.OperatorConfig{},
+			// The ReplicaSet predates the operator
+			StartTime: time.Now(),
+		}}
+
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "web"}})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(<-recorder.Events).To(gomega.And(
+			gomega.ContainSubstring("RollbackConfigMapMissing"), gomega.ContainSubstring("gone-config")))
+
+		var cm corev1.ConfigMap
+		gomega.Expect(c.Get(ctx, types.NamespacedName{Namespace: "default", Name: "app-config"}, &cm)).To(gomega.Succeed())
+		gomega.Expect(cm.OwnerReferences).To(gomega.ConsistOf(gomega.HaveField("Name", "web")))
+	})
+})
@@ -0,0 +1,204 @@
+package controller
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// SecretReconciler adds ReplicaSets as owners of the Secrets they reference, so the credentials and certificates
+// generated for each rollout are garbage collected with it, like ConfigMaps
+type SecretReconciler struct {
+	// ReplicaSetReconciler provides the namespace filter, write holds, write client and events
+	*ReplicaSetReconciler
+
+	// SecretReader reads Secrets directly from the API server, so Secrets are never cached
+	SecretReader client.Reader
+}
+
+// +kubebuilder:rbac:groups=apps,resources=replicasets,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;update;patch
+
+func (r *SecretReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithValues("replicaset", req.NamespacedName)
+	// The ReplicaSet controller records why ReplicaSets are filtered out
+	if !r.shouldProcessNamespace(ctx, req.Namespace) {
+		return ctrl.Result{}, nil
+	}
+
+	var rs appsv1.ReplicaSet
+	if err := r.Get(ctx, req.NamespacedName, &rs); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if rs.CreationTimestamp.Time.Before(r.StartTime) {
+		return ctrl.Result{}, nil
+	}
+	if reason, err := r.ignoreReason(ctx, &rs); err != nil || reason != "" {
+		return ctrl.Result{}, err
+	}
+
+	now := time.Now()
+	holdReason := r.holdReason(ctx, rs.Namespace, now, logger)
+	for _, name := range r.podSecrets(&rs.Spec.Template.Spec) {
+		if err := r.processSecret(ctx, &rs, name, holdReason, logger); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	return r.heldResult(holdReason, now), nil
+}
+
+// podSecretReferences returns the Secrets spec references in the ways types lists, every way if empty: those the
+// volumes mounted by its containers, or with unmounted every volume, declare as a volume or projection, then the
+// envFrom sources and env variables of each container
+func podSecretReferences(spec *corev1.PodSpec, types []string, unmounted bool) []string {
+	if len(types) == 0 {
+		types = referenceTypes()
+	}
+	var names []string
+	for i := range spec.Volumes {
+		volume := &spec.Volumes[i]
+		if !unmounted && !isMountedVolume(spec, volume.Name) {
+			continue
+		}
+		if volume.Secret != nil && slices.Contains(types, ReferenceVolume) {
+			names = append(names, volume.Secret.SecretName)
+		}
+		if volume.Projected == nil || !slices.Contains(types, ReferenceProjected) {
+			continue
+		}
+		for _, source := range volume.Projected.Sources {
+			if source.Secret != nil {
+				names = append(names, source.Secret.Name)
+			}
+		}
+	}
+	return distinct(append(names, podEnvSecrets(spec, types)...))
+}
+
+// podEnvSecrets returns the Secrets the envFrom sources and env variables of each container of spec reference, in
+// the ways types lists
+func podEnvSecrets(spec *corev1.PodSpec, types []string) []string {
+	var names []string
+	containers := append(append([]corev1.Container(nil), spec.Containers...), spec.InitContainers...)
+	envFrom, env := slices.Contains(types, ReferenceEnvFrom), slices.Contains(types, ReferenceEnv)
+	for i := range containers {
+		container := &containers[i]
+		for _, source := range container.EnvFrom {
+			if envFrom && source.SecretRef != nil {
+				names = append(names, source.SecretRef.Name)
+			}
+		}
+		for _, variable := range container.Env {
+			if ref := variable.ValueFrom; env && ref != nil && ref.SecretKeyRef != nil {
+				names = append(names, ref.SecretKeyRef.Name)
+			}
+		}
+	}
+	return names
+}
+
+// podSecrets returns the Secrets spec references in the ways --reference-types lists, including the volumes no
+// container mounts with --unmounted-volumes, followed by its image pull Secrets with --image-pull-secrets
+func (r *SecretReconciler) podSecrets(spec *corev1.PodSpec) []string {
+	names := podSecretReferences(spec, r.Config.ReferenceTypes, r.Config.UnmountedVolumes)
+	if !r.Config.ImagePullSecrets {
+		return names
+	}
+	for _, ref := range spec.ImagePullSecrets {
+		names = append(names, ref.Name)
+	}
+	return distinct(names)
+}
+
+// systemSecretTypes are the Secret types Kubernetes and Helm manage themselves, which are never owned
+var systemSecretTypes = []corev1.SecretType{
+	corev1.SecretTypeServiceAccountToken,
+	corev1.SecretTypeBootstrapToken,
+	"helm.sh/release.v1",
+}
+
+// processSecret adds the owner --owner-target picks for rs to the Secret name. As for ConfigMaps, the reference is
+// proposed with --require-approval and patched with --patch-only.
+func (r *SecretReconciler) processSecret(
+	ctx context.Context,
+	rs *appsv1.ReplicaSet,
+	name, holdReason string,
+	logger logr.Logger,
+) error {
+	var secret corev1.Secret
+	if err := r.SecretReader.Get(ctx, types.NamespacedName{Namespace: rs.Namespace, Name: name}, &secret); err != nil {
+		if errors.IsNotFound(err) {
+			logger.V(1).Info("Secret not found", "secret", name)
+			return nil
+		}
+		logger.Error(err, "Failed to get Secret", "secret", name)
+		return err
+	}
+	if slices.Contains(systemSecretTypes, secret.Type) {
+		logger.V(1).Info("Skipping system Secret", "secret", name, "type", secret.Type)
+		return nil
+	}
+	if managedByCertManager(&secret) {
+		logger.V(1).Info("Skipping Secret issued by cert-manager", "secret", name)
+		return nil
+	}
+	owner := r.targetOwner(rs)
+	for _, ref := range secret.OwnerReferences {
+		if ref.UID == owner.UID {
+			return nil
+		}
+	}
+	if r.tooManyOwners(&secret) {
+		logger.Info("Skipping Secret with too many owners", "secret", name, "owners", len(secret.OwnerReferences))
+		return nil
+	}
+	if holdReason != "" {
+		logger.Info(holdReason+": Would add OwnerReference", "secret", name, "replicaset", rs.Name)
+		return nil
+	}
+	if r.Config.RequireApproval {
+		return r.proposeOwner(ctx, &secret, owner, logger)
+	}
+
+	if err := r.addOwner(ctx, &secret, owner); err != nil {
+		secretOwnerReferencesTotal.WithLabelValues("failed").Inc()
+		logger.Error(err, "Failed to update Secret with owner reference", "secret", name)
+		r.recordEvent(&secret, corev1.EventTypeWarning, "OwnerReferenceFailed",
+			"Failed to add owner reference to %s %s: %v", owner.Kind, owner.Name, err)
+		return err
+	}
+
+	secretOwnerReferencesTotal.WithLabelValues("added").Inc()
+	logger.Info("Added OwnerReference to Secret", "secret", name, "replicaset", rs.Name, "owner", owner.Kind)
+	r.recordEvent(&secret, corev1.EventTypeNormal, "OwnerReferenceAdded",
+		"Added owner reference to %s %s", owner.Kind, owner.Name)
+	r.observeChurn(rs.Namespace, ownershipAdded, logger)
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager. Like for ConfigMaps, only ReplicaSets created after
+// the operator started are processed.
+func (r *SecretReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("secret").
+		For(&appsv1.ReplicaSet{}).
+		WithEventFilter(predicate.Funcs{
+			CreateFunc:  func(e event.CreateEvent) bool { return e.Object.GetCreationTimestamp().After(r.StartTime) },
+			UpdateFunc:  func(event.UpdateEvent) bool { return false },
+			DeleteFunc:  func(event.DeleteEvent) bool { return false },
+			GenericFunc: func(event.GenericEvent) bool { return false },
+		}).
+		WithOptions(r.controllerOptions()).
+		Complete(r.tracked("secret", r))
+}
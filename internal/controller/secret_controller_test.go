@@ -0,0 +1,196 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+	"github.com/matanbaruch/configmap-rs-operator/internal/schedule"
+)
+
+var _ = ginkgo.Describe("SecretReconciler", func() {
+	var ctx context.Context
+
+	ginkgo.BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	// secretReplicaSet mounts the Secret tls as a volume, projects credentials and declares the unmounted
+	// volume sidecar
+	secretReplicaSet := func() *appsv1.ReplicaSet {
+		rs := testReplicaSet("web-abc", "default")
+		rs.CreationTimestamp = metav1.Now()
+		spec := &rs.Spec.Template.Spec
+		spec.Volumes = []corev1.Volume{
+			{Name: "tls", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "tls"}}},
+			{Name: "bundle", VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{
+				Sources: []corev1.VolumeProjection{{Secret: &corev1.SecretProjection{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "credentials"},
+				}}},
+			}}},
+			{Name: "sidecar", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "sidecar"}}},
+		}
+		spec.Containers[0].VolumeMounts = []corev1.VolumeMount{
+			{Name: "tls", MountPath: "/etc/tls"}, {Name: "bundle", MountPath: "/etc/bundle"},
+		}
+		return rs
+	}
+	testSecret := func(name string, annotations map[string]string) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Annotations: annotations}}
+	}
+
+	reconcile := func(cfg *config.OperatorConfig, objs ...client.Object) client.Client {
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objs...).Build()
+		r := &SecretReconciler{
+			ReplicaSetReconciler: &ReplicaSetReconciler{Client: c, Scheme: scheme.Scheme, Config: cfg},
+			SecretReader:         c,
+		}
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "web-abc"}})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		return c
+	}
+	owners := func(c client.Client, name string) []metav1.OwnerReference {
+		var secret corev1.Secret
+		gomega.Expect(c.Get(ctx, types.NamespacedName{Namespace: "default", Name: name}, &secret)).To(gomega.Succeed())
+		return secret.OwnerReferences
+	}
+
+	ginkgo.It("Should add the ReplicaSet as owner of the Secrets it mounts", func() {
+		c := reconcile(&config.OperatorConfig{}, secretReplicaSet(),
+			testSecret("tls", nil), testSecret("credentials", nil), testSecret("sidecar", nil))
+		gomega.Expect(owners(c, "tls")).To(gomega.ConsistOf(gomega.HaveField("Name", "web-abc")))
+		gomega.Expect(owners(c, "credentials")).To(gomega.ConsistOf(gomega.HaveField("Name", "web-abc")))
+		gomega.Expect(owners(c, "sidecar")).To(gomega.BeEmpty())
+
+		c = reconcile(&config.OperatorConfig{UnmountedVolumes: true}, secretReplicaSet(), testSecret("sidecar", nil))
+		gomega.Expect(owners(c, "sidecar")).To(gomega.ConsistOf(gomega.HaveField("Name", "web-abc")))
+	})
+
+	ginkgo.It("Should add the ReplicaSet as owner of the Secrets its containers read into the environment", func() {
+		rs := secretReplicaSet()
+		spec := &rs.Spec.Template.Spec
+		spec.Containers[0].EnvFrom = []corev1.EnvFromSource{{SecretRef: &corev1.SecretEnvSource{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "app-env"},
+		}}}
+		spec.InitContainers = []corev1.Container{{Name: "migrate", Env: []corev1.EnvVar{{
+			Name: "PASSWORD", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "db"}, Key: "password",
+			}},
+		}}}}
+
+		gomega.Expect(podSecretReferences(spec, nil, false)).To(gomega.Equal([]string{"tls", "credentials", "app-env", "db"}))
+		gomega.Expect(podSecretReferences(spec, []string{ReferenceEnv}, false)).To(gomega.Equal([]string{"db"}))
+		c := reconcile(&config.OperatorConfig{}, rs, testSecret("app-env", nil), testSecret("db", nil))
+		gomega.Expect(owners(c, "app-env")).To(gomega.ConsistOf(gomega.HaveField("Name", "web-abc")))
+		gomega.Expect(owners(c, "db")).To(gomega.ConsistOf(gomega.HaveField("Name", "web-abc")))
+	})
+
+	ginkgo.It("Should add the ReplicaSet as owner of its image pull Secrets only with --image-pull-secrets", func() {
+		rs := secretReplicaSet()
+		rs.Spec.Template.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "registry"}}
+
+		c := reconcile(&config.OperatorConfig{}, rs, testSecret("registry", nil))
+		gomega.Expect(owners(c, "registry")).To(gomega.BeEmpty())
+		c = reconcile(&config.OperatorConfig{ImagePullSecrets: true}, rs, testSecret("registry", nil))
+		gomega.Expect(owners(c, "registry")).To(gomega.ConsistOf(gomega.HaveField("Name", "web-abc")))
+	})
+
+	ginkgo.It("Should follow the reference types", func() {
+		gomega.Expect(podSecretReferences(&secretReplicaSet().Spec.Template.Spec, []string{ReferenceProjected}, false)).To(
+			gomega.Equal([]string{"credentials"}))
+	})
+
+	ginkgo.It("Should leave Secrets issued by cert-manager alone", func() {
+		c := reconcile(&config.OperatorConfig{}, secretReplicaSet(),
+			testSecret("tls", map[string]string{"cert-manager.io/certificate-name": "web"}))
+		gomega.Expect(owners(c, "tls")).To(gomega.BeEmpty())
+	})
+
+	ginkgo.It("Should leave system Secrets alone", func() {
+		secret := testSecret("tls", nil)
+		secret.Type = corev1.SecretTypeServiceAccountToken
+		c := reconcile(&config.OperatorConfig{}, secretReplicaSet(), secret)
+		gomega.Expect(owners(c, "tls")).To(gomega.BeEmpty())
+	})
+
+	ginkgo.It("Should propose the owner reference with --require-approval and add it once approved", func() {
+		cfg := &config.OperatorConfig{RequireApproval: true}
+		c := reconcile(cfg, secretReplicaSet(), testSecret("tls", nil))
+		gomega.Expect(owners(c, "tls")).To(gomega.BeEmpty())
+
+		key := types.NamespacedName{Namespace: "default", Name: "tls"}
+		var secret corev1.Secret
+		gomega.Expect(c.Get(ctx, key, &secret)).To(gomega.Succeed())
+		gomega.Expect(pendingOwners(&secret)).To(gomega.ConsistOf(gomega.HaveField("UID", types.UID("web-abc-uid"))))
+		secret.Annotations[ApprovedAnnotation] = "true"
+		gomega.Expect(c.Update(ctx, &secret)).To(gomega.Succeed())
+
+		approval := &secretApproval{&ApprovalReconciler{
+			ReplicaSetReconciler: &ReplicaSetReconciler{Client: c, Scheme: scheme.Scheme, Config: cfg},
+			SecretReader:         c,
+		}}
+		_, err := approval.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(owners(c, "tls")).To(gomega.ConsistOf(gomega.HaveField("Name", "web-abc")))
+	})
+
+	ginkgo.It("Should patch Secrets with --patch-only and follow --owner-target", func() {
+		isController := true
+		rs := secretReplicaSet()
+		rs.OwnerReferences = []metav1.OwnerReference{
+			{APIVersion: "apps/v1", Kind: "Deployment", Name: "web", UID: "web-uid", Controller: &isController},
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(rs, testSecret("tls", nil)).
+			WithInterceptorFuncs(interceptor.Funcs{
+				Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+					if _, ok := obj.(*corev1.Secret); ok {
+						return fmt.Errorf("secrets is forbidden: cannot update")
+					}
+					return c.Update(ctx, obj, opts...)
+				},
+			}).Build()
+		r := &SecretReconciler{
+			ReplicaSetReconciler: &ReplicaSetReconciler{Client: c, Scheme: scheme.Scheme,
+				Config: &config.OperatorConfig{PatchOnly: true, OwnerTarget: OwnerDeployment}},
+			SecretReader: c,
+		}
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "web-abc"}})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(owners(c, "tls")).To(gomega.ConsistOf(gomega.And(
+			gomega.HaveField("Kind", "Deployment"), gomega.HaveField("Name", "web"))))
+	})
+
+	ginkgo.It("Should only log in dry-run mode", func() {
+		c := reconcile(&config.OperatorConfig{DryRun: true}, secretReplicaSet(), testSecret("tls", nil))
+		gomega.Expect(owners(c, "tls")).To(gomega.BeEmpty())
+	})
+	ginkgo.It("Should requeue for the next maintenance window", func() {
+		window, err := schedule.Parse([]string{"0 0 1 1 * 1m"}, time.UTC)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(secretReplicaSet(), testSecret("tls", nil)).Build()
+		r := &SecretReconciler{
+			ReplicaSetReconciler: &ReplicaSetReconciler{
+				Client: c, Scheme: scheme.Scheme, Config: &config.OperatorConfig{}, MaintenanceWindow: window,
+			},
+			SecretReader: c,
+		}
+		key := types.NamespacedName{Namespace: "default", Name: "web-abc"}
+		result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(result.RequeueAfter).To(gomega.BeNumerically(">", 0))
+		gomega.Expect(owners(c, "tls")).To(gomega.BeEmpty())
+	})
+})
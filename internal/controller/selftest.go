@@ -0,0 +1,129 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+// selfTestNamespace is the namespace of the in-memory fixtures
+const selfTestNamespace = "configmap-rs-operator-selftest"
+
+// SelfTestCheck is the outcome of a single self-test step
+type SelfTestCheck struct {
+	Name    string `json:"name"`
+	Passed  bool   `json:"passed"`
+	Message string `json:"message,omitempty"`
+}
+
+// SelfTestResult is the outcome of a self-test run
+type SelfTestResult struct {
+	Passed   bool            `json:"passed"`
+	Duration string          `json:"duration"`
+	Checks   []SelfTestCheck `json:"checks"`
+}
+
+// SelfTest runs the real extraction and reconcile logic against in-memory fixtures, so the decision
+// engine can be verified continuously without touching the cluster. Namespace filters and write
+// holds are ignored, since they would legitimately skip the fixtures.
+func SelfTest(ctx context.Context, cfg *config.OperatorConfig) SelfTestResult {
+	start := time.Now()
+	result := SelfTestResult{Passed: true}
+	check := func(name string, err error) {
+		c := SelfTestCheck{Name: name, Passed: err == nil}
+		if err != nil {
+			c.Message = err.Error()
+			result.Passed = false
+		}
+		result.Checks = append(result.Checks, c)
+	}
+
+	rs := selfTestReplicaSet()
+	testConfig := *cfg
+	testConfig.NamespaceRegex = nil
+	testConfig.NamespaceExclude = nil
+	testConfig.DryRun = false
+
+	s := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(s); err != nil {
+		check("scheme", err)
+		return result
+	}
+	c := fake.NewClientBuilder().WithScheme(s).WithObjects(
+		rs,
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "selftest-config", Namespace: selfTestNamespace}},
+	).Build()
+	r := &ReplicaSetReconciler{Client: c, Scheme: s, Config: &testConfig}
+
+	check("extraction", func() error {
+		names := r.extractConfigMapVolumes(rs)
+		if !slices.Equal(names, []string{"selftest-config"}) {
+			return fmt.Errorf("expected [selftest-config], extracted %v", names)
+		}
+		return nil
+	}())
+
+	check("reconcile", func() error {
+		ctx := log.IntoContext(ctx, logr.Discard())
+		key := types.NamespacedName{Name: rs.Name, Namespace: rs.Namespace}
+		if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: key}); err != nil {
+			return err
+		}
+		var cm corev1.ConfigMap
+		if err := c.Get(ctx, types.NamespacedName{Name: "selftest-config", Namespace: selfTestNamespace}, &cm); err != nil {
+			return err
+		}
+		if !r.isOwnerReferencePresent(&cm, rs) {
+			return fmt.Errorf("owner reference was not added to the fixture ConfigMap")
+		}
+		return nil
+	}())
+
+	result.Duration = time.Since(start).String()
+	return result
+}
+
+// selfTestReplicaSet returns a fixture ReplicaSet mounting one ConfigMap from an init container
+// and one container, plus an unmounted ConfigMap volume that must be ignored
+func selfTestReplicaSet() *appsv1.ReplicaSet {
+	mount := []corev1.VolumeMount{{Name: "config", MountPath: "/etc/config"}}
+	return &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "selftest", Namespace: selfTestNamespace, UID: "selftest-uid"},
+		Spec: appsv1.ReplicaSetSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					InitContainers: []corev1.Container{{Name: "init", VolumeMounts: mount}},
+					Containers:     []corev1.Container{{Name: "app", VolumeMounts: mount}},
+					Volumes: []corev1.Volume{
+						{
+							Name: "config",
+							VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+								LocalObjectReference: corev1.LocalObjectReference{Name: "selftest-config"},
+							}},
+						},
+						{
+							Name: "unmounted",
+							VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+								LocalObjectReference: corev1.LocalObjectReference{Name: "selftest-unmounted"},
+							}},
+						},
+					},
+				},
+			},
+		},
+	}
+}
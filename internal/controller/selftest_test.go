@@ -0,0 +1,26 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+var _ = ginkgo.Describe("SelfTest", func() {
+	ginkgo.It("Should pass with the default configuration", func() {
+		result := SelfTest(context.Background(), &config.OperatorConfig{})
+		gomega.Expect(result.Passed).To(gomega.BeTrue(), "%+v", result.Checks)
+		gomega.Expect(result.Checks).To(gomega.HaveLen(2))
+	})
+
+	ginkgo.It("Should ignore namespace filters and dry-run", func() {
+		result := SelfTest(context.Background(), &config.OperatorConfig{
+			NamespaceRegex: []string{"^production$"},
+			DryRun:         true,
+		})
+		gomega.Expect(result.Passed).To(gomega.BeTrue(), "%+v", result.Checks)
+	})
+})
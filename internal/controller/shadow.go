@@ -0,0 +1,129 @@
+package controller
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Kinds of divergence between the shadow's decisions and the active operator's writes
+const (
+	// divergenceMissingOwner: the shadow would own the ConfigMap, but the active operator did not
+	divergenceMissingOwner = "missing_owner"
+	// divergenceUnexpectedOwner: the active operator owns the ConfigMap, but the shadow would not
+	divergenceUnexpectedOwner = "unexpected_owner"
+)
+
+// defaultShadowReportSize bounds the number of divergences kept in the report
+const defaultShadowReportSize = 500
+
+// Divergence is a decision of the shadow operator that differs from what the active operator did
+type Divergence struct {
+	Time       time.Time `json:"time"`
+	Type       string    `json:"type"`
+	Namespace  string    `json:"namespace"`
+	ReplicaSet string    `json:"replicaSet"`
+	ConfigMap  string    `json:"configMap"`
+}
+
+// ShadowReport compares the decisions of a read-only shadow operator with the writes of the active one
+// and keeps the most recent divergences
+type ShadowReport struct {
+	// Delay gives the active operator time to act before a workload is compared
+	Delay time.Duration
+
+	// Size bounds the number of divergences kept (default: 500)
+	Size int
+
+	mu          sync.Mutex
+	comparisons int
+	divergences []Divergence
+}
+
+// ShadowSummary is the diff report served to users
+type ShadowSummary struct {
+	Comparisons int          `json:"comparisons"`
+	Divergences []Divergence `json:"divergences"`
+}
+
+// NewShadowReport returns a report comparing workloads delay after their creation
+func NewShadowReport(delay time.Duration) *ShadowReport {
+	return &ShadowReport{Delay: delay}
+}
+
+// Summary returns the number of compared workloads and the most recent divergences
+func (s *ShadowReport) Summary() ShadowSummary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return ShadowSummary{Comparisons: s.comparisons, Divergences: slices.Clone(s.divergences)}
+}
+
+func (s *ShadowReport) record(divergences []Divergence) {
+	shadowComparisonsTotal.Inc()
+	for _, d := range divergences {
+		shadowDivergencesTotal.WithLabelValues(d.Type).Inc()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.comparisons++
+	s.divergences = append(s.divergences, divergences...)
+	size := s.Size
+	if size <= 0 {
+		size = defaultShadowReportSize
+	}
+	if excess := len(s.divergences) - size; excess > 0 {
+		s.divergences = slices.Delete(s.divergences, 0, excess)
+	}
+}
+
+// compareShadow checks the active operator's writes for rs against the ConfigMaps this version
+// would own and records every divergence. It never writes.
+func (r *ReplicaSetReconciler) compareShadow(
+	ctx context.Context,
+	rs *appsv1.ReplicaSet,
+	configMapNames []string,
+	logger logr.Logger,
+) error {
+	now := time.Now()
+	diverge := func(kind, configMap string) Divergence {
+		logger.Info("SHADOW: Decision differs from the active operator", "divergence", kind, "configmap", configMap)
+		return Divergence{Time: now, Type: kind, Namespace: rs.Namespace, ReplicaSet: rs.Name, ConfigMap: configMap}
+	}
+
+	var divergences []Divergence
+	for _, name := range configMapNames {
+		var cm corev1.ConfigMap
+		if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: rs.Namespace}, &cm); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		if !r.isOwnerReferencePresent(&cm, rs) {
+			divergences = append(divergences, diverge(divergenceMissingOwner, name))
+		}
+	}
+
+	var list corev1.ConfigMapList
+	if err := r.List(ctx, &list, client.InNamespace(rs.Namespace)); err != nil {
+		return err
+	}
+	for i := range list.Items {
+		cm := &list.Items[i]
+		if slices.Contains(managedOwnerUIDs(cm), rs.UID) && !slices.Contains(configMapNames, cm.Name) {
+			divergences = append(divergences, diverge(divergenceUnexpectedOwner, cm.Name))
+		}
+	}
+
+	r.Shadow.record(divergences)
+	return nil
+}
@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+var _ = ginkgo.Describe("Shadow mode", func() {
+	ctx := context.Background()
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Name: "test-rs", Namespace: "default"}}
+
+	ginkgo.It("Should report divergences without writing", func() {
+		rs := testReplicaSet("test-rs", "default", "wanted")
+		wanted := testConfigMap("wanted", "default")
+		stale := testConfigMap("stale", "default")
+		stale.OwnerReferences = []metav1.OwnerReference{{
+			APIVersion: "apps/v1", Kind: "ReplicaSet", Name: rs.Name, UID: rs.UID,
+		}}
+		addManagedOwner(stale, rs.UID)
+
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(rs, wanted, stale).Build()
+		report := NewShadowReport(0)
+		reconciler := &ReplicaSetReconciler{
+			Client: c, Scheme: scheme.Scheme, Config: &config.OperatorConfig{}, Shadow: report,
+		}
+
+		_, err := reconciler.Reconcile(ctx, request)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+		summary := report.Summary()
+		gomega.Expect(summary.Comparisons).To(gomega.Equal(1))
+		gomega.Expect(summary.Divergences).To(gomega.ConsistOf(
+			gomega.And(gomega.HaveField("Type", divergenceMissingOwner), gomega.HaveField("ConfigMap", "wanted")),
+			gomega.And(gomega.HaveField("Type", divergenceUnexpectedOwner), gomega.HaveField("ConfigMap", "stale")),
+		))
+
+		var cm corev1.ConfigMap
+		gomega.Expect(c.Get(ctx, types.NamespacedName{Name: "wanted", Namespace: "default"}, &cm)).To(gomega.Succeed())
+		gomega.Expect(cm.OwnerReferences).To(gomega.BeEmpty())
+	})
+
+	ginkgo.It("Should wait for the active operator before comparing", func() {
+		rs := testReplicaSet("test-rs", "default", "wanted")
+		rs.CreationTimestamp = metav1.Now()
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(rs).Build()
+		report := NewShadowReport(time.Minute)
+		reconciler := &ReplicaSetReconciler{
+			Client: c, Scheme: scheme.Scheme, Config: &config.OperatorConfig{}, Shadow: report,
+		}
+
+		result, err := reconciler.Reconcile(ctx, request)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(result.RequeueAfter).To(gomega.BeNumerically(">", 0))
+		gomega.Expect(report.Summary().Comparisons).To(gomega.Equal(0))
+	})
+})
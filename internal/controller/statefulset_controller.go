@@ -0,0 +1,44 @@
+package controller
+
+import (
+	"context"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+// StatefulSetKind is the workload kind of StatefulSets. Unlike ReplicaSets their pod template changes in place, so
+// a StatefulSet is processed again whenever its spec changes.
+var StatefulSetKind = &WorkloadKind{
+	Kind:         "StatefulSet",
+	GroupVersion: appsv1.SchemeGroupVersion,
+	New:          func() client.Object { return &appsv1.StatefulSet{} },
+	NewList:      func() client.ObjectList { return &appsv1.StatefulSetList{} },
+	PodSpec: func(obj client.Object) (*corev1.PodSpec, error) {
+		return &obj.(*appsv1.StatefulSet).Spec.Template.Spec, nil
+	},
+	Mutable: true,
+	Enabled: func(cfg *config.OperatorConfig) bool { return cfg.StatefulSets },
+}
+
+// StatefulSetReconciler adds StatefulSets as owners of the ConfigMaps their pod template mounts, using the same
+// extraction as for ReplicaSets
+type StatefulSetReconciler struct {
+	// ReplicaSetReconciler provides the namespace filter, write holds, write client and events
+	*ReplicaSetReconciler
+}
+
+// +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch
+
+func (r *StatefulSetReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	return r.workload(StatefulSetKind).Reconcile(ctx, req)
+}
+
+// SetupWithManager sets up the controller with the Manager
+func (r *StatefulSetReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return r.workload(StatefulSetKind).SetupWithManager(mgr)
+}
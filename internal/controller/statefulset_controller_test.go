@@ -0,0 +1,104 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+	"github.com/matanbaruch/configmap-rs-operator/internal/schedule"
+)
+
+var _ = ginkgo.Describe("StatefulSetReconciler", func() {
+	ginkgo.It("Should add the StatefulSet as owner of the ConfigMaps it mounts", func() {
+		ctx := context.Background()
+		sts := &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "postgres", Namespace: "default", UID: "postgres-uid"},
+			Spec:       appsv1.StatefulSetSpec{Template: testReplicaSet("unused", "default", "cluster-config").Spec.Template},
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).
+			WithObjects(sts, testConfigMap("cluster-config", "default")).Build()
+		r := &StatefulSetReconciler{
+			ReplicaSetReconciler: &ReplicaSetReconciler{Client: c, Scheme: scheme.Scheme, Config: &config.OperatorConfig{}},
+		}
+
+		key := types.NamespacedName{Namespace: "default", Name: "postgres"}
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+		var cm corev1.ConfigMap
+		cmKey := types.NamespacedName{Namespace: "default", Name: "cluster-config"}
+		gomega.Expect(c.Get(ctx, cmKey, &cm)).To(gomega.Succeed())
+		gomega.Expect(cm.OwnerReferences).To(gomega.ConsistOf(gomega.And(
+			gomega.HaveField("APIVersion", "apps/v1"),
+			gomega.HaveField("Kind", "StatefulSet"),
+			gomega.HaveField("Name", "postgres"),
+		)))
+		gomega.Expect(managedOwnerUIDs(&cm)).To(gomega.ConsistOf(sts.UID))
+	})
+	ginkgo.It("Should decide like for ReplicaSets: owner rules, decisions and workload annotations", func() {
+		ctx, collector := withDecisions(context.Background())
+		sts := &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "postgres", Namespace: "default", UID: "postgres-uid"},
+			Spec: appsv1.StatefulSetSpec{
+				Template: testReplicaSet("unused", "default", "cluster-config", "shared-config").Spec.Template,
+			},
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+			sts, testConfigMap("cluster-config", "default"), testConfigMap("shared-config", "default"),
+		).Build()
+		rules, err := ParseOwnerRules([]string{"^shared-=skip"})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		r := &StatefulSetReconciler{ReplicaSetReconciler: &ReplicaSetReconciler{
+			Client: c, Scheme: scheme.Scheme, OwnerRules: rules,
+			Config: &config.OperatorConfig{AnnotateWorkloads: true},
+		}}
+
+		_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(sts)})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		var cm corev1.ConfigMap
+		gomega.Expect(c.Get(ctx, types.NamespacedName{Namespace: "default", Name: "shared-config"}, &cm)).To(gomega.Succeed())
+		gomega.Expect(cm.OwnerReferences).To(gomega.BeEmpty())
+		gomega.Expect(collector.list()).To(gomega.ConsistOf(
+			Decision{Namespace: "default", ReplicaSet: "postgres", Kind: "StatefulSet", ConfigMap: "cluster-config",
+				Action: decisionAdded},
+			Decision{Namespace: "default", ReplicaSet: "postgres", Kind: "StatefulSet", ConfigMap: "shared-config",
+				Action: decisionSkipped, Reason: reasonOwnerRule},
+		))
+		gomega.Expect(c.Get(ctx, client.ObjectKeyFromObject(sts), sts)).To(gomega.Succeed())
+		gomega.Expect(sts.Annotations).To(gomega.HaveKeyWithValue(BoundConfigMapsAnnotation, "cluster-config"))
+	})
+
+	ginkgo.It("Should requeue for the next maintenance window", func() {
+		ctx := context.Background()
+		sts := &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "postgres", Namespace: "default", UID: "postgres-uid"},
+			Spec:       appsv1.StatefulSetSpec{Template: testReplicaSet("unused", "default", "cluster-config").Spec.Template},
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).
+			WithObjects(sts, testConfigMap("cluster-config", "default")).Build()
+		window, err := schedule.Parse([]string{"0 0 1 1 * 1m"}, time.UTC)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		r := &StatefulSetReconciler{ReplicaSetReconciler: &ReplicaSetReconciler{
+			Client: c, Scheme: scheme.Scheme, Config: &config.OperatorConfig{}, MaintenanceWindow: window,
+		}}
+
+		result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(sts)})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(result.RequeueAfter).To(gomega.BeNumerically(">", 0))
+		var cm corev1.ConfigMap
+		cmKey := types.NamespacedName{Namespace: "default", Name: "cluster-config"}
+		gomega.Expect(c.Get(ctx, cmKey, &cm)).To(gomega.Succeed())
+		gomega.Expect(cm.OwnerReferences).To(gomega.BeEmpty())
+	})
+})
@@ -0,0 +1,134 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	appsv1 "k8s.io/api/apps/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Detail levels of the ConfigMap usage metrics
+const (
+	UsageLevelOff       = "off"
+	UsageLevelConfigMap = "configmap"
+	UsageLevelWorkload  = "workload"
+)
+
+// usageScrapeTimeout bounds the listing done on every scrape
+const usageScrapeTimeout = 10 * time.Second
+
+var (
+	configMapWorkloadsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "", "configmap_workloads"),
+		"Number of workloads with at least one desired replica that mount the ConfigMap.",
+		[]string{"namespace", "configmap"}, nil,
+	)
+	configMapMountedByDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "", "configmap_mounted_by"),
+		"Workload with at least one desired replica that mounts the ConfigMap (value is always 1).",
+		[]string{"namespace", "configmap", "kind", "workload"}, nil,
+	)
+	usageTruncatedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "", "usage_series_truncated"),
+		"Whether the ConfigMap usage series were truncated by the series limit (1) or not (0).",
+		nil, nil,
+	)
+)
+
+// UsageCollector exports which workloads mount which ConfigMaps, in the style of kube-state-metrics,
+// so questions like "is anything still using this ConfigMap?" can be answered from Prometheus.
+// It reads from the informer cache on every scrape.
+type UsageCollector struct {
+	// Reader lists workloads, normally the manager's cached client
+	Reader client.Reader
+
+	// Level is UsageLevelConfigMap for one series per ConfigMap, or UsageLevelWorkload to
+	// add one series per ConfigMap and workload
+	Level string
+
+	// MaxSeries caps the number of usage series per scrape; 0 means unlimited
+	MaxSeries int
+
+	// NamespaceRegex and NamespaceExclude limit the series to the namespaces selected by the operator; empty
+	// selects all
+	NamespaceRegex   []string
+	NamespaceExclude []string
+}
+
+// ValidateUsageLevel returns an error for unknown usage metric levels
+func ValidateUsageLevel(level string) error {
+	switch level {
+	case UsageLevelOff, UsageLevelConfigMap, UsageLevelWorkload:
+		return nil
+	default:
+		return fmt.Errorf("invalid usage metrics level %q: expected %s, %s or %s",
+			level, UsageLevelOff, UsageLevelConfigMap, UsageLevelWorkload)
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *UsageCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- configMapWorkloadsDesc
+	ch <- usageTruncatedDesc
+	if c.Level == UsageLevelWorkload {
+		ch <- configMapMountedByDesc
+	}
+}
+
+// Collect implements prometheus.Collector
+func (c *UsageCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), usageScrapeTimeout)
+	defer cancel()
+
+	var list appsv1.ReplicaSetList
+	if err := c.Reader.List(ctx, &list); err != nil {
+		ch <- prometheus.NewInvalidMetric(configMapWorkloadsDesc, err)
+		return
+	}
+
+	type configMapKey struct{ namespace, name string }
+	counts := map[configMapKey]int{}
+	series, truncated := 0, false
+	emit := func() bool {
+		if c.MaxSeries > 0 && series >= c.MaxSeries {
+			truncated = true
+			return false
+		}
+		series++
+		return true
+	}
+
+	for i := range list.Items {
+		rs := &list.Items[i]
+		if rs.Spec.Replicas != nil && *rs.Spec.Replicas == 0 {
+			continue
+		}
+		if !namespaceMatches(c.NamespaceRegex, c.NamespaceExclude, rs.Namespace) {
+			continue
+		}
+		for _, name := range podConfigMapVolumes(&rs.Spec.Template.Spec) {
+			key := configMapKey{rs.Namespace, name}
+			if _, ok := counts[key]; !ok && !emit() {
+				continue
+			}
+			counts[key]++
+			if c.Level == UsageLevelWorkload && emit() {
+				ch <- prometheus.MustNewConstMetric(configMapMountedByDesc, prometheus.GaugeValue, 1,
+					rs.Namespace, name, "ReplicaSet", rs.Name)
+			}
+		}
+	}
+
+	for key, n := range counts {
+		ch <- prometheus.MustNewConstMetric(configMapWorkloadsDesc, prometheus.GaugeValue, float64(n),
+			key.namespace, key.name)
+	}
+	value := 0.0
+	if truncated {
+		value = 1
+	}
+	ch <- prometheus.MustNewConstMetric(usageTruncatedDesc, prometheus.GaugeValue, value)
+}
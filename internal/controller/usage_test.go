@@ -0,0 +1,57 @@
+package controller
+
+import (
+	"strings"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = ginkgo.Describe("UsageCollector", func() {
+	newCollector := func() *UsageCollector {
+		scaledDown := testReplicaSet("old-rs", "default", "shared")
+		scaledDown.Spec.Replicas = int32Ptr(0)
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+			testReplicaSet("rs-a", "default", "shared", "only-a"),
+			testReplicaSet("rs-b", "default", "shared"),
+			testReplicaSet("rs-c", "kube-system", "shared"),
+			scaledDown,
+		).Build()
+		return &UsageCollector{Reader: c, Level: UsageLevelConfigMap, NamespaceRegex: []string{"^default$"}}
+	}
+
+	ginkgo.It("Should count the active workloads mounting each ConfigMap", func() {
+		expected := `
+# HELP configmap_rs_operator_configmap_workloads Number of workloads with at least one desired replica that mount the ConfigMap.
+# TYPE configmap_rs_operator_configmap_workloads gauge
+configmap_rs_operator_configmap_workloads{configmap="only-a",namespace="default"} 1
+configmap_rs_operator_configmap_workloads{configmap="shared",namespace="default"} 2
+`
+		gomega.Expect(testutil.CollectAndCompare(newCollector(), strings.NewReader(expected),
+			"configmap_rs_operator_configmap_workloads")).To(gomega.Succeed())
+	})
+
+	ginkgo.It("Should add per-workload series and honor the series limit", func() {
+		collector := newCollector()
+		collector.Level = UsageLevelWorkload
+		gomega.Expect(testutil.CollectAndCount(collector, "configmap_rs_operator_configmap_mounted_by")).To(gomega.Equal(3))
+
+		collector.MaxSeries = 2
+		gomega.Expect(testutil.CollectAndCount(collector,
+			"configmap_rs_operator_configmap_workloads", "configmap_rs_operator_configmap_mounted_by")).To(gomega.Equal(2))
+		truncated := `
+# HELP configmap_rs_operator_usage_series_truncated Whether the ConfigMap usage series were truncated by the series limit (1) or not (0).
+# TYPE configmap_rs_operator_usage_series_truncated gauge
+configmap_rs_operator_usage_series_truncated 1
+`
+		gomega.Expect(testutil.CollectAndCompare(collector, strings.NewReader(truncated),
+			"configmap_rs_operator_usage_series_truncated")).To(gomega.Succeed())
+	})
+
+	ginkgo.It("Should reject unknown levels", func() {
+		gomega.Expect(ValidateUsageLevel("everything")).NotTo(gomega.Succeed())
+	})
+})
@@ -0,0 +1,162 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Actions the watchdog takes on a stalled watch
+const (
+	// WatchStallUnready fails the readiness check until events arrive again
+	WatchStallUnready = "unready"
+
+	// WatchStallRestart fails the liveness check, so the kubelet restarts the pod and its informers re-list
+	WatchStallRestart = "restart"
+)
+
+const (
+	// defaultWatchStallFactor is how many typical gaps between events a watch may stay silent
+	defaultWatchStallFactor = 20
+
+	// watchGapWeight is the weight of the latest gap in the moving average of the gaps between events
+	watchGapWeight = 0.1
+)
+
+// ValidateWatchStallAction returns an error unless action is one the watchdog knows
+func ValidateWatchStallAction(action string) error {
+	switch action {
+	case WatchStallUnready, WatchStallRestart:
+		return nil
+	}
+	return fmt.Errorf("invalid watch stall action %q: expected %s or %s", action, WatchStallUnready, WatchStallRestart)
+}
+
+// Watchdog detects informers whose watch silently stopped delivering events. A watch counts as stalled when
+// it has been silent for Factor times the typical gap between its events, and at least MinSilence, so
+// the threshold follows the activity of the cluster.
+type Watchdog struct {
+	// MinSilence is the shortest silence that counts as a stall
+	MinSilence time.Duration
+
+	// Factor is how many typical gaps between events a watch may stay silent (default: 20)
+	Factor float64
+
+	mu      sync.Mutex
+	watches map[string]*watchActivity
+	now     func() time.Time
+}
+
+// watchActivity is the event history of one kind's informer
+type watchActivity struct {
+	last    time.Time
+	gap     time.Duration
+	stalled bool
+}
+
+// NewWatchdog returns a watchdog flagging silences of at least minSilence, or nil when minSilence is not positive
+func NewWatchdog(minSilence time.Duration) *Watchdog {
+	if minSilence <= 0 {
+		return nil
+	}
+	return &Watchdog{MinSilence: minSilence, Factor: defaultWatchStallFactor, watches: make(map[string]*watchActivity)}
+}
+
+func (w *Watchdog) clock() time.Time {
+	if w.now != nil {
+		return w.now()
+	}
+	return time.Now()
+}
+
+// Watch observes the events of the informer of obj's type, reported as kind
+func (w *Watchdog) Watch(ctx context.Context, informers cache.Informers, kind string, obj client.Object) error {
+	informer, err := informers.GetInformer(ctx, obj)
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	w.watches[kind] = &watchActivity{last: w.clock()}
+	w.mu.Unlock()
+
+	// The initial list isn't watch activity and would make the typical gap look far shorter than it is
+	_, err = informer.AddEventHandler(toolscache.ResourceEventHandlerDetailedFuncs{
+		AddFunc: func(_ interface{}, isInInitialList bool) {
+			if !isInInitialList {
+				w.observe(kind)
+			}
+		},
+		UpdateFunc: func(_, _ interface{}) { w.observe(kind) },
+		DeleteFunc: func(interface{}) { w.observe(kind) },
+	})
+	return err
+}
+
+// observe records a watch event of kind
+func (w *Watchdog) observe(kind string) {
+	now := w.clock()
+	watchLastEvent.WithLabelValues(kind).Set(float64(now.Unix()))
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	a := w.watches[kind]
+	gap := now.Sub(a.last)
+	if a.gap == 0 {
+		a.gap = gap
+	} else {
+		a.gap = time.Duration(watchGapWeight*float64(gap) + (1-watchGapWeight)*float64(a.gap))
+	}
+	a.last = now
+	a.stalled = false
+}
+
+// threshold returns how long the watch of a may stay silent
+func (w *Watchdog) threshold(a *watchActivity) time.Duration {
+	factor := w.Factor
+	if factor <= 0 {
+		factor = defaultWatchStallFactor
+	}
+	return max(w.MinSilence, time.Duration(factor*float64(a.gap)))
+}
+
+// Stalled returns the kinds whose watch has been silent for abnormally long, with how long
+func (w *Watchdog) Stalled() map[string]time.Duration {
+	now := w.clock()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	stalled := make(map[string]time.Duration)
+	for kind, a := range w.watches {
+		silence := now.Sub(a.last)
+		if silence <= w.threshold(a) {
+			continue
+		}
+		if !a.stalled {
+			a.stalled = true
+			watchStallsTotal.WithLabelValues(kind).Inc()
+		}
+		stalled[kind] = silence
+	}
+	return stalled
+}
+
+// Check is a healthz checker failing while a watch is stalled
+func (w *Watchdog) Check(_ *http.Request) error {
+	stalled := w.Stalled()
+	if len(stalled) == 0 {
+		return nil
+	}
+	kinds := make([]string, 0, len(stalled))
+	for kind, silence := range stalled {
+		kinds = append(kinds, fmt.Sprintf("%s (silent for %s)", kind, silence.Truncate(time.Second)))
+	}
+	sort.Strings(kinds)
+	return fmt.Errorf("watch stalled: %s", strings.Join(kinds, ", "))
+}
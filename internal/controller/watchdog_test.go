@@ -0,0 +1,53 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+)
+
+var _ = ginkgo.Describe("Watchdog", func() {
+	var (
+		now      time.Time
+		watchdog *Watchdog
+	)
+
+	ginkgo.BeforeEach(func() {
+		now = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+		watchdog = NewWatchdog(time.Minute)
+		watchdog.now = func() time.Time { return now }
+		watchdog.watches["ReplicaSet"] = &watchActivity{last: now}
+	})
+
+	ginkgo.It("Should be disabled without a minimum silence", func() {
+		gomega.Expect(NewWatchdog(0)).To(gomega.BeNil())
+	})
+
+	ginkgo.It("Should flag a busy watch that goes silent for the minimum silence", func() {
+		for range 10 {
+			now = now.Add(time.Second)
+			watchdog.observe("ReplicaSet")
+		}
+		now = now.Add(50 * time.Second)
+		gomega.Expect(watchdog.Check(nil)).To(gomega.Succeed())
+
+		now = now.Add(20 * time.Second)
+		gomega.Expect(watchdog.Check(nil)).To(gomega.MatchError(gomega.ContainSubstring("ReplicaSet (silent for 1m10s)")))
+
+		watchdog.observe("ReplicaSet")
+		gomega.Expect(watchdog.Check(nil)).To(gomega.Succeed())
+	})
+
+	ginkgo.It("Should give a quiet watch more time relative to its usual gaps", func() {
+		for range 3 {
+			now = now.Add(10 * time.Minute)
+			watchdog.observe("ReplicaSet")
+		}
+		now = now.Add(time.Hour)
+		gomega.Expect(watchdog.Stalled()).To(gomega.BeEmpty())
+
+		now = now.Add(3 * time.Hour)
+		gomega.Expect(watchdog.Stalled()).To(gomega.HaveKey("ReplicaSet"))
+	})
+})
@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"context"
+	"slices"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Ways a workload can reference a ConfigMap
+const (
+	ReferenceVolume    = "volume"
+	ReferenceProjected = "projected"
+	ReferenceEnv       = "env"
+	ReferenceEnvFrom   = "envFrom"
+	ReferenceExtra     = "extra"
+)
+
+// ConfigMapUser is a workload referencing a ConfigMap, whether or not the operator owns it for that workload
+type ConfigMapUser struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+
+	// Controller is the kind/name of the workload's controller, e.g. the Deployment of a ReplicaSet
+	Controller string `json:"controller,omitempty"`
+
+	// References lists how the workload references the ConfigMap: volume, projected, env, envFrom, or extra for the
+	// ExtraConfigMapsAnnotation
+	References []string `json:"references"`
+}
+
+// WhoUses lists every object of the watched workload kinds, ReplicaSets by default, referencing the ConfigMap key
+// by any means, not just the volumes the operator owns ConfigMaps for, so cleanup decisions can be made safely
+func (r *ReplicaSetReconciler) WhoUses(ctx context.Context, key types.NamespacedName) ([]ConfigMapUser, error) {
+	var users []ConfigMapUser
+	if r.Config.WatchesReplicaSets() {
+		var replicaSets appsv1.ReplicaSetList
+		if err := r.List(ctx, &replicaSets, client.InNamespace(key.Namespace)); err != nil {
+			return nil, err
+		}
+		for i := range replicaSets.Items {
+			rs := &replicaSets.Items[i]
+			refs := withExtraReference(configMapReferences(&rs.Spec.Template.Spec, key.Name), key.Name,
+				rs.Annotations, rs.Spec.Template.Annotations)
+			if len(refs) > 0 {
+				users = append(users, ConfigMapUser{
+					Kind: "ReplicaSet", Name: rs.Name, Controller: controllerOf(rs), References: refs,
+				})
+			}
+		}
+	}
+
+	for _, kind := range EnabledWorkloadKinds(r.Config) {
+		objs, err := kind.list(ctx, r, client.InNamespace(key.Namespace))
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range objs {
+			// Pods with a controller are listed through it
+			if kind == PodKind && !isBarePod(obj) {
+				continue
+			}
+			spec, err := kind.PodSpec(obj)
+			if err != nil {
+				return nil, err
+			}
+			if refs := withExtraReference(configMapReferences(spec, key.Name), key.Name, obj.GetAnnotations()); len(refs) > 0 {
+				users = append(users, ConfigMapUser{
+					Kind: kind.Kind, Name: obj.GetName(), Controller: controllerOf(obj), References: refs,
+				})
+			}
+		}
+	}
+	return users, nil
+}
+
+// configMapReferences returns how spec references the ConfigMap name, sorted
+func configMapReferences(spec *corev1.PodSpec, name string) []string {
+	var refs []string
+	add := func(ref string) {
+		if !slices.Contains(refs, ref) {
+			refs = append(refs, ref)
+		}
+	}
+	for i := range spec.Volumes {
+		volume := &spec.Volumes[i]
+		if volume.ConfigMap != nil && volume.ConfigMap.Name == name {
+			add(ReferenceVolume)
+		}
+		if volume.Projected == nil {
+			continue
+		}
+		for _, source := range volume.Projected.Sources {
+			if source.ConfigMap != nil && source.ConfigMap.Name == name {
+				add(ReferenceProjected)
+			}
+		}
+	}
+	containers := append(append([]corev1.Container(nil), spec.InitContainers...), spec.Containers...)
+	for i := range containers {
+		container := &containers[i]
+		for _, env := range container.Env {
+			if ref := env.ValueFrom; ref != nil && ref.ConfigMapKeyRef != nil && ref.ConfigMapKeyRef.Name == name {
+				add(ReferenceEnv)
+			}
+		}
+		for _, source := range container.EnvFrom {
+			if source.ConfigMapRef != nil && source.ConfigMapRef.Name == name {
+				add(ReferenceEnvFrom)
+			}
+		}
+	}
+	slices.Sort(refs)
+	return refs
+}
+
+// controllerOf returns the kind/name of the controller of obj, or "" if it has none
+func controllerOf(obj metav1.Object) string {
+	if ref := metav1.GetControllerOf(obj); ref != nil {
+		return ref.Kind + "/" + ref.Name
+	}
+	return ""
+}
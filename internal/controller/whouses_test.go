@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+var _ = ginkgo.Describe("WhoUses", func() {
+	ginkgo.It("Should list every workload referencing the ConfigMap by reference type", func() {
+		ctx := context.Background()
+		isController := true
+		mounted := testReplicaSet("web-abc", "default", "settings")
+		mounted.OwnerReferences = []metav1.OwnerReference{{
+			APIVersion: "apps/v1", Kind: "Deployment", Name: "web", UID: "web-uid", Controller: &isController,
+		}}
+		env := testReplicaSet("worker", "default")
+		container := &env.Spec.Template.Spec.Containers[0]
+		container.EnvFrom = []corev1.EnvFromSource{{
+			ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "settings"}},
+		}}
+		container.Env = []corev1.EnvVar{{Name: "MODE", ValueFrom: &corev1.EnvVarSource{
+			ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "settings"}, Key: "mode",
+			},
+		}}}
+		template := &corev1.PodTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: "batch", Namespace: "default"},
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Volumes: []corev1.Volume{{
+				Name: "config",
+				VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{
+					Sources: []corev1.VolumeProjection{{ConfigMap: &corev1.ConfigMapProjection{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "settings"},
+					}}},
+				}},
+			}}}},
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+			mounted, env, template, testReplicaSet("unrelated", "default", "other"),
+		).Build()
+		reconciler := &ReplicaSetReconciler{Client: c, Scheme: scheme.Scheme,
+			Config: &config.OperatorConfig{PodTemplates: true}}
+
+		users, err := reconciler.WhoUses(ctx, types.NamespacedName{Namespace: "default", Name: "settings"})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(users).To(gomega.ConsistOf(
+			ConfigMapUser{Kind: "ReplicaSet", Name: "web-abc", Controller: "Deployment/web",
+				References: []string{ReferenceVolume}},
+			ConfigMapUser{Kind: "ReplicaSet", Name: "worker", References: []string{ReferenceEnv, ReferenceEnvFrom}},
+			ConfigMapUser{Kind: "PodTemplate", Name: "batch", References: []string{ReferenceProjected}},
+		))
+	})
+})
@@ -0,0 +1,195 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+// WorkloadKind describes a kind of object embedding a pod spec, whose objects the workload engine adds as owners
+// of the ConfigMaps the pod spec mounts. ReplicaSets have their own reconciler; every other kind is a WorkloadKind.
+type WorkloadKind struct {
+	// Kind and GroupVersion identify the kind in owner references
+	Kind         string
+	GroupVersion schema.GroupVersion
+
+	// New and NewList return empty objects of the kind
+	New     func() client.Object
+	NewList func() client.ObjectList
+
+	// PodSpec returns the pod spec embedded in an object of the kind
+	PodSpec func(obj client.Object) (*corev1.PodSpec, error)
+
+	// Mutable kinds change their pod spec in place, so their objects are processed again whenever their spec
+	// changes. Objects of the other kinds are processed once, when they are created.
+	Mutable bool
+
+	// Enabled reports whether cfg owns ConfigMaps for the kind; custom kinds are enabled by declaring them
+	Enabled func(cfg *config.OperatorConfig) bool
+
+	// Accepts reports whether the ConfigMaps of obj are owned with cfg; nil accepts every object
+	Accepts func(cfg *config.OperatorConfig, obj client.Object) bool
+}
+
+// workloadKinds are the kinds of the workload engine, in the order their objects are listed
+var workloadKinds = []*WorkloadKind{PodTemplateKind, StatefulSetKind, DaemonSetKind, JobKind, CronJobKind, PodKind}
+
+// EnabledWorkloadKinds returns the kinds of the workload engine cfg owns ConfigMaps for, followed by its custom kinds
+func EnabledWorkloadKinds(cfg *config.OperatorConfig) []*WorkloadKind {
+	var kinds []*WorkloadKind
+	for _, kind := range workloadKinds {
+		if kind.Enabled(cfg) {
+			kinds = append(kinds, kind)
+		}
+	}
+	// Custom kinds are validated on startup
+	custom, _ := ParseCustomKinds(cfg.CustomKinds)
+	return append(kinds, custom...)
+}
+
+// ValidateWatchKinds returns an error for kinds --watch-kinds doesn't accept
+func ValidateWatchKinds(kinds []string) error {
+	for _, kind := range kinds {
+		if !slices.Contains(config.WatchKindNames, kind) {
+			return fmt.Errorf("invalid watch kind %q: expected one of %s", kind, strings.Join(config.WatchKindNames, ", "))
+		}
+	}
+	return nil
+}
+
+// name is the lowercase kind, which names its controller and keys its objects in logs
+func (k *WorkloadKind) name() string {
+	return strings.ToLower(k.Kind)
+}
+
+// accepts reports whether the ConfigMaps of obj are owned with cfg
+func (k *WorkloadKind) accepts(cfg *config.OperatorConfig, obj client.Object) bool {
+	return k.Accepts == nil || k.Accepts(cfg, obj)
+}
+
+// list returns the objects of the kind matching opts
+func (k *WorkloadKind) list(ctx context.Context, c client.Reader, opts ...client.ListOption) ([]client.Object, error) {
+	list := k.NewList()
+	if err := c.List(ctx, list, opts...); err != nil {
+		return nil, err
+	}
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return nil, err
+	}
+	objs := make([]client.Object, 0, len(items))
+	for _, item := range items {
+		if obj, ok := item.(client.Object); ok {
+			objs = append(objs, obj)
+		}
+	}
+	return objs, nil
+}
+
+// WorkloadReconciler is the workload engine: it adds the objects of Kind as owners of the ConfigMaps their pod spec
+// mounts, using the same extraction as for ReplicaSets. The per-kind reconcilers delegate to it.
+type WorkloadReconciler struct {
+	// ReplicaSetReconciler provides the namespace filter, write holds, write client and events
+	*ReplicaSetReconciler
+
+	Kind *WorkloadKind
+}
+
+// workload returns the workload engine for kind
+func (r *ReplicaSetReconciler) workload(kind *WorkloadKind) *WorkloadReconciler {
+	return &WorkloadReconciler{ReplicaSetReconciler: r, Kind: kind}
+}
+
+func (r *WorkloadReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithValues(r.Kind.name(), req.NamespacedName)
+	if !r.shouldProcessNamespace(ctx, req.Namespace) {
+		recordFiltered(dropReasonNamespace)
+		return ctrl.Result{}, nil
+	}
+
+	obj := r.Kind.New()
+	if err := r.Get(ctx, req.NamespacedName, obj); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !r.Kind.Mutable && obj.GetCreationTimestamp().Time.Before(r.StartTime) {
+		recordFiltered(dropReasonStartTime)
+		return ctrl.Result{}, nil
+	}
+	if !r.Kind.accepts(r.Config, obj) {
+		return ctrl.Result{}, nil
+	}
+
+	spec, err := r.Kind.PodSpec(obj)
+	if err != nil {
+		// The object won't change by retrying, so it is processed again with its next spec change
+		logger.Error(err, "Failed to get pod spec")
+		return ctrl.Result{}, nil
+	}
+	w := r.Kind.podWorkload(obj, spec)
+	if reason := r.filterReason(obj, obj.GetName(), nil); reason != "" {
+		logger.V(1).Info("Skipping ignored workload", "reason", reason)
+		recordFiltered(reason)
+		decision := w.decision("")
+		decision.Action, decision.Reason = decisionSkipped, reason
+		recordDecision(ctx, decision)
+		return ctrl.Result{}, nil
+	}
+	configMapNames, err := r.withConventionConfigMap(ctx, obj.GetNamespace(), obj.GetName(),
+		withExtraConfigMaps(r.podConfigMaps(spec, obj), obj.GetAnnotations()))
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	configMapsPerWorkload.WithLabelValues(r.Kind.Kind).Observe(float64(len(configMapNames)))
+
+	now := time.Now()
+	holdReason := r.holdReason(ctx, obj.GetNamespace(), now, logger)
+	if err := r.processConfigMaps(ctx, w, configMapNames, holdReason, logger); err != nil {
+		return ctrl.Result{}, err
+	}
+	return r.heldResult(holdReason, now), nil
+}
+
+// podWorkload returns obj, an object of the kind with the pod spec spec, as a podWorkload
+func (k *WorkloadKind) podWorkload(obj client.Object, spec *corev1.PodSpec) *podWorkload {
+	return &podWorkload{
+		obj:  obj,
+		spec: spec,
+		self: metav1.OwnerReference{
+			APIVersion: k.GroupVersion.String(), Kind: k.Kind, Name: obj.GetName(), UID: obj.GetUID(),
+		},
+	}
+}
+
+// SetupWithManager sets up the controller of the kind with the Manager. Like ReplicaSets, only objects created
+// after the operator started are processed, and those of mutable kinds again whenever their spec changes.
+func (r *WorkloadReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(r.Kind.New()).
+		Named(r.Kind.name()).
+		WithEventFilter(predicate.Funcs{
+			CreateFunc: func(e event.CreateEvent) bool {
+				return e.Object.GetCreationTimestamp().After(r.StartTime) && r.Kind.accepts(r.Config, e.Object)
+			},
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				return r.Kind.Mutable && e.ObjectNew.GetGeneration() != e.ObjectOld.GetGeneration()
+			},
+			DeleteFunc:  func(event.DeleteEvent) bool { return false },
+			GenericFunc: func(event.GenericEvent) bool { return false },
+		}).
+		WithOptions(r.controllerOptions()).
+		Complete(r.tracked(r.Kind.name(), r))
+}
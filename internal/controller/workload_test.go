@@ -0,0 +1,25 @@
+package controller
+
+import (
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+var _ = ginkgo.Describe("EnabledWorkloadKinds", func() {
+	ginkgo.It("Should return the kinds not returned by DisabledKinds, in listing order", func() {
+		cfg := &config.OperatorConfig{DaemonSets: true, CronJobs: true, CronJobOwner: CronJobOwnerJob, Pods: true}
+
+		var kinds []string
+		for _, kind := range EnabledWorkloadKinds(cfg) {
+			kinds = append(kinds, kind.Kind)
+		}
+		gomega.Expect(kinds).To(gomega.Equal([]string{"DaemonSet", "Job", "Pod"}))
+		gomega.Expect(DisabledKinds(cfg)).To(gomega.Equal([]string{"PodTemplate", "StatefulSet", "CronJob"}))
+	})
+
+	ginkgo.It("Should return no kinds by default", func() {
+		gomega.Expect(EnabledWorkloadKinds(&config.OperatorConfig{})).To(gomega.BeEmpty())
+	})
+})
@@ -0,0 +1,24 @@
+package controller
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// ValidateWorkloadNamePatterns returns an error for the --workload-name-regex or --workload-name-exclude-regex
+// patterns that don't compile
+func ValidateWorkloadNamePatterns(include, exclude []string) error {
+	for _, pattern := range append(append([]string(nil), include...), exclude...) {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid workload name regex %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// selectsWorkloadName reports whether the ConfigMaps of the workload name are owned: it matches a
+// --workload-name-regex pattern, or there are none, and no --workload-name-exclude-regex pattern
+func (r *ReplicaSetReconciler) selectsWorkloadName(name string) bool {
+	return (len(r.Config.WorkloadNameRegex) == 0 || matchesAny(r.Config.WorkloadNameRegex, name)) &&
+		!matchesAny(r.Config.WorkloadNameExclude, name)
+}
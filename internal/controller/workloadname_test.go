@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+var _ = ginkgo.Describe("Workload names", func() {
+	ginkgo.It("Should only own the ConfigMaps of the selected workload names", func() {
+		ctx := context.Background()
+		preview := testReplicaSet("pr-42-web-abc", "default", "preview-config")
+		preview.CreationTimestamp = metav1.Now()
+		isController := true
+		preview.OwnerReferences = []metav1.OwnerReference{{
+			APIVersion: "apps/v1", Kind: "Deployment", Name: "pr-42-web", UID: "web-uid", Controller: &isController,
+		}}
+		loadtest := testReplicaSet("pr-42-loadtest", "default", "loadtest-config")
+		loadtest.CreationTimestamp = metav1.Now()
+		service := testReplicaSet("web-abc", "default", "web-config")
+		service.CreationTimestamp = metav1.Now()
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(preview, loadtest, service,
+			testConfigMap("preview-config", "default"), testConfigMap("loadtest-config", "default"),
+			testConfigMap("web-config", "default")).Build()
+		r := &ReplicaSetReconciler{Client: c, Scheme: scheme.Scheme, Config: &config.OperatorConfig{
+			WorkloadNameRegex: []string{`^pr-\d+-`}, WorkloadNameExclude: []string{`-loadtest$`},
+		}}
+
+		for _, name := range []string{"pr-42-web-abc", "pr-42-loadtest", "web-abc"} {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: name}})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		}
+		owners := func(name string) []metav1.OwnerReference {
+			var cm corev1.ConfigMap
+			gomega.Expect(c.Get(ctx, types.NamespacedName{Namespace: "default", Name: name}, &cm)).To(gomega.Succeed())
+			return cm.OwnerReferences
+		}
+		gomega.Expect(owners("preview-config")).To(gomega.HaveLen(1))
+		gomega.Expect(owners("loadtest-config")).To(gomega.BeEmpty())
+		gomega.Expect(owners("web-config")).To(gomega.BeEmpty())
+
+		inv, err := r.Inventory(ctx)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(inv.Skips).To(gomega.HaveKeyWithValue(dropReasonWorkloadName, 2))
+	})
+
+	ginkgo.It("Should reject invalid patterns", func() {
+		gomega.Expect(ValidateWorkloadNamePatterns([]string{`^pr-\d+-`}, []string{`-loadtest$`})).To(gomega.Succeed())
+		gomega.Expect(ValidateWorkloadNamePatterns(nil, []string{"pr-("})).To(
+			gomega.MatchError(gomega.ContainSubstring("invalid workload name regex")))
+	})
+
+	ginkgo.It("Should compile each pattern once", func() {
+		gomega.Expect(matchesAny([]string{`^pr-\d+-`}, "pr-42-web")).To(gomega.BeTrue())
+		compiled := compiledPattern(`^pr-\d+-`)
+		gomega.Expect(compiled).NotTo(gomega.BeNil())
+		gomega.Expect(matchesAny([]string{`^pr-\d+-`}, "web")).To(gomega.BeFalse())
+		gomega.Expect(compiledPattern(`^pr-\d+-`)).To(gomega.BeIdenticalTo(compiled))
+
+		// A pattern that doesn't compile never matches
+		gomega.Expect(matchesAny([]string{"pr-(", `^web$`}, "web")).To(gomega.BeTrue())
+		gomega.Expect(compiledPattern("pr-(")).To(gomega.BeNil())
+	})
+})
@@ -0,0 +1,132 @@
+// Package leadership makes leader election visible through metrics and structured logs.
+package leadership
+
+import (
+	"context"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// serviceAccountNamespaceFile holds the pod's namespace when running in-cluster
+const serviceAccountNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+var (
+	// leaderInfo reports the identity currently holding the leader election lease
+	leaderInfo = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "configmap_rs_operator",
+			Name:      "leader_info",
+			Help:      "Identity of the replica currently holding the leader election lease (value is always 1).",
+		},
+		[]string{"identity"},
+	)
+
+	// isLeader reports whether this replica is the elected leader
+	isLeader = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "configmap_rs_operator",
+			Name:      "is_leader",
+			Help:      "Whether this replica is the elected leader (1) or a standby (0).",
+		},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(leaderInfo, isLeader)
+}
+
+// Observer polls the leader election lease and reports leadership transitions.
+// It runs on every replica, so standbys also know which replica is doing the work.
+type Observer struct {
+	// Reader reads the lease; an uncached reader avoids watching leases cluster-wide
+	Reader client.Reader
+
+	// Lease is the leader election lease
+	Lease types.NamespacedName
+
+	// Elected is closed once this replica becomes leader
+	Elected <-chan struct{}
+
+	// Interval is how often the lease is polled
+	Interval time.Duration
+
+	Log logr.Logger
+
+	holder string
+}
+
+// Namespace returns the namespace the leader election lease lives in when running in-cluster
+func Namespace() string {
+	if ns := os.Getenv("POD_NAMESPACE"); ns != "" {
+		return ns
+	}
+	if data, err := os.ReadFile(serviceAccountNamespaceFile); err == nil {
+		return strings.TrimSpace(string(data))
+	}
+	return ""
+}
+
+// Start implements manager.Runnable
+func (o *Observer) Start(ctx context.Context) error {
+	hostname, _ := os.Hostname()
+	o.Log.Info("Waiting for leadership", "replica", hostname, "lease", o.Lease)
+
+	ticker := time.NewTicker(o.Interval)
+	defer ticker.Stop()
+
+	elected := o.Elected
+	o.poll(ctx, hostname)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-elected:
+			o.Log.Info("Acquired leadership", "replica", hostname, "lease", o.Lease)
+			isLeader.Set(1)
+			// A closed channel is always ready; stop selecting on it
+			elected = nil
+		case <-ticker.C:
+			o.poll(ctx, hostname)
+		}
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable; the observer runs on every replica
+func (o *Observer) NeedLeaderElection() bool {
+	return false
+}
+
+func (o *Observer) poll(ctx context.Context, hostname string) {
+	var lease coordinationv1.Lease
+	if err := o.Reader.Get(ctx, o.Lease, &lease); err != nil {
+		o.Log.V(1).Info("Unable to read leader election lease", "lease", o.Lease, "error", err.Error())
+		return
+	}
+
+	holder := ""
+	if lease.Spec.HolderIdentity != nil {
+		holder = *lease.Spec.HolderIdentity
+	}
+	if holder == o.holder {
+		return
+	}
+
+	o.Log.Info("Leadership changed",
+		"previousLeader", o.holder,
+		"leader", holder,
+		"replica", hostname,
+		"self", strings.HasPrefix(holder, hostname+"_"))
+	leaderInfo.Reset()
+	if holder != "" {
+		leaderInfo.WithLabelValues(holder).Set(1)
+	}
+	o.holder = holder
+}
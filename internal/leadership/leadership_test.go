@@ -0,0 +1,48 @@
+package leadership
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = ginkgo.Describe("Observer", func() {
+	ginkgo.It("should export the current lease holder", func() {
+		holder := "replica-a_1234"
+		lease := &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{Name: "lease", Namespace: "ops"},
+			Spec:       coordinationv1.LeaseSpec{HolderIdentity: &holder},
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(lease).Build()
+		o := &Observer{
+			Reader: c,
+			Lease:  types.NamespacedName{Namespace: "ops", Name: "lease"},
+			Log:    logr.Discard(),
+		}
+
+		o.poll(context.Background(), "replica-b")
+		gomega.Expect(testutil.ToFloat64(leaderInfo.WithLabelValues("replica-a_1234"))).To(gomega.Equal(1.0))
+
+		holder = "replica-b_5678"
+		lease.Spec.HolderIdentity = &holder
+		gomega.Expect(c.Update(context.Background(), lease)).To(gomega.Succeed())
+
+		o.poll(context.Background(), "replica-b")
+		gomega.Expect(testutil.CollectAndCount(leaderInfo)).To(gomega.Equal(1))
+		gomega.Expect(testutil.ToFloat64(leaderInfo.WithLabelValues("replica-b_5678"))).To(gomega.Equal(1.0))
+	})
+})
+
+func TestLeadership(t *testing.T) {
+	gomega.RegisterFailHandler(ginkgo.Fail)
+	ginkgo.RunSpecs(t, "Leadership Suite")
+}
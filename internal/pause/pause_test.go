@@ -0,0 +1,83 @@
+package pause
+
+import (
+	"context"
+	"testing"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = ginkgo.Describe("Switch", func() {
+	ctx := context.Background()
+
+	ginkgo.It("should be disabled without a control ConfigMap", func() {
+		s, err := NewSwitch(nil, "")
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(s).To(gomega.BeNil())
+
+		paused, err := s.Paused(ctx)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(paused).To(gomega.BeFalse())
+	})
+
+	ginkgo.It("should reject malformed references", func() {
+		_, err := NewSwitch(nil, "no-namespace")
+		gomega.Expect(err).To(gomega.HaveOccurred())
+	})
+
+	ginkgo.It("should not be paused when the control ConfigMap is missing", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+		s, err := NewSwitch(c, "ops/control")
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+		paused, err := s.Paused(ctx)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(paused).To(gomega.BeFalse())
+	})
+
+	ginkgo.It("should be paused when the paused key is true", func() {
+		control := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "control", Namespace: "ops"},
+			Data:       map[string]string{PausedKey: "true"},
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(control).Build()
+		s, err := NewSwitch(c, "ops/control")
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+		paused, err := s.Paused(ctx)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(paused).To(gomega.BeTrue())
+	})
+
+	ginkgo.It("should record who paused and resumed the operator", func() {
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+		s, err := NewSwitch(c, "ops/control")
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+		_, err = s.Pause(ctx, "alice", "incident 42")
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		state, err := s.State(ctx)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(state.Paused).To(gomega.BeTrue())
+		gomega.Expect(state.ChangedBy).To(gomega.Equal("alice"))
+		gomega.Expect(state.Reason).To(gomega.Equal("incident 42"))
+		gomega.Expect(state.ChangedAt).NotTo(gomega.BeEmpty())
+
+		_, err = s.Resume(ctx, "bob", "")
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		state, err = s.State(ctx)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(state.Paused).To(gomega.BeFalse())
+		gomega.Expect(state.ChangedBy).To(gomega.Equal("bob"))
+	})
+})
+
+func TestPause(t *testing.T) {
+	gomega.RegisterFailHandler(ginkgo.Fail)
+	ginkgo.RunSpecs(t, "Pause Suite")
+}
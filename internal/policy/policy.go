@@ -0,0 +1,257 @@
+// Package policy converts the operator's active filters into admission policies for Kyverno or
+// Gatekeeper, so admission-time guardrails mirror the policy the operator applies at reconcile time.
+package policy
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+	"github.com/matanbaruch/configmap-rs-operator/internal/controller"
+)
+
+// Supported policy engines
+const (
+	FormatKyverno    = "kyverno"
+	FormatGatekeeper = "gatekeeper"
+)
+
+// garbageCollector is the identity deleting ConfigMaps once all their owners are gone
+const garbageCollector = "system:serviceaccount:kube-system:generic-garbage-collector"
+
+// policyName names the generated policies, constraint templates and constraints
+const policyName = "configmap-rs-operator-ownership"
+
+// constraintKind is the kind of the generated Gatekeeper constraint
+const constraintKind = "ConfigMapRsOperatorOwnership"
+
+// Options selects what the generated policies protect
+type Options struct {
+	// NamespaceRegex limits the policies to the namespaces selected by the operator; empty selects all
+	NamespaceRegex []string
+
+	// NamespaceExclude removes the namespaces the operator excludes from the policies
+	NamespaceExclude []string
+
+	// ExemptUsers may change ownership metadata and delete managed ConfigMaps; "*" matches one
+	// segment of a user name, e.g. system:serviceaccount:*:writer
+	ExemptUsers []string
+}
+
+// ExemptUsers returns every identity the operator writes with for cfg, running as the service account
+// namespace/name, and the garbage collector
+func ExemptUsers(cfg *config.OperatorConfig, serviceAccount string) []string {
+	users := []string{garbageCollector}
+	if namespace, name, ok := strings.Cut(serviceAccount, "/"); ok {
+		users = append(users, "system:serviceaccount:"+namespace+":"+name)
+	}
+	if cfg.ImpersonateUser != "" {
+		users = append(users, cfg.ImpersonateUser)
+	}
+	for namespace, name := range cfg.TenantServiceAccounts {
+		users = append(users, "system:serviceaccount:"+namespace+":"+name)
+	}
+	slices.Sort(users)
+	return slices.Compact(users)
+}
+
+// Generate returns the YAML manifests enforcing, for the given engine, that only the exempt users
+// change the operator's ownership annotation of ConfigMaps or delete ConfigMaps it manages
+func Generate(format string, opts Options) ([]byte, error) {
+	var objects []interface{}
+	switch format {
+	case FormatKyverno:
+		objects = []interface{}{kyvernoPolicy(opts)}
+	case FormatGatekeeper:
+		objects = []interface{}{gatekeeperTemplate(), gatekeeperConstraint(opts)}
+	default:
+		return nil, fmt.Errorf("invalid policy format %q: expected %s or %s", format, FormatKyverno, FormatGatekeeper)
+	}
+
+	var out bytes.Buffer
+	for i, obj := range objects {
+		data, err := yaml.Marshal(obj)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			out.WriteString("---\n")
+		}
+		out.Write(data)
+	}
+	return out.Bytes(), nil
+}
+
+// namespacePattern joins the namespace patterns into a single regular expression
+func namespacePattern(patterns []string) string {
+	groups := make([]string, len(patterns))
+	for i, p := range patterns {
+		groups[i] = "(?:" + p + ")"
+	}
+	return strings.Join(groups, "|")
+}
+
+// kyvernoPolicy returns a ClusterPolicy with one rule for annotation changes and one for deletions
+func kyvernoPolicy(opts Options) map[string]interface{} {
+	annotation := fmt.Sprintf(`request.%%s.metadata.annotations."%s" || ''`, controller.ManagedOwnersAnnotation)
+	oldAnnotation := "{{ " + fmt.Sprintf(annotation, "oldObject") + " }}"
+
+	conditions := func(extra ...map[string]interface{}) map[string]interface{} {
+		all := []map[string]interface{}{{
+			"key": "{{ request.userInfo.username }}", "operator": "AnyNotIn", "value": opts.ExemptUsers,
+		}}
+		if len(opts.NamespaceRegex) > 0 {
+			pattern := strings.ReplaceAll(namespacePattern(opts.NamespaceRegex), `'`, `\'`)
+			all = append(all, map[string]interface{}{
+				"key": fmt.Sprintf("{{ regex_match('%s', request.namespace) }}", pattern), "operator": "Equals", "value": true,
+			})
+		}
+		if len(opts.NamespaceExclude) > 0 {
+			pattern := strings.ReplaceAll(namespacePattern(opts.NamespaceExclude), `'`, `\'`)
+			all = append(all, map[string]interface{}{
+				"key": fmt.Sprintf("{{ regex_match('%s', request.namespace) }}", pattern), "operator": "Equals", "value": false,
+			})
+		}
+		return map[string]interface{}{"all": append(all, extra...)}
+	}
+	rule := func(name, operation, message string, extra map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"name": name,
+			"match": map[string]interface{}{"any": []interface{}{map[string]interface{}{
+				"resources": map[string]interface{}{"kinds": []string{"ConfigMap"}, "operations": []string{operation}},
+			}}},
+			"preconditions": conditions(extra),
+			"validate":      map[string]interface{}{"message": message, "deny": map[string]interface{}{}},
+		}
+	}
+
+	return map[string]interface{}{
+		"apiVersion": "kyverno.io/v1",
+		"kind":       "ClusterPolicy",
+		"metadata": map[string]interface{}{
+			"name": policyName,
+			"annotations": map[string]string{
+				"policies.kyverno.io/description": "Generated by configmap-rs-operator from its active filters.",
+			},
+		},
+		"spec": map[string]interface{}{
+			"validationFailureAction": "Enforce",
+			"background":              false,
+			"rules": []interface{}{
+				rule("protect-ownership-annotation", "UPDATE",
+					"The "+controller.ManagedOwnersAnnotation+" annotation is maintained by configmap-rs-operator.",
+					map[string]interface{}{
+						"key": oldAnnotation, "operator": "NotEquals",
+						"value": "{{ " + fmt.Sprintf(annotation, "object") + " }}",
+					}),
+				rule("protect-managed-configmaps", "DELETE",
+					"ConfigMaps owned through configmap-rs-operator are deleted with their last owner.",
+					map[string]interface{}{"key": oldAnnotation, "operator": "NotEquals", "value": ""}),
+			},
+		},
+	}
+}
+
+// gatekeeperRego denies changes of the ownership annotation and deletions of managed ConfigMaps
+// by anyone but the exempt users
+const gatekeeperRego = `package configmaprsoperator
+
+annotation := "` + controller.ManagedOwnersAnnotation + `"
+
+managed_owners(obj) := object.get(obj, ["metadata", "annotations", annotation], "")
+
+included {
+  count(input.parameters.namespacePatterns) == 0
+}
+
+included {
+  regex.match(input.parameters.namespacePatterns[_], input.review.namespace)
+}
+
+excluded {
+  regex.match(object.get(input.parameters, "namespaceExcludePatterns", [])[_], input.review.namespace)
+}
+
+in_scope {
+  included
+  not excluded
+}
+
+exempt {
+  glob.match(input.parameters.exemptUsers[_], [":"], input.review.userInfo.username)
+}
+
+violation[{"msg": msg}] {
+  input.review.operation == "UPDATE"
+  in_scope
+  not exempt
+  managed_owners(input.review.oldObject) != managed_owners(input.review.object)
+  msg := sprintf("the %v annotation is maintained by configmap-rs-operator", [annotation])
+}
+
+violation[{"msg": msg}] {
+  input.review.operation == "DELETE"
+  in_scope
+  not exempt
+  managed_owners(input.review.oldObject) != ""
+  msg := "ConfigMaps owned through configmap-rs-operator are deleted with their last owner"
+}
+`
+
+// gatekeeperTemplate returns the ConstraintTemplate defining the constraint kind
+func gatekeeperTemplate() map[string]interface{} {
+	stringList := map[string]interface{}{"type": "array", "items": map[string]string{"type": "string"}}
+	return map[string]interface{}{
+		"apiVersion": "templates.gatekeeper.sh/v1",
+		"kind":       "ConstraintTemplate",
+		"metadata":   map[string]interface{}{"name": strings.ToLower(constraintKind)},
+		"spec": map[string]interface{}{
+			"crd": map[string]interface{}{"spec": map[string]interface{}{
+				"names": map[string]string{"kind": constraintKind},
+				"validation": map[string]interface{}{"openAPIV3Schema": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"namespacePatterns":        stringList,
+						"namespaceExcludePatterns": stringList,
+						"exemptUsers":              stringList,
+					},
+				}},
+			}},
+			"targets": []interface{}{map[string]interface{}{
+				"target": "admission.k8s.gatekeeper.sh",
+				"rego":   gatekeeperRego,
+			}},
+		},
+	}
+}
+
+// gatekeeperConstraint returns the constraint applying the template with the operator's filters
+func gatekeeperConstraint(opts Options) map[string]interface{} {
+	patterns, exclude := opts.NamespaceRegex, opts.NamespaceExclude
+	if patterns == nil {
+		patterns = []string{}
+	}
+	if exclude == nil {
+		exclude = []string{}
+	}
+	return map[string]interface{}{
+		"apiVersion": "constraints.gatekeeper.sh/v1beta1",
+		"kind":       constraintKind,
+		"metadata":   map[string]interface{}{"name": policyName},
+		"spec": map[string]interface{}{
+			"enforcementAction": "deny",
+			"match": map[string]interface{}{"kinds": []interface{}{map[string]interface{}{
+				"apiGroups": []string{""}, "kinds": []string{"ConfigMap"},
+			}}},
+			"parameters": map[string]interface{}{
+				"namespacePatterns":        patterns,
+				"namespaceExcludePatterns": exclude,
+				"exemptUsers":              opts.ExemptUsers,
+			},
+		},
+	}
+}
@@ -0,0 +1,85 @@
+package policy
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+// decode parses the generated multi-document YAML
+func decode(manifests []byte) []unstructured.Unstructured {
+	var objs []unstructured.Unstructured
+	for _, doc := range strings.Split(string(manifests), "---\n") {
+		var obj unstructured.Unstructured
+		gomega.Expect(yaml.Unmarshal([]byte(doc), &obj.Object)).To(gomega.Succeed())
+		objs = append(objs, obj)
+	}
+	return objs
+}
+
+var _ = ginkgo.Describe("ExemptUsers", func() {
+	ginkgo.It("should include every identity the operator writes with", func() {
+		cfg := &config.OperatorConfig{
+			ImpersonateUser:       "alice",
+			TenantServiceAccounts: map[string]string{"team-a": "writer", "*": "tenant"},
+		}
+		gomega.Expect(ExemptUsers(cfg, "ops/operator")).To(gomega.Equal([]string{
+			"alice",
+			"system:serviceaccount:*:tenant",
+			"system:serviceaccount:kube-system:generic-garbage-collector",
+			"system:serviceaccount:ops:operator",
+			"system:serviceaccount:team-a:writer",
+		}))
+	})
+})
+
+var _ = ginkgo.Describe("Generate", func() {
+	opts := Options{
+		NamespaceRegex:   []string{"^team-", "^prod$"},
+		NamespaceExclude: []string{"^team-sandbox$"},
+		ExemptUsers:      []string{"system:serviceaccount:ops:operator"},
+	}
+
+	ginkgo.It("should generate a Kyverno ClusterPolicy scoped to the selected namespaces", func() {
+		manifests, err := Generate(FormatKyverno, opts)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		objs := decode(manifests)
+		gomega.Expect(objs).To(gomega.HaveLen(1))
+		gomega.Expect(objs[0].GetKind()).To(gomega.Equal("ClusterPolicy"))
+
+		rules, _, _ := unstructured.NestedSlice(objs[0].Object, "spec", "rules")
+		gomega.Expect(rules).To(gomega.HaveLen(2))
+		gomega.Expect(string(manifests)).To(gomega.ContainSubstring("regex_match(''(?:^team-)|(?:^prod$)''"))
+		gomega.Expect(string(manifests)).To(gomega.ContainSubstring("regex_match(''(?:^team-sandbox$)''"))
+	})
+
+	ginkgo.It("should generate a Gatekeeper template and constraint", func() {
+		manifests, err := Generate(FormatGatekeeper, opts)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		objs := decode(manifests)
+		gomega.Expect(objs).To(gomega.HaveLen(2))
+		gomega.Expect(objs[0].GetKind()).To(gomega.Equal("ConstraintTemplate"))
+		gomega.Expect(objs[1].GetKind()).To(gomega.Equal(constraintKind))
+
+		patterns, _, _ := unstructured.NestedStringSlice(objs[1].Object, "spec", "parameters", "namespacePatterns")
+		gomega.Expect(patterns).To(gomega.Equal(opts.NamespaceRegex))
+		exclude, _, _ := unstructured.NestedStringSlice(objs[1].Object, "spec", "parameters", "namespaceExcludePatterns")
+		gomega.Expect(exclude).To(gomega.Equal(opts.NamespaceExclude))
+	})
+
+	ginkgo.It("should reject unknown formats", func() {
+		_, err := Generate("opa", opts)
+		gomega.Expect(err).To(gomega.HaveOccurred())
+	})
+})
+
+func TestPolicy(t *testing.T) {
+	gomega.RegisterFailHandler(ginkgo.Fail)
+	ginkgo.RunSpecs(t, "Policy Suite")
+}
@@ -0,0 +1,216 @@
+// Package preflight verifies at startup that the operator has the permissions its enabled features need.
+package preflight
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+// missingPermissions reports how many required permissions were denied by the last preflight run
+var missingPermissions = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Namespace: "configmap_rs_operator",
+		Name:      "rbac_missing_permissions",
+		Help:      "Number of permissions required by the enabled features that the operator lacks (0 when healthy).",
+	},
+)
+
+func init() {
+	metrics.Registry.MustRegister(missingPermissions)
+}
+
+// Requirement is a single permission needed by a feature
+type Requirement struct {
+	Group       string
+	Resource    string
+	Subresource string
+	Verb        string
+	Namespace   string
+
+	// Feature names what needs the permission, for the error message
+	Feature string
+}
+
+func (r Requirement) String() string {
+	resource := r.Resource
+	if r.Subresource != "" {
+		resource += "/" + r.Subresource
+	}
+	if r.Group != "" {
+		resource += "." + r.Group
+	}
+	if r.Namespace != "" {
+		return fmt.Sprintf("%s %s in namespace %s (%s)", r.Verb, resource, r.Namespace, r.Feature)
+	}
+	return fmt.Sprintf("%s %s (%s)", r.Verb, resource, r.Feature)
+}
+
+// Requirements returns the permissions needed by the features enabled in cfg
+func Requirements(cfg *config.OperatorConfig) []Requirement {
+	var reqs []Requirement
+	add := func(feature, group, resource, namespace string, verbs ...string) {
+		for _, verb := range verbs {
+			reqs = append(reqs, Requirement{
+				Group: group, Resource: resource, Verb: verb, Namespace: namespace, Feature: feature,
+			})
+		}
+	}
+
+	if cfg.WatchesReplicaSets() {
+		add("watch ReplicaSets", "apps", "replicasets", "", "get", "list", "watch")
+	}
+	add("read ConfigMaps", "", "configmaps", "", "get", "list", "watch")
+	switch {
+	case cfg.DryRun || cfg.Shadow:
+	case cfg.PatchOnly:
+		add("add owner references", "", "configmaps", "", "patch")
+	default:
+		add("add owner references", "", "configmaps", "", "update", "patch")
+	}
+	add("namespace overrides and opt-outs", "", "namespaces", "", "get", "list", "watch")
+	if cfg.EventsEnabled {
+		add("emit Events", "", "events", "", "create", "patch")
+	}
+	if sa, ok := strings.CutPrefix(cfg.ImpersonateUser, "system:serviceaccount:"); ok {
+		namespace, _, _ := strings.Cut(sa, ":")
+		add("impersonation", "", "serviceaccounts", namespace, "impersonate")
+	} else if cfg.ImpersonateUser != "" {
+		add("impersonation", "", "users", "", "impersonate")
+	}
+	if len(cfg.ImpersonateGroups) > 0 {
+		add("impersonation", "", "groups", "", "impersonate")
+	}
+	for namespace := range cfg.TenantServiceAccounts {
+		if namespace == "*" {
+			namespace = ""
+		}
+		reqs = append(reqs, Requirement{
+			Resource: "serviceaccounts", Subresource: "token", Verb: "create",
+			Namespace: namespace, Feature: "tenant credentials",
+		})
+	}
+	if cfg.IngressTLSSecrets {
+		add("watch Ingresses", "networking.k8s.io", "ingresses", "", "get", "list", "watch")
+		add("read TLS Secrets", "", "secrets", "", "get")
+		if !cfg.DryRun && !cfg.Shadow {
+			add("add owner references to TLS Secrets", "", "secrets", "", "update")
+		}
+	}
+	if cfg.SecretsEnabled {
+		add("read Secrets", "", "secrets", "", "get")
+		if !cfg.DryRun && !cfg.Shadow {
+			add("add owner references to Secrets", "", "secrets", "", "update")
+		}
+	}
+	if cfg.PodTemplates {
+		add("watch PodTemplates", "", "podtemplates", "", "get", "list", "watch")
+	}
+	if cfg.StatefulSets {
+		add("watch StatefulSets", "apps", "statefulsets", "", "get", "list", "watch")
+	}
+	if cfg.DaemonSets {
+		add("watch DaemonSets", "apps", "daemonsets", "", "get", "list", "watch")
+	}
+	// --cronjob-owner=job owns the ConfigMaps of CronJobs through the Jobs they spawn
+	if cfg.Jobs || cfg.CronJobs && cfg.CronJobOwner == "job" {
+		add("watch Jobs", "batch", "jobs", "", "get", "list", "watch")
+	}
+	if cfg.CronJobs && cfg.CronJobOwner != "job" {
+		add("watch CronJobs", "batch", "cronjobs", "", "get", "list", "watch")
+	}
+	if cfg.Pods {
+		add("watch Pods", "", "pods", "", "get", "list", "watch")
+	}
+	for _, kind := range cfg.CustomKinds {
+		// Without discovery, the plural resource is guessed from the kind
+		gvk, _, _ := strings.Cut(kind, "=")
+		if i := strings.LastIndex(gvk, "/"); i >= 0 {
+			gv, _ := schema.ParseGroupVersion(gvk[:i])
+			resource, _ := meta.UnsafeGuessKindToResource(gv.WithKind(gvk[i+1:]))
+			add("watch "+gvk[i+1:], resource.Group, resource.Resource, "", "get", "list", "watch")
+		}
+	}
+	if cfg.PrecomputeDeployments {
+		add("watch Deployments", "apps", "deployments", "", "get", "list", "watch")
+	}
+	if cfg.ConfigMapBindings {
+		add("ConfigMap bindings to Deployments", "apps", "deployments", "", "get", "list", "watch")
+	}
+	if cfg.SkipDormant {
+		add("skip paused Deployments", "apps", "deployments", "", "get", "list", "watch")
+	}
+	if cfg.OwnerTarget == "deployment" && cfg.DriftScanInterval > 0 {
+		add("drift scans of Deployment owners", "apps", "deployments", "", "list")
+	}
+	if cfg.KustomizeCleanupInterval > 0 && !cfg.DryRun && !cfg.Shadow {
+		add("delete superseded kustomize ConfigMaps", "", "configmaps", "", "delete")
+	}
+	if cfg.AnnotateWorkloads && !cfg.DryRun && !cfg.Shadow {
+		add("annotate workloads", "apps", "replicasets", "", "patch")
+		add("annotate workloads", "apps", "deployments", "", "get", "list", "watch", "patch")
+	}
+	if cfg.ControlConfigMap != "" {
+		namespace, _, _ := strings.Cut(cfg.ControlConfigMap, "/")
+		add("pause control", "", "configmaps", namespace, "create", "update")
+	}
+	return reqs
+}
+
+// Check runs a SelfSubjectAccessReview for every requirement and returns the denied ones
+func Check(ctx context.Context, clientset kubernetes.Interface, reqs []Requirement) ([]Requirement, error) {
+	var missing []Requirement
+	for _, req := range reqs {
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Group:       req.Group,
+					Resource:    req.Resource,
+					Subresource: req.Subresource,
+					Verb:        req.Verb,
+					Namespace:   req.Namespace,
+				},
+			},
+		}
+		result, err := clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("unable to review access for %s: %w", req, err)
+		}
+		if !result.Status.Allowed {
+			missing = append(missing, req)
+		}
+	}
+	return missing, nil
+}
+
+// Run checks the permissions needed by cfg, logs every missing permission and exports their count.
+// It reports whether the operator has every permission it needs; missing permissions degrade the
+// operator rather than stopping it, since unaffected features keep working.
+func Run(ctx context.Context, clientset kubernetes.Interface, cfg *config.OperatorConfig, logger logr.Logger) bool {
+	missing, err := Check(ctx, clientset, Requirements(cfg))
+	if err != nil {
+		logger.Error(err, "RBAC preflight check could not run")
+		return false
+	}
+	missingPermissions.Set(float64(len(missing)))
+	for _, req := range missing {
+		logger.Error(fmt.Errorf("permission denied"), "Missing RBAC permission, operator is degraded",
+			"verb", req.Verb, "group", req.Group, "resource", req.Resource, "subresource", req.Subresource,
+			"namespace", req.Namespace, "feature", req.Feature)
+	}
+	if len(missing) == 0 {
+		logger.Info("RBAC preflight check passed")
+	}
+	return len(missing) == 0
+}
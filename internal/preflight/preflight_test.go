@@ -0,0 +1,61 @@
+package preflight
+
+import (
+	"context"
+	"testing"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+)
+
+var _ = ginkgo.Describe("Preflight", func() {
+	ginkgo.It("should only require write permissions outside dry-run", func() {
+		writes := func(reqs []Requirement) int {
+			n := 0
+			for _, r := range reqs {
+				if r.Resource == "configmaps" && (r.Verb == "update" || r.Verb == "patch") {
+					n++
+				}
+			}
+			return n
+		}
+		gomega.Expect(writes(Requirements(&config.OperatorConfig{}))).To(gomega.Equal(2))
+		gomega.Expect(writes(Requirements(&config.OperatorConfig{DryRun: true}))).To(gomega.Equal(0))
+		gomega.Expect(writes(Requirements(&config.OperatorConfig{PatchOnly: true}))).To(gomega.Equal(1))
+	})
+
+	ginkgo.It("should require impersonating service accounts in their namespace", func() {
+		reqs := Requirements(&config.OperatorConfig{ImpersonateUser: "system:serviceaccount:team-a:writer"})
+		gomega.Expect(reqs).To(gomega.ContainElement(gomega.And(
+			gomega.HaveField("Resource", "serviceaccounts"),
+			gomega.HaveField("Namespace", "team-a"),
+			gomega.HaveField("Verb", "impersonate"),
+		)))
+	})
+
+	ginkgo.It("should report denied permissions", func() {
+		clientset := fake.NewSimpleClientset()
+		clientset.PrependReactor("create", "selfsubjectaccessreviews",
+			func(action k8stesting.Action) (bool, runtime.Object, error) {
+				review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+				review.Status.Allowed = review.Spec.ResourceAttributes.Resource != "events"
+				return true, review, nil
+			})
+
+		missing, err := Check(context.Background(), clientset, Requirements(&config.OperatorConfig{EventsEnabled: true}))
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(missing).To(gomega.HaveLen(2))
+		gomega.Expect(missing[0].Resource).To(gomega.Equal("events"))
+	})
+})
+
+func TestPreflight(t *testing.T) {
+	gomega.RegisterFailHandler(ginkgo.Fail)
+	ginkgo.RunSpecs(t, "Preflight Suite")
+}
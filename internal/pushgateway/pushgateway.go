@@ -0,0 +1,61 @@
+// Package pushgateway pushes the metrics of short-lived batch runs to a Prometheus Pushgateway,
+// since they finish before any scrape would see them.
+package pushgateway
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const metricsNamespace = "configmap_rs_operator"
+
+// timeout bounds a push, which also runs after the run itself was interrupted
+const timeout = 10 * time.Second
+
+// Push adds the operator's metrics and the outcome of the batch run that started at start to the group
+// of job on the Pushgateway at url. The metrics of earlier runs not pushed again are kept, so the last
+// success timestamp survives failed runs.
+func Push(ctx context.Context, url, job string, start time.Time, runErr error) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+	defer cancel()
+
+	duration := prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "batch_duration_seconds",
+		Help:      "Duration of the last batch run",
+	})
+	completion := prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "batch_last_completion_timestamp_seconds",
+		Help:      "Unix time the last batch run finished, successfully or not",
+	})
+	success := prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "batch_success",
+		Help:      "Whether the last batch run succeeded (1) or failed (0)",
+	})
+
+	now := time.Now()
+	duration.Set(now.Sub(start).Seconds())
+	completion.Set(float64(now.Unix()))
+	pusher := push.New(url, job).Gatherer(metrics.Registry).Collector(duration).Collector(completion).Collector(success)
+	if runErr == nil {
+		success.Set(1)
+		lastSuccess := prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "batch_last_success_timestamp_seconds",
+			Help:      "Unix time the last successful batch run finished",
+		})
+		lastSuccess.Set(float64(now.Unix()))
+		pusher = pusher.Collector(lastSuccess)
+	}
+	if err := pusher.AddContext(ctx); err != nil {
+		return fmt.Errorf("unable to push metrics to %s: %w", url, err)
+	}
+	return nil
+}
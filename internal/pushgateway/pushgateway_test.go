@@ -0,0 +1,72 @@
+package pushgateway
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+)
+
+var _ = ginkgo.Describe("Push", func() {
+	var (
+		server   *httptest.Server
+		requests chan *http.Request
+		bodies   chan string
+	)
+
+	ginkgo.BeforeEach(func() {
+		requests = make(chan *http.Request, 1)
+		bodies = make(chan string, 1)
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			requests <- r
+			bodies <- string(body)
+			w.WriteHeader(http.StatusOK)
+		}))
+	})
+
+	ginkgo.AfterEach(func() {
+		server.Close()
+	})
+
+	ginkgo.It("should add the outcome of a successful run to the job's group", func() {
+		err := Push(context.Background(), server.URL, "backfill", time.Now().Add(-time.Minute), nil)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+		r := <-requests
+		gomega.Expect(r.Method).To(gomega.Equal(http.MethodPost))
+		gomega.Expect(r.URL.Path).To(gomega.Equal("/metrics/job/backfill"))
+		body := <-bodies
+		gomega.Expect(body).To(gomega.ContainSubstring("configmap_rs_operator_batch_duration_seconds"))
+		gomega.Expect(body).To(gomega.ContainSubstring("configmap_rs_operator_batch_last_success_timestamp_seconds"))
+	})
+
+	ginkgo.It("should keep the last success timestamp of earlier runs when a run fails", func() {
+		err := Push(context.Background(), server.URL, "cleanup", time.Now(), errors.New("boom"))
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+		<-requests
+		body := <-bodies
+		gomega.Expect(body).To(gomega.ContainSubstring("configmap_rs_operator_batch_success"))
+		gomega.Expect(body).NotTo(gomega.ContainSubstring("configmap_rs_operator_batch_last_success_timestamp_seconds"))
+	})
+
+	ginkgo.It("should report a Pushgateway that rejects the push", func() {
+		server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		})
+		err := Push(context.Background(), server.URL, "report", time.Now(), nil)
+		gomega.Expect(err).To(gomega.HaveOccurred())
+	})
+})
+
+func TestPushgateway(t *testing.T) {
+	gomega.RegisterFailHandler(ginkgo.Fail)
+	ginkgo.RunSpecs(t, "Pushgateway Suite")
+}
@@ -0,0 +1,172 @@
+// Package schedule implements cron-like maintenance windows that gate the operator's writes.
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Window is a recurring period that opens whenever its cron expression matches and stays
+// open for Duration
+type Window struct {
+	minute, hour, dom, month, dow field
+	Duration                      time.Duration
+
+	// anyDay is set when the day-of-month or day-of-week field starts with "*", so the days are those matching
+	// both; otherwise, as in cron, a day matching either of them is enough
+	anyDay bool
+}
+
+// Schedule is a set of maintenance windows evaluated in a single location
+type Schedule struct {
+	Windows  []Window
+	Location *time.Location
+}
+
+// field is the set of values matched by one cron field
+type field map[int]bool
+
+// maxLookahead bounds the search for the next window opening
+const maxLookahead = 366 * 24 * time.Hour
+
+// Parse builds a Schedule from window specs of the form "<minute> <hour> <dom> <month> <dow> <duration>",
+// e.g. "0 2 * * 1-5 2h" opens at 02:00 on weekdays for two hours
+func Parse(specs []string, location *time.Location) (*Schedule, error) {
+	if location == nil {
+		location = time.UTC
+	}
+	s := &Schedule{Location: location}
+	for _, spec := range specs {
+		w, err := ParseWindow(spec)
+		if err != nil {
+			return nil, err
+		}
+		s.Windows = append(s.Windows, w)
+	}
+	return s, nil
+}
+
+// ParseWindow parses a single window spec
+func ParseWindow(spec string) (Window, error) {
+	parts := strings.Fields(spec)
+	if len(parts) != 6 {
+		return Window{}, fmt.Errorf("invalid maintenance window %q: expected 5 cron fields and a duration", spec)
+	}
+
+	var w Window
+	bounds := []struct {
+		target   *field
+		min, max int
+	}{
+		{&w.minute, 0, 59},
+		{&w.hour, 0, 23},
+		{&w.dom, 1, 31},
+		{&w.month, 1, 12},
+		{&w.dow, 0, 6},
+	}
+	for i, b := range bounds {
+		f, err := parseField(parts[i], b.min, b.max)
+		if err != nil {
+			return Window{}, fmt.Errorf("invalid maintenance window %q: %w", spec, err)
+		}
+		*b.target = f
+	}
+
+	w.anyDay = strings.HasPrefix(parts[2], "*") || strings.HasPrefix(parts[4], "*")
+
+	d, err := time.ParseDuration(parts[5])
+	if err != nil || d <= 0 {
+		return Window{}, fmt.Errorf("invalid maintenance window %q: bad duration %q", spec, parts[5])
+	}
+	w.Duration = d
+	return w, nil
+}
+
+// parseField parses a cron field supporting "*", lists, ranges and steps
+func parseField(expr string, minValue, maxValue int) (field, error) {
+	f := field{}
+	for _, part := range strings.Split(expr, ",") {
+		step := 1
+		if rangePart, stepPart, ok := strings.Cut(part, "/"); ok {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("bad step in %q", part)
+			}
+			part, step = rangePart, n
+		}
+
+		lo, hi := minValue, maxValue
+		if part != "*" {
+			from, to, isRange := strings.Cut(part, "-")
+			var err error
+			if lo, err = strconv.Atoi(from); err != nil {
+				return nil, fmt.Errorf("bad value in %q", expr)
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(to); err != nil {
+					return nil, fmt.Errorf("bad range in %q", expr)
+				}
+			}
+		}
+		if lo < minValue || hi > maxValue || lo > hi {
+			return nil, fmt.Errorf("value out of range [%d-%d] in %q", minValue, maxValue, expr)
+		}
+		for v := lo; v <= hi; v += step {
+			f[v] = true
+		}
+	}
+	return f, nil
+}
+
+// opensAt reports whether the window opens at the minute t
+func (w Window) opensAt(t time.Time) bool {
+	day := w.dom[t.Day()] && w.dow[int(t.Weekday())]
+	if !w.anyDay {
+		day = w.dom[t.Day()] || w.dow[int(t.Weekday())]
+	}
+	return day && w.minute[t.Minute()] && w.hour[t.Hour()] && w.month[int(t.Month())]
+}
+
+// Active reports whether the window is open at t
+func (w Window) Active(t time.Time) bool {
+	start := t.Truncate(time.Minute)
+	for offset := time.Duration(0); offset < w.Duration; offset += time.Minute {
+		if w.opensAt(start.Add(-offset)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Active reports whether writes are allowed at t. A nil or empty schedule is always open.
+func (s *Schedule) Active(t time.Time) bool {
+	if s == nil || len(s.Windows) == 0 {
+		return true
+	}
+	t = t.In(s.Location)
+	for _, w := range s.Windows {
+		if w.Active(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// Next returns the next time at or after t when a window is open
+func (s *Schedule) Next(t time.Time) time.Time {
+	if s.Active(t) {
+		return t
+	}
+	candidate := t.In(s.Location).Truncate(time.Minute).Add(time.Minute)
+	for end := t.Add(maxLookahead); candidate.Before(end); candidate = candidate.Add(time.Minute) {
+		for _, w := range s.Windows {
+			if w.opensAt(candidate) {
+				return candidate
+			}
+		}
+	}
+	return t.Add(maxLookahead)
+}
@@ -0,0 +1,92 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+)
+
+var _ = ginkgo.Describe("Schedule", func() {
+	// 2025-06-02 is a Monday
+	at := func(value string) time.Time {
+		t, err := time.Parse(time.RFC3339, value)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		return t
+	}
+
+	ginkgo.Describe("Parse", func() {
+		ginkgo.It("should reject specs with the wrong number of fields", func() {
+			_, err := Parse([]string{"0 2 * * 2h"}, nil)
+			gomega.Expect(err).To(gomega.HaveOccurred())
+		})
+
+		ginkgo.It("should reject out of range values", func() {
+			_, err := Parse([]string{"0 25 * * * 1h"}, nil)
+			gomega.Expect(err).To(gomega.HaveOccurred())
+		})
+
+		ginkgo.It("should reject invalid durations", func() {
+			_, err := Parse([]string{"0 2 * * * soon"}, nil)
+			gomega.Expect(err).To(gomega.HaveOccurred())
+		})
+	})
+
+	ginkgo.Describe("Active", func() {
+		ginkgo.It("should always be open without windows", func() {
+			var s *Schedule
+			gomega.Expect(s.Active(time.Now())).To(gomega.BeTrue())
+		})
+
+		ginkgo.It("should be open only inside the window", func() {
+			s, err := Parse([]string{"0 2 * * 1-5 2h"}, time.UTC)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+			gomega.Expect(s.Active(at("2025-06-02T01:59:00Z"))).To(gomega.BeFalse())
+			gomega.Expect(s.Active(at("2025-06-02T02:00:00Z"))).To(gomega.BeTrue())
+			gomega.Expect(s.Active(at("2025-06-02T03:59:59Z"))).To(gomega.BeTrue())
+			gomega.Expect(s.Active(at("2025-06-02T04:00:00Z"))).To(gomega.BeFalse())
+			// Sunday is excluded by the day-of-week field
+			gomega.Expect(s.Active(at("2025-06-01T02:30:00Z"))).To(gomega.BeFalse())
+		})
+
+		ginkgo.It("should support lists and steps", func() {
+			s, err := Parse([]string{"*/15 9,17 * * * 5m"}, time.UTC)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+			gomega.Expect(s.Active(at("2025-06-02T09:31:00Z"))).To(gomega.BeTrue())
+			gomega.Expect(s.Active(at("2025-06-02T17:50:00Z"))).To(gomega.BeFalse())
+		})
+
+		ginkgo.It("should match either restricted day field, as cron does", func() {
+			// The 1st of the month or any Sunday
+			s, err := Parse([]string{"0 2 1 * 0 1h"}, time.UTC)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+			gomega.Expect(s.Active(at("2025-07-01T02:30:00Z"))).To(gomega.BeTrue(), "Tuesday the 1st")
+			gomega.Expect(s.Active(at("2025-06-08T02:30:00Z"))).To(gomega.BeTrue(), "Sunday the 8th")
+			gomega.Expect(s.Active(at("2025-06-09T02:30:00Z"))).To(gomega.BeFalse(), "Monday the 9th")
+
+			// As in cron, a day field starting with "*" narrows the other one down instead
+			s, err = Parse([]string{"0 2 */2 * 0 1h"}, time.UTC)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(s.Active(at("2025-06-01T02:30:00Z"))).To(gomega.BeTrue(), "Sunday the 1st")
+			gomega.Expect(s.Active(at("2025-06-08T02:30:00Z"))).To(gomega.BeFalse(), "Sunday the 8th")
+		})
+	})
+
+	ginkgo.Describe("Next", func() {
+		ginkgo.It("should return the next window opening", func() {
+			s, err := Parse([]string{"0 2 * * 1-5 2h"}, time.UTC)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+			gomega.Expect(s.Next(at("2025-06-01T10:00:00Z"))).To(gomega.Equal(at("2025-06-02T02:00:00Z")))
+		})
+	})
+})
+
+func TestSchedule(t *testing.T) {
+	gomega.RegisterFailHandler(ginkgo.Fail)
+	ginkgo.RunSpecs(t, "Schedule Suite")
+}
@@ -0,0 +1,192 @@
+// Package state persists the operator's bookkeeping, such as backfill checkpoints and the drifts already reported,
+// so HA replicas and restarts share it instead of each pod keeping its own copy in memory.
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Kinds of store backends
+const (
+	KindConfigMap = "configmap"
+	KindLease     = "lease"
+)
+
+// leaseAnnotationPrefix prefixes the annotations a Lease store keeps its values in
+const leaseAnnotationPrefix = "state.configmap-rs-operator/"
+
+// Store persists JSON values by key. Keys are short names such as "backfill", valid as ConfigMap keys.
+type Store interface {
+	// Load unmarshals the value of key into v, and returns false if nothing was saved under key
+	Load(ctx context.Context, key string, v any) (bool, error)
+
+	// Save marshals v and stores it under key
+	Save(ctx context.Context, key string, v any) error
+}
+
+// New returns the store given as "<kind>:<namespace>/<name>", where kind is configmap or lease. An empty ref
+// returns an in-memory store, which doesn't survive restarts. Reads go through reader, which should be uncached
+// so a new leader sees what the previous one saved.
+func New(c client.Client, reader client.Reader, ref string) (Store, error) {
+	if ref == "" {
+		return &MemoryStore{}, nil
+	}
+	kind, object, _ := strings.Cut(ref, ":")
+	namespace, name, ok := strings.Cut(object, "/")
+	if !ok || namespace == "" || name == "" {
+		return nil, fmt.Errorf("invalid state store %q: expected <kind>:<namespace>/<name>", ref)
+	}
+	key := types.NamespacedName{Namespace: namespace, Name: name}
+	switch kind {
+	case KindConfigMap:
+		return &ConfigMapStore{Client: c, Reader: reader, Key: key}, nil
+	case KindLease:
+		return &LeaseStore{Client: c, Reader: reader, Key: key}, nil
+	}
+	return nil, fmt.Errorf("invalid state store kind %q: expected %s or %s", kind, KindConfigMap, KindLease)
+}
+
+// MemoryStore keeps the values in memory
+type MemoryStore struct {
+	mu     sync.Mutex
+	values map[string][]byte
+}
+
+// Load implements Store
+func (s *MemoryStore) Load(_ context.Context, key string, v any) (bool, error) {
+	s.mu.Lock()
+	data, ok := s.values[key]
+	s.mu.Unlock()
+	if !ok {
+		return false, nil
+	}
+	return true, json.Unmarshal(data, v)
+}
+
+// Save implements Store
+func (s *MemoryStore) Save(_ context.Context, key string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.values == nil {
+		s.values = map[string][]byte{}
+	}
+	s.values[key] = data
+	return nil
+}
+
+// ConfigMapStore keeps each value in a data key of a ConfigMap, created on the first save
+type ConfigMapStore struct {
+	Client client.Client
+	Reader client.Reader
+	Key    types.NamespacedName
+}
+
+// Load implements Store
+func (s *ConfigMapStore) Load(ctx context.Context, key string, v any) (bool, error) {
+	var cm corev1.ConfigMap
+	if err := s.Reader.Get(ctx, s.Key, &cm); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	data, ok := cm.Data[key]
+	if !ok {
+		return false, nil
+	}
+	if err := json.Unmarshal([]byte(data), v); err != nil {
+		return false, fmt.Errorf("invalid state %s in ConfigMap %s: %w", key, s.Key, err)
+	}
+	return true, nil
+}
+
+// Save implements Store
+func (s *ConfigMapStore) Save(ctx context.Context, key string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	var cm corev1.ConfigMap
+	err = s.Reader.Get(ctx, s.Key, &cm)
+	if errors.IsNotFound(err) {
+		cm = corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: s.Key.Namespace, Name: s.Key.Name},
+			Data:       map[string]string{key: string(data)},
+		}
+		return s.Client.Create(ctx, &cm)
+	}
+	if err != nil {
+		return err
+	}
+	// A patch rather than an update, which --patch-only doesn't grant; the lock keeps the conflict of an update
+	patch := client.MergeFromWithOptions(cm.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	if cm.Data == nil {
+		cm.Data = map[string]string{}
+	}
+	cm.Data[key] = string(data)
+	return s.Client.Patch(ctx, &cm, patch)
+}
+
+// LeaseStore keeps each value in an annotation of a Lease, created on the first save. Leases are small and
+// namespaced, so the store fits where the operator may only write its own namespace, e.g. next to its leader
+// election lease.
+type LeaseStore struct {
+	Client client.Client
+	Reader client.Reader
+	Key    types.NamespacedName
+}
+
+// Load implements Store
+func (s *LeaseStore) Load(ctx context.Context, key string, v any) (bool, error) {
+	var lease coordinationv1.Lease
+	if err := s.Reader.Get(ctx, s.Key, &lease); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	data, ok := lease.Annotations[leaseAnnotationPrefix+key]
+	if !ok {
+		return false, nil
+	}
+	if err := json.Unmarshal([]byte(data), v); err != nil {
+		return false, fmt.Errorf("invalid state %s in Lease %s: %w", key, s.Key, err)
+	}
+	return true, nil
+}
+
+// Save implements Store
+func (s *LeaseStore) Save(ctx context.Context, key string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var lease coordinationv1.Lease
+	err = s.Reader.Get(ctx, s.Key, &lease)
+	if errors.IsNotFound(err) {
+		lease = coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: s.Key.Namespace, Name: s.Key.Name,
+				Annotations: map[string]string{leaseAnnotationPrefix + key: string(data)},
+			},
+		}
+		return s.Client.Create(ctx, &lease)
+	}
+	if err != nil {
+		return err
+	}
+	if lease.Annotations == nil {
+		lease.Annotations = map[string]string{}
+	}
+	lease.Annotations[leaseAnnotationPrefix+key] = string(data)
+	return s.Client.Update(ctx, &lease)
+}
@@ -0,0 +1,51 @@
+package state
+
+import (
+	"context"
+	"testing"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type progress struct {
+	Completed []string `json:"completed"`
+}
+
+var _ = ginkgo.Describe("Store", func() {
+	ctx := context.Background()
+
+	ginkgo.It("should reject malformed references", func() {
+		for _, ref := range []string{"configmap:no-namespace", "secret:ops/state", "ops/state"} {
+			_, err := New(nil, nil, ref)
+			gomega.Expect(err).To(gomega.HaveOccurred(), ref)
+		}
+	})
+
+	for _, ref := range []string{"", "configmap:ops/state", "lease:ops/state"} {
+		ginkgo.It("should load what was saved with "+ref, func() {
+			c := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+			s, err := New(c, c, ref)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+			var loaded progress
+			found, err := s.Load(ctx, "backfill", &loaded)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(found).To(gomega.BeFalse())
+
+			gomega.Expect(s.Save(ctx, "backfill", progress{Completed: []string{"a"}})).To(gomega.Succeed())
+			gomega.Expect(s.Save(ctx, "drifts", []string{"x"})).To(gomega.Succeed())
+			found, err = s.Load(ctx, "backfill", &loaded)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(found).To(gomega.BeTrue())
+			gomega.Expect(loaded.Completed).To(gomega.Equal([]string{"a"}))
+		})
+	}
+})
+
+func TestState(t *testing.T) {
+	gomega.RegisterFailHandler(ginkgo.Fail)
+	ginkgo.RunSpecs(t, "State Suite")
+}
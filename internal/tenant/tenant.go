@@ -0,0 +1,207 @@
+// Package tenant performs writes in tenant namespaces with the tenants' own service account credentials,
+// so that the API server authorizes them against the tenant's RBAC and audit logs attribute them to the tenant.
+package tenant
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// AllNamespaces is the account mapping key that applies to every namespace without an explicit entry
+const AllNamespaces = "*"
+
+// defaultTokenTTL is the lifetime requested for tenant tokens
+const defaultTokenTTL = time.Hour
+
+// Writer routes every write to the client of the tenant owning the object's namespace.
+// Namespaces without a tenant service account are written through Fallback.
+type Writer struct {
+	// Config is the operator's REST config; only its server address and TLS settings are reused
+	Config *rest.Config
+
+	// Clientset requests tokens for the tenant service accounts
+	Clientset kubernetes.Interface
+
+	// Scheme is used by the tenant clients
+	Scheme *runtime.Scheme
+
+	// Accounts maps a namespace to the name of the service account used for writes in it.
+	// The AllNamespaces key applies to every namespace without an explicit entry.
+	Accounts map[string]string
+
+	// Fallback performs writes in namespaces without a tenant service account
+	Fallback client.Writer
+
+	// TokenTTL is the lifetime requested for tenant tokens (default: one hour)
+	TokenTTL time.Duration
+
+	mu      sync.Mutex
+	clients map[string]client.Client
+}
+
+// Account returns the service account used for writes in namespace, if any
+func (w *Writer) Account(namespace string) (string, bool) {
+	if name, ok := w.Accounts[namespace]; ok {
+		return name, name != ""
+	}
+	name, ok := w.Accounts[AllNamespaces]
+	return name, ok && name != ""
+}
+
+// For returns the writer for namespace
+func (w *Writer) For(namespace string) (client.Writer, error) {
+	account, ok := w.Account(namespace)
+	if !ok {
+		if w.Fallback == nil {
+			return nil, fmt.Errorf("no credentials configured for namespace %s", namespace)
+		}
+		return w.Fallback, nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if c, ok := w.clients[namespace]; ok {
+		return c, nil
+	}
+	c, err := client.New(w.tenantConfig(namespace, account), client.Options{Scheme: w.Scheme})
+	if err != nil {
+		return nil, fmt.Errorf("unable to create client for service account %s/%s: %w", namespace, account, err)
+	}
+	if w.clients == nil {
+		w.clients = make(map[string]client.Client)
+	}
+	w.clients[namespace] = c
+	return c, nil
+}
+
+// tenantConfig returns a REST config that authenticates with short-lived tokens of the service account
+func (w *Writer) tenantConfig(namespace, account string) *rest.Config {
+	ttl := w.TokenTTL
+	if ttl <= 0 {
+		ttl = defaultTokenTTL
+	}
+	source := &TokenSource{Clientset: w.Clientset, Namespace: namespace, Name: account, TTL: ttl}
+
+	cfg := rest.AnonymousClientConfig(w.Config)
+	cfg.WrapTransport = func(rt http.RoundTripper) http.RoundTripper {
+		return &bearerRoundTripper{source: source, next: rt}
+	}
+	return cfg
+}
+
+// Create implements client.Writer
+func (w *Writer) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	c, err := w.For(obj.GetNamespace())
+	if err != nil {
+		return err
+	}
+	return c.Create(ctx, obj, opts...)
+}
+
+// Delete implements client.Writer
+func (w *Writer) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	c, err := w.For(obj.GetNamespace())
+	if err != nil {
+		return err
+	}
+	return c.Delete(ctx, obj, opts...)
+}
+
+// Update implements client.Writer
+func (w *Writer) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	c, err := w.For(obj.GetNamespace())
+	if err != nil {
+		return err
+	}
+	return c.Update(ctx, obj, opts...)
+}
+
+// Patch implements client.Writer
+func (w *Writer) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	c, err := w.For(obj.GetNamespace())
+	if err != nil {
+		return err
+	}
+	return c.Patch(ctx, obj, patch, opts...)
+}
+
+// DeleteAllOf implements client.Writer, routing by the namespace given in the options
+func (w *Writer) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	var options client.DeleteAllOfOptions
+	options.ApplyOptions(opts)
+	c, err := w.For(options.Namespace)
+	if err != nil {
+		return err
+	}
+	return c.DeleteAllOf(ctx, obj, opts...)
+}
+
+// TokenSource issues and caches bound tokens for a service account through the TokenRequest API
+type TokenSource struct {
+	Clientset kubernetes.Interface
+	Namespace string
+	Name      string
+	TTL       time.Duration
+
+	mu      sync.Mutex
+	token   string
+	refresh time.Time
+}
+
+// Token returns a cached token, requesting a new one once 80% of its lifetime has passed
+func (s *TokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if s.token != "" && now.Before(s.refresh) {
+		return s.token, nil
+	}
+
+	expiration := int64(s.TTL.Seconds())
+	request := &authenticationv1.TokenRequest{
+		Spec: authenticationv1.TokenRequestSpec{ExpirationSeconds: &expiration},
+	}
+	result, err := s.Clientset.CoreV1().ServiceAccounts(s.Namespace).
+		CreateToken(ctx, s.Name, request, metav1.CreateOptions{})
+	if err != nil {
+		return "", fmt.Errorf("unable to request token for service account %s/%s: %w", s.Namespace, s.Name, err)
+	}
+
+	lifetime := s.TTL
+	if !result.Status.ExpirationTimestamp.IsZero() {
+		lifetime = result.Status.ExpirationTimestamp.Sub(now)
+	}
+	s.token = result.Status.Token
+	s.refresh = now.Add(lifetime * 4 / 5)
+	return s.token, nil
+}
+
+// bearerRoundTripper authenticates every request with a token from source
+type bearerRoundTripper struct {
+	source *TokenSource
+	next   http.RoundTripper
+}
+
+func (rt *bearerRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := rt.source.Token(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	req = utilnet.CloneRequest(req)
+	req.Header.Set("Authorization", "Bearer "+token)
+	return rt.next.RoundTrip(req)
+}
+
+func (rt *bearerRoundTripper) WrappedRoundTripper() http.RoundTripper { return rt.next }
@@ -0,0 +1,101 @@
+package tenant
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// tokenClientset returns a clientset that issues "<namespace>-<name>" tokens and counts the requests
+func tokenClientset(requests *int) *kubefake.Clientset {
+	clientset := kubefake.NewSimpleClientset()
+	clientset.PrependReactor("create", "serviceaccounts",
+		func(action k8stesting.Action) (bool, runtime.Object, error) {
+			if action.GetSubresource() != "token" {
+				return false, nil, nil
+			}
+			*requests++
+			request := action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenRequest)
+			request.Status.Token = action.GetNamespace() + "-" + action.(k8stesting.CreateActionImpl).Name
+			return true, request, nil
+		})
+	return clientset
+}
+
+var _ = ginkgo.Describe("Writer", func() {
+	ctx := context.Background()
+
+	ginkgo.It("should resolve explicit accounts before the wildcard", func() {
+		w := &Writer{Accounts: map[string]string{"team-a": "writer-a", AllNamespaces: "writer", "opted-out": ""}}
+
+		account, ok := w.Account("team-a")
+		gomega.Expect(ok).To(gomega.BeTrue())
+		gomega.Expect(account).To(gomega.Equal("writer-a"))
+
+		account, ok = w.Account("team-b")
+		gomega.Expect(ok).To(gomega.BeTrue())
+		gomega.Expect(account).To(gomega.Equal("writer"))
+
+		_, ok = w.Account("opted-out")
+		gomega.Expect(ok).To(gomega.BeFalse())
+	})
+
+	ginkgo.It("should write through the fallback in namespaces without an account", func() {
+		fallback := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+		w := &Writer{Accounts: map[string]string{"team-a": "writer-a"}, Fallback: fallback}
+
+		cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cfg", Namespace: "shared"}}
+		gomega.Expect(w.Create(ctx, cm)).To(gomega.Succeed())
+		gomega.Expect(fallback.Get(ctx, client.ObjectKeyFromObject(cm), &corev1.ConfigMap{})).To(gomega.Succeed())
+	})
+
+	ginkgo.It("should fail without an account or fallback", func() {
+		w := &Writer{}
+		_, err := w.For("team-a")
+		gomega.Expect(err).To(gomega.HaveOccurred())
+	})
+
+	ginkgo.It("should authenticate tenant requests with the tenant's token", func() {
+		var header string
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			header = req.Header.Get("Authorization")
+		}))
+		defer server.Close()
+
+		requests := 0
+		w := &Writer{
+			Config:    &rest.Config{Host: server.URL, BearerToken: "operator-token"},
+			Clientset: tokenClientset(&requests),
+			Accounts:  map[string]string{"team-a": "writer-a"},
+		}
+		httpClient, err := rest.HTTPClientFor(w.tenantConfig("team-a", "writer-a"))
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+		for range 2 {
+			resp, err := httpClient.Get(server.URL)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(resp.Body.Close()).To(gomega.Succeed())
+			gomega.Expect(header).To(gomega.Equal("Bearer team-a-writer-a"))
+		}
+		gomega.Expect(requests).To(gomega.Equal(1), "the token should be cached")
+	})
+})
+
+func TestTenant(t *testing.T) {
+	gomega.RegisterFailHandler(ginkgo.Fail)
+	ginkgo.RunSpecs(t, "Tenant Suite")
+}
@@ -0,0 +1,122 @@
+// Package webhookcert implements a health check for the webhook serving certificate.
+package webhookcert
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// +kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=validatingwebhookconfigurations;mutatingwebhookconfigurations,verbs=get
+
+// Checker verifies that the webhook serving certificate is inside its validity window,
+// is not about to expire and is trusted by the CA bundles of the webhook configurations
+type Checker struct {
+	// CertFile is the path of the PEM encoded serving certificate
+	CertFile string
+
+	// MinValidity is the minimum remaining validity before the check fails
+	MinValidity time.Duration
+
+	// Reader reads the webhook configurations
+	Reader client.Reader
+
+	// Configurations lists the webhook configurations as "validating/<name>" or "mutating/<name>"
+	Configurations []string
+}
+
+// Check implements healthz.Checker
+func (c *Checker) Check(req *http.Request) error {
+	cert, err := c.loadCertificate()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if now.Before(cert.NotBefore) {
+		return fmt.Errorf("webhook certificate is not valid before %s", cert.NotBefore.Format(time.RFC3339))
+	}
+	if remaining := cert.NotAfter.Sub(now); remaining < c.MinValidity {
+		return fmt.Errorf("webhook certificate expires at %s (in %s)", cert.NotAfter.Format(time.RFC3339),
+			remaining.Round(time.Second))
+	}
+
+	for _, ref := range c.Configurations {
+		bundles, err := c.caBundles(req.Context(), ref)
+		if err != nil {
+			return err
+		}
+		for i, bundle := range bundles {
+			if err := verify(cert, bundle); err != nil {
+				return fmt.Errorf("CA bundle of webhook %d in %s does not match the serving certificate: %w", i, ref, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (c *Checker) loadCertificate() (*x509.Certificate, error) {
+	data, err := os.ReadFile(c.CertFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read webhook certificate: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data in webhook certificate %s", c.CertFile)
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// caBundles returns the CA bundle of every webhook in the referenced configuration
+func (c *Checker) caBundles(ctx context.Context, ref string) ([][]byte, error) {
+	kind, name, ok := strings.Cut(ref, "/")
+	if !ok {
+		return nil, fmt.Errorf("invalid webhook configuration %q: expected validating/<name> or mutating/<name>", ref)
+	}
+
+	var bundles [][]byte
+	key := types.NamespacedName{Name: name}
+	switch kind {
+	case "validating":
+		var cfg admissionregistrationv1.ValidatingWebhookConfiguration
+		if err := c.Reader.Get(ctx, key, &cfg); err != nil {
+			return nil, fmt.Errorf("unable to get %s: %w", ref, err)
+		}
+		for _, w := range cfg.Webhooks {
+			bundles = append(bundles, w.ClientConfig.CABundle)
+		}
+	case "mutating":
+		var cfg admissionregistrationv1.MutatingWebhookConfiguration
+		if err := c.Reader.Get(ctx, key, &cfg); err != nil {
+			return nil, fmt.Errorf("unable to get %s: %w", ref, err)
+		}
+		for _, w := range cfg.Webhooks {
+			bundles = append(bundles, w.ClientConfig.CABundle)
+		}
+	default:
+		return nil, fmt.Errorf("invalid webhook configuration kind %q in %q", kind, ref)
+	}
+	return bundles, nil
+}
+
+// verify checks that cert chains up to a CA in bundle
+func verify(cert *x509.Certificate, bundle []byte) error {
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(bundle) {
+		return fmt.Errorf("CA bundle contains no certificates")
+	}
+	_, err := cert.Verify(x509.VerifyOptions{
+		Roots:     pool,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	return err
+}
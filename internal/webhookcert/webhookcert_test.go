@@ -0,0 +1,123 @@
+package webhookcert
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// newCA returns a self-signed CA certificate, its key and its PEM encoding
+func newCA() (*x509.Certificate, *ecdsa.PrivateKey, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	gomega.Expect(err).NotTo(gomega.HaveOccurred())
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(365 * 24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	gomega.Expect(err).NotTo(gomega.HaveOccurred())
+	cert, err := x509.ParseCertificate(der)
+	gomega.Expect(err).NotTo(gomega.HaveOccurred())
+	return cert, key, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+// writeServingCert writes a serving certificate signed by the CA and valid for validity
+func writeServingCert(dir string, ca *x509.Certificate, caKey *ecdsa.PrivateKey, validity time.Duration) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	gomega.Expect(err).NotTo(gomega.HaveOccurred())
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "webhook"},
+		DNSNames:     []string{"webhook.svc"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(validity),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca, &key.PublicKey, caKey)
+	gomega.Expect(err).NotTo(gomega.HaveOccurred())
+	path := filepath.Join(dir, "tls.crt")
+	gomega.Expect(os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600)).
+		To(gomega.Succeed())
+	return path
+}
+
+var _ = ginkgo.Describe("Checker", func() {
+	var (
+		ca    *x509.Certificate
+		caKey *ecdsa.PrivateKey
+		caPEM []byte
+		dir   string
+	)
+
+	ginkgo.BeforeEach(func() {
+		ca, caKey, caPEM = newCA()
+		dir = ginkgo.GinkgoT().TempDir()
+	})
+
+	webhookConfig := func(bundle []byte) *admissionregistrationv1.ValidatingWebhookConfiguration {
+		return &admissionregistrationv1.ValidatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: "operator"},
+			Webhooks: []admissionregistrationv1.ValidatingWebhook{{
+				Name:         "validate.example.com",
+				ClientConfig: admissionregistrationv1.WebhookClientConfig{CABundle: bundle},
+			}},
+		}
+	}
+
+	ginkgo.It("should pass for a valid certificate trusted by the CA bundle", func() {
+		checker := &Checker{
+			CertFile:       writeServingCert(dir, ca, caKey, 30*24*time.Hour),
+			MinValidity:    72 * time.Hour,
+			Reader:         fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(webhookConfig(caPEM)).Build(),
+			Configurations: []string{"validating/operator"},
+		}
+		gomega.Expect(checker.Check(httptest.NewRequest("GET", "/healthz", nil))).To(gomega.Succeed())
+	})
+
+	ginkgo.It("should fail when the certificate is about to expire", func() {
+		checker := &Checker{
+			CertFile:    writeServingCert(dir, ca, caKey, time.Hour),
+			MinValidity: 72 * time.Hour,
+		}
+		gomega.Expect(checker.Check(httptest.NewRequest("GET", "/healthz", nil))).
+			To(gomega.MatchError(gomega.ContainSubstring("expires")))
+	})
+
+	ginkgo.It("should fail when the CA bundle does not match", func() {
+		_, _, otherPEM := newCA()
+		checker := &Checker{
+			CertFile:       writeServingCert(dir, ca, caKey, 30*24*time.Hour),
+			MinValidity:    72 * time.Hour,
+			Reader:         fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(webhookConfig(otherPEM)).Build(),
+			Configurations: []string{"validating/operator"},
+		}
+		gomega.Expect(checker.Check(httptest.NewRequest("GET", "/healthz", nil))).
+			To(gomega.MatchError(gomega.ContainSubstring("does not match")))
+	})
+})
+
+func TestWebhookCert(t *testing.T) {
+	gomega.RegisterFailHandler(ginkgo.Fail)
+	ginkgo.RunSpecs(t, "WebhookCert Suite")
+}
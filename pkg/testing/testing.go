@@ -0,0 +1,130 @@
+// Package testing provides fixtures, a fake client and ownership assertions for testing code that builds on the
+// operator, such as policies or tools reading its owner references, against the semantics the operator uses.
+package testing
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	gotesting "testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/matanbaruch/configmap-rs-operator/internal/config"
+	"github.com/matanbaruch/configmap-rs-operator/internal/controller"
+)
+
+// NewFakeClient returns a fake client holding objs, with the client-go scheme the operator uses
+func NewFakeClient(objs ...client.Object) client.WithWatch {
+	return fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objs...).Build()
+}
+
+// ConfigMap returns an empty ConfigMap
+func ConfigMap(name, namespace string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+}
+
+// ConfigMaps returns n empty ConfigMaps named prefix-0 to prefix-<n-1>
+func ConfigMaps(prefix, namespace string, n int) []*corev1.ConfigMap {
+	configMaps := make([]*corev1.ConfigMap, n)
+	for i := range configMaps {
+		configMaps[i] = ConfigMap(fmt.Sprintf("%s-%d", prefix, i), namespace)
+	}
+	return configMaps
+}
+
+// Names returns the names of objs, e.g. to mount ConfigMaps built by ConfigMaps
+func Names[T client.Object](objs []T) []string {
+	names := make([]string, len(objs))
+	for i, obj := range objs {
+		names[i] = obj.GetName()
+	}
+	return names
+}
+
+// PodSpec returns a pod spec with one container mounting each of configMaps as a volume
+func PodSpec(configMaps ...string) corev1.PodSpec {
+	spec := corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Image: "app"}}}
+	for _, name := range configMaps {
+		spec.Volumes = append(spec.Volumes, corev1.Volume{
+			Name: name,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: name}},
+			},
+		})
+		spec.Containers[0].VolumeMounts = append(spec.Containers[0].VolumeMounts,
+			corev1.VolumeMount{Name: name, MountPath: "/etc/" + name})
+	}
+	return spec
+}
+
+// ReplicaSet returns a ReplicaSet mounting configMaps. Its UID is <name>-uid, since fake clients don't assign UIDs.
+func ReplicaSet(name, namespace string, configMaps ...string) *appsv1.ReplicaSet {
+	labels := map[string]string{"app": name}
+	return &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, UID: types.UID(name + "-uid")},
+		Spec: appsv1.ReplicaSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec:       PodSpec(configMaps...),
+			},
+		},
+	}
+}
+
+// Reconcile runs the operator's ReplicaSet reconciler once for the ReplicaSet key against c, with the
+// default configuration
+func Reconcile(ctx context.Context, c client.Client, key types.NamespacedName) error {
+	r := &controller.ReplicaSetReconciler{Client: c, Scheme: c.Scheme(), Config: &config.OperatorConfig{}}
+	_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+	return err
+}
+
+// OwnedBy returns nil if the operator added owner as an owner of cm: cm has an owner reference to it and
+// records its UID as one the operator added. Otherwise it returns an error describing what is missing.
+func OwnedBy(cm *corev1.ConfigMap, owner client.Object) error {
+	hasRef := slices.ContainsFunc(cm.OwnerReferences, func(ref metav1.OwnerReference) bool {
+		return ref.UID == owner.GetUID() && ref.Name == owner.GetName()
+	})
+	if !hasRef {
+		return fmt.Errorf("ConfigMap %s/%s has no owner reference to %s (%s)",
+			cm.Namespace, cm.Name, owner.GetName(), owner.GetUID())
+	}
+	if !slices.Contains(controller.ManagedOwnerUIDs(cm), owner.GetUID()) {
+		return fmt.Errorf("ConfigMap %s/%s doesn't record the owner reference to %s (%s) as added by the operator",
+			cm.Namespace, cm.Name, owner.GetName(), owner.GetUID())
+	}
+	return nil
+}
+
+// ExpectOwnedBy fails t unless the ConfigMap key read from c is owned by owner, as OwnedBy checks
+func ExpectOwnedBy(t gotesting.TB, c client.Reader, key types.NamespacedName, owner client.Object) {
+	t.Helper()
+	var cm corev1.ConfigMap
+	if err := c.Get(context.Background(), key, &cm); err != nil {
+		t.Fatalf("unable to get ConfigMap %s: %v", key, err)
+	}
+	if err := OwnedBy(&cm, owner); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// ExpectNotOwned fails t if the ConfigMap key read from c carries any owner reference
+func ExpectNotOwned(t gotesting.TB, c client.Reader, key types.NamespacedName) {
+	t.Helper()
+	var cm corev1.ConfigMap
+	if err := c.Get(context.Background(), key, &cm); err != nil {
+		t.Fatalf("unable to get ConfigMap %s: %v", key, err)
+	}
+	if len(cm.OwnerReferences) > 0 {
+		t.Fatalf("ConfigMap %s has %d owner references, expected none", key, len(cm.OwnerReferences))
+	}
+}
@@ -0,0 +1,39 @@
+package testing_test
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	optesting "github.com/matanbaruch/configmap-rs-operator/pkg/testing"
+)
+
+func TestReconcileOwnsEveryMountedConfigMap(t *testing.T) {
+	configMaps := optesting.ConfigMaps("config", "default", 3)
+	rs := optesting.ReplicaSet("web", "default", optesting.Names(configMaps)...)
+	objs := []client.Object{rs, optesting.ConfigMap("unmounted", "default")}
+	for _, cm := range configMaps {
+		objs = append(objs, cm)
+	}
+	c := optesting.NewFakeClient(objs...)
+
+	if err := optesting.Reconcile(context.Background(), c, client.ObjectKeyFromObject(rs)); err != nil {
+		t.Fatal(err)
+	}
+	for _, cm := range configMaps {
+		optesting.ExpectOwnedBy(t, c, client.ObjectKeyFromObject(cm), rs)
+	}
+	optesting.ExpectNotOwned(t, c, types.NamespacedName{Namespace: "default", Name: "unmounted"})
+}
+
+func TestOwnedByRequiresProvenance(t *testing.T) {
+	rs := optesting.ReplicaSet("web", "default")
+	cm := optesting.ConfigMap("config", "default")
+	cm.OwnerReferences = []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: rs.Name, UID: rs.UID}}
+	if err := optesting.OwnedBy(cm, rs); err == nil {
+		t.Fatal("expected an owner reference without provenance not to count as owned by the operator")
+	}
+}